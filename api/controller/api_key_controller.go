@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/response/response_success"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyController struct {
+	APIKeyUsecase domain.APIKeyUsecase
+}
+
+// Create issues a new API key for a shop
+// @Summary Create API key
+// @Description Issue a shop-scoped API key for service-to-service calls. The key is returned only once.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param shopID path string true "Shop ID"
+// @Param payload body domain.CreateAPIKeyRequest true "Key name, scopes and expiry"
+// @Success 201 {object} domain.APIKeyCreated "API key created"
+// @Failure 400 {object} map[string]interface{} "Invalid payload"
+// @Failure 404 {object} map[string]interface{} "Shop not found"
+// @Security BearerAuth
+// @Router /shop/{shopID}/api-keys [post]
+func (ac *APIKeyController) Create(c *gin.Context) {
+	userID, shopID, ok := ac.params(c, "APIKeyController.Create")
+	if !ok {
+		return
+	}
+
+	var body domain.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid api key payload", errx.Op("APIKeyController.Create"), err))
+		return
+	}
+
+	created, err := ac.APIKeyUsecase.Create(c.Request.Context(), userID, shopID, body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("success create api key").Status("success").Data(created).Send(http.StatusCreated)
+}
+
+// List returns the API keys the authenticated user created for a shop
+// @Summary List API keys
+// @Description List API keys of a shop created by the authenticated user. Secrets are never returned.
+// @Tags API Keys
+// @Produce json
+// @Param shopID path string true "Shop ID"
+// @Success 200 {array} domain.APIKey "API keys"
+// @Security BearerAuth
+// @Router /shop/{shopID}/api-keys [get]
+func (ac *APIKeyController) List(c *gin.Context) {
+	userID, shopID, ok := ac.params(c, "APIKeyController.List")
+	if !ok {
+		return
+	}
+
+	keys, err := ac.APIKeyUsecase.List(c.Request.Context(), userID, shopID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("success retrieve api keys").Status("success").Data(keys).Send(http.StatusOK)
+}
+
+// Rotate replaces an API key
+// @Summary Rotate API key
+// @Description Issue a replacement key with the same scopes. The old key keeps working for a grace period.
+// @Tags API Keys
+// @Produce json
+// @Param shopID path string true "Shop ID"
+// @Param keyID path string true "API key ID"
+// @Success 201 {object} domain.APIKeyCreated "API key rotated"
+// @Failure 404 {object} map[string]interface{} "API key not found"
+// @Failure 412 {object} map[string]interface{} "API key no longer active"
+// @Security BearerAuth
+// @Router /shop/{shopID}/api-keys/{keyID}/rotate [post]
+func (ac *APIKeyController) Rotate(c *gin.Context) {
+	userID, shopID, ok := ac.params(c, "APIKeyController.Rotate")
+	if !ok {
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("keyID"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid api key ID", errx.Op("APIKeyController.Rotate"), err))
+		return
+	}
+
+	created, err := ac.APIKeyUsecase.Rotate(c.Request.Context(), userID, shopID, keyID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("success rotate api key").Status("success").Data(created).Send(http.StatusCreated)
+}
+
+// Revoke disables an API key immediately
+// @Summary Revoke API key
+// @Tags API Keys
+// @Produce json
+// @Param shopID path string true "Shop ID"
+// @Param keyID path string true "API key ID"
+// @Success 200 {object} map[string]interface{} "API key revoked"
+// @Failure 404 {object} map[string]interface{} "API key not found"
+// @Failure 409 {object} map[string]interface{} "API key already revoked"
+// @Security BearerAuth
+// @Router /shop/{shopID}/api-keys/{keyID} [delete]
+func (ac *APIKeyController) Revoke(c *gin.Context) {
+	userID, shopID, ok := ac.params(c, "APIKeyController.Revoke")
+	if !ok {
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("keyID"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid api key ID", errx.Op("APIKeyController.Revoke"), err))
+		return
+	}
+
+	if err := ac.APIKeyUsecase.Revoke(c.Request.Context(), userID, shopID, keyID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("success revoke api key").Status("success").Send(http.StatusOK)
+}
+
+func (ac *APIKeyController) params(c *gin.Context, op string) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op(op), err))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	shopID, err := uuid.Parse(c.Param("shopID"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid shop ID", errx.Op(op), err))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, shopID, true
+}
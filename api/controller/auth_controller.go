@@ -43,7 +43,7 @@ func (ac *AuthController) Register(c *gin.Context) {
 
 // Login authenticates a user and returns a JWT token
 // @Summary User login
-// @Description Authenticate user with email/phone and password, returns JWT access token
+// @Description Authenticate user with email/phone and password, returns JWT access token. Accounts with two-factor authentication receive a challenge token to exchange at /auth/login/2fa.
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -61,11 +61,16 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := ac.AuthUsecase.Login(c.Request.Context(), payload)
+	result, err := ac.AuthUsecase.Login(c.Request.Context(), payload)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	response_success.JSON(c).Msg("Login successful").Status("success").Data(gin.H{"access_token": token}).Send(http.StatusOK)
+	if result.TwoFactorRequired {
+		response_success.JSON(c).Msg("Two-factor authentication required").Status("success").Data(result).Send(http.StatusOK)
+		return
+	}
+
+	response_success.JSON(c).Msg("Login successful").Status("success").Data(result).Send(http.StatusOK)
 }
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	HealthUsecase domain.HealthUsecase
+}
+
+// Live reports that the process is running
+// @Summary Liveness probe
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Process is alive"
+// @Router /healthz [get]
+func (hc *HealthController) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": domain.HealthOK})
+}
+
+// Ready reports whether the service can take traffic
+// @Summary Readiness probe
+// @Description Check Postgres, the crypto heap database, the schema version and the stock release worker. Fails while shutting down.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} domain.HealthReport "Ready"
+// @Failure 503 {object} domain.HealthReport "Not ready"
+// @Router /readyz [get]
+func (hc *HealthController) Ready(c *gin.Context) {
+	report := hc.HealthUsecase.Ready(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != domain.HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
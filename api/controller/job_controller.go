@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/paginator"
+	"github.com/dyaksa/warehouse/pkg/response/response_success"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type JobController struct {
+	JobUsecase domain.JobUsecase
+}
+
+// List returns background jobs, newest first
+// @Summary List jobs
+// @Description List background jobs, optionally filtered by status and type. Dead jobs exhausted their retries.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param status query string false "Job status" Enums(PENDING, SUCCEEDED, DEAD, CANCELLED)
+// @Param type query string false "Job type"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} paginator.PaginationResult[domain.Job] "Jobs"
+// @Failure 400 {object} map[string]interface{} "Invalid query"
+// @Failure 401 {object} map[string]interface{} "Invalid admin token"
+// @Router /admin/jobs [get]
+func (jc *JobController) List(c *gin.Context) {
+	var filter domain.JobFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid job filter", errx.Op("JobController.List"), err))
+		return
+	}
+
+	var pagination paginator.PaginationRequest
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid pagination query", errx.Op("JobController.List"), err))
+		return
+	}
+
+	result, err := jc.JobUsecase.List(c.Request.Context(), filter, pagination)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("success retrieve jobs").Status("success").Data(result).Send(http.StatusOK)
+}
+
+// Retry runs a dead or cancelled job again
+// @Summary Retry job
+// @Description Requeue a dead or cancelled job to run now with a fresh retry budget.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param jobID path string true "Job ID"
+// @Success 200 {object} domain.Job "Requeued job"
+// @Failure 404 {object} map[string]interface{} "Job not found"
+// @Failure 412 {object} map[string]interface{} "Job is not dead or cancelled"
+// @Router /admin/jobs/{jobID}/retry [post]
+func (jc *JobController) Retry(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("jobID"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid job ID", errx.Op("JobController.Retry"), err))
+		return
+	}
+
+	job, err := jc.JobUsecase.Retry(c.Request.Context(), jobID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("job requeued").Status("success").Data(job).Send(http.StatusOK)
+}
+
+// Cancel stops a pending job from running
+// @Summary Cancel job
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param jobID path string true "Job ID"
+// @Success 200 {object} domain.Job "Cancelled job"
+// @Failure 404 {object} map[string]interface{} "Job not found"
+// @Failure 412 {object} map[string]interface{} "Job is not pending"
+// @Router /admin/jobs/{jobID}/cancel [post]
+func (jc *JobController) Cancel(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("jobID"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid job ID", errx.Op("JobController.Cancel"), err))
+		return
+	}
+
+	job, err := jc.JobUsecase.Cancel(c.Request.Context(), jobID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("job cancelled").Status("success").Data(job).Send(http.StatusOK)
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/response/response_success"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultLogLevelRevert = 15 * time.Minute
+
+type LogLevelController struct {
+	Levels *log.Levels
+}
+
+// Get returns the effective log levels
+// @Summary Get log levels
+// @Description Show the configured base level and any temporary overrides with their revert times.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Success 200 {object} log.LevelState "Log levels"
+// @Failure 401 {object} map[string]interface{} "Invalid admin token"
+// @Router /admin/log-level [get]
+func (lc *LogLevelController) Get(c *gin.Context) {
+	response_success.JSON(c).Msg("success retrieve log levels").Status("success").Data(lc.Levels.State()).Send(http.StatusOK)
+}
+
+// Set temporarily changes the log level
+// @Summary Set log level
+// @Description Change the level of the whole process or one module (order, transfer, worker). The previous level is restored automatically.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param payload body domain.SetLogLevelRequest true "Level, module and revert delay"
+// @Success 200 {object} log.LevelOverride "Override applied"
+// @Failure 400 {object} map[string]interface{} "Invalid payload"
+// @Failure 401 {object} map[string]interface{} "Invalid admin token"
+// @Router /admin/log-level [put]
+func (lc *LogLevelController) Set(c *gin.Context) {
+	var body domain.SetLogLevelRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid log level payload", errx.Op("LogLevelController.Set"), err))
+		return
+	}
+
+	level, err := log.ParseLevel(body.Level)
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid log level", errx.Op("LogLevelController.Set"), err))
+		return
+	}
+
+	revert := defaultLogLevelRevert
+	if body.RevertAfterMinutes > 0 {
+		revert = time.Duration(body.RevertAfterMinutes) * time.Minute
+	}
+
+	override, err := lc.Levels.Set(body.Module, level, revert)
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid log level override", errx.Op("LogLevelController.Set"), err))
+		return
+	}
+
+	response_success.JSON(c).Msg("success set log level").Status("success").Data(override).Send(http.StatusOK)
+}
+
+// Reset restores the configured log level
+// @Summary Reset log level
+// @Description Drop the override of a module, or the process-wide override when no module is given.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param module query string false "Module (order, transfer, worker)"
+// @Success 200 {object} log.LevelState "Log levels"
+// @Failure 401 {object} map[string]interface{} "Invalid admin token"
+// @Router /admin/log-level [delete]
+func (lc *LogLevelController) Reset(c *gin.Context) {
+	lc.Levels.Reset(c.Query("module"))
+
+	response_success.JSON(c).Msg("success reset log level").Status("success").Data(lc.Levels.State()).Send(http.StatusOK)
+}
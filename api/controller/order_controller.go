@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/dyaksa/warehouse/domain"
@@ -12,7 +14,8 @@ import (
 )
 
 type OrderController struct {
-	OrderUsecase domain.OrderUsecase
+	OrderUsecase   domain.OrderUsecase
+	PaymentUsecase domain.PaymentUsecase
 }
 
 func (oc *OrderController) Checkout(c *gin.Context) {
@@ -24,6 +27,11 @@ func (oc *OrderController) Checkout(c *gin.Context) {
 		return
 	}
 
+	if shopID := c.GetString("x-shop-id"); shopID != "" && shopID != body.ShopID {
+		c.Error(errx.E(errx.CodePermission, "api key is not valid for this shop", errx.Op("OrderController.Checkout")))
+		return
+	}
+
 	result, err := oc.OrderUsecase.Checkout(c.Request.Context(), body)
 	if err != nil {
 		c.Error(err)
@@ -33,7 +41,8 @@ func (oc *OrderController) Checkout(c *gin.Context) {
 	response_success.JSON(c).Msg("success checkout order").Status("success").Data(result).Send(http.StatusOK)
 }
 
-// ConfirmPayment handles payment confirmation for an order
+// ConfirmPayment marks an order paid by hand. Payments are normally confirmed by the signed provider
+// webhook, so this is an operator endpoint behind the admin token, e.g. for a webhook that never arrived.
 func (oc *OrderController) ConfirmPayment(c *gin.Context) {
 	orderIDParam := c.Param("orderID")
 	orderID, err := uuid.Parse(orderIDParam)
@@ -51,6 +60,29 @@ func (oc *OrderController) ConfirmPayment(c *gin.Context) {
 	response_success.JSON(c).Msg("payment confirmed successfully").Status("success").Send(http.StatusOK)
 }
 
+// CreatePaymentIntent starts the payment of an order and returns where the customer pays it
+func (oc *OrderController) CreatePaymentIntent(c *gin.Context) {
+	orderIDParam := c.Param("orderID")
+	orderID, err := uuid.Parse(orderIDParam)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if _, err := oc.orderForCaller(c, orderID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	intent, err := oc.PaymentUsecase.CreateIntent(c.Request.Context(), orderID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("payment intent created successfully").Status("success").Data(intent).Send(http.StatusOK)
+}
+
 // CancelOrder handles order cancellation
 func (oc *OrderController) CancelOrder(c *gin.Context) {
 	orderIDParam := c.Param("orderID")
@@ -60,6 +92,11 @@ func (oc *OrderController) CancelOrder(c *gin.Context) {
 		return
 	}
 
+	if _, err := oc.orderForCaller(c, orderID); err != nil {
+		c.Error(err)
+		return
+	}
+
 	err = oc.OrderUsecase.CancelOrder(c.Request.Context(), orderID)
 	if err != nil {
 		c.Error(err)
@@ -69,6 +106,102 @@ func (oc *OrderController) CancelOrder(c *gin.Context) {
 	response_success.JSON(c).Msg("order cancelled successfully").Status("success").Send(http.StatusOK)
 }
 
+// ExtendReservation extends how long an unpaid order keeps its stock reserved
+func (oc *OrderController) ExtendReservation(c *gin.Context) {
+	orderIDParam := c.Param("orderID")
+	orderID, err := uuid.Parse(orderIDParam)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// An empty body extends by the default
+	var body domain.ExtendReservationRequest
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(errx.E(errx.CodeValidation, "invalid reservation extension payload", errx.Op("OrderController.ExtendReservation"), err))
+		return
+	}
+
+	if _, err := oc.orderForCaller(c, orderID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := oc.OrderUsecase.ExtendReservation(c.Request.Context(), orderID, body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("reservation extended successfully").Status("success").Data(result).Send(http.StatusOK)
+}
+
+// RefundOrder refunds some or all items of a paid order. Refunds are issued by the shop, so only
+// its API keys and its owner may call it.
+func (oc *OrderController) RefundOrder(c *gin.Context) {
+	orderIDParam := c.Param("orderID")
+	orderID, err := uuid.Parse(orderIDParam)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var body domain.RefundOrderRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid refund payload", errx.Op("OrderController.RefundOrder"), err))
+		return
+	}
+
+	refund, err := oc.refund(c, orderID, body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("order refunded successfully").Status("success").Data(refund).Send(http.StatusCreated)
+}
+
+// refund refunds the order for a shop API key, or for the owner of the order's shop.
+func (oc *OrderController) refund(c *gin.Context, orderID uuid.UUID, body domain.RefundOrderRequest) (*domain.Refund, error) {
+	if c.GetString("x-shop-id") != "" {
+		if _, err := oc.orderForCaller(c, orderID); err != nil {
+			return nil, err
+		}
+
+		return oc.OrderUsecase.RefundOrder(c.Request.Context(), orderID, body)
+	}
+
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		return nil, errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("OrderController.RefundOrder"), err)
+	}
+
+	return oc.OrderUsecase.RefundOrderAsOwner(c.Request.Context(), orderID, userID, body)
+}
+
+// ListRefunds lists the refunds of an order
+func (oc *OrderController) ListRefunds(c *gin.Context) {
+	orderIDParam := c.Param("orderID")
+	orderID, err := uuid.Parse(orderIDParam)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if _, err := oc.orderForCaller(c, orderID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	refunds, err := oc.OrderUsecase.ListRefunds(c.Request.Context(), orderID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("refunds retrieved successfully").Status("success").Data(refunds).Send(http.StatusOK)
+}
+
 // GetOrderDetails retrieves order details
 func (oc *OrderController) GetOrderDetails(c *gin.Context) {
 	orderIDParam := c.Param("orderID")
@@ -78,7 +211,7 @@ func (oc *OrderController) GetOrderDetails(c *gin.Context) {
 		return
 	}
 
-	order, err := oc.OrderUsecase.GetOrderDetails(c.Request.Context(), orderID)
+	order, err := oc.orderForCaller(c, orderID)
 	if err != nil {
 		c.Error(err)
 		return
@@ -104,6 +237,24 @@ func (oc *OrderController) GetUserOrders(c *gin.Context) {
 		return
 	}
 
+	// Shop-scoped API keys list the orders of their shop rather than those of the key creator
+	if shopIDStr := c.GetString("x-shop-id"); shopIDStr != "" {
+		shopID, err := uuid.Parse(shopIDStr)
+		if err != nil {
+			c.Error(errx.E(errx.CodeValidation, "invalid shop ID", errx.Op("OrderController.GetUserOrders"), err))
+			return
+		}
+
+		result, err := oc.OrderUsecase.GetShopOrders(c.Request.Context(), shopID, pagination)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		response_success.JSON(c).Msg("orders retrieved successfully").Status("success").Data(result).Send(http.StatusOK)
+		return
+	}
+
 	// Get user orders
 	result, err := oc.OrderUsecase.GetUserOrders(c.Request.Context(), userID, pagination)
 	if err != nil {
@@ -113,3 +264,24 @@ func (oc *OrderController) GetUserOrders(c *gin.Context) {
 
 	response_success.JSON(c).Msg("orders retrieved successfully").Status("success").Data(result).Send(http.StatusOK)
 }
+
+// orderForCaller loads an order the caller may act on: shop-scoped API keys reach the orders of
+// their shop and users only the orders they placed. Other orders are reported as missing so
+// their existence is not disclosed.
+func (oc *OrderController) orderForCaller(c *gin.Context, orderID uuid.UUID) (*domain.Order, error) {
+	order, err := oc.OrderUsecase.GetOrderDetails(c.Request.Context(), orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := order.UserID.String() == c.GetString("x-user-id")
+	if shopID := c.GetString("x-shop-id"); shopID != "" {
+		owner = order.ShopID.String() == shopID
+	}
+
+	if !owner {
+		return nil, errx.E(errx.CodeNotFound, "order not found", errx.Op("OrderController.orderForCaller"))
+	}
+
+	return order, nil
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/response/response_success"
+	"github.com/gin-gonic/gin"
+)
+
+// maxPaymentWebhookBody bounds the webhook body read before its signature is checked.
+const maxPaymentWebhookBody = 1 << 20
+
+type PaymentController struct {
+	PaymentUsecase domain.PaymentUsecase
+}
+
+// Webhook receives payment provider events. It is unauthenticated; the provider signature
+// over the raw body is verified instead.
+func (pc *PaymentController) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPaymentWebhookBody))
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, "failed to read payment webhook", errx.Op("PaymentController.Webhook"), err))
+		return
+	}
+
+	duplicate, err := pc.PaymentUsecase.HandleWebhook(c.Request.Context(), c.Request.Header, body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	msg := "payment event processed"
+	if duplicate {
+		msg = "payment event already processed"
+	}
+
+	response_success.JSON(c).Msg(msg).Status("success").Send(http.StatusOK)
+}
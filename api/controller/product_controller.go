@@ -8,6 +8,7 @@ import (
 	"github.com/dyaksa/warehouse/pkg/paginator"
 	"github.com/dyaksa/warehouse/pkg/response/response_success"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type ProductController struct {
@@ -34,6 +35,23 @@ func (pc *ProductController) Create(c *gin.Context) {
 		return
 	}
 
+	// Shop-scoped API keys may only stock their shop's warehouses
+	if shopIDStr := c.GetString("x-shop-id"); shopIDStr != "" {
+		shopID, err := uuid.Parse(shopIDStr)
+		if err != nil {
+			c.Error(errx.E(errx.CodeValidation, "invalid shop ID", errx.Op("ProductController.Create"), err))
+			return
+		}
+
+		if err := pc.ProductUsecase.CreateForShop(c.Request.Context(), shopID, body); err != nil {
+			c.Error(err)
+			return
+		}
+
+		response_success.JSON(c).Msg("success create products").Status("success").Send(http.StatusCreated)
+		return
+	}
+
 	if err := pc.ProductUsecase.Create(c.Request.Context(), body); err != nil {
 		c.Error(err)
 		return
@@ -64,6 +82,24 @@ func (pc *ProductController) RetrieveAll(c *gin.Context) {
 		return
 	}
 
+	// Shop-scoped API keys list the products stocked by their shop only
+	if shopIDStr := c.GetString("x-shop-id"); shopIDStr != "" {
+		shopID, err := uuid.Parse(shopIDStr)
+		if err != nil {
+			c.Error(errx.E(errx.CodeValidation, "invalid shop ID", errx.Op("ProductController.RetrieveAll"), err))
+			return
+		}
+
+		result, err := pc.ProductUsecase.RetrieveByShop(c.Request.Context(), shopID, pagination)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		response_success.JSON(c).Msg("success retrieve products").Status("success").Data(result).Send(http.StatusOK)
+		return
+	}
+
 	result, err := pc.ProductUsecase.RetrieveAll(c.Request.Context(), pagination)
 
 	if err != nil {
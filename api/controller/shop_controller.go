@@ -7,6 +7,7 @@ import (
 	"github.com/dyaksa/warehouse/pkg/errx"
 	"github.com/dyaksa/warehouse/pkg/response/response_success"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type ShopController struct {
@@ -16,12 +17,18 @@ type ShopController struct {
 func (sc *ShopController) Create(c *gin.Context) {
 	var body domain.CreateShopRequest
 
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("ShopController.Create"), err))
+		return
+	}
+
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.Error(errx.E(errx.CodeValidation, "invalid shop payload", errx.Op("ShopController.Create"), err))
 		return
 	}
 
-	if err := sc.ShopUsecase.Create(c.Request.Context(), body); err != nil {
+	if err := sc.ShopUsecase.Create(c.Request.Context(), userID, body); err != nil {
 		c.Error(err)
 		return
 	}
@@ -4,44 +4,160 @@ import (
 	"net/http"
 
 	"github.com/dyaksa/warehouse/api/worker"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/paginator"
 	"github.com/dyaksa/warehouse/pkg/response/response_success"
+	"github.com/dyaksa/warehouse/usecase"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type StockReleaseController struct {
-	stockReleaseWorker *worker.StockReleaseWorker
+	stockReleaseWorker  *worker.StockReleaseWorker
+	stockReleaseUsecase usecase.StockReleaseUsecase
 }
 
-func NewStockReleaseController(stockReleaseWorker *worker.StockReleaseWorker) *StockReleaseController {
+func NewStockReleaseController(stockReleaseWorker *worker.StockReleaseWorker, stockReleaseUsecase usecase.StockReleaseUsecase) *StockReleaseController {
 	return &StockReleaseController{
-		stockReleaseWorker: stockReleaseWorker,
+		stockReleaseWorker:  stockReleaseWorker,
+		stockReleaseUsecase: stockReleaseUsecase,
 	}
 }
 
 // ManualRelease manually triggers the stock release process
 func (src *StockReleaseController) ManualRelease(c *gin.Context) {
-	err := src.stockReleaseWorker.ProcessNow(c.Request.Context())
+	released, err := src.stockReleaseWorker.ProcessNow(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to process stock release",
-			"message": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
 	response_success.JSON(c).
 		Status("success").
 		Msg("Stock release processing triggered successfully").
+		Data(gin.H{
+			"released": released,
+		}).
 		Send(http.StatusOK)
 }
 
-// Status returns the status of the stock release worker
+// Status returns what the stock release worker has been doing
 func (src *StockReleaseController) Status(c *gin.Context) {
+	status, err := src.stockReleaseWorker.Status(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	response_success.JSON(c).
 		Status("success").
-		Msg("Stock release worker is running").
-		Data(gin.H{
-			"worker_status": "active",
-		}).
+		Msg("Stock release worker status").
+		Data(status).
+		Send(http.StatusOK)
+}
+
+// Pause stops the stock release worker from running scheduled batches on every replica
+func (src *StockReleaseController) Pause(c *gin.Context) {
+	changed, err := src.stockReleaseWorker.Pause(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	msg := "Stock release worker paused"
+	if !changed {
+		msg = "Stock release worker was already paused"
+	}
+
+	src.sendStatus(c, msg)
+}
+
+// Resume lets a paused stock release worker run scheduled batches again
+func (src *StockReleaseController) Resume(c *gin.Context) {
+	changed, err := src.stockReleaseWorker.Resume(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	msg := "Stock release worker resumed"
+	if !changed {
+		msg = "Stock release worker was not paused"
+	}
+
+	src.sendStatus(c, msg)
+}
+
+// sendStatus responds with msg and the worker status after a change.
+func (src *StockReleaseController) sendStatus(c *gin.Context, msg string) {
+	status, err := src.stockReleaseWorker.Status(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).
+		Status("success").
+		Msg(msg).
+		Data(status).
+		Send(http.StatusOK)
+}
+
+// ListQuarantined returns the reservations the worker gave up on, most recently quarantined first
+func (src *StockReleaseController) ListQuarantined(c *gin.Context) {
+	var pagination paginator.PaginationRequest
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid pagination query", errx.Op("StockReleaseController.ListQuarantined"), err))
+		return
+	}
+
+	result, err := src.stockReleaseUsecase.ListQuarantined(c.Request.Context(), pagination)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).
+		Status("success").
+		Msg("Quarantined reservations").
+		Data(result).
+		Send(http.StatusOK)
+}
+
+// RetryQuarantined hands a quarantined reservation back to the worker
+func (src *StockReleaseController) RetryQuarantined(c *gin.Context) {
+	reservationID, err := uuid.Parse(c.Param("reservationID"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid reservation ID", errx.Op("StockReleaseController.RetryQuarantined"), err))
+		return
+	}
+
+	if err := src.stockReleaseUsecase.RetryQuarantined(c.Request.Context(), reservationID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).
+		Status("success").
+		Msg("Reservation requeued for the next batch").
+		Send(http.StatusOK)
+}
+
+// ReleaseQuarantined releases the stock of a quarantined reservation now
+func (src *StockReleaseController) ReleaseQuarantined(c *gin.Context) {
+	reservationID, err := uuid.Parse(c.Param("reservationID"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid reservation ID", errx.Op("StockReleaseController.ReleaseQuarantined"), err))
+		return
+	}
+
+	if err := src.stockReleaseUsecase.ReleaseQuarantined(c.Request.Context(), reservationID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).
+		Status("success").
+		Msg("Reservation released").
 		Send(http.StatusOK)
 }
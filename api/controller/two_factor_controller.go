@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/response/response_success"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TwoFactorController struct {
+	TwoFactorUsecase domain.TwoFactorUsecase
+}
+
+// Enroll starts TOTP enrollment for the authenticated user
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and provisioning URI to render as a QR code. Two-factor authentication is not active until confirmed.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} domain.TwoFactorEnrollment "Enrollment started"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Two-factor authentication already enabled"
+// @Security BearerAuth
+// @Router /auth/2fa/enroll [post]
+func (tc *TwoFactorController) Enroll(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("TwoFactorController.Enroll"), err))
+		return
+	}
+
+	enrollment, err := tc.TwoFactorUsecase.Enroll(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("two-factor enrollment started").Status("success").Data(enrollment).Send(http.StatusOK)
+}
+
+// Activate confirms TOTP enrollment
+// @Summary Confirm two-factor enrollment
+// @Description Enable two-factor authentication with a code from the authenticator app. Returns recovery codes, shown only once.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param payload body domain.TwoFactorCodeRequest true "Authenticator code"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication enabled"
+// @Failure 400 {object} map[string]interface{} "Invalid code"
+// @Failure 412 {object} map[string]interface{} "Enrollment not started"
+// @Security BearerAuth
+// @Router /auth/2fa/verify [post]
+func (tc *TwoFactorController) Activate(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("TwoFactorController.Activate"), err))
+		return
+	}
+
+	var payload domain.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("TwoFactorController.Activate"), err))
+		return
+	}
+
+	codes, err := tc.TwoFactorUsecase.Activate(c.Request.Context(), userID, payload.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("two-factor authentication enabled").Status("success").Data(gin.H{"recovery_codes": codes}).Send(http.StatusOK)
+}
+
+// Disable turns off two-factor authentication
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication using the current password and an authenticator or recovery code
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param payload body domain.TwoFactorDisableRequest true "Password and code"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication disabled"
+// @Failure 401 {object} map[string]interface{} "Invalid credentials or code"
+// @Security BearerAuth
+// @Router /auth/2fa/disable [post]
+func (tc *TwoFactorController) Disable(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("TwoFactorController.Disable"), err))
+		return
+	}
+
+	var payload domain.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("TwoFactorController.Disable"), err))
+		return
+	}
+
+	if err := tc.TwoFactorUsecase.Disable(c.Request.Context(), userID, payload); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("two-factor authentication disabled").Status("success").Send(http.StatusOK)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the authenticated user
+// @Summary Regenerate recovery codes
+// @Description Invalidate existing recovery codes and return a new set, shown only once
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param payload body domain.TwoFactorCodeRequest true "Authenticator code"
+// @Success 200 {object} map[string]interface{} "Recovery codes regenerated"
+// @Failure 401 {object} map[string]interface{} "Invalid code"
+// @Security BearerAuth
+// @Router /auth/2fa/recovery-codes [post]
+func (tc *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("TwoFactorController.RegenerateRecoveryCodes"), err))
+		return
+	}
+
+	var payload domain.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("TwoFactorController.RegenerateRecoveryCodes"), err))
+		return
+	}
+
+	codes, err := tc.TwoFactorUsecase.RegenerateRecoveryCodes(c.Request.Context(), userID, payload.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("recovery codes regenerated").Status("success").Data(gin.H{"recovery_codes": codes}).Send(http.StatusOK)
+}
+
+// CompleteLogin exchanges a challenge token and code for an access token
+// @Summary Complete two-factor login
+// @Description Exchange the challenge token returned by login and an authenticator or recovery code for a JWT access token
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param payload body domain.TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} map[string]interface{} "Login successful with access token"
+// @Failure 401 {object} map[string]interface{} "Invalid challenge or code"
+// @Router /auth/login/2fa [post]
+func (tc *TwoFactorController) CompleteLogin(c *gin.Context) {
+	var payload domain.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("TwoFactorController.CompleteLogin"), err))
+		return
+	}
+
+	token, err := tc.TwoFactorUsecase.CompleteLogin(c.Request.Context(), payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("Login successful").Status("success").Data(gin.H{"access_token": token}).Send(http.StatusOK)
+}
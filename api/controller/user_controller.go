@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/response/response_success"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type UserController struct {
+	UserUsecase domain.UserUsecase
+}
+
+// Me returns the profile of the authenticated user
+// @Summary Get current user
+// @Description Return the decrypted email and phone of the authenticated user
+// @Tags User
+// @Produce json
+// @Success 200 {object} domain.UserProfile "User profile"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Security BearerAuth
+// @Router /user/me [get]
+func (uc *UserController) Me(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("UserController.Me"), err))
+		return
+	}
+
+	profile, err := uc.UserUsecase.Me(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("success retrieve user").Status("success").Data(profile).Send(http.StatusOK)
+}
+
+// ChangeEmail changes the email of the authenticated user
+// @Summary Change email
+// @Description Replace the account email. The new address must be verified again.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body domain.ChangeEmailRequest true "New email and current password"
+// @Success 200 {object} map[string]interface{} "Email changed"
+// @Failure 401 {object} map[string]interface{} "Invalid credentials"
+// @Failure 409 {object} map[string]interface{} "Email already in use"
+// @Security BearerAuth
+// @Router /user/me/email [put]
+func (uc *UserController) ChangeEmail(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("UserController.ChangeEmail"), err))
+		return
+	}
+
+	var payload domain.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("UserController.ChangeEmail"), err))
+		return
+	}
+
+	if err := uc.UserUsecase.ChangeEmail(c.Request.Context(), userID, payload); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("email changed successfully").Status("success").Send(http.StatusOK)
+}
+
+// ChangePhone changes the phone number of the authenticated user
+// @Summary Change phone number
+// @Description Replace the account phone number. The new number must be verified again.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body domain.ChangePhoneRequest true "New phone and current password"
+// @Success 200 {object} map[string]interface{} "Phone changed"
+// @Failure 401 {object} map[string]interface{} "Invalid credentials"
+// @Failure 409 {object} map[string]interface{} "Phone already in use"
+// @Security BearerAuth
+// @Router /user/me/phone [put]
+func (uc *UserController) ChangePhone(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("UserController.ChangePhone"), err))
+		return
+	}
+
+	var payload domain.ChangePhoneRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("UserController.ChangePhone"), err))
+		return
+	}
+
+	if err := uc.UserUsecase.ChangePhone(c.Request.Context(), userID, payload); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("phone changed successfully").Status("success").Send(http.StatusOK)
+}
+
+// ChangePassword changes the password of the authenticated user
+// @Summary Change password
+// @Description Replace the account password after confirming the current one
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body domain.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} map[string]interface{} "Password changed"
+// @Failure 401 {object} map[string]interface{} "Invalid credentials"
+// @Security BearerAuth
+// @Router /user/me/password [put]
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("UserController.ChangePassword"), err))
+		return
+	}
+
+	var payload domain.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("UserController.ChangePassword"), err))
+		return
+	}
+
+	if err := uc.UserUsecase.ChangePassword(c.Request.Context(), userID, payload); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("password changed successfully").Status("success").Send(http.StatusOK)
+}
+
+// DeleteAccount erases the authenticated user's personal data
+// @Summary Delete account
+// @Description Erase the email, phone, blind indexes and credentials of the account. Orders are kept under the now anonymous user ID.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body domain.DeleteAccountRequest true "Current password"
+// @Success 200 {object} map[string]interface{} "Account deleted"
+// @Failure 401 {object} map[string]interface{} "Invalid credentials"
+// @Security BearerAuth
+// @Router /user/me [delete]
+func (uc *UserController) DeleteAccount(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("UserController.DeleteAccount"), err))
+		return
+	}
+
+	var payload domain.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("UserController.DeleteAccount"), err))
+		return
+	}
+
+	if err := uc.UserUsecase.DeleteAccount(c.Request.Context(), userID, payload); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("account deleted successfully").Status("success").Send(http.StatusOK)
+}
+
+// Export returns everything held about the authenticated user
+// @Summary Export personal data
+// @Description Return the decrypted profile and order history of the authenticated user as JSON
+// @Tags User
+// @Produce json
+// @Success 200 {object} domain.UserDataExport "Personal data export"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Security BearerAuth
+// @Router /user/me/export [get]
+func (uc *UserController) Export(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("UserController.Export"), err))
+		return
+	}
+
+	export, err := uc.UserUsecase.Export(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="user-data.json"`)
+	c.Header("Cache-Control", "no-store")
+	response_success.JSON(c).Msg("success export user data").Status("success").Data(export).Send(http.StatusOK)
+}
@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/response/response_success"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type VerificationController struct {
+	VerificationUsecase domain.VerificationUsecase
+}
+
+// RequestEmailVerification sends an email verification token to the authenticated user
+// @Summary Request email verification
+// @Description Send a single-use email verification token to the authenticated user's email address
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Verification email sent"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Email already verified"
+// @Security BearerAuth
+// @Router /auth/verify/email/request [post]
+func (vc *VerificationController) RequestEmailVerification(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("VerificationController.RequestEmailVerification"), err))
+		return
+	}
+
+	if err := vc.VerificationUsecase.RequestEmailVerification(c.Request.Context(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("verification email sent").Status("success").Send(http.StatusOK)
+}
+
+// ConfirmEmail verifies an email address using a token
+// @Summary Confirm email address
+// @Description Mark the email address as verified using the token sent by email
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param payload body domain.ConfirmEmailRequest true "Verification token"
+// @Success 200 {object} map[string]interface{} "Email verified"
+// @Failure 400 {object} map[string]interface{} "Invalid or expired token"
+// @Router /auth/verify/email/confirm [post]
+func (vc *VerificationController) ConfirmEmail(c *gin.Context) {
+	var payload domain.ConfirmEmailRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("VerificationController.ConfirmEmail"), err))
+		return
+	}
+
+	if err := vc.VerificationUsecase.ConfirmEmail(c.Request.Context(), payload.Token); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("email verified successfully").Status("success").Send(http.StatusOK)
+}
+
+// RequestPhoneVerification sends a one-time code to the authenticated user's phone
+// @Summary Request phone verification
+// @Description Send a 6 digit one-time code by SMS to the authenticated user's phone number
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Verification code sent"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Phone already verified"
+// @Security BearerAuth
+// @Router /auth/verify/phone/request [post]
+func (vc *VerificationController) RequestPhoneVerification(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("VerificationController.RequestPhoneVerification"), err))
+		return
+	}
+
+	if err := vc.VerificationUsecase.RequestPhoneVerification(c.Request.Context(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("verification code sent").Status("success").Send(http.StatusOK)
+}
+
+// ConfirmPhone verifies the authenticated user's phone using a one-time code
+// @Summary Confirm phone number
+// @Description Mark the phone number as verified using the one-time code sent by SMS
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param payload body domain.ConfirmPhoneRequest true "One-time code"
+// @Success 200 {object} map[string]interface{} "Phone verified"
+// @Failure 400 {object} map[string]interface{} "Invalid or expired code"
+// @Security BearerAuth
+// @Router /auth/verify/phone/confirm [post]
+func (vc *VerificationController) ConfirmPhone(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("VerificationController.ConfirmPhone"), err))
+		return
+	}
+
+	var payload domain.ConfirmPhoneRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("VerificationController.ConfirmPhone"), err))
+		return
+	}
+
+	if err := vc.VerificationUsecase.ConfirmPhone(c.Request.Context(), userID, payload.Code); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("phone verified successfully").Status("success").Send(http.StatusOK)
+}
+
+// ForgotPassword starts a password reset
+// @Summary Request password reset
+// @Description Send a password reset token to the email or phone of the account. Always succeeds to avoid account enumeration.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param payload body domain.ForgotPasswordRequest true "Account identifier"
+// @Success 200 {object} map[string]interface{} "Reset instructions sent if the account exists"
+// @Failure 400 {object} map[string]interface{} "Invalid identifier"
+// @Router /auth/password/forgot [post]
+func (vc *VerificationController) ForgotPassword(c *gin.Context) {
+	var payload domain.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("VerificationController.ForgotPassword"), err))
+		return
+	}
+
+	if err := vc.VerificationUsecase.RequestPasswordReset(c.Request.Context(), payload.Identifier); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("if the account exists, reset instructions have been sent").Status("success").Send(http.StatusOK)
+}
+
+// ResetPassword completes a password reset
+// @Summary Reset password
+// @Description Set a new password using a password reset token
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param payload body domain.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]interface{} "Password reset"
+// @Failure 400 {object} map[string]interface{} "Invalid or expired token"
+// @Router /auth/password/reset [post]
+func (vc *VerificationController) ResetPassword(c *gin.Context) {
+	var payload domain.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid request payload", errx.Op("VerificationController.ResetPassword"), err))
+		return
+	}
+
+	if err := vc.VerificationUsecase.ResetPassword(c.Request.Context(), payload); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("password reset successfully").Status("success").Send(http.StatusOK)
+}
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/paginator"
+	"github.com/dyaksa/warehouse/pkg/response/response_success"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WebhookController struct {
+	WebhookUsecase domain.WebhookUsecase
+}
+
+// Create subscribes an endpoint to shop events
+// @Summary Create webhook
+// @Description Subscribe a URL to shop events. Requests are signed with HMAC-SHA256 using the returned secret, which is shown only once.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param shopID path string true "Shop ID"
+// @Param payload body domain.CreateWebhookRequest true "Endpoint URL and event types"
+// @Success 201 {object} domain.WebhookSubscriptionCreated "Webhook created"
+// @Failure 400 {object} map[string]interface{} "Invalid payload"
+// @Failure 404 {object} map[string]interface{} "Shop not found"
+// @Security BearerAuth
+// @Router /shop/{shopID}/webhooks [post]
+func (wc *WebhookController) Create(c *gin.Context) {
+	userID, shopID, ok := wc.params(c, "WebhookController.Create")
+	if !ok {
+		return
+	}
+
+	var body domain.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid webhook payload", errx.Op("WebhookController.Create"), err))
+		return
+	}
+
+	created, err := wc.WebhookUsecase.Create(c.Request.Context(), userID, shopID, body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("success create webhook").Status("success").Data(created).Send(http.StatusCreated)
+}
+
+// List returns the webhooks the authenticated user created for a shop
+// @Summary List webhooks
+// @Tags Webhooks
+// @Produce json
+// @Param shopID path string true "Shop ID"
+// @Success 200 {array} domain.WebhookSubscription "Webhooks"
+// @Security BearerAuth
+// @Router /shop/{shopID}/webhooks [get]
+func (wc *WebhookController) List(c *gin.Context) {
+	userID, shopID, ok := wc.params(c, "WebhookController.List")
+	if !ok {
+		return
+	}
+
+	subs, err := wc.WebhookUsecase.List(c.Request.Context(), userID, shopID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("success retrieve webhooks").Status("success").Data(subs).Send(http.StatusOK)
+}
+
+// Delete removes a webhook and its delivery log
+// @Summary Delete webhook
+// @Tags Webhooks
+// @Produce json
+// @Param shopID path string true "Shop ID"
+// @Param webhookID path string true "Webhook ID"
+// @Success 200 {object} map[string]interface{} "Webhook deleted"
+// @Failure 404 {object} map[string]interface{} "Webhook not found"
+// @Security BearerAuth
+// @Router /shop/{shopID}/webhooks/{webhookID} [delete]
+func (wc *WebhookController) Delete(c *gin.Context) {
+	userID, shopID, ok := wc.params(c, "WebhookController.Delete")
+	if !ok {
+		return
+	}
+
+	webhookID, ok := wc.uuidParam(c, "webhookID", "invalid webhook ID", "WebhookController.Delete")
+	if !ok {
+		return
+	}
+
+	if err := wc.WebhookUsecase.Delete(c.Request.Context(), userID, shopID, webhookID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("success delete webhook").Status("success").Send(http.StatusOK)
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first
+// @Summary List webhook deliveries
+// @Tags Webhooks
+// @Produce json
+// @Param shopID path string true "Shop ID"
+// @Param webhookID path string true "Webhook ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} paginator.PaginationResult[domain.WebhookDelivery] "Deliveries"
+// @Failure 404 {object} map[string]interface{} "Webhook not found"
+// @Security BearerAuth
+// @Router /shop/{shopID}/webhooks/{webhookID}/deliveries [get]
+func (wc *WebhookController) ListDeliveries(c *gin.Context) {
+	userID, shopID, ok := wc.params(c, "WebhookController.ListDeliveries")
+	if !ok {
+		return
+	}
+
+	webhookID, ok := wc.uuidParam(c, "webhookID", "invalid webhook ID", "WebhookController.ListDeliveries")
+	if !ok {
+		return
+	}
+
+	var pagination paginator.PaginationRequest
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.Error(errx.E(errx.CodeValidation, "invalid pagination query", errx.Op("WebhookController.ListDeliveries"), err))
+		return
+	}
+
+	result, err := wc.WebhookUsecase.ListDeliveries(c.Request.Context(), userID, shopID, webhookID, pagination)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("success retrieve webhook deliveries").Status("success").Data(result).Send(http.StatusOK)
+}
+
+// Redeliver queues a delivery to be sent again
+// @Summary Redeliver webhook
+// @Description Send a delivery again with a fresh retry budget, whatever its current status.
+// @Tags Webhooks
+// @Produce json
+// @Param shopID path string true "Shop ID"
+// @Param webhookID path string true "Webhook ID"
+// @Param deliveryID path string true "Delivery ID"
+// @Success 202 {object} map[string]interface{} "Delivery queued"
+// @Failure 404 {object} map[string]interface{} "Webhook or delivery not found"
+// @Security BearerAuth
+// @Router /shop/{shopID}/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver [post]
+func (wc *WebhookController) Redeliver(c *gin.Context) {
+	userID, shopID, ok := wc.params(c, "WebhookController.Redeliver")
+	if !ok {
+		return
+	}
+
+	webhookID, ok := wc.uuidParam(c, "webhookID", "invalid webhook ID", "WebhookController.Redeliver")
+	if !ok {
+		return
+	}
+
+	deliveryID, ok := wc.uuidParam(c, "deliveryID", "invalid delivery ID", "WebhookController.Redeliver")
+	if !ok {
+		return
+	}
+
+	if err := wc.WebhookUsecase.Redeliver(c.Request.Context(), userID, shopID, webhookID, deliveryID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response_success.JSON(c).Msg("webhook delivery queued").Status("success").Send(http.StatusAccepted)
+}
+
+func (wc *WebhookController) params(c *gin.Context, op string) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("x-user-id"))
+	if err != nil {
+		c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op(op), err))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	shopID, ok := wc.uuidParam(c, "shopID", "invalid shop ID", op)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, shopID, true
+}
+
+func (wc *WebhookController) uuidParam(c *gin.Context, name string, msg string, op string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.Error(errx.E(errx.CodeValidation, msg, errx.Op(op), err))
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
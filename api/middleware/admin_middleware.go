@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/dyaksa/warehouse/pkg/response/response_error"
+	"github.com/gin-gonic/gin"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminTokenMiddleware guards operational endpoints with the shared ADMIN_TOKEN.
+// When no token is configured the endpoints are reported as missing.
+func AdminTokenMiddleware(token string) gin.HandlerFunc {
+	want := sha256.Sum256([]byte(token))
+
+	return func(c *gin.Context) {
+		if token == "" {
+			response_error.JSON(c).Msg("Not found").Status("error").Send(http.StatusNotFound)
+			c.Abort()
+			return
+		}
+
+		// Hashing first keeps the comparison constant-time regardless of the header length.
+		got := sha256.Sum256([]byte(c.GetHeader(AdminTokenHeader)))
+		if subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+			response_error.JSON(c).Msg("Not authorized").Status("error").Send(http.StatusUnauthorized)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
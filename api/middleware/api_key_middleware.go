@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries a service-to-service API key.
+const APIKeyHeader = "X-API-Key"
+
+// AuthMiddleware accepts either an API key in X-API-Key or a JWT bearer token.
+// Both paths populate x-user-id; API keys act as the user that created them and also set
+// x-shop-id, x-api-key-id and x-api-scopes so handlers can restrict them to their shop.
+func AuthMiddleware(secret string, users domain.ActiveUserChecker, apiKeyUsecase domain.APIKeyUsecase) gin.HandlerFunc {
+	jwtMiddleware := JwtAuthMiddleware(secret, users)
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			jwtMiddleware(c)
+			return
+		}
+
+		apiKey, err := apiKeyUsecase.Authenticate(c.Request.Context(), key)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set("x-user-id", apiKey.UserID.String())
+		c.Set("x-shop-id", apiKey.ShopID.String())
+		c.Set("x-api-key-id", apiKey.ID.String())
+		c.Set("x-api-scopes", apiKey.Scopes)
+		c.Next()
+	}
+}
+
+// RequireScopeMiddleware rejects API key requests lacking scope. JWT-authenticated users are not
+// restricted by scopes. It must run after AuthMiddleware.
+func RequireScopeMiddleware(scope domain.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("x-api-key-id") == "" {
+			c.Next()
+			return
+		}
+
+		scopes, _ := c.Get("x-api-scopes")
+		granted, _ := scopes.([]domain.APIKeyScope)
+		if !slices.Contains(granted, scope) {
+			c.Error(errx.E(errx.CodePermission, "api key lacks scope "+string(scope), errx.Op("RequireScopeMiddleware")))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
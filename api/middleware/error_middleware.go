@@ -5,7 +5,9 @@ import (
 
 	"github.com/dyaksa/warehouse/pkg/errx"
 	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/tracing"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ErrorMiddleware handles returned errors stored in context and panics, mapping them to structured JSON.
@@ -52,6 +54,12 @@ func writeError(c *gin.Context, ae *errx.AppError, l log.Logger) {
 	if len(ae.Meta) > 0 {
 		payload["error"].(gin.H)["meta"] = ae.Meta
 	}
-	l.Error("request_error", log.Any("code", ae.Code), log.Any("message", ae.Message), log.Any("status", status), log.Any("path", c.FullPath()))
+	if id := log.RequestID(c.Request.Context()); id != "" {
+		payload["error"].(gin.H)["request_id"] = id
+	}
+	if status >= 500 {
+		tracing.RecordError(trace.SpanFromContext(c.Request.Context()), ae)
+	}
+	log.WithContext(c.Request.Context(), l).Error("request_error", log.Any("code", ae.Code), log.Any("message", ae.Message), log.Any("status", status), log.Any("path", c.FullPath()))
 	c.JSON(status, payload)
 }
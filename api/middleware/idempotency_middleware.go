@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotentReplayedHeader marks a response replayed from an earlier request with the same key.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLength = 128
+
+// maxIdempotentBodyBytes bounds the body read into memory to hash it.
+const maxIdempotentBodyBytes = 1 << 20
+
+// responseRecorder keeps a copy of the response body for storing.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware makes requests carrying an Idempotency-Key header safe to retry. The key is
+// scoped to the method, path and caller, and bound to a server-side hash of the raw body. Successful
+// responses are stored and replayed for retries; failed requests release the key so they can be
+// retried with it. Keys are kept for the retention of the route. Requests without the header pass
+// through. It must run after AuthMiddleware.
+func IdempotencyMiddleware(idempotencyUsecase domain.IdempotencyUsecase, retention domain.IdempotencyRetention, l log.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(domain.IdempotencyKeyHeader)
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		if len(header) > maxIdempotencyKeyLength {
+			c.Error(errx.E(errx.CodeValidation, "idempotency key is too long", errx.Op("IdempotencyMiddleware")))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			c.Error(errx.E(errx.CodeValidation, "failed to read request body", errx.Op("IdempotencyMiddleware"), err))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		key := domain.IdempotencyKey{
+			Key:       header,
+			Endpoint:  c.Request.Method + " " + c.Request.URL.Path,
+			Principal: idempotencyPrincipal(c),
+		}
+
+		ctx := c.Request.Context()
+		// Settling the key must not be skipped when the client disconnects mid-request, or the key
+		// would stay locked until its lease expires.
+		settleCtx := context.WithoutCancel(ctx)
+
+		lease, replay, err := idempotencyUsecase.Begin(ctx, key, hex.EncodeToString(sum[:]), retention.For(c.Request.Method+" "+c.FullPath()))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if replay != nil {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(replay.StatusCode, replay.ContentType, replay.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		status := recorder.Status()
+		if len(c.Errors) > 0 || status < http.StatusOK || status >= http.StatusMultipleChoices {
+			if err := idempotencyUsecase.Release(settleCtx, key, lease); err != nil {
+				log.WithContext(ctx, l).Error("failed to release idempotency key", log.Error("error", err))
+			}
+			return
+		}
+
+		if err := idempotencyUsecase.Complete(settleCtx, key, lease, domain.IdempotentResponse{
+			StatusCode:  status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}); err != nil {
+			// The response is already sent; a retry will wait for the lease and run again
+			log.WithContext(ctx, l).Error("failed to store idempotent response", log.Error("error", err))
+		}
+	}
+}
+
+// idempotencyPrincipal identifies the caller: the user, plus the shop for API keys.
+func idempotencyPrincipal(c *gin.Context) string {
+	principal := c.GetString("x-user-id")
+	if shopID := c.GetString("x-shop-id"); shopID != "" {
+		principal += ":" + shopID
+	}
+
+	return principal
+}
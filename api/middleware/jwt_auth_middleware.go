@@ -4,12 +4,15 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/pkg/response/response_error"
 	"github.com/dyaksa/warehouse/pkg/tokenutils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-func JwtAuthMiddleware(secret string) gin.HandlerFunc {
+// JwtAuthMiddleware authenticates bearer tokens and rejects those whose user has since been erased.
+func JwtAuthMiddleware(secret string, users domain.ActiveUserChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.Request.Header.Get("Authorization")
 		t := strings.Split(authHeader, " ")
@@ -23,6 +26,23 @@ func JwtAuthMiddleware(secret string) gin.HandlerFunc {
 					c.Abort()
 					return
 				}
+				id, err := uuid.Parse(userID)
+				if err != nil {
+					response_error.JSON(c).Msg("Failed to extract user ID from token").Status("error").Send(http.StatusUnauthorized)
+					c.Abort()
+					return
+				}
+				active, err := users.IsActive(c.Request.Context(), id)
+				if err != nil {
+					response_error.JSON(c).Msg("Failed to check account").Status("error").Send(http.StatusInternalServerError)
+					c.Abort()
+					return
+				}
+				if !active {
+					response_error.JSON(c).Msg("Account no longer exists").Status("error").Send(http.StatusUnauthorized)
+					c.Abort()
+					return
+				}
 				c.Set("x-user-id", userID)
 				c.Next()
 				return
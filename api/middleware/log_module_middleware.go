@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/gin-gonic/gin"
+)
+
+// LogModuleMiddleware tags the request context with a log module, so the request and error
+// logs of the group follow that module's runtime level.
+func LogModuleMiddleware(module string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(log.WithModule(c.Request.Context(), module))
+		c.Next()
+	}
+}
@@ -13,6 +13,6 @@ func LoggerMiddleware(l log.Logger) gin.HandlerFunc {
 		ctx.Next()
 
 		duration := time.Since(start)
-		l.Info("request", log.Any("method", ctx.Request.Method), log.Any("path", ctx.Request.URL.Path), log.Any("status", ctx.Writer.Status()), log.Any("duration", duration))
+		log.WithContext(ctx.Request.Context(), l).Info("request", log.Any("method", ctx.Request.Method), log.Any("path", ctx.Request.URL.Path), log.Any("status", ctx.Writer.Status()), log.Any("duration", duration))
 	}
 }
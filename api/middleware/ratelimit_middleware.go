@@ -2,67 +2,33 @@ package middleware
 
 import (
 	"fmt"
-	"time"
+	"math"
+	"strconv"
 
+	"github.com/dyaksa/warehouse/pkg/errx"
 	"github.com/dyaksa/warehouse/pkg/ratelimit"
 	"github.com/gin-gonic/gin"
 )
 
-type Options func(*ratelimit.Options)
+// RateLimit enforces policy with counters kept in store. The X-RateLimit-* headers describe the last
+// policy applied, so a route policy registered after a global one reports the route's own quota.
+func RateLimit(store ratelimit.Store, policy ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, quota := policy.Resolve(c)
+		info := store.Limit(c.Request.Context(), key, quota)
 
-func keyFunc(ctx *gin.Context) string {
-	return ctx.ClientIP()
-}
-
-func errorHandler(ctx *gin.Context, info ratelimit.Info) {
-	ctx.Header("X-RateLimit-Limit", fmt.Sprintf("%d", info.Limit))
-	ctx.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", info.RemainingHits))
-	ctx.Header("X-RateLimit-Reset", fmt.Sprintf("%d", info.ResetTime.Unix()))
-	ctx.String(429, "Too Many Requests")
-}
-
-func beforeResponse(ctx *gin.Context, info ratelimit.Info) {
-	ctx.Header("X-RateLimit-Limit", fmt.Sprintf("%d", info.Limit))
-	ctx.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", info.RemainingHits))
-	ctx.Header("X-RateLimit-Reset", fmt.Sprintf("%d", info.ResetTime.Unix()))
-}
-
-func RateLimit(store ratelimit.Store) gin.HandlerFunc {
-	options := &ratelimit.Options{
-		KeyFunc:        keyFunc,
-		ErrorHandler:   errorHandler,
-		BeforeResponse: beforeResponse,
-	}
-
-	return func(ctx *gin.Context) {
-		key := options.KeyFunc(ctx)
-		info := store.Limit(key, ctx)
-		options.BeforeResponse(ctx, info)
+		c.Header("X-RateLimit-Policy", fmt.Sprintf("%d;w=%d;name=%q", quota.Limit, int(math.Ceil(quota.Rate.Seconds())), policy.Name))
+		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", info.Limit))
+		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", info.RemainingHits))
+		c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", info.ResetTime.Unix()))
 
-		if ctx.IsAborted() {
+		if info.RateLimited {
+			c.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(info.RetryAfter.Seconds())), 1)))
+			c.Error(errx.E(errx.CodeRateLimited, "too many requests", errx.Op("RateLimitMiddleware")))
+			c.Abort()
 			return
 		}
 
-		if info.RateLimited {
-			options.ErrorHandler(ctx, info)
-			ctx.Abort()
-		} else {
-			ctx.Next()
-		}
+		c.Next()
 	}
 }
-
-func RateLimitDefault() gin.HandlerFunc {
-	return RateLimit(ratelimit.InMemoryStore(&ratelimit.InMemoryOptions{
-		Rate:  time.Second,
-		Limit: 100,
-	}))
-}
-
-// RateLimitRedisCustom creates a Redis-based rate limiter with custom settings
-func RateLimitMiddleware(rate time.Duration, limit uint, prefix string) gin.HandlerFunc {
-	return RateLimit(ratelimit.InMemoryStore(&ratelimit.InMemoryOptions{
-		Rate:  rate,
-		Limit: limit,
-	}))
-}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequireVerifiedMiddleware rejects authenticated users whose account does not satisfy policy.
+// It must run after JwtAuthMiddleware so that x-user-id is populated.
+func RequireVerifiedMiddleware(policy domain.VerificationPolicy, verificationUsecase domain.VerificationUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy == "" || policy == domain.VerificationNone {
+			c.Next()
+			return
+		}
+
+		userID, err := uuid.Parse(c.GetString("x-user-id"))
+		if err != nil {
+			c.Error(errx.E(errx.CodeUnauthenticated, "invalid user ID", errx.Op("RequireVerifiedMiddleware"), err))
+			c.Abort()
+			return
+		}
+
+		if err := verificationUsecase.EnsureVerified(c.Request.Context(), userID, policy); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package route
+
+import (
+	"github.com/dyaksa/warehouse/api/controller"
+	"github.com/dyaksa/warehouse/api/middleware"
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/gin-gonic/gin"
+)
+
+// NewAdminRoute takes the job usecase from main.go, which shares it with the job worker.
+func NewAdminRoute(env *bootstrap.Env, gin *gin.Engine, levels *log.Levels, jobUsecase domain.JobUsecase) {
+	logLevelController := &controller.LogLevelController{Levels: levels}
+	jobController := &controller.JobController{JobUsecase: jobUsecase}
+
+	adminGroup := gin.Group("/api/admin", middleware.AdminTokenMiddleware(env.AdminToken))
+	adminGroup.GET("/log-level", logLevelController.Get)
+	adminGroup.PUT("/log-level", logLevelController.Set)
+	adminGroup.DELETE("/log-level", logLevelController.Reset)
+
+	adminGroup.GET("/jobs", jobController.List)
+	adminGroup.POST("/jobs/:jobID/retry", jobController.Retry)
+	adminGroup.POST("/jobs/:jobID/cancel", jobController.Cancel)
+}
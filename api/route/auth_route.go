@@ -4,24 +4,88 @@ import (
 	"time"
 
 	"github.com/dyaksa/warehouse/api/controller"
+	"github.com/dyaksa/warehouse/api/middleware"
 	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/infrastructure/crypto"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
 	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/ratelimit"
 	"github.com/dyaksa/warehouse/repository"
 	"github.com/dyaksa/warehouse/usecase"
 	"github.com/gin-gonic/gin"
 )
 
-func NewAuthRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, group *gin.RouterGroup) {
+func NewAuthRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, notifier domain.Notifier, rateLimitStore ratelimit.Store, group *gin.RouterGroup) {
 	userRepository := repository.NewUserRepository(db)
+	jwtMiddleware := middleware.JwtAuthMiddleware(env.JwtSecret, userRepository)
+	userTokenRepository := repository.NewUserTokenRepository(db)
+	twoFactorRepository := repository.NewTwoFactorRepository(db)
 	authUsecase := usecase.NewAuthUsecase(userRepository, crypto, env)
+	verificationUsecase := usecase.NewVerificationUsecase(db.Database(), userRepository, userTokenRepository, notifier, crypto, env)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(db.Database(), userRepository, twoFactorRepository, crypto, env)
 
 	authController := controller.AuthController{
 		AuthUsecase: authUsecase,
 	}
 
+	verificationController := controller.VerificationController{
+		VerificationUsecase: verificationUsecase,
+	}
+
+	twoFactorController := controller.TwoFactorController{
+		TwoFactorUsecase: twoFactorUsecase,
+	}
+
+	// Guessing passwords is throttled per account, whichever addresses the attempts come from.
+	loginRateLimit := middleware.RateLimit(rateLimitStore, ratelimit.Policy{
+		Name:  "login",
+		Quota: ratelimit.Quota{Limit: 5, Rate: time.Minute},
+		Key:   ratelimit.ByJSONField("identifier"),
+	})
+
+	// Challenge tokens live for minutes; throttle the second step so codes cannot be brute forced.
+	twoFactorRateLimit := middleware.RateLimit(rateLimitStore, ratelimit.Policy{
+		Name:  "two_factor",
+		Quota: ratelimit.Quota{Limit: 10, Rate: time.Minute},
+		Key:   ratelimit.ByIP,
+	})
+
+	// Every forgot or verification request sends an email or SMS; cap them per account so nobody can
+	// flood an inbox or run up SMS costs.
+	passwordForgotRateLimit := middleware.RateLimit(rateLimitStore, ratelimit.Policy{
+		Name:  "password_forgot",
+		Quota: ratelimit.Quota{Limit: 5, Rate: time.Hour},
+		Key:   ratelimit.ByJSONField("identifier"),
+	})
+
+	verificationRequestRateLimit := middleware.RateLimit(rateLimitStore, ratelimit.Policy{
+		Name:  "verification_request",
+		Quota: ratelimit.Quota{Limit: 5, Rate: time.Hour},
+		Key:   ratelimit.ByUser,
+	})
+
+	// Reset and confirmation tokens are guessed from wherever the caller is.
+	tokenRateLimit := middleware.RateLimit(rateLimitStore, ratelimit.Policy{
+		Name:  "verification_token",
+		Quota: ratelimit.Quota{Limit: 10, Rate: time.Minute},
+		Key:   ratelimit.ByIP,
+	})
+
 	authGroup := group.Group("/auth")
 	authGroup.POST("/register", authController.Register)
-	authGroup.POST("/login", authController.Login)
+	authGroup.POST("/login", loginRateLimit, authController.Login)
+	authGroup.POST("/login/2fa", twoFactorRateLimit, twoFactorController.CompleteLogin)
+
+	authGroup.POST("/password/forgot", passwordForgotRateLimit, verificationController.ForgotPassword)
+	authGroup.POST("/password/reset", tokenRateLimit, verificationController.ResetPassword)
+	authGroup.POST("/verify/email/confirm", tokenRateLimit, verificationController.ConfirmEmail)
+	authGroup.POST("/verify/email/request", jwtMiddleware, verificationRequestRateLimit, verificationController.RequestEmailVerification)
+	authGroup.POST("/verify/phone/request", jwtMiddleware, verificationRequestRateLimit, verificationController.RequestPhoneVerification)
+	authGroup.POST("/verify/phone/confirm", jwtMiddleware, tokenRateLimit, verificationController.ConfirmPhone)
+
+	authGroup.POST("/2fa/enroll", jwtMiddleware, twoFactorController.Enroll)
+	authGroup.POST("/2fa/verify", jwtMiddleware, twoFactorRateLimit, twoFactorController.Activate)
+	authGroup.POST("/2fa/disable", jwtMiddleware, twoFactorRateLimit, twoFactorController.Disable)
+	authGroup.POST("/2fa/recovery-codes", jwtMiddleware, twoFactorRateLimit, twoFactorController.RegenerateRecoveryCodes)
 }
@@ -0,0 +1,14 @@
+package route
+
+import (
+	"github.com/dyaksa/warehouse/api/controller"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// NewHealthRoute registers the probes at the root so orchestrators can reach them without auth.
+func NewHealthRoute(gin *gin.Engine, healthUsecase domain.HealthUsecase) {
+	healthController := &controller.HealthController{HealthUsecase: healthUsecase}
+	gin.GET("/healthz", healthController.Live)
+	gin.GET("/readyz", healthController.Ready)
+}
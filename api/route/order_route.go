@@ -6,16 +6,22 @@ import (
 	"github.com/dyaksa/warehouse/api/controller"
 	"github.com/dyaksa/warehouse/api/middleware"
 	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/infrastructure/crypto"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
 	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/ratelimit"
 	"github.com/dyaksa/warehouse/repository"
 	"github.com/dyaksa/warehouse/usecase"
 	"github.com/gin-gonic/gin"
 )
 
-func NewOrderRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, group *gin.RouterGroup) {
-	jwtMiddleware := middleware.JwtAuthMiddleware(env.JwtSecret)
+func NewOrderRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, notifier domain.Notifier, rateLimitStore ratelimit.Store, group *gin.RouterGroup) {
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(db.Database(), repository.NewAPIKeyRepository(db), repository.NewShopRepository(db), env)
+	userRepository := repository.NewUserRepository(db)
+	authMiddleware := middleware.AuthMiddleware(env.JwtSecret, userRepository, apiKeyUsecase)
+	readScope := middleware.RequireScopeMiddleware(domain.ScopeOrdersRead)
+	writeScope := middleware.RequireScopeMiddleware(domain.ScopeOrdersWrite)
 	orderRepository := repository.NewOrderRepository(db)
 	idempotencyRepository := repository.NewIdempotencyRequestRepository(db)
 	orderItemRepository := repository.NewOrderItemRepository(db)
@@ -23,24 +29,74 @@ func NewOrderRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l
 	movementRepository := repository.NewMovementRepository(db)
 	productStockRepository := repository.NewProductStockRepository(db)
 	pickWarehouseRepository := repository.NewWarehouseRepository(db)
+	outboxRepository := repository.NewOutboxRepository(db)
+	paymentRepository := repository.NewPaymentRepository(db)
+	refundRepository := repository.NewRefundRepository(db)
+	userTokenRepository := repository.NewUserTokenRepository(db)
+	paymentProvider := bootstrap.NewPaymentProvider(env, l)
+
+	checkoutRateLimit := middleware.RateLimit(rateLimitStore, ratelimit.Policy{
+		Name:       "checkout",
+		Quota:      ratelimit.Quota{Limit: 10, Rate: time.Minute},
+		Key:        ratelimit.ByUser,
+		ShopQuotas: bootstrap.NewRateLimitShopQuotas(env, l),
+	})
+
+	idempotencyUsecase := usecase.NewIdempotencyUsecase(db.Database(), idempotencyRepository)
+	idempotencyMiddleware := middleware.IdempotencyMiddleware(idempotencyUsecase, bootstrap.NewIdempotencyRetention(env, l), l)
+
+	verificationUsecase := usecase.NewVerificationUsecase(db.Database(), userRepository, userTokenRepository, notifier, crypto, env)
+	verifiedMiddleware := middleware.RequireVerifiedMiddleware(domain.VerificationPolicy(env.CheckoutVerification), verificationUsecase)
+
+	paymentUsecase := usecase.NewPaymentUsecase(
+		db.Database(),
+		paymentRepository,
+		paymentProvider,
+		orderRepository,
+		orderItemRepository,
+		refundRepository,
+		reservationRepository,
+		movementRepository,
+		productStockRepository,
+		outboxRepository,
+		l,
+	)
 
 	orderController := controller.OrderController{
 		OrderUsecase: usecase.NewOrderUsecase(
 			db.Database(),
 			orderRepository,
-			idempotencyRepository,
 			orderItemRepository,
 			reservationRepository,
 			movementRepository,
 			productStockRepository,
 			pickWarehouseRepository,
+			outboxRepository,
+			refundRepository,
+			paymentRepository,
+			usecase.NewJobUsecase(db.Database(), repository.NewJobRepository(db), nil, l),
+			repository.NewShopRepository(db),
+			bootstrap.NewReservationHoldLimits(env, l),
 		),
+		PaymentUsecase: paymentUsecase,
 	}
+	paymentController := controller.PaymentController{PaymentUsecase: paymentUsecase}
+
+	groupOrder := group.Group("/order", middleware.LogModuleMiddleware(bootstrap.LogModuleOrder), authMiddleware)
+	groupOrder.POST("/checkout", checkoutRateLimit, writeScope, verifiedMiddleware, idempotencyMiddleware, orderController.Checkout)
+	groupOrder.POST("/:orderID/payment-intent", writeScope, idempotencyMiddleware, orderController.CreatePaymentIntent)
+	groupOrder.POST("/:orderID/cancel", writeScope, idempotencyMiddleware, orderController.CancelOrder)
+	groupOrder.POST("/:orderID/extend-reservation", writeScope, idempotencyMiddleware, orderController.ExtendReservation)
+	groupOrder.POST("/:orderID/refunds", writeScope, idempotencyMiddleware, orderController.RefundOrder)
+	groupOrder.GET("/:orderID/refunds", readScope, orderController.ListRefunds)
+	groupOrder.GET("/:orderID", readScope, orderController.GetOrderDetails)
+	groupOrder.GET("/list", readScope, orderController.GetUserOrders)
+
+	// Customers' payments are confirmed by the provider webhook; operators can still confirm one by hand
+	groupAdminOrder := group.Group("/admin/orders", middleware.LogModuleMiddleware(bootstrap.LogModuleOrder), middleware.AdminTokenMiddleware(env.AdminToken))
+	groupAdminOrder.POST("/:orderID/confirm-payment", orderController.ConfirmPayment)
 
-	groupOrder := group.Group("/order", jwtMiddleware)
-	groupOrder.POST("/checkout", orderController.Checkout)
-	groupOrder.POST("/:orderID/confirm-payment", orderController.ConfirmPayment)
-	groupOrder.POST("/:orderID/cancel", orderController.CancelOrder)
-	groupOrder.GET("/:orderID", orderController.GetOrderDetails)
-	groupOrder.GET("/list", orderController.GetUserOrders)
+	// Called by the payment provider, which authenticates with the webhook signature instead of a token
+	groupPayment := group.Group("/payments", middleware.LogModuleMiddleware(bootstrap.LogModuleOrder))
+	groupPayment.POST("/webhook", paymentController.Webhook)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/dyaksa/warehouse/api/controller"
 	"github.com/dyaksa/warehouse/api/middleware"
 	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/infrastructure/crypto"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
 	"github.com/dyaksa/warehouse/pkg/log"
@@ -15,10 +16,11 @@ import (
 )
 
 func NewProductRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, group *gin.RouterGroup) {
-	jwtMiddleware := middleware.JwtAuthMiddleware(env.JwtSecret)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(db.Database(), repository.NewAPIKeyRepository(db), repository.NewShopRepository(db), env)
+	authMiddleware := middleware.AuthMiddleware(env.JwtSecret, repository.NewUserRepository(db), apiKeyUsecase)
 	productRepository := repository.NewProductRepository(db)
 	productStockRepository := repository.NewProductStockRepository(db)
-	productUsecase := usecase.NewProductUsecase(productRepository, productStockRepository)
+	productUsecase := usecase.NewProductUsecase(productRepository, productStockRepository, repository.NewWarehouseRepository(db))
 
 	productController := controller.ProductController{
 		ProductUsecase: productUsecase,
@@ -26,6 +28,6 @@ func NewProductRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client,
 
 	groupProduct := group.Group("/product")
 
-	groupProduct.POST("/create", jwtMiddleware, productController.Create)
-	groupProduct.GET("/list", jwtMiddleware, productController.RetrieveAll)
+	groupProduct.POST("/create", authMiddleware, middleware.RequireScopeMiddleware(domain.ScopeProductsWrite), productController.Create)
+	groupProduct.GET("/list", authMiddleware, middleware.RequireScopeMiddleware(domain.ScopeProductsRead), productController.RetrieveAll)
 }
@@ -4,23 +4,29 @@ import (
 	"time"
 
 	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/infrastructure/crypto"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
 	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/metrics"
+	"github.com/dyaksa/warehouse/pkg/ratelimit"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func Setup(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, r *gin.Engine) {
+func Setup(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, notifier domain.Notifier, rateLimitStore ratelimit.Store, r *gin.Engine) {
 	publicGroup := r.Group("/api")
 
-	NewAuthRoute(env, timeout, db, l, crypto, publicGroup)
+	NewAuthRoute(env, timeout, db, l, crypto, notifier, rateLimitStore, publicGroup)
 	NewWarehouseRoute(env, timeout, db, l, crypto, publicGroup)
 	NewWarehouseTransferRoute(env, timeout, db, l, crypto, publicGroup)
 	NewProductRoute(env, timeout, db, l, crypto, publicGroup)
 	NewShopRoute(env, timeout, db, l, crypto, publicGroup)
-	NewOrderRoute(env, timeout, db, l, crypto, publicGroup)
+	NewOrderRoute(env, timeout, db, l, crypto, notifier, rateLimitStore, publicGroup)
+	NewUserRoute(env, timeout, db, l, crypto, publicGroup)
+
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	swaggerRoute := r.Group("/swagger")
 	{
@@ -15,7 +15,7 @@ import (
 )
 
 func NewShopRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, group *gin.RouterGroup) {
-	jwtMiddleware := middleware.JwtAuthMiddleware(env.JwtSecret)
+	jwtMiddleware := middleware.JwtAuthMiddleware(env.JwtSecret, repository.NewUserRepository(db))
 	shopRepository := repository.NewShopRepository(db)
 	shopUsecase := usecase.NewShopUsecase(shopRepository)
 
@@ -23,9 +23,19 @@ func NewShopRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l
 		ShopUsecase: shopUsecase,
 	}
 
+	apiKeyController := controller.APIKeyController{
+		APIKeyUsecase: usecase.NewAPIKeyUsecase(db.Database(), repository.NewAPIKeyRepository(db), shopRepository, env),
+	}
+
 	shopGroup := group.Group("/shop", jwtMiddleware)
 	shopGroup.POST("/create", shopController.Create)
 	shopGroup.GET("/retrieve", shopController.Retrieve)
 	shopGroup.PUT("/update", shopController.Update)
 	shopGroup.DELETE("/delete", shopController.Delete)
+
+	// API keys are managed with a user session only; a key cannot mint or rotate keys.
+	shopGroup.POST("/:shopID/api-keys", apiKeyController.Create)
+	shopGroup.GET("/:shopID/api-keys", apiKeyController.List)
+	shopGroup.POST("/:shopID/api-keys/:keyID/rotate", apiKeyController.Rotate)
+	shopGroup.DELETE("/:shopID/api-keys/:keyID", apiKeyController.Revoke)
 }
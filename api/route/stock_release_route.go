@@ -10,6 +10,7 @@ import (
 	"github.com/dyaksa/warehouse/infrastructure/crypto"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
 	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/usecase"
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,10 +22,16 @@ func NewStockReleaseRoute(
 	crypto crypto.Crypto,
 	gin *gin.Engine,
 	stockReleaseWorker *worker.StockReleaseWorker,
+	stockReleaseUsecase usecase.StockReleaseUsecase,
 ) {
-	stockReleaseController := controller.NewStockReleaseController(stockReleaseWorker)
-	stockReleaseGroup := gin.Group("/api/stock-release")
-	stockReleaseGroup.Use(middleware.JwtAuthMiddleware(env.JwtSecret))
+	stockReleaseController := controller.NewStockReleaseController(stockReleaseWorker, stockReleaseUsecase)
+	// Operator controls, guarded by the admin token like the /api/admin routes
+	stockReleaseGroup := gin.Group("/api/stock-release", middleware.AdminTokenMiddleware(env.AdminToken))
 	stockReleaseGroup.POST("/trigger", stockReleaseController.ManualRelease)
 	stockReleaseGroup.GET("/status", stockReleaseController.Status)
+	stockReleaseGroup.POST("/pause", stockReleaseController.Pause)
+	stockReleaseGroup.POST("/resume", stockReleaseController.Resume)
+	stockReleaseGroup.GET("/quarantined", stockReleaseController.ListQuarantined)
+	stockReleaseGroup.POST("/quarantined/:reservationID/retry", stockReleaseController.RetryQuarantined)
+	stockReleaseGroup.POST("/quarantined/:reservationID/release", stockReleaseController.ReleaseQuarantined)
 }
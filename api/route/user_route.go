@@ -3,12 +3,34 @@ package route
 import (
 	"time"
 
+	"github.com/dyaksa/warehouse/api/controller"
+	"github.com/dyaksa/warehouse/api/middleware"
 	"github.com/dyaksa/warehouse/bootstrap"
 	"github.com/dyaksa/warehouse/infrastructure/crypto"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
 	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/repository"
+	"github.com/dyaksa/warehouse/usecase"
 	"github.com/gin-gonic/gin"
 )
 
 func NewUserRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, group *gin.RouterGroup) {
+	userRepository := repository.NewUserRepository(db)
+	jwtMiddleware := middleware.JwtAuthMiddleware(env.JwtSecret, userRepository)
+	orderRepository := repository.NewOrderRepository(db)
+	// Only enqueues; the job worker runs the jobs.
+	jobUsecase := usecase.NewJobUsecase(db.Database(), repository.NewJobRepository(db), nil, l)
+	userUsecase := usecase.NewUserUsecase(db.Database(), userRepository, orderRepository, repository.NewOrderItemRepository(db), jobUsecase, crypto)
+
+	userController := controller.UserController{
+		UserUsecase: userUsecase,
+	}
+
+	userGroup := group.Group("/user", jwtMiddleware)
+	userGroup.GET("/me", userController.Me)
+	userGroup.PUT("/me/email", userController.ChangeEmail)
+	userGroup.PUT("/me/phone", userController.ChangePhone)
+	userGroup.PUT("/me/password", userController.ChangePassword)
+	userGroup.GET("/me/export", userController.Export)
+	userGroup.DELETE("/me", userController.DeleteAccount)
 }
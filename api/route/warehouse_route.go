@@ -15,7 +15,7 @@ import (
 )
 
 func NewWarehouseRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, group *gin.RouterGroup) {
-	jwtMiddleware := middleware.JwtAuthMiddleware(env.JwtSecret)
+	jwtMiddleware := middleware.JwtAuthMiddleware(env.JwtSecret, repository.NewUserRepository(db))
 	wareHouseRepository := repository.NewWarehouseRepository(db)
 	warehouseTransferRepository := repository.NewWarehouseTransferRepository(db)
 	wareHouseUsecase := usecase.NewWarehouseUsecase(wareHouseRepository, warehouseTransferRepository)
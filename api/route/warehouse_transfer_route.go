@@ -15,13 +15,15 @@ import (
 )
 
 func NewWarehouseTransferRoute(env *bootstrap.Env, timeout time.Duration, db pqsql.Client, l log.Logger, crypto crypto.Crypto, group *gin.RouterGroup) {
-	jwtMiddleware := middleware.JwtAuthMiddleware(env.JwtSecret)
+	jwtMiddleware := middleware.JwtAuthMiddleware(env.JwtSecret, repository.NewUserRepository(db))
 
 	// Initialize repositories
 	warehouseTransferRepo := repository.NewWarehouseTransferRepository(db)
 	warehouseRepo := repository.NewWarehouseRepository(db)
 	productStockRepo := repository.NewProductStockRepository(db)
 	movementRepo := repository.NewMovementRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRequestRepository(db)
 
 	// Initialize usecase
 	warehouseTransferUsecase := usecase.NewWarehouseTransferUsecase(
@@ -30,18 +32,21 @@ func NewWarehouseTransferRoute(env *bootstrap.Env, timeout time.Duration, db pqs
 		warehouseRepo,
 		productStockRepo,
 		movementRepo,
+		outboxRepo,
 	)
 
+	idempotencyMiddleware := middleware.IdempotencyMiddleware(usecase.NewIdempotencyUsecase(db.Database(), idempotencyRepo), bootstrap.NewIdempotencyRetention(env, l), l)
+
 	// Initialize controller
 	warehouseTransferController := controller.WarehouseTransferController{
 		TransferUsecase: warehouseTransferUsecase,
 	}
 
 	// Create route group
-	transferGroup := group.Group("/transfers", jwtMiddleware)
-	transferGroup.POST("/", warehouseTransferController.CreateTransfer)
+	transferGroup := group.Group("/transfers", middleware.LogModuleMiddleware(bootstrap.LogModuleTransfer), jwtMiddleware)
+	transferGroup.POST("/", idempotencyMiddleware, warehouseTransferController.CreateTransfer)
 	transferGroup.GET("/:id", warehouseTransferController.GetTransfer)
-	transferGroup.PUT("/:id/status", warehouseTransferController.UpdateTransferStatus)
-	transferGroup.POST("/:id/execute", warehouseTransferController.ExecuteTransfer)
+	transferGroup.PUT("/:id/status", idempotencyMiddleware, warehouseTransferController.UpdateTransferStatus)
+	transferGroup.POST("/:id/execute", idempotencyMiddleware, warehouseTransferController.ExecuteTransfer)
 	transferGroup.GET("/warehouse/:warehouse_id", warehouseTransferController.GetTransfersByWarehouse)
 }
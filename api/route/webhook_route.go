@@ -0,0 +1,24 @@
+package route
+
+import (
+	"github.com/dyaksa/warehouse/api/controller"
+	"github.com/dyaksa/warehouse/api/middleware"
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// NewWebhookRoute takes the usecase from main.go, which shares it with the outbox relay and the delivery worker.
+func NewWebhookRoute(env *bootstrap.Env, db pqsql.Client, gin *gin.Engine, webhookUsecase domain.WebhookUsecase) {
+	webhookController := controller.WebhookController{WebhookUsecase: webhookUsecase}
+
+	// Like API keys, webhooks are managed with a user session only.
+	webhookGroup := gin.Group("/api/shop/:shopID/webhooks", middleware.JwtAuthMiddleware(env.JwtSecret, repository.NewUserRepository(db)))
+	webhookGroup.POST("", webhookController.Create)
+	webhookGroup.GET("", webhookController.List)
+	webhookGroup.DELETE("/:webhookID", webhookController.Delete)
+	webhookGroup.GET("/:webhookID/deliveries", webhookController.ListDeliveries)
+	webhookGroup.POST("/:webhookID/deliveries/:deliveryID/redeliver", webhookController.Redeliver)
+}
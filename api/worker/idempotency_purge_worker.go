@@ -0,0 +1,14 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+// NewIdempotencyPurgeWorker deletes expired idempotency keys. Small batches keep each delete from
+// holding locks on the table for long.
+func NewIdempotencyPurgeWorker(idempotencyUsecase domain.IdempotencyUsecase, config PeriodicWorkerConfig, logger log.Logger) *PeriodicWorker {
+	return newPeriodicWorker("idempotency purge worker", idempotencyUsecase.PurgeExpired, config, PeriodicWorkerConfig{BatchSize: 500, Interval: 5 * time.Minute}, logger)
+}
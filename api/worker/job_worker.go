@@ -0,0 +1,13 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+// NewJobWorker runs due background jobs.
+func NewJobWorker(jobUsecase domain.JobUsecase, config PeriodicWorkerConfig, logger log.Logger) *PeriodicWorker {
+	return newPeriodicWorker("job worker", jobUsecase.RunBatch, config, PeriodicWorkerConfig{BatchSize: 10, Interval: time.Second}, logger)
+}
@@ -0,0 +1,13 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+// NewOutboxRelayWorker relays pending outbox events to the event sinks.
+func NewOutboxRelayWorker(relayUsecase domain.OutboxRelayUsecase, config PeriodicWorkerConfig, logger log.Logger) *PeriodicWorker {
+	return newPeriodicWorker("outbox relay worker", relayUsecase.RelayBatch, config, PeriodicWorkerConfig{BatchSize: 100, Interval: time.Second}, logger)
+}
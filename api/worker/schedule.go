@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/google/uuid"
+)
+
+// Elector reports whether this replica currently leads an election, such as a pqsql.LeaderElection.
+//
+// A worker configured with an Elector is a singleton: only the leader runs its batches, so replicas
+// do not contend for the same rows. Without one a worker is sharded: every replica runs batches and
+// they split the rows between them with FOR UPDATE SKIP LOCKED.
+type Elector interface {
+	IsLeader() bool
+}
+
+// runEvery calls run every interval until ctx is cancelled or stopCh is closed. name identifies
+// the worker in its start and stop log entries.
+func runEvery(ctx context.Context, stopCh <-chan struct{}, interval time.Duration, name string, logger log.Logger, run func(context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(name + " stopped due to context cancellation")
+			return
+		case <-stopCh:
+			logger.Info(name + " stopped")
+			return
+		case <-ticker.C:
+			run(ctx)
+		}
+	}
+}
+
+// singleton wraps run so it only runs while elector reports this replica as the leader. A nil
+// elector runs it on every replica.
+func singleton(elector Elector, run func(context.Context)) func(context.Context) {
+	if elector == nil {
+		return run
+	}
+
+	return func(ctx context.Context) {
+		if elector.IsLeader() {
+			run(ctx)
+		}
+	}
+}
+
+// BatchFunc handles up to limit rows and reports how many it handled.
+type BatchFunc func(ctx context.Context, limit int) (int, error)
+
+type PeriodicWorkerConfig struct {
+	BatchSize int           // Number of rows to handle per batch
+	Interval  time.Duration // How often to poll for rows
+	Elector   Elector       // When set, only the leader runs batches
+}
+
+// PeriodicWorker runs a BatchFunc every interval, repeating it while batches come back full so a
+// backlog clears without waiting for the next tick.
+type PeriodicWorker struct {
+	name      string
+	batch     BatchFunc
+	batchSize int
+	interval  time.Duration
+	elector   Elector
+	stopCh    chan struct{}
+	logger    log.Logger
+}
+
+// newPeriodicWorker builds a worker named name, e.g. "outbox relay worker". Config values left
+// unset fall back to defaults.
+func newPeriodicWorker(name string, batch BatchFunc, config, defaults PeriodicWorkerConfig, logger log.Logger) *PeriodicWorker {
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+
+	return &PeriodicWorker{
+		name:      name,
+		batch:     batch,
+		batchSize: config.BatchSize,
+		interval:  config.Interval,
+		elector:   config.Elector,
+		stopCh:    make(chan struct{}),
+		logger:    logger,
+	}
+}
+
+// Start runs batches until ctx is cancelled or Stop is called.
+func (w *PeriodicWorker) Start(ctx context.Context) {
+	w.logger.Info("starting "+w.name, log.Int64("batch_size", int64(w.batchSize)), log.Duration("interval", w.interval))
+
+	runEvery(ctx, w.stopCh, w.interval, w.name, w.logger, singleton(w.elector, w.drain))
+}
+
+// Stop gracefully stops the worker
+func (w *PeriodicWorker) Stop() {
+	close(w.stopCh)
+}
+
+// drain runs batches until one comes back short.
+func (w *PeriodicWorker) drain(ctx context.Context) {
+	prefix := strings.ReplaceAll(strings.TrimSuffix(w.name, " worker"), " ", "-") + "-"
+
+	for ctx.Err() == nil {
+		batchCtx := log.WithRequestID(ctx, prefix+uuid.NewString())
+
+		handled, err := w.batch(batchCtx, w.batchSize)
+		if err != nil {
+			log.WithContext(batchCtx, w.logger).Error(w.name+" batch failed", log.Error("error", err))
+			return
+		}
+		if handled > 0 {
+			log.WithContext(batchCtx, w.logger).Debug(w.name+" batch done", log.Int64("handled", int64(handled)))
+		}
+		if handled < w.batchSize {
+			return
+		}
+	}
+}
@@ -2,27 +2,50 @@ package worker
 
 import (
 	"context"
-	"log"
+	"sync/atomic"
 	"time"
 
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/metrics"
 	"github.com/dyaksa/warehouse/usecase"
+	"github.com/google/uuid"
 )
 
 type StockReleaseWorker struct {
 	stockReleaseUsecase usecase.StockReleaseUsecase
 	batchSize           int
 	interval            time.Duration
+	elector             Elector
 	stopCh              chan struct{}
+	lastSuccess         atomic.Int64 // unix nanoseconds of the last successful batch on this replica
+	running             atomic.Bool
+	logger              log.Logger
+}
+
+// StockReleaseStatus is a snapshot of what the stock release worker has been doing. Running and
+// Leader describe the replica that answered; the rest is shared by all replicas.
+type StockReleaseStatus struct {
+	Running       bool       `json:"running"`        // the scheduling loop is active
+	Paused        bool       `json:"paused"`         // scheduled batches are skipped on every replica until resumed
+	Leader        bool       `json:"leader"`         // this replica runs the batches; always true without an elector
+	BatchSize     int        `json:"batch_size"`     // reservations processed per batch
+	Interval      string     `json:"interval"`       // time between batches
+	LastRunAt     *time.Time `json:"last_run_at"`    // when a batch last finished, successfully or not
+	LastError     string     `json:"last_error"`     // error of the last batch, empty if it succeeded
+	LastReleased  int        `json:"last_released"`  // reservations released by the last batch
+	TotalReleased int64      `json:"total_released"` // reservations released by all batches
 }
 
 type StockReleaseWorkerConfig struct {
 	BatchSize int           // Number of reservations to process per batch
 	Interval  time.Duration // How often to check for expired reservations
+	Elector   Elector       // When set, only the leader processes batches
 }
 
 func NewStockReleaseWorker(
 	stockReleaseUsecase usecase.StockReleaseUsecase,
 	config StockReleaseWorkerConfig,
+	logger log.Logger,
 ) *StockReleaseWorker {
 	// Set default values if not provided
 	if config.BatchSize <= 0 {
@@ -36,29 +59,20 @@ func NewStockReleaseWorker(
 		stockReleaseUsecase: stockReleaseUsecase,
 		batchSize:           config.BatchSize,
 		interval:            config.Interval,
+		elector:             config.Elector,
 		stopCh:              make(chan struct{}),
+		logger:              logger,
 	}
 }
 
 // Start begins the background worker that periodically processes expired reservations
 func (w *StockReleaseWorker) Start(ctx context.Context) {
-	log.Printf("Starting stock release worker with batch size %d and interval %v", w.batchSize, w.interval)
-
-	ticker := time.NewTicker(w.interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Stock release worker stopped due to context cancellation")
-			return
-		case <-w.stopCh:
-			log.Println("Stock release worker stopped")
-			return
-		case <-ticker.C:
-			w.processExpiredReservations(ctx)
-		}
-	}
+	w.logger.Info("starting stock release worker", log.Int64("batch_size", int64(w.batchSize)), log.Duration("interval", w.interval))
+
+	w.running.Store(true)
+	defer w.running.Store(false)
+
+	runEvery(ctx, w.stopCh, w.interval, "stock release worker", w.logger, w.processExpiredReservations)
 }
 
 // Stop gracefully stops the worker
@@ -72,25 +86,119 @@ func (w *StockReleaseWorker) StartWithGracefulShutdown(ctx context.Context) {
 
 	// Wait for context cancellation
 	<-ctx.Done()
-	log.Println("Shutting down stock release worker...")
+	w.logger.Info("shutting down stock release worker")
 	w.Stop()
 }
 
 func (w *StockReleaseWorker) processExpiredReservations(ctx context.Context) {
+	// A standby replica is healthy as long as it keeps campaigning, the leader does the work.
+	if w.elector != nil && !w.elector.IsLeader() {
+		w.lastSuccess.Store(time.Now().UnixNano())
+		return
+	}
+
 	start := time.Now()
+	ctx = batchContext(ctx)
+	l := log.WithContext(ctx, w.logger)
 
-	err := w.stockReleaseUsecase.ProcessExpiredReservations(ctx, w.batchSize)
+	state, err := w.stockReleaseUsecase.State(ctx)
 	if err != nil {
-		log.Printf("Error processing expired reservations: %v", err)
+		l.Error("failed to read stock release state", log.Error("error", err))
+		return
+	}
+	// A paused worker was stopped on purpose, so it stays healthy.
+	if state.Paused {
+		w.lastSuccess.Store(time.Now().UnixNano())
+		return
+	}
+
+	released, err := w.stockReleaseUsecase.ProcessExpiredReservations(ctx, w.batchSize)
+	w.recordRun(ctx, released, err)
+	if err != nil {
+		metrics.StockReleaseBatchDuration.WithLabelValues("error").Observe(time.Since(start).Seconds())
+		l.Error("failed to process expired reservations", log.Error("error", err))
 		return
 	}
 
 	duration := time.Since(start)
-	log.Printf("Processed expired reservations in %v", duration)
+	metrics.StockReleaseBatchDuration.WithLabelValues("success").Observe(duration.Seconds())
+	l.Info("processed expired reservations", log.Int64("released", int64(released)), log.Duration("duration", duration))
+}
+
+// ProcessNow immediately processes expired reservations (useful for manual triggers) and returns
+// how many were released. It runs even while the worker is paused.
+func (w *StockReleaseWorker) ProcessNow(ctx context.Context) (int, error) {
+	log.WithContext(ctx, w.logger).Info("manually triggered stock release processing")
+
+	released, err := w.stockReleaseUsecase.ProcessExpiredReservations(ctx, w.batchSize)
+	w.recordRun(ctx, released, err)
+
+	return released, err
+}
+
+// recordRun updates the status after a batch. Failing to store it only costs the status, so it is
+// logged rather than returned.
+func (w *StockReleaseWorker) recordRun(ctx context.Context, released int, err error) {
+	if err == nil {
+		w.lastSuccess.Store(time.Now().UnixNano())
+	}
+
+	if recordErr := w.stockReleaseUsecase.RecordRun(ctx, released, err); recordErr != nil {
+		log.WithContext(ctx, w.logger).Warn("failed to record stock release run", log.Error("error", recordErr))
+	}
+}
+
+// Pause makes every replica skip scheduled batches until Resume is called. It reports false if the
+// worker was already paused.
+func (w *StockReleaseWorker) Pause(ctx context.Context) (bool, error) {
+	return w.stockReleaseUsecase.SetPaused(ctx, true)
+}
+
+// Resume undoes Pause. It reports false if the worker was not paused.
+func (w *StockReleaseWorker) Resume(ctx context.Context) (bool, error) {
+	return w.stockReleaseUsecase.SetPaused(ctx, false)
+}
+
+// Status returns a snapshot of the worker's state.
+func (w *StockReleaseWorker) Status(ctx context.Context) (StockReleaseStatus, error) {
+	state, err := w.stockReleaseUsecase.State(ctx)
+	if err != nil {
+		return StockReleaseStatus{}, err
+	}
+
+	status := StockReleaseStatus{
+		Running:       w.running.Load(),
+		Paused:        state.Paused,
+		Leader:        w.elector == nil || w.elector.IsLeader(),
+		BatchSize:     w.batchSize,
+		Interval:      w.interval.String(),
+		LastRunAt:     state.LastRunAt,
+		LastReleased:  state.LastReleased,
+		TotalReleased: state.TotalReleased,
+	}
+	if state.LastError != nil {
+		status.LastError = *state.LastError
+	}
+
+	return status, nil
+}
+
+// batchContext gives each scheduled batch its own correlation ID, so its log entries can be grouped.
+func batchContext(ctx context.Context) context.Context {
+	return log.WithRequestID(ctx, "stock-release-"+uuid.NewString())
+}
+
+// LastSuccess returns when a batch last completed without error, or the zero time if none has.
+func (w *StockReleaseWorker) LastSuccess() time.Time {
+	nanos := w.lastSuccess.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, nanos)
 }
 
-// ProcessNow immediately processes expired reservations (useful for manual triggers)
-func (w *StockReleaseWorker) ProcessNow(ctx context.Context) error {
-	log.Println("Manually triggered stock release processing")
-	return w.stockReleaseUsecase.ProcessExpiredReservations(ctx, w.batchSize)
+// Interval returns how often the worker runs.
+func (w *StockReleaseWorker) Interval() time.Duration {
+	return w.interval
 }
@@ -0,0 +1,13 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+// NewWebhookDeliveryWorker sends due webhook deliveries.
+func NewWebhookDeliveryWorker(webhookUsecase domain.WebhookUsecase, config PeriodicWorkerConfig, logger log.Logger) *PeriodicWorker {
+	return newPeriodicWorker("webhook delivery worker", webhookUsecase.DeliverBatch, config, PeriodicWorkerConfig{BatchSize: 20, Interval: 2 * time.Second}, logger)
+}
@@ -2,11 +2,14 @@ package bootstrap
 
 import (
 	"context"
+	"io"
+	"time"
 
+	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/infrastructure/crypto"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
 	"github.com/dyaksa/warehouse/pkg/log"
-	"github.com/dyaksa/warehouse/pkg/log/logrus"
+	"github.com/dyaksa/warehouse/pkg/ratelimit"
 	"github.com/dyaksa/warehouse/pkg/validationutils"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -19,6 +22,16 @@ type Application struct {
 	Log      log.Logger
 	Postgres pqsql.Client
 	Crypto   crypto.Crypto
+	Notifier domain.Notifier
+
+	RateLimitStore ratelimit.Store // shared by every rate limit policy
+
+	EventSinks []domain.EventSink // outbox relay destinations besides webhooks
+	LogLevels  *log.Levels        // changes the level of Log at runtime
+
+	notifierCloser   io.Closer
+	eventSinkClosers []io.Closer
+	tracerShutdown   func(context.Context) error
 }
 
 func App(ctx context.Context) *Application {
@@ -27,24 +40,43 @@ func App(ctx context.Context) *Application {
 		App: gin.Default(),
 	}
 
-	ll, err := logrus.New(
-		logrus.WithLevel("info"),
-		logrus.WithJSONFormatter(),
-	)
-
+	ll, levels, err := NewLogger(app.Env)
 	if err != nil {
 		panic(err)
 	}
 
 	app.Log = ll
+	app.LogLevels = levels
+	// Tracing is set up before Postgres so the instrumented driver picks up the provider.
+	app.tracerShutdown = NewTracing(ctx, app.Env, app.Log)
 	app.Postgres = NewPostgres(app.Env, app.Log)
 	app.Crypto = NewDerivaleCrypto(app.Log)
+	app.RateLimitStore = NewRateLimitStore(app.Env, app.Postgres, app.Log)
+	app.Notifier, app.notifierCloser = NewNotifier(app.Env, app.Log)
+	app.EventSinks, app.eventSinkClosers = NewEventSinks(app.Env, app.Log)
 
 	return app
 }
 
 func (app *Application) CloseConnection() {
 	CloseConnection(app.Postgres, app.Log)
+
+	if app.notifierCloser != nil {
+		_ = app.notifierCloser.Close()
+	}
+
+	for _, closer := range app.eventSinkClosers {
+		_ = closer.Close()
+	}
+
+	if app.tracerShutdown != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := app.tracerShutdown(ctx); err != nil {
+			app.Log.Warn("failed to flush traces", log.Error("error", err))
+		}
+	}
 }
 
 func (app *Application) CustomValidation() {
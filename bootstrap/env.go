@@ -14,6 +14,10 @@ type Env struct {
 	Port     string `env:"APP_PORT" default:"8080"`
 	LogLevel string `env:"LOG_LEVEL" default:"info"`
 
+	LogBackend string `env:"LOG_BACKEND" default:"logrus"` // logrus or slog
+
+	LogRedactPatterns string `env:"LOG_REDACT_PATTERNS" default:"bearer,jwt,email,phone"` // comma-separated built-in patterns; empty enables all
+
 	DBHost string `env:"DB_HOST" default:"localhost"`
 	DBPort string `env:"DB_PORT" default:"5432"`
 	DBUser string `env:"DB_USER" default:"postgres"`
@@ -28,6 +32,53 @@ type Env struct {
 
 	AutoMigrate bool   `env:"AUTO_MIGRATE" default:"false"`
 	DB_DIALECT  string `env:"DB_DIALECT" default:"postgres"`
+	GooseTable  string `env:"GOOSE_TABLE" default:"goose_db_version"`
+
+	NotifierDriver   string `env:"NOTIFIER_DRIVER" default:"stdout"`
+	NotifierFilePath string `env:"NOTIFIER_FILE_PATH" default:"./logs/notifications.log"`
+
+	VerificationSecret   string `env:"VERIFICATION_SECRET"`
+	EmailVerificationTTL int    `env:"EMAIL_VERIFICATION_TTL" default:"1440"` // minutes
+	PhoneOTPTTL          int    `env:"PHONE_OTP_TTL" default:"10"`            // minutes
+	PasswordResetTTL     int    `env:"PASSWORD_RESET_TTL" default:"30"`       // minutes
+	CheckoutVerification string `env:"CHECKOUT_VERIFICATION" default:"none"`  // none, email, phone or both
+
+	TwoFactorIssuer       string `env:"TWO_FACTOR_ISSUER" default:"Warehouse"`
+	TwoFactorChallengeTTL int    `env:"TWO_FACTOR_CHALLENGE_TTL" default:"5"` // minutes
+
+	APIKeyRotationGrace int `env:"API_KEY_ROTATION_GRACE" default:"1440"` // minutes the old key keeps working after rotation
+
+	ShutdownDrainDelay int `env:"SHUTDOWN_DRAIN_DELAY" default:"5"` // seconds /readyz reports failure before the server stops accepting requests
+
+	AdminToken string `env:"ADMIN_TOKEN"` // shared secret for /admin endpoints; empty disables them
+
+	RateLimitStore      string `env:"RATE_LIMIT_STORE" default:"memory"` // memory or postgres; use postgres with several instances
+	RateLimitShopQuotas string `env:"RATE_LIMIT_SHOP_QUOTAS"`            // comma-separated "shop id=requests per minute" for API key traffic
+
+	StockReleaseBatchSize   int `env:"STOCK_RELEASE_BATCH_SIZE,default=50"`  // expired reservations released per batch
+	StockReleaseInterval    int `env:"STOCK_RELEASE_INTERVAL,default=30"`    // seconds between batches
+	StockReleaseMaxAttempts int `env:"STOCK_RELEASE_MAX_ATTEMPTS,default=5"` // failed releases before a reservation is QUARANTINED
+
+	ReservationMinutes       int    `env:"RESERVATION_MINUTES,default=15"`       // minutes an extension adds when the request names none
+	ReservationMaxHold       int    `env:"RESERVATION_MAX_HOLD,default=60"`      // minutes from checkout an extended reservation may last
+	ReservationMaxExtensions int    `env:"RESERVATION_MAX_EXTENSIONS,default=3"` // extensions allowed per order
+	ReservationHoldShops     string `env:"RESERVATION_HOLD_SHOPS"`               // comma-separated "shop id=minutes/extensions" overrides
+
+	IdempotencyRetention       int    `env:"IDEMPOTENCY_RETENTION" default:"1440"` // minutes a key is remembered
+	IdempotencyRetentionRoutes string `env:"IDEMPOTENCY_RETENTION_ROUTES"`         // comma-separated "METHOD /route=minutes" overrides
+
+	OutboxSinks    string `env:"OUTBOX_SINKS" default:"stdout"` // comma-separated: stdout, file
+	OutboxFilePath string `env:"OUTBOX_FILE_PATH" default:"./logs/events.log"`
+
+	WebhookMaxAttempts  int  `env:"WEBHOOK_MAX_ATTEMPTS,default=8"`      // attempts before a delivery is marked DEAD
+	WebhookTimeout      int  `env:"WEBHOOK_TIMEOUT,default=10"`          // seconds per delivery request
+	WebhookAllowPrivate bool `env:"WEBHOOK_ALLOW_PRIVATE,default=false"` // allow loopback and private URLs; local development only
+
+	PaymentProvider      string `env:"PAYMENT_PROVIDER" default:"fake"` // fake
+	PaymentWebhookSecret string `env:"PAYMENT_WEBHOOK_SECRET"`          // verifies provider webhooks; empty rejects them all
+
+	TracingExporter string `env:"TRACING_EXPORTER" default:"none"` // none, otlp, stdout or file
+	TracingFilePath string `env:"TRACING_FILE_PATH" default:"./logs/traces.jsonl"`
 }
 
 func NewEnv(ctx context.Context) *Env {
@@ -0,0 +1,44 @@
+package bootstrap
+
+import (
+	"io"
+	"strings"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/eventsink"
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+// NewEventSinks builds the outbox sinks listed in OUTBOX_SINKS. Unknown or failing sinks are
+// skipped with an error log. The webhook sink is added in main.go, as it needs the webhook usecase.
+func NewEventSinks(env *Env, l log.Logger) ([]domain.EventSink, []io.Closer) {
+	var (
+		sinks   []domain.EventSink
+		closers []io.Closer
+	)
+
+	for _, name := range strings.Split(env.OutboxSinks, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "stdout":
+			sinks = append(sinks, eventsink.NewStdout())
+		case "file":
+			path := env.OutboxFilePath
+			if path == "" {
+				path = "./logs/events.log"
+			}
+
+			sink, closer, err := eventsink.NewFile(path)
+			if err != nil {
+				l.Error("failed to open outbox event file", log.Error("error", err))
+				continue
+			}
+			sinks = append(sinks, sink)
+			closers = append(closers, closer)
+		default:
+			l.Error("unknown outbox sink", log.String("sink", name))
+		}
+	}
+
+	return sinks, closers
+}
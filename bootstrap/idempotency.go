@@ -0,0 +1,41 @@
+package bootstrap
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+// NewIdempotencyRetention builds the key retention from IDEMPOTENCY_RETENTION and the per-route
+// overrides in IDEMPOTENCY_RETENTION_ROUTES, e.g. "POST /api/order/checkout=4320". Invalid
+// overrides are skipped with an error log.
+func NewIdempotencyRetention(env *Env, l log.Logger) domain.IdempotencyRetention {
+	retention := domain.IdempotencyRetention{
+		Default: time.Duration(env.IdempotencyRetention) * time.Minute,
+		Routes:  map[string]time.Duration{},
+	}
+	if env.IdempotencyRetention <= 0 {
+		retention.Default = 24 * time.Hour
+	}
+
+	for _, override := range strings.Split(env.IdempotencyRetentionRoutes, ",") {
+		override = strings.TrimSpace(override)
+		if override == "" {
+			continue
+		}
+
+		route, value, ok := strings.Cut(override, "=")
+		minutes, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil || minutes <= 0 {
+			l.Error("invalid idempotency retention override", log.String("override", override))
+			continue
+		}
+
+		retention.Routes[strings.TrimSpace(route)] = time.Duration(minutes) * time.Minute
+	}
+
+	return retention
+}
@@ -0,0 +1,69 @@
+package bootstrap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/log/logrus"
+	"github.com/dyaksa/warehouse/pkg/log/slog"
+)
+
+// Log modules whose level can be changed on their own at runtime.
+const (
+	LogModuleOrder    = "order"
+	LogModuleTransfer = "transfer"
+	LogModuleWorker   = "worker"
+)
+
+// NewLogger builds the logger selected by LOG_BACKEND at LOG_LEVEL. The returned levels change it at runtime.
+func NewLogger(env *Env) (log.Logger, *log.Levels, error) {
+	level, err := log.ParseLevel(env.LogLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	levels := log.NewLevels(level, LogModuleOrder, LogModuleTransfer, LogModuleWorker)
+
+	redactor, err := NewRedactor(env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var l log.Logger
+	switch strings.ToLower(env.LogBackend) {
+	case "", "logrus":
+		l, err = logrus.New(
+			logrus.WithJSONFormatter(),
+			logrus.WithRedactor(redactor),
+			logrus.WithLevels(levels),
+		)
+	case "slog":
+		l, err = slog.New(
+			slog.WithJSONFormatter(),
+			slog.WithRedactor(redactor),
+			slog.WithLevels(levels),
+		)
+	default:
+		err = fmt.Errorf("unknown log backend %q", env.LogBackend)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return l, levels, nil
+}
+
+// NewRedactor builds the log redactor from LOG_REDACT_PATTERNS. An empty value enables every built-in pattern.
+func NewRedactor(env *Env) (*log.Redactor, error) {
+	if strings.TrimSpace(env.LogRedactPatterns) == "" {
+		return log.NewRedactor(), nil
+	}
+
+	patterns, err := log.RedactPatternsByName(strings.Split(env.LogRedactPatterns, ",")...)
+	if err != nil {
+		return nil, err
+	}
+
+	return log.NewRedactor(patterns...), nil
+}
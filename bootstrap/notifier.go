@@ -0,0 +1,28 @@
+package bootstrap
+
+import (
+	"io"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/notifier"
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+func NewNotifier(env *Env, l log.Logger) (domain.Notifier, io.Closer) {
+	switch env.NotifierDriver {
+	case "file":
+		path := env.NotifierFilePath
+		if path == "" {
+			path = "./logs/notifications.log"
+		}
+
+		n, closer, err := notifier.NewFile(path)
+		if err != nil {
+			l.Error("failed to open notification file, falling back to stdout", log.Error("error", err))
+			return notifier.NewStdout(), nil
+		}
+		return n, closer
+	default:
+		return notifier.NewStdout(), nil
+	}
+}
@@ -0,0 +1,25 @@
+package bootstrap
+
+import (
+	"strings"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/payment"
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+// NewPaymentProvider builds the provider named by PAYMENT_PROVIDER. Unknown names fall back to
+// the fake provider with an error log.
+func NewPaymentProvider(env *Env, l log.Logger) domain.PaymentProvider {
+	switch name := strings.TrimSpace(env.PaymentProvider); name {
+	case "", payment.FakeName:
+	default:
+		l.Error("unknown payment provider, using fake", log.String("provider", name))
+	}
+
+	if env.PaymentWebhookSecret == "" {
+		l.Warn("PAYMENT_WEBHOOK_SECRET is empty, payment webhooks will be rejected")
+	}
+
+	return payment.NewFake(env.PaymentWebhookSecret)
+}
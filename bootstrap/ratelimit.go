@@ -0,0 +1,51 @@
+package bootstrap
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/ratelimit"
+	"github.com/google/uuid"
+)
+
+// NewRateLimitStore builds the store named in RATE_LIMIT_STORE. Unknown names fall back to the
+// in-memory store with an error log.
+func NewRateLimitStore(env *Env, db pqsql.Client, l log.Logger) ratelimit.Store {
+	switch env.RateLimitStore {
+	case "postgres":
+		return ratelimit.PostgresStore(db.Database(), l)
+	case "memory", "":
+	default:
+		l.Error("unknown rate limit store, using memory", log.String("store", env.RateLimitStore))
+	}
+
+	return ratelimit.InMemoryStore()
+}
+
+// NewRateLimitShopQuotas builds the per-shop quotas in RATE_LIMIT_SHOP_QUOTAS, e.g.
+// "<shop id>=600", in requests per minute. Invalid entries are skipped with an error log.
+func NewRateLimitShopQuotas(env *Env, l log.Logger) map[string]ratelimit.Quota {
+	quotas := map[string]ratelimit.Quota{}
+
+	for _, entry := range strings.Split(env.RateLimitShopQuotas, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		shop, value, ok := strings.Cut(entry, "=")
+		shopID, idErr := uuid.Parse(strings.TrimSpace(shop))
+		limit, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+		if !ok || idErr != nil || err != nil || limit == 0 {
+			l.Error("invalid rate limit shop quota", log.String("quota", entry))
+			continue
+		}
+
+		quotas[shopID.String()] = ratelimit.Quota{Limit: uint(limit), Rate: time.Minute}
+	}
+
+	return quotas
+}
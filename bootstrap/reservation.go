@@ -0,0 +1,56 @@
+package bootstrap
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/google/uuid"
+)
+
+// NewReservationHoldLimits builds the reservation hold limits from RESERVATION_MAX_HOLD,
+// RESERVATION_MAX_EXTENSIONS and the per-shop overrides in RESERVATION_HOLD_SHOPS, e.g.
+// "<shop id>=120/5" for a 120 minute hold extended at most 5 times. Invalid overrides are skipped
+// with an error log.
+func NewReservationHoldLimits(env *Env, l log.Logger) domain.ReservationHoldLimits {
+	limits := domain.ReservationHoldLimits{
+		Default: domain.ReservationHoldLimit{
+			MaxHold:       time.Duration(env.ReservationMaxHold) * time.Minute,
+			MaxExtensions: env.ReservationMaxExtensions,
+		},
+		Shops:     map[uuid.UUID]domain.ReservationHoldLimit{},
+		Extension: time.Duration(env.ReservationMinutes) * time.Minute,
+	}
+	if env.ReservationMaxHold <= 0 {
+		limits.Default.MaxHold = time.Hour
+	}
+	if env.ReservationMinutes <= 0 {
+		limits.Extension = 15 * time.Minute
+	}
+
+	for _, override := range strings.Split(env.ReservationHoldShops, ",") {
+		override = strings.TrimSpace(override)
+		if override == "" {
+			continue
+		}
+
+		shop, value, ok := strings.Cut(override, "=")
+		hold, extensions, hasExtensions := strings.Cut(value, "/")
+		shopID, idErr := uuid.Parse(strings.TrimSpace(shop))
+		minutes, holdErr := strconv.Atoi(strings.TrimSpace(hold))
+		maxExtensions, extensionsErr := strconv.Atoi(strings.TrimSpace(extensions))
+		if !ok || !hasExtensions || idErr != nil || holdErr != nil || extensionsErr != nil || minutes <= 0 || maxExtensions < 0 {
+			l.Error("invalid reservation hold override", log.String("override", override))
+			continue
+		}
+
+		limits.Shops[shopID] = domain.ReservationHoldLimit{
+			MaxHold:       time.Duration(minutes) * time.Minute,
+			MaxExtensions: maxExtensions,
+		}
+	}
+
+	return limits
+}
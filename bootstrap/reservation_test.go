@@ -0,0 +1,35 @@
+package bootstrap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/sethvargo/go-envconfig"
+)
+
+func TestNewReservationHoldLimits_Defaults(t *testing.T) {
+	var env Env
+	if err := envconfig.Process(context.Background(), &envconfig.Config{
+		Target:   &env,
+		Lookuper: envconfig.MapLookuper(nil),
+	}); err != nil {
+		t.Fatalf("process env: %v", err)
+	}
+
+	limits := NewReservationHoldLimits(&env, log.Nop())
+
+	if limits.Default.MaxHold != 60*time.Minute {
+		t.Errorf("expected a 60 minute max hold, got %s", limits.Default.MaxHold)
+	}
+	if limits.Default.MaxExtensions != 3 {
+		t.Errorf("expected 3 extensions, got %d", limits.Default.MaxExtensions)
+	}
+	if limits.Extension != 15*time.Minute {
+		t.Errorf("expected a 15 minute extension, got %s", limits.Extension)
+	}
+	if len(limits.Shops) != 0 {
+		t.Errorf("expected no shop overrides, got %v", limits.Shops)
+	}
+}
@@ -0,0 +1,28 @@
+package bootstrap
+
+import (
+	"context"
+
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+)
+
+func NewTracing(ctx context.Context, env *Env, l log.Logger) func(context.Context) error {
+	path := env.TracingFilePath
+	if path == "" {
+		path = "./logs/traces.jsonl"
+	}
+
+	shutdown, err := tracing.Init(ctx, tracing.Config{
+		ServiceName: env.AppName,
+		Environment: env.AppEnv,
+		Exporter:    env.TracingExporter,
+		FilePath:    path,
+	})
+	if err != nil {
+		l.Error("failed to initialize tracing, spans will not be exported", log.Error("error", err))
+		return func(context.Context) error { return nil }
+	}
+
+	return shutdown
+}
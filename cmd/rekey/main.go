@@ -0,0 +1,140 @@
+// Command rekey re-encrypts and re-indexes user PII, then webhook signing secrets, with the
+// active crypto key version.
+//
+// It walks the users and webhook_subscriptions tables in id order, one transaction per batch,
+// and can run while the service is online. Interrupting it is safe: rerun with -after (or
+// -webhooks-after) set to the last logged id, or from the start, since rows already on the
+// active version are skipped.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/log/logrus"
+	"github.com/dyaksa/warehouse/repository"
+	"github.com/dyaksa/warehouse/usecase"
+	"github.com/google/uuid"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 200, "users re-keyed per transaction")
+	after := flag.String("after", uuid.Nil.String(), "resume after this user id")
+	webhooksAfter := flag.String("webhooks-after", uuid.Nil.String(), "resume after this webhook subscription id")
+	pause := flag.Duration("pause", 100*time.Millisecond, "pause between batches to limit load")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	l, err := logrus.New(
+		logrus.WithLevel("info"),
+		logrus.WithJSONFormatter(),
+		logrus.WithRedactor(log.NewRedactor()),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	afterID, err := uuid.Parse(*after)
+	if err != nil {
+		l.Fatal("invalid -after id", log.Error("error", err))
+	}
+
+	webhooksAfterID, err := uuid.Parse(*webhooksAfter)
+	if err != nil {
+		l.Fatal("invalid -webhooks-after id", log.Error("error", err))
+	}
+
+	if *batchSize <= 0 {
+		l.Fatal("-batch must be positive")
+	}
+
+	env := bootstrap.NewEnv(ctx)
+	db := bootstrap.NewPostgres(env, l)
+	defer bootstrap.CloseConnection(db, l)
+
+	crypto := bootstrap.NewDerivaleCrypto(l)
+	if crypto == nil {
+		os.Exit(1)
+	}
+
+	rotation := usecase.NewKeyRotationUsecase(db.Database(), repository.NewUserKeyRepository(db), repository.NewWebhookKeyRepository(db), crypto)
+
+	passes := []rekeyPass{
+		{name: "users", afterFlag: "-after", after: afterID, batch: rotation.RekeyBatch, remaining: rotation.Remaining},
+		{name: "webhook secrets", afterFlag: "-webhooks-after", after: webhooksAfterID, batch: rotation.RekeyWebhookBatch, remaining: rotation.RemainingWebhooks},
+	}
+	for _, p := range passes {
+		if !p.run(ctx, l, crypto.KeyVersion(), *batchSize, *pause) {
+			return
+		}
+	}
+}
+
+// rekeyPass re-keys one kind of encrypted record in id order.
+type rekeyPass struct {
+	name      string
+	afterFlag string
+	after     uuid.UUID
+	batch     func(ctx context.Context, afterID uuid.UUID, limit int) (domain.RekeyBatchResult, error)
+	remaining func(ctx context.Context) (int, error)
+}
+
+// run reports whether the pass finished, so that later passes only start once it has.
+func (p rekeyPass) run(ctx context.Context, l log.Logger, keyVersion int, batchSize int, pause time.Duration) bool {
+	remaining, err := p.remaining(ctx)
+	if err != nil {
+		l.Fatal(fmt.Sprintf("failed to count %s to re-key", p.name), log.Error("error", err))
+	}
+	l.Info(fmt.Sprintf("re-keying %d %s to key version %d", remaining, p.name, keyVersion))
+
+	afterID := p.after
+	total := 0
+	for ctx.Err() == nil {
+		result, err := p.batch(ctx, afterID, batchSize)
+		if err != nil {
+			l.Fatal(fmt.Sprintf("batch of %s after %s failed, resume with %s %s", p.name, afterID, p.afterFlag, afterID), log.Error("error", err))
+		}
+
+		total += result.Processed
+		afterID = result.LastID
+		l.Info(fmt.Sprintf("re-keyed %d %s, last id %s", total, p.name, afterID))
+
+		if result.Done {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(pause):
+		}
+	}
+
+	if ctx.Err() != nil {
+		l.Warn(fmt.Sprintf("interrupted, resume with %s %s", p.afterFlag, afterID))
+		return false
+	}
+
+	remaining, err = p.remaining(ctx)
+	if err != nil {
+		l.Fatal(fmt.Sprintf("failed to count %s to re-key", p.name), log.Error("error", err))
+	}
+
+	// Rows locked by live traffic are skipped and need another pass.
+	if remaining > 0 {
+		l.Warn(fmt.Sprintf("%d %s still on an older key version, rerun to finish", remaining, p.name))
+		return true
+	}
+
+	l.Info(fmt.Sprintf("all %s re-keyed", p.name))
+	return true
+}
@@ -30,5 +30,5 @@ func (u AuthRegisterRequest) MustFormattedPhone() string {
 
 type AuthUsecase interface {
 	Register(ctx context.Context, payload AuthRegisterRequest) (User, error)
-	Login(ctx context.Context, payload AuthLoginRequest) (string, error)
+	Login(ctx context.Context, payload AuthLoginRequest) (LoginResult, error)
 }
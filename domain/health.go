@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type HealthStatus string
+
+const (
+	HealthOK   HealthStatus = "ok"
+	HealthFail HealthStatus = "fail"
+)
+
+// HealthCheckResult is the outcome of one readiness dependency check.
+type HealthCheckResult struct {
+	Name   string       `json:"name"`
+	Status HealthStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// HealthReport aggregates readiness checks. Status is ok only when every check passed.
+type HealthReport struct {
+	Status HealthStatus        `json:"status"`
+	Checks []HealthCheckResult `json:"checks"`
+}
+
+// WorkerStatus reports the progress of a periodic background worker.
+type WorkerStatus interface {
+	LastSuccess() time.Time
+	Interval() time.Duration
+}
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (int64, error)
+}
+
+type HealthUsecase interface {
+	Ready(ctx context.Context) HealthReport
+	// SetShuttingDown makes readiness fail so load balancers drain traffic before the server stops.
+	SetShuttingDown()
+}
@@ -3,12 +3,84 @@ package domain
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// IdempotencyKeyHeader carries the client-chosen key that makes a mutating request safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ErrIdempotencyInFlight is returned while the first request with the same key is still being processed.
+var ErrIdempotencyInFlight = errors.New("idempotency key in flight")
+
+// IdempotencyRetention says how long keys are kept. Routes overrides Default for a method and route
+// pattern, e.g. "POST /api/order/checkout".
+type IdempotencyRetention struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+// For returns the retention of keys sent to route.
+func (r IdempotencyRetention) For(route string) time.Duration {
+	if retention, ok := r.Routes[route]; ok {
+		return retention
+	}
+
+	return r.Default
+}
+
+// IdempotencyKey identifies a request: the header value, the method and path it was sent to and
+// the caller. The same key sent by another caller or to another endpoint is unrelated.
+type IdempotencyKey struct {
+	Key       string
+	Endpoint  string
+	Principal string
+}
+
+// IdempotencyRecord is a request seen before. StatusCode is nil until its response is stored.
+type IdempotencyRecord struct {
+	IdempotencyKey
+	PayloadHash  string
+	StatusCode   *int
+	ContentType  string
+	ResponseBody []byte
+	LockedAt     time.Time
+	ExpiresAt    time.Time
+}
+
+// IdempotentResponse is a stored response to replay.
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
 type IdempotencyRequestRepository interface {
-	BeginKey(ctx context.Context, tx *sql.Tx, key, endpoint, payloadHash string) (isNew bool, err error)
-	LoadIfExists(ctx context.Context, tx *sql.Tx, key, endpoint string) (payloadHash string, orderID *uuid.UUID, responseJSON []byte, exists bool, err error)
-	SaveResponse(ctx context.Context, tx *sql.Tx, key, endpoint string, orderID uuid.UUID, responseJSON []byte) error
+	// Claim records key as in flight under lease. It reports false when the key is already recorded.
+	Claim(ctx context.Context, tx *sql.Tx, key IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time) (bool, error)
+	GetForUpdate(ctx context.Context, tx *sql.Tx, key IdempotencyKey) (*IdempotencyRecord, error)
+	// Reclaim records an expired key, or an in-flight key whose request was abandoned, as in flight
+	// again under lease.
+	Reclaim(ctx context.Context, tx *sql.Tx, key IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time) error
+	// Complete stores the response of the request holding lease, or returns sql.ErrNoRows when the
+	// key was taken over by another request.
+	Complete(ctx context.Context, key IdempotencyKey, lease uuid.UUID, response IdempotentResponse) error
+	// Release forgets an in-flight key held by lease so the request can be retried with it. A key
+	// taken over by another request is left as it is.
+	Release(ctx context.Context, key IdempotencyKey, lease uuid.UUID) error
+	// DeleteExpired deletes up to limit expired keys and returns how many it deleted.
+	DeleteExpired(ctx context.Context, limit int) (int, error)
+}
+
+type IdempotencyUsecase interface {
+	// Begin claims key for retention for a request whose body hashes to payloadHash. It returns the
+	// stored response when the request was already completed, or the lease the caller then processes
+	// the request under and settles the key with.
+	Begin(ctx context.Context, key IdempotencyKey, payloadHash string, retention time.Duration) (uuid.UUID, *IdempotentResponse, error)
+	Complete(ctx context.Context, key IdempotencyKey, lease uuid.UUID, response IdempotentResponse) error
+	Release(ctx context.Context, key IdempotencyKey, lease uuid.UUID) error
+	// PurgeExpired deletes one batch of expired keys and returns how many it deleted.
+	PurgeExpired(ctx context.Context, batchSize int) (int, error)
 }
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dyaksa/warehouse/pkg/paginator"
+	"github.com/google/uuid"
+)
+
+// JobType names a kind of background job and selects its handler.
+type JobType string
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "PENDING"
+	JobSucceeded JobStatus = "SUCCEEDED"
+	JobDead      JobStatus = "DEAD" // attempts exhausted; only a manual retry runs it again
+	JobCancelled JobStatus = "CANCELLED"
+)
+
+// DefaultJobMaxAttempts applies to jobs enqueued without a limit of their own.
+const DefaultJobMaxAttempts = 5
+
+// JobPayload is a typed job argument, stored as JSON.
+type JobPayload interface {
+	JobType() JobType
+}
+
+// Job is one unit of background work with the outcome of its latest attempt.
+type Job struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        JobType         `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      JobStatus       `json:"status"`
+	RunAt       time.Time       `json:"run_at"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   *string         `json:"last_error"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	LockedUntil *time.Time      `json:"locked_until"` // set while a worker runs the job
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// JobHandler runs one job. An error retries it with backoff until its attempts are exhausted.
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
+
+// JobHandlers registers the handler of each job type a worker runs.
+type JobHandlers map[JobType]JobHandler
+
+// HandleJob adapts a handler of typed payloads. A payload that does not decode fails the job.
+func HandleJob[T JobPayload](handle func(ctx context.Context, payload T) error) JobHandler {
+	return func(ctx context.Context, raw json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return fmt.Errorf("decode %T payload: %w", payload, err)
+		}
+
+		return handle(ctx, payload)
+	}
+}
+
+// EnqueueJobOptions tunes a job. The zero value runs it as soon as possible with the default attempts.
+type EnqueueJobOptions struct {
+	RunAt       time.Time
+	MaxAttempts int
+}
+
+// JobFilter narrows the admin job list. Empty fields match every job.
+type JobFilter struct {
+	Status JobStatus `form:"status" binding:"omitempty,oneof=PENDING SUCCEEDED DEAD CANCELLED" example:"DEAD"`
+	Type   JobType   `form:"type" binding:"omitempty,max=64" example:"report.generate"`
+}
+
+type JobRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, job *Job) error
+	GetForUpdate(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*Job, error)
+	List(ctx context.Context, filter JobFilter, limit, offset int) ([]Job, int, error)
+	// ClaimDue leases the next due pending job of types for lease, counts the attempt and returns it,
+	// or sql.ErrNoRows when none is due. Jobs leased by other workers are skipped until their lease
+	// runs out; those whose lease ran out on their last attempt are marked DEAD instead.
+	ClaimDue(ctx context.Context, tx *sql.Tx, types []JobType, lease time.Duration) (*Job, error)
+	// MarkSucceeded, MarkRetry and MarkDead record the outcome of claim, a job as ClaimDue returned it,
+	// and release its lease. They return sql.ErrNoRows when the claim no longer holds the job: it was
+	// cancelled while running, or its lease ran out and another worker claimed it.
+	MarkSucceeded(ctx context.Context, tx *sql.Tx, claim *Job) error
+	MarkRetry(ctx context.Context, tx *sql.Tx, claim *Job, lastError string, runAt time.Time) error
+	MarkDead(ctx context.Context, tx *sql.Tx, claim *Job, lastError string) error
+	// Requeue makes a job pending again, due now and unleased, with a fresh retry budget.
+	Requeue(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
+	Cancel(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
+}
+
+type JobUsecase interface {
+	// Enqueue queues payload in tx, so the job only exists if the surrounding change commits.
+	Enqueue(ctx context.Context, tx *sql.Tx, payload JobPayload, options EnqueueJobOptions) (*Job, error)
+	// RunBatch runs up to limit due jobs and reports how many it picked.
+	RunBatch(ctx context.Context, limit int) (int, error)
+	List(ctx context.Context, filter JobFilter, pagination paginator.PaginationRequest) (*paginator.PaginationResult[Job], error)
+	// Retry requeues a dead or cancelled job.
+	Retry(ctx context.Context, id uuid.UUID) (*Job, error)
+	// Cancel stops a pending job from running.
+	Cancel(ctx context.Context, id uuid.UUID) (*Job, error)
+}
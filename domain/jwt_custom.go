@@ -5,7 +5,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// JwtCustomClaims is shared by access and challenge tokens. Access tokens have an empty Scope.
 type JwtCustomClaims struct {
-	ID uuid.UUID `json:"id,omitempty"`
+	ID    uuid.UUID `json:"id,omitempty"`
+	Scope string    `json:"scope,omitempty"`
 	jwt.StandardClaims
 }
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dyaksa/encryption-pii/crypto/types"
+	"github.com/google/uuid"
+)
+
+// UserCiphertexts is the raw encrypted state of a user as stored, before any key is applied.
+// Nil fields are NULL in the database.
+type UserCiphertexts struct {
+	ID              uuid.UUID
+	Email           []byte
+	EmailKeyVersion int
+	Phone           []byte
+	PhoneKeyVersion int
+	TOTPSecret      []byte
+	TOTPKeyVersion  int
+}
+
+// RekeyedUser carries values re-encrypted and re-indexed with KeyVersion.
+// Nil ciphers are left untouched.
+type RekeyedUser struct {
+	ID         uuid.UUID
+	KeyVersion int
+	Email      *types.AESCipher
+	EmailBidx  string
+	Phone      *types.AESCipher
+	PhoneBidx  string
+	TOTPSecret *types.AESCipher
+}
+
+// WebhookSecretCiphertext is the raw encrypted signing secret of a webhook subscription.
+type WebhookSecretCiphertext struct {
+	ID         uuid.UUID
+	Secret     []byte
+	KeyVersion int
+}
+
+// RekeyBatchResult reports the progress of one re-key batch. LastID is the cursor for the next batch.
+type RekeyBatchResult struct {
+	Processed int       `json:"processed"`
+	LastID    uuid.UUID `json:"last_id"`
+	Done      bool      `json:"done"`
+}
+
+type UserKeyRepository interface {
+	ListStale(ctx context.Context, tx *sql.Tx, keyVersion int, afterID uuid.UUID, limit int) ([]UserCiphertexts, error)
+	Rekey(ctx context.Context, tx *sql.Tx, user RekeyedUser) error
+	CountStale(ctx context.Context, keyVersion int) (int, error)
+}
+
+// WebhookKeyRepository finds and rewrites webhook signing secrets written under an older key version.
+type WebhookKeyRepository interface {
+	ListStale(ctx context.Context, tx *sql.Tx, keyVersion int, afterID uuid.UUID, limit int) ([]WebhookSecretCiphertext, error)
+	Rekey(ctx context.Context, tx *sql.Tx, id uuid.UUID, secret types.AESCipher, keyVersion int) error
+	CountStale(ctx context.Context, keyVersion int) (int, error)
+}
+
+type KeyRotationUsecase interface {
+	RekeyBatch(ctx context.Context, afterID uuid.UUID, limit int) (RekeyBatchResult, error)
+	Remaining(ctx context.Context) (int, error)
+	// RekeyWebhookBatch and RemainingWebhooks do the same for webhook signing secrets.
+	RekeyWebhookBatch(ctx context.Context, afterID uuid.UUID, limit int) (RekeyBatchResult, error)
+	RemainingWebhooks(ctx context.Context) (int, error)
+}
@@ -0,0 +1,8 @@
+package domain
+
+// SetLogLevelRequest represents the request payload for temporarily changing the log level
+type SetLogLevelRequest struct {
+	Level              string `json:"level" binding:"required,oneof=debug info warn error" example:"debug" description:"Level to log at"`
+	Module             string `json:"module" binding:"omitempty,oneof=order transfer worker" example:"order" description:"Module to change; omit for the whole process"`
+	RevertAfterMinutes int    `json:"revert_after_minutes" binding:"omitempty,min=1,max=1440" example:"15" description:"Minutes until the previous level is restored, default 15"`
+}
@@ -13,12 +13,14 @@ import (
 type OrderStatus string
 
 const (
-	StatusPending         OrderStatus = "PENDING"
-	StatusAwaitingPayment OrderStatus = "AWAITING_PAYMENT"
-	StatusPaid            OrderStatus = "PAID"
-	StatusCancelled       OrderStatus = "CANCELLED"
-	StatusExpired         OrderStatus = "EXPIRED"
-	StatusFulfilled       OrderStatus = "FULFILLED"
+	StatusPending           OrderStatus = "PENDING"
+	StatusAwaitingPayment   OrderStatus = "AWAITING_PAYMENT"
+	StatusPaid              OrderStatus = "PAID"
+	StatusCancelled         OrderStatus = "CANCELLED"
+	StatusExpired           OrderStatus = "EXPIRED"
+	StatusFulfilled         OrderStatus = "FULFILLED"
+	StatusPartiallyRefunded OrderStatus = "PARTIALLY_REFUNDED"
+	StatusRefunded          OrderStatus = "REFUNDED"
 )
 
 var (
@@ -35,8 +37,10 @@ type Order struct {
 	Items         []OrderItem
 	Total         int64
 	ReservedUntil *time.Time
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	// ReservationExtensions counts how many times the reservation hold was extended.
+	ReservationExtensions int
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
 }
 
 type OrderItem struct {
@@ -54,8 +58,15 @@ const (
 	ResvCommitted ReservationStatus = "COMMITTED"
 	ResvReleased  ReservationStatus = "RELEASED"
 	ResvExpired   ReservationStatus = "EXPIRED"
+	// ResvQuarantined reservations failed to release too many times and need an operator; they
+	// still hold their stock.
+	ResvQuarantined ReservationStatus = "QUARANTINED"
 )
 
+// DefaultMaxReleaseAttempts is how many times releasing an expired reservation may fail before it
+// is quarantined, unless configured otherwise.
+const DefaultMaxReleaseAttempts = 5
+
 type Reservation struct {
 	ID          uuid.UUID
 	OrderID     uuid.UUID
@@ -64,8 +75,24 @@ type Reservation struct {
 	Qty         int
 	Status      ReservationStatus
 	ExpiresAt   time.Time
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	// ReleaseAttempts counts failed attempts to release the reservation after it expired.
+	ReleaseAttempts int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// QuarantinedReservation is a reservation the stock release worker gave up on, for an operator to
+// retry or release.
+type QuarantinedReservation struct {
+	ID               uuid.UUID `json:"id"`
+	OrderID          uuid.UUID `json:"order_id"`
+	ProductID        uuid.UUID `json:"product_id"`
+	WarehouseID      uuid.UUID `json:"warehouse_id"`
+	Qty              int       `json:"qty"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	ReleaseAttempts  int       `json:"release_attempts"`
+	LastReleaseError *string   `json:"last_release_error"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 type CheckoutItem struct {
@@ -78,8 +105,6 @@ type CheckoutInput struct {
 	ShopID             string         `json:"shop_id" binding:"required"`
 	Items              []CheckoutItem `json:"items" binding:"required,dive,required"`
 	UserID             string         `json:"user_id" binding:"required"`
-	IdemKey            string         `json:"idem_key"`
-	PayloadHash        string         `json:"payload_hash"`
 	ReservationTTL     time.Duration  `json:"reservation_ttl"`     // in seconds (for internal use)
 	ReservationMinutes int            `json:"reservation_minutes"` // in minutes (for API convenience)
 }
@@ -91,6 +116,43 @@ type CheckoutOutput struct {
 	Status               string    `json:"status"`
 }
 
+// ExtendReservationRequest represents the request payload for extending an order's reservation.
+// Without minutes, the reservation is extended by 15 minutes.
+type ExtendReservationRequest struct {
+	Minutes int `json:"minutes" binding:"omitempty,gt=0,lte=1440" example:"10" description:"Minutes to add to the reservation"`
+}
+
+type ExtendReservationOutput struct {
+	OrderID              uuid.UUID `json:"order_id"`
+	ReservationExpiresAt time.Time `json:"reservation_expires_at"`
+	Extensions           int       `json:"extensions"`
+	ExtensionsLeft       int       `json:"extensions_left"`
+	HoldUntil            time.Time `json:"hold_until"` // the reservation cannot be extended past this
+}
+
+// ReservationHoldLimit caps how long an unpaid order may keep its stock reserved by extending it.
+type ReservationHoldLimit struct {
+	MaxHold       time.Duration // from checkout until the reservation expires for good
+	MaxExtensions int
+}
+
+// ReservationHoldLimits is the default hold limit with per-shop overrides, and the extension
+// applied when a request does not name one.
+type ReservationHoldLimits struct {
+	Default   ReservationHoldLimit
+	Shops     map[uuid.UUID]ReservationHoldLimit
+	Extension time.Duration
+}
+
+// For returns the hold limit of shopID.
+func (l ReservationHoldLimits) For(shopID uuid.UUID) ReservationHoldLimit {
+	if limit, ok := l.Shops[shopID]; ok {
+		return limit
+	}
+
+	return l.Default
+}
+
 type OrderListItem struct {
 	ID                   uuid.UUID  `json:"order_id"`
 	Total                int64      `json:"total"`
@@ -102,24 +164,50 @@ type OrderListItem struct {
 
 type OrderRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, o *Order) error
-	Updatestatus(ctx context.Context, orderID uuid.UUID, status OrderStatus) error
+	Updatestatus(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, status OrderStatus) error
 	GetByID(ctx context.Context, orderID uuid.UUID) (*Order, error)
+	// GetByIDForUpdate locks the order until tx ends.
+	GetByIDForUpdate(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (*Order, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]OrderListItem, int, error)
+	GetByShopID(ctx context.Context, shopID uuid.UUID, limit, offset int) ([]OrderListItem, int, error)
+	// ExtendReservation moves the order's reservation expiry to expiresAt and counts the extension.
+	ExtendReservation(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, expiresAt time.Time) error
 }
 
 type OrderItemRepository interface {
 	BulkInsert(ctx context.Context, tx *sql.Tx, items []OrderItem) error
+	GetByOrderID(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]OrderItem, error)
 }
 
 type ReservationRepository interface {
 	CreateMany(ctx context.Context, tx *sql.Tx, reservations []Reservation) error
+	// PickExpiredForUpdate locks expired PENDING reservations, those with fewer failed release
+	// attempts first.
 	PickExpiredForUpdate(ctx context.Context, tx *sql.Tx, limit int) ([]Reservation, error)
+	// MarkExpired expires a PENDING or QUARANTINED reservation.
 	MarkExpired(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
-	MarkCommitted(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error
-	MarkReleased(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error
-	PendingCountByOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error)
+	// RecordReleaseFailure counts a failed release of a PENDING reservation and quarantines it once
+	// it has failed maxAttempts times. It returns the updated reservation.
+	RecordReleaseFailure(ctx context.Context, tx *sql.Tx, id uuid.UUID, reason string, maxAttempts int) (*Reservation, error)
+	// MarkCommitted and MarkReleased move the order's PENDING reservations on and return how many
+	// they moved.
+	MarkCommitted(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error)
+	MarkReleased(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error)
+	// HeldCountByOrder counts the order's reservations that still hold stock, by status: those
+	// PENDING and those QUARANTINED.
+	HeldCountByOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (pending int, quarantined int, err error)
+	ListQuarantined(ctx context.Context, limit, offset int) ([]QuarantinedReservation, int, error)
+	// GetQuarantinedForUpdate locks a QUARANTINED reservation, or returns sql.ErrNoRows if id is not one.
+	GetQuarantinedForUpdate(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*Reservation, error)
+	// RequeueQuarantined makes a QUARANTINED reservation PENDING again with a fresh attempt budget.
+	RequeueQuarantined(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
 	Retrieve(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*Reservation, error)
 	GetByOrderID(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]Reservation, error)
+	// LockPendingByOrder locks the order's PENDING reservations until tx ends. It waits for rows the
+	// stock release worker holds, and leaves out those it expired meanwhile.
+	LockPendingByOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]Reservation, error)
+	// ExtendPending moves the expiry of the order's PENDING reservations to expiresAt.
+	ExtendPending(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, expiresAt time.Time) error
 }
 
 type MovementRepository interface {
@@ -130,6 +218,12 @@ type OrderUsecase interface {
 	Checkout(ctx context.Context, input CheckoutInput) (*CheckoutOutput, error)
 	ConfirmPayment(ctx context.Context, orderID uuid.UUID) error
 	CancelOrder(ctx context.Context, orderID uuid.UUID) error
+	ExtendReservation(ctx context.Context, orderID uuid.UUID, input ExtendReservationRequest) (*ExtendReservationOutput, error)
+	RefundOrder(ctx context.Context, orderID uuid.UUID, input RefundOrderRequest) (*Refund, error)
+	// RefundOrderAsOwner refunds an order of a shop owned by userID.
+	RefundOrderAsOwner(ctx context.Context, orderID, userID uuid.UUID, input RefundOrderRequest) (*Refund, error)
+	ListRefunds(ctx context.Context, orderID uuid.UUID) ([]Refund, error)
 	GetOrderDetails(ctx context.Context, orderID uuid.UUID) (*Order, error)
 	GetUserOrders(ctx context.Context, userID uuid.UUID, pagination paginator.PaginationRequest) (*paginator.PaginationResult[OrderListItem], error)
+	GetShopOrders(ctx context.Context, shopID uuid.UUID, pagination paginator.PaginationRequest) (*paginator.PaginationResult[OrderListItem], error)
 }
@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType names a domain event published through the outbox.
+type EventType string
+
+const (
+	EventOrderCheckedOut          EventType = "order.checked_out"
+	EventOrderPaymentConfirmed    EventType = "order.payment_confirmed"
+	EventOrderCancelled           EventType = "order.cancelled"
+	EventOrderExpired             EventType = "order.expired"
+	EventOrderRefunded            EventType = "order.refunded"
+	EventOrderReservationExtended EventType = "order.reservation_extended"
+	EventReservationExpired       EventType = "reservation.expired"
+	EventStockDecreased           EventType = "stock.decreased"
+	EventTransferCompleted        EventType = "transfer.completed"
+)
+
+// Aggregate types. Events of the same aggregate ID are delivered in the order they were written.
+const (
+	AggregateOrder    = "order"
+	AggregateTransfer = "transfer"
+	AggregateProduct  = "product"
+)
+
+// Event is a typed payload stored in the outbox in the same transaction as the change it describes.
+type Event interface {
+	EventType() EventType
+	AggregateType() string
+	AggregateID() uuid.UUID
+}
+
+type OrderEventItem struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	WarehouseID uuid.UUID `json:"warehouse_id"`
+	Qty         int       `json:"qty"`
+	Price       int64     `json:"price,omitempty"`
+}
+
+// OrderCheckedOut is emitted when stock is reserved for a new order.
+type OrderCheckedOut struct {
+	OrderID              uuid.UUID        `json:"order_id"`
+	ShopID               uuid.UUID        `json:"shop_id"`
+	UserID               uuid.UUID        `json:"user_id"`
+	Total                int64            `json:"total"`
+	Items                []OrderEventItem `json:"items"`
+	ReservationExpiresAt time.Time        `json:"reservation_expires_at"`
+}
+
+func (e OrderCheckedOut) EventType() EventType   { return EventOrderCheckedOut }
+func (e OrderCheckedOut) AggregateType() string  { return AggregateOrder }
+func (e OrderCheckedOut) AggregateID() uuid.UUID { return e.OrderID }
+
+// OrderPaymentConfirmed is emitted when reserved stock is committed for a paid order.
+type OrderPaymentConfirmed struct {
+	OrderID     uuid.UUID        `json:"order_id"`
+	ShopID      uuid.UUID        `json:"shop_id"`
+	Total       int64            `json:"total"`
+	Items       []OrderEventItem `json:"items"`
+	ConfirmedAt time.Time        `json:"confirmed_at"`
+}
+
+func (e OrderPaymentConfirmed) EventType() EventType   { return EventOrderPaymentConfirmed }
+func (e OrderPaymentConfirmed) AggregateType() string  { return AggregateOrder }
+func (e OrderPaymentConfirmed) AggregateID() uuid.UUID { return e.OrderID }
+
+// OrderCancelled is emitted when an order is cancelled and its reservations are released.
+type OrderCancelled struct {
+	OrderID     uuid.UUID `json:"order_id"`
+	ShopID      uuid.UUID `json:"shop_id"`
+	CancelledAt time.Time `json:"cancelled_at"`
+}
+
+func (e OrderCancelled) EventType() EventType   { return EventOrderCancelled }
+func (e OrderCancelled) AggregateType() string  { return AggregateOrder }
+func (e OrderCancelled) AggregateID() uuid.UUID { return e.OrderID }
+
+// OrderExpired is emitted when the last pending reservation of an unpaid order expires.
+type OrderExpired struct {
+	OrderID   uuid.UUID `json:"order_id"`
+	ShopID    uuid.UUID `json:"shop_id"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+func (e OrderExpired) EventType() EventType   { return EventOrderExpired }
+func (e OrderExpired) AggregateType() string  { return AggregateOrder }
+func (e OrderExpired) AggregateID() uuid.UUID { return e.OrderID }
+
+// OrderReservationExtended is emitted when an unpaid order extends the hold on its reserved stock.
+type OrderReservationExtended struct {
+	OrderID              uuid.UUID `json:"order_id"`
+	ShopID               uuid.UUID `json:"shop_id"`
+	ReservationExpiresAt time.Time `json:"reservation_expires_at"`
+	Extensions           int       `json:"extensions"`
+}
+
+func (e OrderReservationExtended) EventType() EventType   { return EventOrderReservationExtended }
+func (e OrderReservationExtended) AggregateType() string  { return AggregateOrder }
+func (e OrderReservationExtended) AggregateID() uuid.UUID { return e.OrderID }
+
+// ReservationExpired is emitted when the stock release worker returns an expired reservation to stock.
+// It belongs to the order aggregate so it is ordered with the order's other events.
+type ReservationExpired struct {
+	ReservationID uuid.UUID `json:"reservation_id"`
+	OrderID       uuid.UUID `json:"order_id"`
+	ProductID     uuid.UUID `json:"product_id"`
+	WarehouseID   uuid.UUID `json:"warehouse_id"`
+	Qty           int       `json:"qty"`
+	ExpiredAt     time.Time `json:"expired_at"`
+}
+
+func (e ReservationExpired) EventType() EventType   { return EventReservationExpired }
+func (e ReservationExpired) AggregateType() string  { return AggregateOrder }
+func (e ReservationExpired) AggregateID() uuid.UUID { return e.OrderID }
+
+// StockDecreased is emitted whenever stock on hand in a shop's warehouse goes down: when a paid
+// order is committed and when a transfer takes stock out of its source warehouse.
+type StockDecreased struct {
+	ShopID      uuid.UUID `json:"shop_id"`
+	ProductID   uuid.UUID `json:"product_id"`
+	WarehouseID uuid.UUID `json:"warehouse_id"`
+	Qty         int       `json:"qty"`
+	Reason      string    `json:"reason"`
+}
+
+func (e StockDecreased) EventType() EventType   { return EventStockDecreased }
+func (e StockDecreased) AggregateType() string  { return AggregateProduct }
+func (e StockDecreased) AggregateID() uuid.UUID { return e.ProductID }
+
+type TransferEventItem struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Qty       int32     `json:"qty"`
+}
+
+// TransferCompleted is emitted when stock has moved to the destination warehouse.
+type TransferCompleted struct {
+	TransferID      uuid.UUID           `json:"transfer_id"`
+	FromWarehouseID uuid.UUID           `json:"from_warehouse_id"`
+	ToWarehouseID   uuid.UUID           `json:"to_warehouse_id"`
+	Items           []TransferEventItem `json:"items"`
+	CompletedAt     time.Time           `json:"completed_at"`
+}
+
+func (e TransferCompleted) EventType() EventType   { return EventTransferCompleted }
+func (e TransferCompleted) AggregateType() string  { return AggregateTransfer }
+func (e TransferCompleted) AggregateID() uuid.UUID { return e.TransferID }
+
+// OutboxEvent is a stored event awaiting or after delivery.
+type OutboxEvent struct {
+	ID            uuid.UUID       `json:"id"`
+	Seq           int64           `json:"seq"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	EventType     EventType       `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int             `json:"attempts"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// EventSink delivers outbox events to another system. Publish must be safe to repeat:
+// delivery is at-least-once, so an event may be published again after a crash or a failed sibling sink.
+type EventSink interface {
+	Name() string
+	Publish(ctx context.Context, event OutboxEvent) error
+}
+
+type OutboxRepository interface {
+	Append(ctx context.Context, tx *sql.Tx, event Event) error
+	// PickPending locks deliverable events. Only the oldest pending event of each aggregate is returned,
+	// so a failing event holds back later events of the same aggregate.
+	PickPending(ctx context.Context, tx *sql.Tx, limit int) ([]OutboxEvent, error)
+	MarkPublished(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
+	MarkFailed(ctx context.Context, tx *sql.Tx, id uuid.UUID, lastError string, nextAttemptAt time.Time) error
+}
+
+type OutboxRelayUsecase interface {
+	// RelayBatch delivers up to limit events and reports how many were published.
+	RelayBatch(ctx context.Context, limit int) (int, error)
+}
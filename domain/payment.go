@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentIntentStatus string
+
+const (
+	PaymentRequiresPayment PaymentIntentStatus = "REQUIRES_PAYMENT"
+	PaymentSucceeded       PaymentIntentStatus = "SUCCEEDED"
+	PaymentFailed          PaymentIntentStatus = "FAILED"
+	PaymentRefunded        PaymentIntentStatus = "REFUNDED"
+)
+
+// PaymentEventType is the provider-neutral kind of a payment webhook event.
+type PaymentEventType string
+
+const (
+	PaymentEventSucceeded PaymentEventType = "succeeded"
+	PaymentEventFailed    PaymentEventType = "failed"
+	PaymentEventRefunded  PaymentEventType = "refunded"
+)
+
+// ErrInvalidPaymentSignature is returned by a PaymentProvider for webhooks it did not sign.
+var ErrInvalidPaymentSignature = errors.New("invalid payment webhook signature")
+
+// PaymentIntent is the attempt to collect the total of an order through a payment provider.
+// CheckoutURL is where the customer completes the payment.
+type PaymentIntent struct {
+	ID          uuid.UUID           `json:"id"`
+	OrderID     uuid.UUID           `json:"order_id"`
+	Provider    string              `json:"provider"`
+	ProviderRef string              `json:"provider_ref"`
+	Amount      int64               `json:"amount"`
+	Status      PaymentIntentStatus `json:"status"`
+	CheckoutURL string              `json:"checkout_url"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// PaymentEvent is a verified provider webhook. ID is unique per provider and is used to drop redeliveries.
+// A refunded event carries the amount of that refund, and RefundID when the refund was requested
+// through PaymentProvider.Refund.
+type PaymentEvent struct {
+	ID          string
+	Type        PaymentEventType
+	ProviderRef string
+	Amount      int64
+	RefundID    uuid.UUID
+}
+
+// PaymentProvider is the integration with an external payment service.
+type PaymentProvider interface {
+	Name() string
+	// CreateIntent registers the payment of order on the provider side and returns
+	// the provider reference of the intent and the URL the customer pays at.
+	CreateIntent(ctx context.Context, intentID uuid.UUID, order *Order) (providerRef string, checkoutURL string, err error)
+	// ParseEvent verifies the signature of a webhook request and decodes it.
+	// Events of no interest are reported with an empty Type.
+	ParseEvent(header http.Header, body []byte) (PaymentEvent, error)
+	// Refund returns amount of the intent known as providerRef to the customer. The provider
+	// treats repeated calls with the same refundID as one refund.
+	Refund(ctx context.Context, providerRef string, refundID uuid.UUID, amount int64) error
+}
+
+type PaymentRepository interface {
+	CreateIntent(ctx context.Context, tx *sql.Tx, intent *PaymentIntent) error
+	// GetOpenIntent returns the intent of orderID still waiting for payment, or sql.ErrNoRows.
+	GetOpenIntent(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (*PaymentIntent, error)
+	// GetPaidIntent returns the intent orderID was paid with, or sql.ErrNoRows.
+	GetPaidIntent(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (*PaymentIntent, error)
+	// GetIntentForUpdate locks the intent known to provider as providerRef.
+	GetIntentForUpdate(ctx context.Context, tx *sql.Tx, provider string, providerRef string) (*PaymentIntent, error)
+	UpdateIntentStatus(ctx context.Context, tx *sql.Tx, id uuid.UUID, status PaymentIntentStatus) error
+	// RecordEvent reports false when the event was already recorded.
+	RecordEvent(ctx context.Context, tx *sql.Tx, provider string, event PaymentEvent, intentID uuid.UUID) (bool, error)
+}
+
+type PaymentUsecase interface {
+	// CreateIntent starts the payment of an order awaiting payment. An intent still open is returned as is.
+	CreateIntent(ctx context.Context, orderID uuid.UUID) (*PaymentIntent, error)
+	// HandleWebhook applies a provider webhook to its intent and order. Redelivered events are
+	// acknowledged without effect and reported as duplicate.
+	HandleWebhook(ctx context.Context, header http.Header, body []byte) (duplicate bool, err error)
+}
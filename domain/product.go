@@ -44,6 +44,8 @@ type RetrieveProduct struct {
 type ProductRepository interface {
 	Create(ctx context.Context, product *Product) (uuid.UUID, error)
 	RetrieveAll(ctx context.Context, limit, offset int) ([]RetrieveProduct, error)
+	// RetrieveByShop lists the products stocked in the shop's warehouses, with their stock there.
+	RetrieveByShop(ctx context.Context, shopID uuid.UUID, limit, offset int) ([]RetrieveProduct, error)
 }
 
 type ProductStockRepository interface {
@@ -56,5 +58,8 @@ type ProductStockRepository interface {
 
 type ProductUsecase interface {
 	Create(ctx context.Context, payload CreateProductRequest) error
+	// CreateForShop creates the product like Create, but only in a warehouse of shopID.
+	CreateForShop(ctx context.Context, shopID uuid.UUID, payload CreateProductRequest) error
 	RetrieveAll(ctx context.Context, pagination paginator.PaginationRequest) (*paginator.PaginationResult[RetrieveProduct], error)
+	RetrieveByShop(ctx context.Context, shopID uuid.UUID, pagination paginator.PaginationRequest) (*paginator.PaginationResult[RetrieveProduct], error)
 }
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefundableStatuses are the order statuses a refund can be issued from.
+var RefundableStatuses = []OrderStatus{StatusPaid, StatusFulfilled, StatusPartiallyRefunded}
+
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "PENDING"   // recorded, the payment provider has not returned the money yet
+	RefundSucceeded RefundStatus = "SUCCEEDED" // money returned, by the provider or outside the system
+)
+
+// Refund returns money for some or all items of a paid order. With Restock the refunded
+// quantities are put back on hand in the warehouse they were committed from.
+type Refund struct {
+	ID        uuid.UUID    `json:"id"`
+	OrderID   uuid.UUID    `json:"order_id"`
+	Amount    int64        `json:"amount"`
+	Reason    string       `json:"reason"`
+	Restock   bool         `json:"restock"`
+	Status    RefundStatus `json:"status"`
+	Items     []RefundItem `json:"items"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type RefundItem struct {
+	ID          uuid.UUID  `json:"id"`
+	RefundID    uuid.UUID  `json:"-"`
+	OrderItemID uuid.UUID  `json:"order_item_id"`
+	ProductID   uuid.UUID  `json:"product_id"`
+	Qty         int        `json:"qty"`
+	Amount      int64      `json:"amount"`
+	WarehouseID *uuid.UUID `json:"warehouse_id,omitempty"`
+}
+
+type RefundItemRequest struct {
+	OrderItemID string `json:"order_item_id" binding:"required,uuid" example:"0b9f7c2e-7f7e-4d55-9f4e-1f2a8a3c9d10"`
+	Qty         int    `json:"qty" binding:"required,gt=0" example:"1"`
+}
+
+// RefundOrderRequest represents the request payload for refunding an order.
+// Without items, everything not refunded yet is refunded.
+type RefundOrderRequest struct {
+	Items   []RefundItemRequest `json:"items" binding:"omitempty,dive"`
+	Restock bool                `json:"restock" example:"true" description:"Put the refunded quantities back on hand"`
+	Reason  string              `json:"reason" binding:"max=500" example:"damaged on arrival"`
+}
+
+// OrderRefunded is emitted when a refund is recorded for an order.
+type OrderRefunded struct {
+	OrderID  uuid.UUID        `json:"order_id"`
+	ShopID   uuid.UUID        `json:"shop_id"`
+	RefundID uuid.UUID        `json:"refund_id"`
+	Amount   int64            `json:"amount"`
+	Restock  bool             `json:"restock"`
+	Status   OrderStatus      `json:"status"`
+	Items    []OrderEventItem `json:"items"`
+}
+
+func (e OrderRefunded) EventType() EventType   { return EventOrderRefunded }
+func (e OrderRefunded) AggregateType() string  { return AggregateOrder }
+func (e OrderRefunded) AggregateID() uuid.UUID { return e.OrderID }
+
+// JobRefundIssue returns the money of a PENDING refund through the payment provider.
+const JobRefundIssue JobType = "refund.issue"
+
+// RefundIssueJob asks the payment provider to return the money of a refund once it is recorded, so
+// no provider call is made while the order is locked.
+type RefundIssueJob struct {
+	RefundID uuid.UUID `json:"refund_id"`
+}
+
+// JobType implements JobPayload.
+func (RefundIssueJob) JobType() JobType { return JobRefundIssue }
+
+type RefundRepository interface {
+	// Create stores the refund together with its items.
+	Create(ctx context.Context, tx *sql.Tx, refund *Refund) error
+	// Get returns the refund without its items, or sql.ErrNoRows.
+	Get(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*Refund, error)
+	UpdateStatus(ctx context.Context, tx *sql.Tx, id uuid.UUID, status RefundStatus) error
+	// RefundedQty returns the quantity already refunded per order item of orderID.
+	RefundedQty(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (refunded map[uuid.UUID]int, err error)
+	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]Refund, error)
+}
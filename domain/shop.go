@@ -9,6 +9,7 @@ import (
 type Shop struct {
 	ID        uuid.UUID
 	Name      string
+	OwnerID   *uuid.UUID
 	CreatedAt string
 }
 
@@ -33,7 +34,7 @@ type ShopRepository interface {
 }
 
 type ShopUsecase interface {
-	Create(ctx context.Context, payload CreateShopRequest) error
+	Create(ctx context.Context, ownerID uuid.UUID, payload CreateShopRequest) error
 	Retrieve(ctx context.Context, id uuid.UUID) (*Shop, error)
 	Update(ctx context.Context, payload UpdateShopRequest) error
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// StockReleaseState is the stock release worker state shared by all replicas.
+type StockReleaseState struct {
+	Paused        bool       // scheduled batches are skipped on every replica until resumed
+	LastRunAt     *time.Time // when a batch last finished, successfully or not
+	LastError     *string    // error of the last batch, nil if it succeeded
+	LastReleased  int        // reservations released by the last batch
+	TotalReleased int64      // reservations released by all batches
+}
+
+//go:generate mockery
+type StockReleaseStateRepository interface {
+	Get(ctx context.Context) (*StockReleaseState, error)
+	// SetPaused reports false if the worker already was in that state.
+	SetPaused(ctx context.Context, paused bool) (bool, error)
+	RecordRun(ctx context.Context, released int, lastError *string) error
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dyaksa/encryption-pii/crypto/types"
+	"github.com/google/uuid"
+)
+
+// TwoFactor holds the TOTP state of a user. Secret is encrypted at rest.
+type TwoFactor struct {
+	UserID     uuid.UUID
+	Secret     types.AESCipher
+	KeyVersion int
+	EnabledAt  *time.Time
+	LastStep   int64
+}
+
+// LoginResult is returned by Login. When TwoFactorRequired is set, only ChallengeToken is filled
+// and must be exchanged for an access token with a TOTP or recovery code.
+type LoginResult struct {
+	AccessToken       string `json:"access_token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+// TwoFactorEnrollment is returned when a user starts TOTP enrollment
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeRequest represents the request payload for confirming TOTP enrollment
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" example:"123456" description:"Code from the authenticator app"`
+}
+
+// TwoFactorDisableRequest represents the request payload for disabling two-factor authentication
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required" example:"password123" description:"Current password"`
+	Code     string `json:"code" binding:"required" example:"123456" description:"Code from the authenticator app or a recovery code"`
+}
+
+// TwoFactorLoginRequest represents the request payload for the second login step
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required" description:"Challenge token returned by login"`
+	Code           string `json:"code" binding:"required" example:"123456" description:"Code from the authenticator app or a recovery code"`
+}
+
+type TwoFactorRepository interface {
+	Get(ctx context.Context, tx *sql.Tx, userID uuid.UUID, fn func(data *TwoFactor) error) (*TwoFactor, error)
+	SaveSecret(ctx context.Context, tx *sql.Tx, userID uuid.UUID, secret types.AESCipher, keyVersion int) error
+	Enable(ctx context.Context, tx *sql.Tx, userID uuid.UUID, step int64) error
+	Disable(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error
+	UpdateLastStep(ctx context.Context, tx *sql.Tx, userID uuid.UUID, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codeHash string) (bool, error)
+}
+
+type TwoFactorUsecase interface {
+	Enroll(ctx context.Context, userID uuid.UUID) (TwoFactorEnrollment, error)
+	Activate(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	Disable(ctx context.Context, userID uuid.UUID, payload TwoFactorDisableRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	CompleteLogin(ctx context.Context, payload TwoFactorLoginRequest) (string, error)
+}
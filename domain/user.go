@@ -2,24 +2,127 @@ package domain
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/dyaksa/encryption-pii/crypto/types"
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrUserNotFound is returned by UserRepository lookups that match no user.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrIdentifierTaken is returned when an email or phone update collides with another user's.
+	ErrIdentifierTaken = errors.New("identifier already in use")
+)
+
 type User struct {
-	ID           uuid.UUID       `json:"id"`
-	Email        types.AESCipher `json:"email"`
-	Phone        types.AESCipher `json:"phone"`
-	EmailBidx    string          `json:"email_bidx" full_text_search:"true"`
-	PhoneBidx    string          `json:"phone_bidx" full_text_search:"true"`
-	PasswordHash string          `json:"password_hash"`
+	ID              uuid.UUID       `json:"id"`
+	Email           types.AESCipher `json:"email"`
+	Phone           types.AESCipher `json:"phone"`
+	EmailBidx       string          `json:"email_bidx" full_text_search:"true"`
+	PhoneBidx       string          `json:"phone_bidx" full_text_search:"true"`
+	PasswordHash    string          `json:"password_hash"`
+	EmailVerifiedAt *time.Time      `json:"email_verified_at,omitempty"`
+	PhoneVerifiedAt *time.Time      `json:"phone_verified_at,omitempty"`
+	TOTPEnabledAt   *time.Time      `json:"totp_enabled_at,omitempty"`
+	EmailKeyVersion int             `json:"-"`
+	PhoneKeyVersion int             `json:"-"`
+}
+
+// UserProfile is the decrypted view of a user returned by /api/user/me
+type UserProfile struct {
+	ID               uuid.UUID  `json:"id"`
+	Email            string     `json:"email"`
+	Phone            string     `json:"phone"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty"`
+	PhoneVerifiedAt  *time.Time `json:"phone_verified_at,omitempty"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+}
+
+// ChangeEmailRequest represents the request payload for changing the account email
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email" example:"new@example.com" description:"New email address"`
+	Password string `json:"password" binding:"required" example:"password123" description:"Current password"`
+}
+
+// ChangePhoneRequest represents the request payload for changing the account phone number
+type ChangePhoneRequest struct {
+	Phone    string `json:"phone" binding:"required,identifier" example:"+6281234567890" description:"New phone number with country code"`
+	Password string `json:"password" binding:"required" example:"password123" description:"Current password"`
+}
+
+// ChangePasswordRequest represents the request payload for changing the account password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"password123" description:"Current password"`
+	NewPassword     string `json:"new_password" binding:"required,min=8" example:"newpassword123" description:"New password (minimum 8 characters)"`
+}
+
+// DeleteAccountRequest represents the request payload for erasing the account
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required" example:"password123" description:"Current password"`
+}
+
+// UserDataExport is everything held about a user, returned by the data export endpoint
+type UserDataExport struct {
+	Profile    UserProfile           `json:"profile"`
+	Orders     []UserDataExportOrder `json:"orders"`
+	ExportedAt time.Time             `json:"exported_at"`
+}
+
+// UserDataExportOrder is an order in a data export together with its line items.
+type UserDataExportOrder struct {
+	OrderListItem
+	Items []UserDataExportOrderItem `json:"items"`
+}
+
+// UserDataExportOrderItem is an order line in a data export.
+type UserDataExportOrderItem struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Qty       int       `json:"qty"`
+	Price     int64     `json:"price"`
+}
+
+// ActiveUserChecker reports whether a user exists and has not been erased. JWT auth uses it so
+// tokens issued before an erasure stop working at once instead of at expiry.
+type ActiveUserChecker interface {
+	IsActive(ctx context.Context, id uuid.UUID) (bool, error)
 }
 
+// JobUserErased cleans up what an erased account left behind.
+const JobUserErased JobType = "user.erased"
+
+// UserErasedJob revokes the API keys and deletes the webhook subscriptions of an erased user, so
+// nothing keeps acting on their behalf.
+type UserErasedJob struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// JobType implements JobPayload.
+func (UserErasedJob) JobType() JobType { return JobUserErased }
+
 //go:generate mockery
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *User) error
-	GetUserByEmail(ctx context.Context, email_bidx string, fn func(data *User)) (*User, error)
-	GetUserByPhone(ctx context.Context, phone_bidx string, fn func(data *User)) (*User, error)
-	GetMailOrPhone(ctx context.Context, email_bidx, phone_bidx string, fn func(data *User)) (*User, error)
+	GetUserByEmail(ctx context.Context, email_bidx string, fn func(data *User) error) (*User, error)
+	GetUserByPhone(ctx context.Context, phone_bidx string, fn func(data *User) error) (*User, error)
+	GetMailOrPhone(ctx context.Context, email_bidx, phone_bidx string, fn func(data *User) error) (*User, error)
+	GetByID(ctx context.Context, id uuid.UUID, fn func(data *User) error) (*User, error)
+	IsActive(ctx context.Context, id uuid.UUID) (bool, error)
+	MarkEmailVerified(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
+	MarkPhoneVerified(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
+	UpdatePassword(ctx context.Context, tx *sql.Tx, id uuid.UUID, passwordHash string) error
+	UpdateEmail(ctx context.Context, tx *sql.Tx, id uuid.UUID, email types.AESCipher, emailBidx string, keyVersion int) error
+	UpdatePhone(ctx context.Context, tx *sql.Tx, id uuid.UUID, phone types.AESCipher, phoneBidx string, keyVersion int) error
+	Shred(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
+}
+
+type UserUsecase interface {
+	Me(ctx context.Context, id uuid.UUID) (UserProfile, error)
+	ChangeEmail(ctx context.Context, id uuid.UUID, payload ChangeEmailRequest) error
+	ChangePhone(ctx context.Context, id uuid.UUID, payload ChangePhoneRequest) error
+	ChangePassword(ctx context.Context, id uuid.UUID, payload ChangePasswordRequest) error
+	DeleteAccount(ctx context.Context, id uuid.UUID, payload DeleteAccountRequest) error
+	Export(ctx context.Context, id uuid.UUID) (UserDataExport, error)
 }
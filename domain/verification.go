@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TokenPurpose string
+
+const (
+	PurposeEmailVerification TokenPurpose = "EMAIL_VERIFICATION"
+	PurposePhoneVerification TokenPurpose = "PHONE_VERIFICATION"
+	PurposePasswordReset     TokenPurpose = "PASSWORD_RESET"
+)
+
+// VerificationPolicy describes which verified channel an account needs before it can use restricted endpoints.
+type VerificationPolicy string
+
+const (
+	VerificationNone  VerificationPolicy = "none"
+	VerificationEmail VerificationPolicy = "email"
+	VerificationPhone VerificationPolicy = "phone"
+	VerificationBoth  VerificationPolicy = "both"
+)
+
+// UserToken is a single-use, expiring token. Only its hash is persisted.
+type UserToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Purpose   TokenPurpose
+	TokenHash string
+	Attempts  int
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type NotificationChannel string
+
+const (
+	ChannelEmail NotificationChannel = "EMAIL"
+	ChannelSMS   NotificationChannel = "SMS"
+)
+
+type Notification struct {
+	Channel   NotificationChannel `json:"channel"`
+	Recipient string              `json:"recipient"`
+	Subject   string              `json:"subject,omitempty"`
+	Body      string              `json:"body"`
+}
+
+// Notifier delivers notifications to users. Implementations live in infrastructure/notifier.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// ConfirmEmailRequest represents the request payload for confirming an email address
+type ConfirmEmailRequest struct {
+	Token string `json:"token" binding:"required" description:"Verification token sent by email"`
+}
+
+// ConfirmPhoneRequest represents the request payload for confirming a phone number
+type ConfirmPhoneRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" example:"123456" description:"One-time code sent by SMS"`
+}
+
+// ForgotPasswordRequest represents the request payload for starting a password reset
+type ForgotPasswordRequest struct {
+	Identifier string `json:"identifier" binding:"required" example:"user@example.com" description:"Email address or phone number of the account"`
+}
+
+// ResetPasswordRequest represents the request payload for completing a password reset
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required" description:"Password reset token"`
+	NewPassword string `json:"new_password" binding:"required,min=8" example:"newpassword123" description:"New password (minimum 8 characters)"`
+}
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, token *UserToken) error
+	RevokeActive(ctx context.Context, tx *sql.Tx, userID uuid.UUID, purpose TokenPurpose) error
+	FindActiveByHash(ctx context.Context, tx *sql.Tx, purpose TokenPurpose, tokenHash string) (*UserToken, error)
+	FindActiveByUser(ctx context.Context, tx *sql.Tx, userID uuid.UUID, purpose TokenPurpose) (*UserToken, error)
+	IncrementAttempts(ctx context.Context, tx *sql.Tx, id uuid.UUID) (int, error)
+	MarkUsed(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
+}
+
+type VerificationUsecase interface {
+	RequestEmailVerification(ctx context.Context, userID uuid.UUID) error
+	ConfirmEmail(ctx context.Context, token string) error
+	RequestPhoneVerification(ctx context.Context, userID uuid.UUID) error
+	ConfirmPhone(ctx context.Context, userID uuid.UUID, code string) error
+	RequestPasswordReset(ctx context.Context, identifier string) error
+	ResetPassword(ctx context.Context, payload ResetPasswordRequest) error
+	EnsureVerified(ctx context.Context, userID uuid.UUID, policy VerificationPolicy) error
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/dyaksa/encryption-pii/crypto/types"
+	"github.com/dyaksa/warehouse/pkg/paginator"
+	"github.com/google/uuid"
+)
+
+// WebhookEventTypes lists the events a shop may subscribe to. Each carries the shop_id it belongs to.
+var WebhookEventTypes = []EventType{
+	EventOrderCheckedOut,
+	EventOrderPaymentConfirmed,
+	EventOrderCancelled,
+	EventOrderExpired,
+	EventOrderRefunded,
+	EventOrderReservationExtended,
+	EventStockDecreased,
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	WebhookDeliveryDead      WebhookDeliveryStatus = "DEAD" // retries exhausted; only a manual redelivery sends it again
+)
+
+// WebhookSubscription sends the shop's events of EventTypes to URL. Secret is encrypted at rest.
+type WebhookSubscription struct {
+	ID               uuid.UUID       `json:"id"`
+	ShopID           uuid.UUID       `json:"shop_id"`
+	UserID           uuid.UUID       `json:"-"`
+	URL              string          `json:"url"`
+	Secret           types.AESCipher `json:"-"`
+	SecretKeyVersion int             `json:"-"`
+	EventTypes       []EventType     `json:"event_types"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+// WebhookSubscriptionCreated is returned once on creation. SigningSecret is never shown again.
+type WebhookSubscriptionCreated struct {
+	WebhookSubscription
+	SigningSecret string `json:"secret"`
+}
+
+// WebhookDelivery is one event queued for one subscription, with the outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id"`
+	SubscriptionID uuid.UUID             `json:"subscription_id"`
+	EventID        uuid.UUID             `json:"event_id"`
+	EventType      EventType             `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	LastStatusCode *int                  `json:"last_status_code"`
+	LastError      *string               `json:"last_error"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
+
+// WebhookDispatch is a due delivery together with the endpoint it goes to.
+type WebhookDispatch struct {
+	WebhookDelivery
+	URL              string
+	Secret           types.AESCipher
+	SecretKeyVersion int
+}
+
+// CreateWebhookRequest represents the request payload for creating a webhook subscription
+type CreateWebhookRequest struct {
+	URL        string      `json:"url" binding:"required,url,max=2048" example:"https://erp.example.com/hooks/warehouse" description:"HTTPS endpoint that receives POSTed events"`
+	EventTypes []EventType `json:"event_types" binding:"required,min=1,dive,required" example:"order.payment_confirmed" description:"Events to deliver"`
+}
+
+// WebhookSender POSTs a signed delivery and reports the HTTP status code of the response.
+// A non-nil error means no response was received.
+type WebhookSender interface {
+	Send(ctx context.Context, url string, secret string, delivery WebhookDelivery) (int, error)
+	// CheckURL rejects URLs the sender refuses to deliver to, such as ones resolving to private addresses.
+	CheckURL(ctx context.Context, url string) error
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, sub *WebhookSubscription) error
+	Get(ctx context.Context, shopID uuid.UUID, userID uuid.UUID, id uuid.UUID) (*WebhookSubscription, error)
+	ListByShop(ctx context.Context, shopID uuid.UUID, userID uuid.UUID) ([]WebhookSubscription, error)
+	Delete(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
+	// DeleteByUser deletes every subscription userID created, along with its deliveries.
+	DeleteByUser(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error
+	// SubscriberIDs returns the subscriptions of shopID that receive eventType.
+	SubscriberIDs(ctx context.Context, shopID uuid.UUID, eventType EventType) ([]uuid.UUID, error)
+	// EnqueueDelivery ignores an event already queued for the subscription, so fan-out is safe to repeat.
+	EnqueueDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]WebhookDelivery, int, error)
+	// ClaimDueDeliveries picks pending deliveries whose next attempt is due and pushes that attempt
+	// lease into the future, so other workers skip them while they are sent outside tx. fn runs for
+	// each row before the secret is scanned, so it can pick the matching decryption key.
+	ClaimDueDeliveries(ctx context.Context, tx *sql.Tx, limit int, lease time.Duration, fn func(dispatch *WebhookDispatch) error) ([]WebhookDispatch, error)
+	MarkSucceeded(ctx context.Context, tx *sql.Tx, id uuid.UUID, statusCode int) error
+	MarkRetry(ctx context.Context, tx *sql.Tx, id uuid.UUID, statusCode *int, lastError string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, tx *sql.Tx, id uuid.UUID, statusCode *int, lastError string) error
+	// Redeliver requeues a delivery of subscriptionID for immediate sending with a fresh retry budget.
+	Redeliver(ctx context.Context, tx *sql.Tx, subscriptionID uuid.UUID, id uuid.UUID) error
+}
+
+type WebhookUsecase interface {
+	Create(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, payload CreateWebhookRequest) (WebhookSubscriptionCreated, error)
+	List(ctx context.Context, userID uuid.UUID, shopID uuid.UUID) ([]WebhookSubscription, error)
+	Delete(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, id uuid.UUID, pagination paginator.PaginationRequest) (*paginator.PaginationResult[WebhookDelivery], error)
+	Redeliver(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, id uuid.UUID, deliveryID uuid.UUID) error
+	// Enqueue fans an outbox event out to the subscriptions of its shop.
+	Enqueue(ctx context.Context, event OutboxEvent) error
+	// DeliverBatch sends up to limit due deliveries and reports how many succeeded.
+	DeliverBatch(ctx context.Context, limit int) (int, error)
+}
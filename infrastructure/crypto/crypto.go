@@ -1,52 +1,201 @@
 package crypto
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+
 	"github.com/dyaksa/encryption-pii/crypto"
 	"github.com/dyaksa/encryption-pii/crypto/aesx"
 	"github.com/dyaksa/encryption-pii/crypto/core"
 )
 
+// Crypto encrypts with the active key version. Ciphertexts and blind indexes written with
+// older versions stay readable through DecryptVersion and HashStringVersions until re-keyed.
 type Crypto interface {
 	AESFunc() func() (core.PrimitiveAES, error)
 	Encrypt(data string) aesx.AES[string, core.PrimitiveAES]
 	Decrypt(def string) aesx.AES[string, core.PrimitiveAES]
 	BindHeap(entity any) error
+	// UnbindHeap removes the heap entries BindHeap wrote for the entity's non-empty indexed values,
+	// under every configured key version.
+	UnbindHeap(ctx context.Context, entity any) error
 	HashString(s string) string
+	KeyVersion() int
+	// DecryptVersion fails for versions that have no configured keys.
+	DecryptVersion(version int, def string) (aesx.AES[string, core.PrimitiveAES], error)
+	HashStringVersions(s string) []string
+	// PingHeap checks that the heap database used by BindHeap is reachable.
+	PingHeap(ctx context.Context) error
 }
 
+const (
+	envAESKey     = "CRYPTO_AES_KEY"
+	envHMACKey    = "CRYPTO_HMAC_KEY"
+	envKeyVersion = "CRYPTO_KEY_VERSION"
+)
+
+var versionedKey = regexp.MustCompile(`^` + envAESKey + `_V([0-9]+)=`)
+
+// envMu serialises crypto.New calls, which read their keys from the process environment.
+var envMu sync.Mutex
+
 type derivaleCrypto struct {
-	c *crypto.Crypto
+	active   int
+	versions []int // newest first
+	keys     map[int]*crypto.Crypto
+	heap     *sql.DB
 }
 
 func (d *derivaleCrypto) Encrypt(data string) aesx.AES[string, core.PrimitiveAES] {
-	return d.c.Encrypt(data, aesx.AesCBC)
+	return d.keys[d.active].Encrypt(data, aesx.AesCBC)
 }
 
 func (d *derivaleCrypto) BindHeap(entity any) error {
-	return d.c.BindHeap(entity)
+	return d.keys[d.active].BindHeap(entity)
+}
+
+func (d *derivaleCrypto) UnbindHeap(ctx context.Context, entity any) error {
+	values, err := heapValues(entity)
+	if err != nil {
+		return err
+	}
+
+	for table, plain := range values {
+		if err := deleteHeap(ctx, d.heap, table, d.HashStringVersions(plain)); err != nil {
+			return fmt.Errorf("crypto heap %s: %w", table, err)
+		}
+	}
+
+	return nil
 }
 
 func (d *derivaleCrypto) Decrypt(def string) aesx.AES[string, core.PrimitiveAES] {
-	return aesx.AESChiper(d.c.AESFunc(), def, aesx.AesCBC)
+	return aesx.AESChiper(d.keys[d.active].AESFunc(), def, aesx.AesCBC)
+}
+
+func (d *derivaleCrypto) DecryptVersion(version int, def string) (aesx.AES[string, core.PrimitiveAES], error) {
+	c, ok := d.keys[version]
+	if !ok {
+		return aesx.AES[string, core.PrimitiveAES]{}, fmt.Errorf("crypto key version %d is not configured", version)
+	}
+
+	return aesx.AESChiper(c.AESFunc(), def, aesx.AesCBC), nil
 }
 
 func (d *derivaleCrypto) AESFunc() func() (core.PrimitiveAES, error) {
-	return d.c.AESFunc()
+	return d.keys[d.active].AESFunc()
 }
 
 func (d *derivaleCrypto) HashString(s string) string {
-	return d.c.HashString(s)
+	return d.keys[d.active].HashString(s)
+}
+
+func (d *derivaleCrypto) HashStringVersions(s string) []string {
+	hashes := make([]string, 0, len(d.versions))
+	for _, v := range d.versions {
+		hashes = append(hashes, d.keys[v].HashString(s))
+	}
+
+	return hashes
+}
+
+func (d *derivaleCrypto) KeyVersion() int {
+	return d.active
 }
 
+func (d *derivaleCrypto) PingHeap(ctx context.Context) error {
+	return d.heap.PingContext(ctx)
+}
+
+// New loads every configured key version. Version 1 uses CRYPTO_AES_KEY and CRYPTO_HMAC_KEY;
+// version N uses CRYPTO_AES_KEY_VN and CRYPTO_HMAC_KEY_VN. CRYPTO_KEY_VERSION picks the
+// version used for new writes and defaults to the highest one configured.
 func New() (Crypto, error) {
-	c, err := crypto.New(
-		crypto.Aes256KeySize,
-		crypto.WithInitHeapConnection(),
-	)
+	keys := map[int][2]string{1: {os.Getenv(envAESKey), os.Getenv(envHMACKey)}}
+	for _, kv := range os.Environ() {
+		m := versionedKey.FindStringSubmatch(kv)
+		if m == nil {
+			continue
+		}
+
+		v, err := strconv.Atoi(m[1])
+		if err != nil || v < 1 {
+			return nil, fmt.Errorf("invalid crypto key version %q", m[1])
+		}
 
+		keys[v] = [2]string{
+			os.Getenv(fmt.Sprintf("%s_V%d", envAESKey, v)),
+			os.Getenv(fmt.Sprintf("%s_V%d", envHMACKey, v)),
+		}
+	}
+
+	d := &derivaleCrypto{keys: make(map[int]*crypto.Crypto, len(keys))}
+	for v := range keys {
+		d.versions = append(d.versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(d.versions)))
+
+	d.active = d.versions[0]
+	if raw := os.Getenv(envKeyVersion); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", envKeyVersion, err)
+		}
+
+		if _, ok := keys[v]; !ok {
+			return nil, fmt.Errorf("%s=%d has no configured keys", envKeyVersion, v)
+		}
+		d.active = v
+	}
+
+	heap, err := openHeap()
 	if err != nil {
+		return nil, fmt.Errorf("crypto heap: %w", err)
+	}
+	d.heap = heap
+
+	for v, pair := range keys {
+		// Only the active version writes, so only it needs the heap connection used by BindHeap.
+		c, err := newWithKeys(pair[0], pair[1], v == d.active)
+		if err != nil {
+			return nil, fmt.Errorf("crypto key version %d: %w", v, err)
+		}
+		d.keys[v] = c
+	}
+
+	return d, nil
+}
+
+// newWithKeys points the library at one key pair for the duration of crypto.New.
+func newWithKeys(aesKey, hmacKey string, withHeap bool) (*crypto.Crypto, error) {
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	prevAES, prevHMAC := os.Getenv(envAESKey), os.Getenv(envHMACKey)
+	defer func() {
+		_ = os.Setenv(envAESKey, prevAES)
+		_ = os.Setenv(envHMACKey, prevHMAC)
+	}()
+
+	if err := os.Setenv(envAESKey, aesKey); err != nil {
+		return nil, err
+	}
+	if err := os.Setenv(envHMACKey, hmacKey); err != nil {
 		return nil, err
 	}
 
-	return &derivaleCrypto{c: c}, nil
+	if withHeap {
+		return crypto.New(
+			crypto.Aes256KeySize,
+			crypto.WithInitHeapConnection(),
+		)
+	}
+
+	return crypto.New(crypto.Aes256KeySize)
 }
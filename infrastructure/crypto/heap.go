@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/dyaksa/encryption-pii/crypto/types"
+	"github.com/lib/pq"
+)
+
+// openHeap opens a small pool to the heap database configured for the encryption library.
+// The library keeps its own connection private, so this pool serves health checks and UnbindHeap.
+func openHeap() (*sql.DB, error) {
+	sslMode := os.Getenv("DB_SSL")
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	db, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		os.Getenv("CRYPTO_HEAP_DB_HOST"),
+		os.Getenv("CRYPTO_HEAP_DB_PORT"),
+		os.Getenv("CRYPTO_HEAP_DB_USER"),
+		os.Getenv("CRYPTO_HEAP_DB_PASS"),
+		os.Getenv("CRYPTO_HEAP_DB_NAME"),
+		sslMode,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	return db, nil
+}
+
+// heapValues returns, by heap table, the plaintext BindHeap indexed for entity. Each field tagged
+// full_text_search indexes the cipher field of the same name without the Bidx suffix, and its
+// entries live in the table named after its column, e.g. email_bidx in email_text_heap.
+func heapValues(entity any) (map[string]string, error) {
+	v := reflect.ValueOf(entity)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("crypto heap: %T is not a struct", entity)
+	}
+
+	values := make(map[string]string)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Tag.Get("full_text_search") != "true" {
+			continue
+		}
+
+		source := v.FieldByName(strings.TrimSuffix(field.Name, "Bidx"))
+		if !source.IsValid() {
+			return nil, fmt.Errorf("crypto heap: %s does not index a cipher field", field.Name)
+		}
+		cipher, ok := source.Interface().(types.AESCipher)
+		if !ok {
+			return nil, fmt.Errorf("crypto heap: %s does not index a cipher field", field.Name)
+		}
+
+		plain := cipher.To()
+		if plain == "" {
+			continue
+		}
+
+		column, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		values[strings.TrimSuffix(column, "_bidx")+"_text_heap"] = plain
+	}
+
+	return values, nil
+}
+
+func deleteHeap(ctx context.Context, db *sql.DB, table string, hashes []string) error {
+	_, err := db.ExecContext(ctx, "DELETE FROM "+pq.QuoteIdentifier(table)+" WHERE hash = ANY($1)", pq.Array(hashes))
+	return err
+}
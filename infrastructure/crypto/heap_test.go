@@ -0,0 +1,24 @@
+package crypto
+
+import "testing"
+
+func TestHeapValues_RejectsIndexWithoutCipherField(t *testing.T) {
+	entity := struct {
+		NameBidx string `json:"name_bidx" full_text_search:"true"`
+	}{}
+
+	if _, err := heapValues(&entity); err == nil {
+		t.Fatal("expected an error for an index field without its cipher field")
+	}
+}
+
+func TestHeapValues_RejectsNonCipherSource(t *testing.T) {
+	entity := struct {
+		Name     string
+		NameBidx string `json:"name_bidx" full_text_search:"true"`
+	}{}
+
+	if _, err := heapValues(&entity); err == nil {
+		t.Fatal("expected an error for an index field whose source is not a cipher")
+	}
+}
@@ -0,0 +1,67 @@
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+)
+
+// writerSink appends every event as a JSON line to w. It is meant for local development
+// and as a tap on the event stream; consumers must tolerate duplicates.
+type writerSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+type entry struct {
+	domain.OutboxEvent
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Name implements domain.EventSink.
+func (s *writerSink) Name() string {
+	return s.name
+}
+
+// Publish implements domain.EventSink.
+func (s *writerSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	b, err := json.Marshal(entry{OutboxEvent: event, PublishedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(append(b, '\n'))
+	return err
+}
+
+func NewWriter(name string, w io.Writer) domain.EventSink {
+	return &writerSink{name: name, w: w}
+}
+
+func NewStdout() domain.EventSink {
+	return NewWriter("stdout", os.Stdout)
+}
+
+// NewFile appends events to the file at path, creating it if needed.
+func NewFile(path string) (domain.EventSink, io.Closer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return NewWriter("file", f), f, nil
+}
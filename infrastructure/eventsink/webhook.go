@@ -0,0 +1,27 @@
+package eventsink
+
+import (
+	"context"
+
+	"github.com/dyaksa/warehouse/domain"
+)
+
+// webhookSink queues outbox events for the shop's webhook subscriptions. The HTTP calls
+// happen later in the webhook delivery worker, so a slow subscriber never holds up the relay.
+type webhookSink struct {
+	webhooks domain.WebhookUsecase
+}
+
+// Name implements domain.EventSink.
+func (s *webhookSink) Name() string {
+	return "webhook"
+}
+
+// Publish implements domain.EventSink.
+func (s *webhookSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	return s.webhooks.Enqueue(ctx, event)
+}
+
+func NewWebhook(webhooks domain.WebhookUsecase) domain.EventSink {
+	return &webhookSink{webhooks: webhooks}
+}
@@ -0,0 +1,61 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+)
+
+// writerNotifier appends every notification as a JSON line to w. It is meant for
+// local development where no email or SMS provider is configured.
+type writerNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+type entry struct {
+	domain.Notification
+	SentAt time.Time `json:"sent_at"`
+}
+
+// Notify implements domain.Notifier.
+func (n *writerNotifier) Notify(ctx context.Context, msg domain.Notification) error {
+	b, err := json.Marshal(entry{Notification: msg, SentAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err = n.w.Write(append(b, '\n'))
+	return err
+}
+
+func NewWriter(w io.Writer) domain.Notifier {
+	return &writerNotifier{w: w}
+}
+
+func NewStdout() domain.Notifier {
+	return NewWriter(os.Stdout)
+}
+
+// NewFile appends notifications to the file at path, creating it if needed.
+func NewFile(path string) (domain.Notifier, io.Closer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return NewWriter(f), f, nil
+}
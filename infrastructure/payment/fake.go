@@ -0,0 +1,112 @@
+// Package payment holds the payment provider integrations.
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/webhook"
+	"github.com/google/uuid"
+)
+
+// FakeName is the provider name of Fake.
+const FakeName = "fake"
+
+// fakeTolerance bounds the age of a webhook accepted by Fake.
+const fakeTolerance = 5 * time.Minute
+
+// FakeEvent is the webhook body sent by Fake. Type is one of the domain.PaymentEventType values.
+// Refund is the refund ID given to Fake.Refund, for refunds requested that way.
+type FakeEvent struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Intent string `json:"intent"`
+	Amount int64  `json:"amount"`
+	Refund string `json:"refund,omitempty"`
+}
+
+// Fake is a payment provider without an external service, for development and tests.
+// Its webhooks are signed like outbound shop webhooks, with the configured secret.
+type Fake struct {
+	secret string
+}
+
+// Name implements domain.PaymentProvider.
+func (f *Fake) Name() string {
+	return FakeName
+}
+
+// CreateIntent implements domain.PaymentProvider.
+func (f *Fake) CreateIntent(ctx context.Context, intentID uuid.UUID, order *domain.Order) (string, string, error) {
+	ref := "pi_fake_" + strings.ReplaceAll(intentID.String(), "-", "")
+	return ref, "https://pay.fake.local/checkout/" + ref, nil
+}
+
+// ParseEvent implements domain.PaymentProvider.
+func (f *Fake) ParseEvent(header http.Header, body []byte) (domain.PaymentEvent, error) {
+	// An empty secret would let anyone compute a valid signature
+	if f.secret == "" {
+		return domain.PaymentEvent{}, domain.ErrInvalidPaymentSignature
+	}
+
+	if err := webhook.Verify(f.secret, header.Get(webhook.HeaderSignature), header.Get(webhook.HeaderTimestamp), body, time.Now(), fakeTolerance); err != nil {
+		return domain.PaymentEvent{}, errors.Join(domain.ErrInvalidPaymentSignature, err)
+	}
+
+	var in FakeEvent
+	if err := json.Unmarshal(body, &in); err != nil {
+		return domain.PaymentEvent{}, err
+	}
+
+	if in.ID == "" || in.Intent == "" {
+		return domain.PaymentEvent{}, errors.New("payment event id and intent are required")
+	}
+
+	event := domain.PaymentEvent{ID: in.ID, ProviderRef: in.Intent, Amount: in.Amount}
+	if in.Refund != "" {
+		refundID, err := uuid.Parse(in.Refund)
+		if err != nil {
+			return domain.PaymentEvent{}, err
+		}
+		event.RefundID = refundID
+	}
+
+	switch t := domain.PaymentEventType(in.Type); t {
+	case domain.PaymentEventSucceeded, domain.PaymentEventFailed, domain.PaymentEventRefunded:
+		event.Type = t
+	}
+
+	return event, nil
+}
+
+// Refund implements domain.PaymentProvider. Fake holds no money, so every refund succeeds.
+func (f *Fake) Refund(ctx context.Context, providerRef string, refundID uuid.UUID, amount int64) error {
+	return nil
+}
+
+// Webhook builds a signed webhook request for event, as the provider would send it.
+func (f *Fake) Webhook(event FakeEvent) (http.Header, []byte, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(webhook.HeaderID, event.ID)
+	header.Set(webhook.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(webhook.HeaderSignature, webhook.Sign(f.secret, now, body))
+
+	return header, body, nil
+}
+
+func NewFake(secret string) *Fake {
+	return &Fake{secret: secret}
+}
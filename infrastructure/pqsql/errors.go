@@ -0,0 +1,40 @@
+package pqsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/lib/pq"
+)
+
+// IsTransient reports whether err is likely to go away on its own: a serialization failure,
+// deadlock, lock or statement timeout, or a lost connection. Retrying the same work later may
+// succeed, so such errors say nothing about the data being processed.
+func IsTransient(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08", "40": // connection exception, transaction rollback
+			return true
+		}
+
+		switch pqErr.Code {
+		case "55P03", "57014", "57P01": // lock_not_available, query_canceled, admin_shutdown
+			return true
+		}
+
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &netErr)
+}
@@ -0,0 +1,162 @@
+package pqsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/metrics"
+)
+
+// leaderLockClass namespaces the advisory locks taken for leader election, so they cannot collide
+// with advisory locks taken for other purposes.
+const leaderLockClass int32 = 0x57484c45
+
+// LeaderElection elects one replica among those campaigning under the same name. The leader holds
+// a session-level Postgres advisory lock on a dedicated connection, so leadership ends as soon as
+// that session does, including when the replica crashes or loses its connection. The lease is
+// renewed every interval by checking the session still holds the lock; a replica that cannot
+// confirm it steps down.
+type LeaderElection struct {
+	db       *sql.DB
+	name     string
+	key      int32
+	interval time.Duration
+	logger   log.Logger
+
+	conn   *sql.Conn
+	leader atomic.Bool
+}
+
+// IsLeader reports whether this replica held the lock at the last renewal.
+func (e *LeaderElection) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run campaigns for and renews leadership every interval until ctx is cancelled, then releases it.
+func (e *LeaderElection) Run(ctx context.Context) {
+	e.logger.Info("starting leader election", log.String("election", e.name), log.Duration("interval", e.interval))
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	e.tick(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			e.resign()
+			return
+		case <-ticker.C:
+			e.tick(ctx)
+		}
+	}
+}
+
+func (e *LeaderElection) tick(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+
+	if e.conn == nil {
+		e.campaign(ctx)
+	} else {
+		e.renew(ctx)
+	}
+}
+
+func (e *LeaderElection) campaign(ctx context.Context) {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		e.logger.Warn("failed to campaign for leadership", log.String("election", e.name), log.Error("error", err))
+		return
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1, $2)", leaderLockClass, e.key).Scan(&acquired); err != nil || !acquired {
+		if err != nil {
+			e.logger.Warn("failed to campaign for leadership", log.String("election", e.name), log.Error("error", err))
+		}
+		discard(conn)
+		return
+	}
+
+	e.conn = conn
+	e.setLeader(true)
+	e.logger.Info("became leader", log.String("election", e.name))
+}
+
+func (e *LeaderElection) renew(ctx context.Context) {
+	var held bool
+	err := e.conn.QueryRowContext(ctx, `SELECT EXISTS (
+		SELECT 1 FROM pg_locks
+		WHERE locktype = 'advisory' AND granted AND pid = pg_backend_pid()
+			AND classid = $1::int::oid AND objid = $2::int::oid AND objsubid = 2
+	)`, leaderLockClass, e.key).Scan(&held)
+	if err == nil && held {
+		return
+	}
+
+	e.logger.Warn("lost leadership", log.String("election", e.name), log.Error("error", err))
+	e.stepDown()
+}
+
+// resign releases leadership on shutdown so another replica can take over without waiting.
+func (e *LeaderElection) resign() {
+	if e.conn == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+	defer cancel()
+
+	if _, err := e.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1, $2)", leaderLockClass, e.key); err != nil {
+		e.logger.Warn("failed to release leadership", log.String("election", e.name), log.Error("error", err))
+	}
+	e.logger.Info("resigned leadership", log.String("election", e.name))
+	e.stepDown()
+}
+
+func (e *LeaderElection) stepDown() {
+	e.setLeader(false)
+	discard(e.conn)
+	e.conn = nil
+}
+
+func (e *LeaderElection) setLeader(leader bool) {
+	e.leader.Store(leader)
+
+	value := 0.0
+	if leader {
+		value = 1
+	}
+	metrics.Leader.WithLabelValues(e.name).Set(value)
+}
+
+// discard closes conn instead of returning it to the pool, where its session could keep holding
+// the advisory lock.
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
+
+// NewLeaderElection creates an election named name. Replicas using the same name compete for the
+// same leadership. interval defaults to 10 seconds.
+func NewLeaderElection(c Client, name string, interval time.Duration, logger log.Logger) *LeaderElection {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+
+	return &LeaderElection{
+		db:       c.DB(),
+		name:     name,
+		key:      int32(h.Sum32() & 0x7fffffff),
+		interval: interval,
+		logger:   logger,
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 type database struct {
@@ -29,12 +31,19 @@ type Database interface {
 	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	Transaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) (any, error)) (any, error)
+	// Savepoint runs fn inside a savepoint of tx. If fn fails only its own work is rolled back, so
+	// tx stays usable and the error is returned.
+	Savepoint(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error) error
 }
 
 func (d *database) Transaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) (any, error)) (any, error) {
 	return d.wrapper.WrapTx(ctx, fn)
 }
 
+func (d *database) Savepoint(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error) error {
+	return savepoint(ctx, tx, fn)
+}
+
 type client struct {
 	db *sql.DB
 }
@@ -48,6 +57,9 @@ type Client interface {
 	Prepare(query string) (*sql.Stmt, error)
 	Close() error
 	Ping() error
+	Stats() sql.DBStats
+	// DB returns the underlying pool, for work that needs a dedicated connection.
+	DB() *sql.DB
 }
 
 func (c *client) Database() Database {
@@ -82,8 +94,23 @@ func (c *client) Ping() error {
 	return c.db.Ping()
 }
 
+func (c *client) Stats() sql.DBStats {
+	return c.db.Stats()
+}
+
+func (c *client) DB() *sql.DB {
+	return c.db
+}
+
 func NewClient(connection string) (Client, error) {
-	db, err := sql.Open("postgres", connection)
+	// Every statement, including those run on a *sql.Tx by the repositories, gets a span.
+	db, err := otelsql.Open("postgres", connection,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -3,6 +3,13 @@ package pqsql
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dyaksa/warehouse/pkg/metrics"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type WrapperTx struct {
@@ -16,24 +23,52 @@ func NewWrapper(db *sql.DB) *WrapperTx {
 }
 
 func (w *WrapperTx) WrapTx(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) (any, error)) (any, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "Database.Transaction")
+	defer span.End()
+
 	tx, err := w.db.Begin()
 	if err != nil {
+		observeTx(span, start, "begin_error", err)
 		return nil, err
 	}
 
+	// Every error rolls back, sql.ErrNoRows included, so callers can map it after the transaction
 	res, err := fn(ctx, tx)
-	switch err {
-	case nil:
-	case sql.ErrNoRows:
-		return res, nil
-	default:
+	if err != nil {
 		_ = tx.Rollback()
+		observeTx(span, start, "rollback", err)
 		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
+		observeTx(span, start, "commit_error", err)
 		return nil, err
 	}
+	observeTx(span, start, "commit", nil)
 
 	return res, nil
 }
+
+// savepoint runs fn between SAVEPOINT and RELEASE, rolling back to the savepoint if fn fails.
+func savepoint(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT pqsql_savepoint"); err != nil {
+		return err
+	}
+
+	if err := fn(ctx); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT pqsql_savepoint"); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT pqsql_savepoint")
+	return err
+}
+
+func observeTx(span trace.Span, start time.Time, outcome string, err error) {
+	metrics.DBTransactionDuration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+	span.SetAttributes(attribute.String("db.transaction.outcome", outcome))
+	tracing.RecordError(span, err)
+}
@@ -0,0 +1,64 @@
+package pqsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+// txRecorder is a database/sql connector whose transactions only record how they end.
+type txRecorder struct {
+	outcomes []string
+}
+
+func (r *txRecorder) Connect(context.Context) (driver.Conn, error) { return &recorderConn{r: r}, nil }
+func (r *txRecorder) Driver() driver.Driver                        { return nil }
+
+type recorderConn struct{ r *txRecorder }
+
+func (c *recorderConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *recorderConn) Close() error                        { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *recorderConn) Commit() error                       { c.r.outcomes = append(c.r.outcomes, "commit"); return nil }
+func (c *recorderConn) Rollback() error                     { c.r.outcomes = append(c.r.outcomes, "rollback"); return nil }
+
+func newRecordingWrapper(t *testing.T) (*WrapperTx, *txRecorder) {
+	t.Helper()
+	rec := &txRecorder{}
+	db := sql.OpenDB(rec)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewWrapper(db), rec
+}
+
+func TestWrapTx_CommitsOnSuccess(t *testing.T) {
+	w, rec := newRecordingWrapper(t)
+
+	res, err := w.WrapTx(context.Background(), func(ctx context.Context, tx *sql.Tx) (any, error) {
+		return "ok", nil
+	})
+	if err != nil || res != "ok" {
+		t.Fatalf("expected ok result, got %v, %v", res, err)
+	}
+	if len(rec.outcomes) != 1 || rec.outcomes[0] != "commit" {
+		t.Errorf("expected one commit, got %v", rec.outcomes)
+	}
+}
+
+func TestWrapTx_RollsBackOnErrNoRows(t *testing.T) {
+	w, rec := newRecordingWrapper(t)
+
+	res, err := w.WrapTx(context.Background(), func(ctx context.Context, tx *sql.Tx) (any, error) {
+		return "partial", sql.ErrNoRows
+	})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+	if res != nil {
+		t.Errorf("expected no result, got %v", res)
+	}
+	if len(rec.outcomes) != 1 || rec.outcomes[0] != "rollback" {
+		t.Errorf("expected one rollback, got %v", rec.outcomes)
+	}
+}
@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/webhook"
+)
+
+// envelope is the JSON body of every webhook request. It is rebuilt identically on each retry.
+type envelope struct {
+	ID        string           `json:"id"`
+	Type      domain.EventType `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      json.RawMessage  `json:"data"`
+}
+
+type httpSender struct {
+	client       *http.Client
+	allowPrivate bool
+}
+
+// CheckURL implements domain.WebhookSender.
+func (s *httpSender) CheckURL(ctx context.Context, url string) error {
+	if s.allowPrivate {
+		return nil
+	}
+
+	return checkURL(ctx, net.DefaultResolver, url)
+}
+
+// Send implements domain.WebhookSender.
+func (s *httpSender) Send(ctx context.Context, url string, secret string, delivery domain.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(envelope{
+		ID:        delivery.EventID.String(),
+		Type:      delivery.EventType,
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderID, delivery.EventID.String())
+	req.Header.Set(webhook.HeaderEvent, string(delivery.EventType))
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(secret, now, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a bounded amount so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// NewHTTPSender posts deliveries with the given per-request timeout. Redirects are not followed: a subscriber must
+// register the final URL, so a signed payload is never forwarded somewhere else. Unless allowPrivate is set,
+// connections to loopback, link-local and private addresses are refused, and no proxy is used.
+func NewHTTPSender(timeout time.Duration, allowPrivate bool) domain.WebhookSender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: dialControl}).DialContext
+	}
+
+	return &httpSender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		allowPrivate: allowPrivate,
+	}
+}
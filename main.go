@@ -14,10 +14,18 @@ import (
 	"github.com/dyaksa/warehouse/api/worker"
 	"github.com/dyaksa/warehouse/bootstrap"
 	_ "github.com/dyaksa/warehouse/docs" // Swagger docs
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/eventsink"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/infrastructure/webhook"
+	"github.com/dyaksa/warehouse/migrations"
 	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/metrics"
+	"github.com/dyaksa/warehouse/pkg/ratelimit"
 	"github.com/dyaksa/warehouse/repository"
 	"github.com/dyaksa/warehouse/usecase"
 	"github.com/gin-contrib/cors"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // @title Warehouse Management API
@@ -51,18 +59,31 @@ func main() {
 	l := app.Log
 	db := app.Postgres
 	crypto := app.Crypto
+	notifier := app.Notifier
 
+	router.Use(otelgin.Middleware(env.AppName))
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.MetricsMiddleware())
 	router.Use(cors.Default())
-	router.Use(middleware.RateLimitMiddleware(time.Second, 100, "api"))
 	router.Use(middleware.LoggerMiddleware(l))
 	router.Use(middleware.ErrorMiddleware(l))
+	router.Use(middleware.RateLimit(app.RateLimitStore, ratelimit.Policy{
+		Name:  "api",
+		Quota: ratelimit.Quota{Limit: 100, Rate: time.Second},
+		Key:   ratelimit.ByIP,
+	}))
 
 	timeout := time.Duration(env.ContextTimeout) * time.Second
 
+	metrics.RegisterDBStats(db)
+
 	reservationRepo := repository.NewReservationRepository(db)
 	productStockRepo := repository.NewProductStockRepository(db)
 	movementRepo := repository.NewMovementRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+
+	workerLog := log.ForModule(l, bootstrap.LogModuleWorker)
 
 	// Initialize stock release usecase
 	stockReleaseUsecase := usecase.NewStockReleaseUsecase(
@@ -71,28 +92,89 @@ func main() {
 		productStockRepo,
 		movementRepo,
 		orderRepo,
+		outboxRepo,
+		repository.NewStockReleaseStateRepository(db),
+		env.StockReleaseMaxAttempts,
+		workerLog,
 	)
 
-	workerConfig := worker.StockReleaseWorkerConfig{
-		BatchSize: 50,
-		Interval:  30 * time.Second,
-	}
-
-	stockReleaseWorker := worker.NewStockReleaseWorker(stockReleaseUsecase, workerConfig)
-
 	// Create context for worker with cancellation
 	workerCtx, workerCancel := context.WithCancel(ctx)
 	defer workerCancel()
 
+	// Expiring reservations and purging keys run on one replica at a time; the outbox relay and
+	// webhook deliveries are sharded across replicas.
+	stockReleaseElection := pqsql.NewLeaderElection(db, "stock-release", 0, workerLog)
+	go stockReleaseElection.Run(workerCtx)
+
+	idempotencyPurgeElection := pqsql.NewLeaderElection(db, "idempotency-purge", 0, workerLog)
+	go idempotencyPurgeElection.Run(workerCtx)
+
+	workerConfig := worker.StockReleaseWorkerConfig{
+		BatchSize: env.StockReleaseBatchSize,
+		Interval:  time.Duration(env.StockReleaseInterval) * time.Second,
+		Elector:   stockReleaseElection,
+	}
+
+	stockReleaseWorker := worker.NewStockReleaseWorker(stockReleaseUsecase, workerConfig, workerLog)
+
 	// Start the stock release worker in a goroutine
 	go func() {
 		l.Info("Starting stock release worker...")
 		stockReleaseWorker.Start(workerCtx)
 	}()
 
-	route.Setup(env, timeout, db, l, crypto, router)
+	webhookUsecase := usecase.NewWebhookUsecase(
+		db.Database(),
+		repository.NewWebhookRepository(db),
+		repository.NewShopRepository(db),
+		webhook.NewHTTPSender(time.Duration(env.WebhookTimeout)*time.Second, env.WebhookAllowPrivate),
+		crypto,
+		env,
+		workerLog,
+	)
+
+	// Webhooks are always fed from the outbox; OUTBOX_SINKS adds further destinations.
+	eventSinks := append([]domain.EventSink{eventsink.NewWebhook(webhookUsecase)}, app.EventSinks...)
+
+	outboxRelayWorker := worker.NewOutboxRelayWorker(
+		usecase.NewOutboxRelayUsecase(db.Database(), outboxRepo, eventSinks, workerLog),
+		worker.PeriodicWorkerConfig{BatchSize: 100, Interval: time.Second},
+		workerLog,
+	)
+	go outboxRelayWorker.Start(workerCtx)
+
+	webhookDeliveryWorker := worker.NewWebhookDeliveryWorker(webhookUsecase, worker.PeriodicWorkerConfig{}, workerLog)
+	go webhookDeliveryWorker.Start(workerCtx)
+
+	idempotencyPurgeWorker := worker.NewIdempotencyPurgeWorker(
+		usecase.NewIdempotencyUsecase(db.Database(), repository.NewIdempotencyRequestRepository(db)),
+		worker.PeriodicWorkerConfig{Elector: idempotencyPurgeElection},
+		workerLog,
+	)
+	go idempotencyPurgeWorker.Start(workerCtx)
 
-	route.NewStockReleaseRoute(env, timeout, db, l, crypto, router, stockReleaseWorker)
+	// Job handlers are registered here by type; each instance runs the jobs it has handlers for.
+	jobUsecase := usecase.NewJobUsecase(db.Database(), repository.NewJobRepository(db), domain.JobHandlers{
+		domain.JobUserErased:  usecase.NewUserErasedJobHandler(db.Database(), repository.NewAPIKeyRepository(db), repository.NewWebhookRepository(db)),
+		domain.JobRefundIssue: usecase.NewRefundIssueJobHandler(db.Database(), repository.NewRefundRepository(db), repository.NewPaymentRepository(db), bootstrap.NewPaymentProvider(env, workerLog)),
+	}, workerLog)
+	jobWorker := worker.NewJobWorker(jobUsecase, worker.PeriodicWorkerConfig{}, workerLog)
+	go jobWorker.Start(workerCtx)
+
+	route.Setup(env, timeout, db, l, crypto, notifier, app.RateLimitStore, router)
+
+	route.NewStockReleaseRoute(env, timeout, db, l, crypto, router, stockReleaseWorker, stockReleaseUsecase)
+
+	healthUsecase := usecase.NewHealthUsecase(
+		repository.NewHealthRepository(db, env.GooseTable),
+		crypto,
+		stockReleaseWorker,
+		migrations.LatestVersion(),
+	)
+	route.NewHealthRoute(router, healthUsecase)
+	route.NewAdminRoute(env, router, app.LogLevels, jobUsecase)
+	route.NewWebhookRoute(env, db, router, webhookUsecase)
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", env.Port),
@@ -111,9 +193,22 @@ func main() {
 	<-quit
 	l.Info("shutting down server")
 
+	// Fail readiness first so the load balancer stops routing here while in-flight requests finish.
+	healthUsecase.SetShuttingDown()
+	drainDelay := time.Duration(env.ShutdownDrainDelay) * time.Second
+	if env.ShutdownDrainDelay <= 0 {
+		drainDelay = 5 * time.Second
+	}
+	l.Info(fmt.Sprintf("draining for %s", drainDelay))
+	time.Sleep(drainDelay)
+
 	l.Info("stopping stock release worker")
 	workerCancel() // Cancel the worker context
 	stockReleaseWorker.Stop()
+	outboxRelayWorker.Stop()
+	webhookDeliveryWorker.Stop()
+	idempotencyPurgeWorker.Stop()
+	jobWorker.Stop()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
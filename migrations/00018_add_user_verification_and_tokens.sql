@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMPTZ,
+ADD COLUMN phone_verified_at TIMESTAMPTZ;
+
+CREATE TYPE user_token_purpose AS ENUM ('EMAIL_VERIFICATION', 'PHONE_VERIFICATION', 'PASSWORD_RESET');
+CREATE TABLE user_tokens (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose     user_token_purpose NOT NULL,
+    token_hash  VARCHAR(128) NOT NULL UNIQUE, -- sha256 of the signed token or HMAC of the OTP; raw tokens are never stored
+    attempts    INT NOT NULL DEFAULT 0,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens(user_id, purpose) WHERE used_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_tokens;
+DROP TYPE IF EXISTS user_token_purpose;
+ALTER TABLE users
+DROP COLUMN email_verified_at,
+DROP COLUMN phone_verified_at;
+-- +goose StatementEnd
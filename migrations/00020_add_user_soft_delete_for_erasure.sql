@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ALTER COLUMN email DROP NOT NULL,
+ADD COLUMN deleted_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Erased users have no email left and are still referenced by their orders, so give them a
+-- unique placeholder instead of deleting them.
+UPDATE users SET email = 'erased-' || id WHERE email IS NULL;
+
+ALTER TABLE users
+ALTER COLUMN email SET NOT NULL,
+DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Key version of each ciphertext and its blind index. Existing rows were written with the original key (version 1).
+ALTER TABLE users
+ADD COLUMN email_key_version SMALLINT NOT NULL DEFAULT 1,
+ADD COLUMN phone_key_version SMALLINT NOT NULL DEFAULT 1,
+ADD COLUMN totp_key_version SMALLINT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+DROP COLUMN email_key_version,
+DROP COLUMN phone_key_version,
+DROP COLUMN totp_key_version;
+-- +goose StatementEnd
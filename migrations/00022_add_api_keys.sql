@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Records who created each shop so that only they can issue its API keys and webhooks.
+-- Shops created earlier have no owner until one is assigned.
+ALTER TABLE shops ADD COLUMN owner_id UUID REFERENCES users(id);
+
+CREATE TABLE api_keys (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    shop_id       UUID NOT NULL REFERENCES shops(id) ON DELETE CASCADE,
+    user_id       UUID NOT NULL REFERENCES users(id), -- creator; requests made with the key act as this user
+    name          VARCHAR(255) NOT NULL,
+    prefix        VARCHAR(32) NOT NULL UNIQUE, -- public part of the key, safe to display
+    key_hash      VARCHAR(128) NOT NULL UNIQUE, -- sha256 of the full key; raw keys are never stored
+    scopes        TEXT[] NOT NULL DEFAULT '{}',
+    expires_at    TIMESTAMPTZ,
+    last_used_at  TIMESTAMPTZ,
+    revoked_at    TIMESTAMPTZ,
+    rotated_to    UUID REFERENCES api_keys(id),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_api_keys_shop ON api_keys(shop_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+ALTER TABLE shops DROP COLUMN IF EXISTS owner_id;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE outbox_events (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seq             BIGSERIAL NOT NULL UNIQUE, -- insertion order; events of one aggregate are delivered in this order
+    aggregate_type  VARCHAR(64) NOT NULL,
+    aggregate_id    UUID NOT NULL,
+    event_type      VARCHAR(128) NOT NULL,
+    payload         JSONB NOT NULL,
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_outbox_events_pending ON outbox_events(seq) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_events_aggregate_pending ON outbox_events(aggregate_id, seq) WHERE published_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox_events;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE webhook_subscriptions (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    shop_id            UUID NOT NULL REFERENCES shops(id) ON DELETE CASCADE,
+    user_id            UUID NOT NULL REFERENCES users(id), -- creator
+    url                TEXT NOT NULL,
+    secret             BYTEA NOT NULL, -- signing secret, encrypted with the application AES key
+    secret_key_version INT NOT NULL DEFAULT 1,
+    event_types        TEXT[] NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_webhook_subscriptions_shop ON webhook_subscriptions(shop_id);
+
+CREATE TYPE webhook_delivery_status AS ENUM ('PENDING', 'SUCCEEDED', 'DEAD');
+
+CREATE TABLE webhook_deliveries (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id  UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id         UUID NOT NULL, -- outbox event; sent as X-Webhook-ID so receivers can deduplicate
+    event_type       VARCHAR(128) NOT NULL,
+    payload          JSONB NOT NULL,
+    status           webhook_delivery_status NOT NULL DEFAULT 'PENDING',
+    attempts         INT NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_status_code INT,
+    last_error       TEXT,
+    delivered_at     TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TYPE IF EXISTS webhook_delivery_status;
+DROP TABLE IF EXISTS webhook_subscriptions;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE payment_intent_status AS ENUM ('REQUIRES_PAYMENT', 'SUCCEEDED', 'FAILED', 'REFUNDED');
+
+CREATE TABLE payment_intents (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id     UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    provider     VARCHAR(32) NOT NULL,
+    provider_ref VARCHAR(255) NOT NULL, -- intent identifier on the provider side
+    amount       BIGINT NOT NULL,
+    status       payment_intent_status NOT NULL DEFAULT 'REQUIRES_PAYMENT',
+    checkout_url TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (provider, provider_ref)
+);
+
+-- At most one intent per order can be waiting for the customer
+CREATE UNIQUE INDEX idx_payment_intents_open_order ON payment_intents(order_id) WHERE status = 'REQUIRES_PAYMENT';
+
+-- Provider webhook events already applied, so redelivered events are acknowledged without effect
+CREATE TABLE payment_events (
+    provider          VARCHAR(32) NOT NULL,
+    event_id          VARCHAR(255) NOT NULL,
+    event_type        VARCHAR(64) NOT NULL,
+    payment_intent_id UUID REFERENCES payment_intents(id) ON DELETE SET NULL,
+    received_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider, event_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS payment_events;
+DROP TABLE IF EXISTS payment_intents;
+DROP TYPE IF EXISTS payment_intent_status;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'PARTIALLY_REFUNDED';
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'REFUNDED';
+
+CREATE TABLE order_refunds (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id   UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    amount     BIGINT NOT NULL,
+    reason     TEXT NOT NULL DEFAULT '',
+    restock    BOOLEAN NOT NULL DEFAULT FALSE,
+    status     VARCHAR(16) NOT NULL DEFAULT 'SUCCEEDED', -- PENDING until the payment provider has returned the money
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_refunds_order ON order_refunds(order_id, created_at);
+
+CREATE TABLE order_refund_items (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    refund_id     UUID NOT NULL REFERENCES order_refunds(id) ON DELETE CASCADE,
+    order_item_id UUID NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    qty           INT NOT NULL CHECK (qty > 0),
+    amount        BIGINT NOT NULL,
+    warehouse_id  UUID REFERENCES warehouses(id) -- where the returned stock was put back, NULL without restock
+);
+
+CREATE INDEX idx_order_refund_items_refund ON order_refund_items(refund_id);
+CREATE INDEX idx_order_refund_items_order_item ON order_refund_items(order_item_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_refund_items;
+DROP TABLE IF EXISTS order_refunds;
+-- Note: PostgreSQL doesn't support removing enum values, PARTIALLY_REFUNDED and REFUNDED stay in order_status
+-- +goose StatementEnd
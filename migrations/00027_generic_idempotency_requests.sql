@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keys are now taken from the Idempotency-Key header of any mutating endpoint and scoped to the caller.
+-- Checkout no longer records its order here, the stored response carries everything to replay.
+DROP INDEX IF EXISTS idx_idempotency_requests_key_endpoint;
+ALTER TABLE idempotency_requests DROP CONSTRAINT IF EXISTS idempotency_requests_key_endpoint_key;
+ALTER TABLE idempotency_requests DROP COLUMN order_id;
+
+ALTER TABLE idempotency_requests
+    ALTER COLUMN endpoint TYPE VARCHAR(255),
+    ALTER COLUMN response_body TYPE BYTEA USING convert_to(response_body::text, 'UTF8'),
+    ADD COLUMN principal    VARCHAR(128) NOT NULL DEFAULT '', -- user, and shop for API keys, that sent the request
+    ADD COLUMN status_code  INT,                              -- NULL while the first request is in flight
+    ADD COLUMN content_type VARCHAR(255),
+    ADD COLUMN locked_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN lease_token  UUID,                             -- request holding the key; only it may settle the key
+    ADD COLUMN completed_at TIMESTAMPTZ,
+    ADD CONSTRAINT idempotency_requests_key_endpoint_principal_key UNIQUE (key, endpoint, principal);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM idempotency_requests WHERE principal <> '';
+
+ALTER TABLE idempotency_requests
+    DROP CONSTRAINT IF EXISTS idempotency_requests_key_endpoint_principal_key,
+    DROP COLUMN completed_at,
+    DROP COLUMN lease_token,
+    DROP COLUMN locked_at,
+    DROP COLUMN content_type,
+    DROP COLUMN status_code,
+    DROP COLUMN principal,
+    ALTER COLUMN response_body TYPE JSONB USING convert_from(response_body, 'UTF8')::jsonb,
+    ALTER COLUMN endpoint TYPE VARCHAR(128),
+    ADD COLUMN order_id UUID,
+    ADD CONSTRAINT idempotency_requests_key_endpoint_key UNIQUE (key, endpoint);
+
+CREATE INDEX idx_idempotency_requests_key_endpoint ON idempotency_requests(key, endpoint);
+-- +goose StatementEnd
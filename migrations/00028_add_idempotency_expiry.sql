@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keys are kept for a retention that depends on the endpoint, then purged in batches by the
+-- idempotency purge worker. An expired key still waiting to be purged is treated as new.
+ALTER TABLE idempotency_requests ADD COLUMN expires_at TIMESTAMPTZ;
+UPDATE idempotency_requests SET expires_at = created_at + INTERVAL '24 hours';
+ALTER TABLE idempotency_requests ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX idx_idempotency_requests_expires_at ON idempotency_requests(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_idempotency_requests_expires_at;
+ALTER TABLE idempotency_requests DROP COLUMN expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Rate limit buckets shared by every instance. Each bucket is the time at which its quota is fully
+-- available again; rows past that time are equivalent to missing ones and are cleared periodically.
+-- Unlogged, as losing the counters on a crash only resets quotas.
+CREATE UNLOGGED TABLE rate_limits (
+    key TEXT PRIMARY KEY,
+    tat TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_rate_limits_tat ON rate_limits(tat);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS rate_limits;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE job_status AS ENUM ('PENDING', 'SUCCEEDED', 'DEAD', 'CANCELLED');
+
+-- Background jobs. Workers claim due PENDING jobs with FOR UPDATE SKIP LOCKED and hold the row lock
+-- while the handler runs. Failed jobs are retried with backoff until max_attempts, then left DEAD.
+CREATE TABLE jobs (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type         VARCHAR(64) NOT NULL,
+    payload      JSONB NOT NULL,
+    status       job_status NOT NULL DEFAULT 'PENDING',
+    run_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    attempts     INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    last_error   TEXT,
+    completed_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_jobs_due ON jobs(run_at) WHERE status = 'PENDING';
+CREATE INDEX idx_jobs_status_created_at ON jobs(status, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS jobs;
+DROP TYPE IF EXISTS job_status;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Expired reservations that keep failing to release are QUARANTINED after a number of attempts, so
+-- the stock release worker stops retrying them and they no longer hold up the rest of the batch.
+ALTER TYPE reservation_status ADD VALUE IF NOT EXISTS 'QUARANTINED';
+
+ALTER TABLE stock_reservations
+    ADD COLUMN release_attempts   INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_release_error TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- PostgreSQL cannot remove an enum value, so QUARANTINED stays; its rows go back to PENDING.
+UPDATE stock_reservations SET status = 'PENDING' WHERE status = 'QUARANTINED';
+
+ALTER TABLE stock_reservations
+    DROP COLUMN IF EXISTS last_release_error,
+    DROP COLUMN IF EXISTS release_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Counts how many times an unpaid order extended its stock reservations, so the extension limit can be enforced.
+ALTER TABLE orders ADD COLUMN reservation_extensions INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP COLUMN IF EXISTS reservation_extensions;
+-- +goose StatementEnd
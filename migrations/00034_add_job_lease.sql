@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Workers lease a job instead of holding its row lock while the handler runs. A claimed job is
+-- skipped until locked_until passes, so a crashed worker's job is picked up again after the lease.
+ALTER TABLE jobs ADD COLUMN locked_until TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE jobs DROP COLUMN IF EXISTS locked_until;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Stock release worker state shared by all replicas: a pause applies to whichever replica leads, and
+-- the outcome of the leader's last batch can be read from any of them. Holds exactly one row.
+CREATE TABLE stock_release_state (
+    id             BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    paused         BOOLEAN NOT NULL DEFAULT false,
+    last_run_at    TIMESTAMPTZ,
+    last_error     TEXT,
+    last_released  INT NOT NULL DEFAULT 0,
+    total_released BIGINT NOT NULL DEFAULT 0,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO stock_release_state DEFAULT VALUES;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS stock_release_state;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+// Package migrations embeds the goose SQL migrations so the binary knows which schema version it expects.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the highest migration version shipped with this build, or 0 if there are none.
+func LatestVersion() int64 {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return 0
+	}
+
+	var latest int64
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+
+		v, err := strconv.ParseInt(prefix, 10, 64)
+		if err == nil && v > latest {
+			latest = v
+		}
+	}
+
+	return latest
+}
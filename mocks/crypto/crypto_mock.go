@@ -5,6 +5,8 @@
 package crypto
 
 import (
+	"context"
+
 	"github.com/dyaksa/encryption-pii/crypto/aesx"
 	"github.com/dyaksa/encryption-pii/crypto/core"
 	mock "github.com/stretchr/testify/mock"
@@ -173,6 +175,61 @@ func (_c *MockCrypto_Decrypt_Call) RunAndReturn(run func(def string) aesx.AES[st
 	return _c
 }
 
+// DecryptVersion provides a mock function for the type MockCrypto
+func (_mock *MockCrypto) DecryptVersion(version int, def string) (aesx.AES[string, core.PrimitiveAES], error) {
+	ret := _mock.Called(version, def)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DecryptVersion")
+	}
+
+	var r0 aesx.AES[string, core.PrimitiveAES]
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(int, string) (aesx.AES[string, core.PrimitiveAES], error)); ok {
+		return returnFunc(version, def)
+	}
+	if returnFunc, ok := ret.Get(0).(func(int, string) aesx.AES[string, core.PrimitiveAES]); ok {
+		r0 = returnFunc(version, def)
+	} else {
+		r0 = ret.Get(0).(aesx.AES[string, core.PrimitiveAES])
+	}
+	if returnFunc, ok := ret.Get(1).(func(int, string) error); ok {
+		r1 = returnFunc(version, def)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCrypto_DecryptVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DecryptVersion'
+type MockCrypto_DecryptVersion_Call struct {
+	*mock.Call
+}
+
+// DecryptVersion is a helper method to define mock.On call
+//   - version
+//   - def
+func (_e *MockCrypto_Expecter) DecryptVersion(version interface{}, def interface{}) *MockCrypto_DecryptVersion_Call {
+	return &MockCrypto_DecryptVersion_Call{Call: _e.mock.On("DecryptVersion", version, def)}
+}
+
+func (_c *MockCrypto_DecryptVersion_Call) Run(run func(version int, def string)) *MockCrypto_DecryptVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockCrypto_DecryptVersion_Call) Return(aES aesx.AES[string, core.PrimitiveAES], err error) *MockCrypto_DecryptVersion_Call {
+	_c.Call.Return(aES, err)
+	return _c
+}
+
+func (_c *MockCrypto_DecryptVersion_Call) RunAndReturn(run func(version int, def string) (aesx.AES[string, core.PrimitiveAES], error)) *MockCrypto_DecryptVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Encrypt provides a mock function for the type MockCrypto
 func (_mock *MockCrypto) Encrypt(data string) aesx.AES[string, core.PrimitiveAES] {
 	ret := _mock.Called(data)
@@ -262,3 +319,185 @@ func (_c *MockCrypto_HashString_Call) RunAndReturn(run func(s string) string) *M
 	_c.Call.Return(run)
 	return _c
 }
+
+// HashStringVersions provides a mock function for the type MockCrypto
+func (_mock *MockCrypto) HashStringVersions(s string) []string {
+	ret := _mock.Called(s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HashStringVersions")
+	}
+
+	var r0 []string
+	if returnFunc, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = returnFunc(s)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	return r0
+}
+
+// MockCrypto_HashStringVersions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HashStringVersions'
+type MockCrypto_HashStringVersions_Call struct {
+	*mock.Call
+}
+
+// HashStringVersions is a helper method to define mock.On call
+//   - s
+func (_e *MockCrypto_Expecter) HashStringVersions(s interface{}) *MockCrypto_HashStringVersions_Call {
+	return &MockCrypto_HashStringVersions_Call{Call: _e.mock.On("HashStringVersions", s)}
+}
+
+func (_c *MockCrypto_HashStringVersions_Call) Run(run func(s string)) *MockCrypto_HashStringVersions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockCrypto_HashStringVersions_Call) Return(strings []string) *MockCrypto_HashStringVersions_Call {
+	_c.Call.Return(strings)
+	return _c
+}
+
+func (_c *MockCrypto_HashStringVersions_Call) RunAndReturn(run func(s string) []string) *MockCrypto_HashStringVersions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// KeyVersion provides a mock function for the type MockCrypto
+func (_mock *MockCrypto) KeyVersion() int {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for KeyVersion")
+	}
+
+	var r0 int
+	if returnFunc, ok := ret.Get(0).(func() int); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	return r0
+}
+
+// MockCrypto_KeyVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'KeyVersion'
+type MockCrypto_KeyVersion_Call struct {
+	*mock.Call
+}
+
+// KeyVersion is a helper method to define mock.On call
+func (_e *MockCrypto_Expecter) KeyVersion() *MockCrypto_KeyVersion_Call {
+	return &MockCrypto_KeyVersion_Call{Call: _e.mock.On("KeyVersion")}
+}
+
+func (_c *MockCrypto_KeyVersion_Call) Run(run func()) *MockCrypto_KeyVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockCrypto_KeyVersion_Call) Return(n int) *MockCrypto_KeyVersion_Call {
+	_c.Call.Return(n)
+	return _c
+}
+
+func (_c *MockCrypto_KeyVersion_Call) RunAndReturn(run func() int) *MockCrypto_KeyVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PingHeap provides a mock function for the type MockCrypto
+func (_mock *MockCrypto) PingHeap(ctx context.Context) error {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PingHeap")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCrypto_PingHeap_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PingHeap'
+type MockCrypto_PingHeap_Call struct {
+	*mock.Call
+}
+
+// PingHeap is a helper method to define mock.On call
+//   - ctx
+func (_e *MockCrypto_Expecter) PingHeap(ctx interface{}) *MockCrypto_PingHeap_Call {
+	return &MockCrypto_PingHeap_Call{Call: _e.mock.On("PingHeap", ctx)}
+}
+
+func (_c *MockCrypto_PingHeap_Call) Run(run func(ctx context.Context)) *MockCrypto_PingHeap_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockCrypto_PingHeap_Call) Return(err error) *MockCrypto_PingHeap_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCrypto_PingHeap_Call) RunAndReturn(run func(ctx context.Context) error) *MockCrypto_PingHeap_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnbindHeap provides a mock function for the type MockCrypto
+func (_mock *MockCrypto) UnbindHeap(ctx context.Context, entity any) error {
+	ret := _mock.Called(ctx, entity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnbindHeap")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, any) error); ok {
+		r0 = returnFunc(ctx, entity)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCrypto_UnbindHeap_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnbindHeap'
+type MockCrypto_UnbindHeap_Call struct {
+	*mock.Call
+}
+
+// UnbindHeap is a helper method to define mock.On call
+//   - ctx
+//   - entity
+func (_e *MockCrypto_Expecter) UnbindHeap(ctx interface{}, entity interface{}) *MockCrypto_UnbindHeap_Call {
+	return &MockCrypto_UnbindHeap_Call{Call: _e.mock.On("UnbindHeap", ctx, entity)}
+}
+
+func (_c *MockCrypto_UnbindHeap_Call) Run(run func(ctx context.Context, entity any)) *MockCrypto_UnbindHeap_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(any))
+	})
+	return _c
+}
+
+func (_c *MockCrypto_UnbindHeap_Call) Return(err error) *MockCrypto_UnbindHeap_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCrypto_UnbindHeap_Call) RunAndReturn(run func(ctx context.Context, entity any) error) *MockCrypto_UnbindHeap_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -195,6 +195,52 @@ func (_c *MockClient_Close_Call) RunAndReturn(run func() error) *MockClient_Clos
 	return _c
 }
 
+// DB provides a mock function for the type MockClient
+func (_mock *MockClient) DB() *sql.DB {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DB")
+	}
+
+	var r0 *sql.DB
+	if returnFunc, ok := ret.Get(0).(func() *sql.DB); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.DB)
+		}
+	}
+	return r0
+}
+
+// MockClient_DB_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DB'
+type MockClient_DB_Call struct {
+	*mock.Call
+}
+
+// DB is a helper method to define mock.On call
+func (_e *MockClient_Expecter) DB() *MockClient_DB_Call {
+	return &MockClient_DB_Call{Call: _e.mock.On("DB")}
+}
+
+func (_c *MockClient_DB_Call) Run(run func()) *MockClient_DB_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockClient_DB_Call) Return(dB *sql.DB) *MockClient_DB_Call {
+	_c.Call.Return(dB)
+	return _c
+}
+
+func (_c *MockClient_DB_Call) RunAndReturn(run func() *sql.DB) *MockClient_DB_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Database provides a mock function for the type MockClient
 func (_mock *MockClient) Database() pqsql.Database {
 	ret := _mock.Called()
@@ -442,3 +488,49 @@ func (_c *MockClient_PrepareContext_Call) RunAndReturn(run func(ctx context.Cont
 	_c.Call.Return(run)
 	return _c
 }
+
+// Stats provides a mock function for the type MockClient
+func (_mock *MockClient) Stats() sql.DBStats {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 sql.DBStats
+	if returnFunc, ok := ret.Get(0).(func() sql.DBStats); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(sql.DBStats)
+		}
+	}
+	return r0
+}
+
+// MockClient_Stats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stats'
+type MockClient_Stats_Call struct {
+	*mock.Call
+}
+
+// Stats is a helper method to define mock.On call
+func (_e *MockClient_Expecter) Stats() *MockClient_Stats_Call {
+	return &MockClient_Stats_Call{Call: _e.mock.On("Stats")}
+}
+
+func (_c *MockClient_Stats_Call) Run(run func()) *MockClient_Stats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockClient_Stats_Call) Return(dBStats sql.DBStats) *MockClient_Stats_Call {
+	_c.Call.Return(dBStats)
+	return _c
+}
+
+func (_c *MockClient_Stats_Call) RunAndReturn(run func() sql.DBStats) *MockClient_Stats_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -227,6 +227,57 @@ func (_c *MockDatabase_QueryRowContext_Call) RunAndReturn(run func(ctx context.C
 	return _c
 }
 
+// Savepoint provides a mock function for the type MockDatabase
+func (_mock *MockDatabase) Savepoint(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error) error {
+	ret := _mock.Called(ctx, tx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Savepoint")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, func(ctx context.Context) error) error); ok {
+		r0 = returnFunc(ctx, tx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockDatabase_Savepoint_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Savepoint'
+type MockDatabase_Savepoint_Call struct {
+	*mock.Call
+}
+
+// Savepoint is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - fn
+func (_e *MockDatabase_Expecter) Savepoint(ctx interface{}, tx interface{}, fn interface{}) *MockDatabase_Savepoint_Call {
+	return &MockDatabase_Savepoint_Call{Call: _e.mock.On("Savepoint", ctx, tx, fn)}
+}
+
+func (_c *MockDatabase_Savepoint_Call) Run(run func(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error)) *MockDatabase_Savepoint_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg1 *sql.Tx
+		if args[1] != nil {
+			arg1 = args[1].(*sql.Tx)
+		}
+		run(args[0].(context.Context), arg1, args[2].(func(ctx context.Context) error))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_Savepoint_Call) Return(err error) *MockDatabase_Savepoint_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockDatabase_Savepoint_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error) error) *MockDatabase_Savepoint_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Transaction provides a mock function for the type MockDatabase
 func (_mock *MockDatabase) Transaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) (any, error)) (any, error) {
 	ret := _mock.Called(ctx, fn)
@@ -7,7 +7,9 @@ package domain
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/dyaksa/warehouse/domain"
 	"github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -39,189 +41,319 @@ func (_m *MockIdempotencyRequestRepository) EXPECT() *MockIdempotencyRequestRepo
 	return &MockIdempotencyRequestRepository_Expecter{mock: &_m.Mock}
 }
 
-// BeginKey provides a mock function for the type MockIdempotencyRequestRepository
-func (_mock *MockIdempotencyRequestRepository) BeginKey(ctx context.Context, tx *sql.Tx, key string, endpoint string, payloadHash string) (bool, error) {
-	ret := _mock.Called(ctx, tx, key, endpoint, payloadHash)
+// Claim provides a mock function for the type MockIdempotencyRequestRepository
+func (_mock *MockIdempotencyRequestRepository) Claim(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time) (bool, error) {
+	ret := _mock.Called(ctx, tx, key, lease, payloadHash, expiresAt)
 
 	if len(ret) == 0 {
-		panic("no return value specified for BeginKey")
+		panic("no return value specified for Claim")
 	}
 
 	var r0 bool
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, string, string) (bool, error)); ok {
-		return returnFunc(ctx, tx, key, endpoint, payloadHash)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, domain.IdempotencyKey, uuid.UUID, string, time.Time) (bool, error)); ok {
+		return returnFunc(ctx, tx, key, lease, payloadHash, expiresAt)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, string, string) bool); ok {
-		r0 = returnFunc(ctx, tx, key, endpoint, payloadHash)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, domain.IdempotencyKey, uuid.UUID, string, time.Time) bool); ok {
+		r0 = returnFunc(ctx, tx, key, lease, payloadHash, expiresAt)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, string, string) error); ok {
-		r1 = returnFunc(ctx, tx, key, endpoint, payloadHash)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, domain.IdempotencyKey, uuid.UUID, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, tx, key, lease, payloadHash, expiresAt)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockIdempotencyRequestRepository_BeginKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BeginKey'
-type MockIdempotencyRequestRepository_BeginKey_Call struct {
+// MockIdempotencyRequestRepository_Claim_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Claim'
+type MockIdempotencyRequestRepository_Claim_Call struct {
 	*mock.Call
 }
 
-// BeginKey is a helper method to define mock.On call
+// Claim is a helper method to define mock.On call
 //   - ctx
 //   - tx
 //   - key
-//   - endpoint
+//   - lease
 //   - payloadHash
-func (_e *MockIdempotencyRequestRepository_Expecter) BeginKey(ctx interface{}, tx interface{}, key interface{}, endpoint interface{}, payloadHash interface{}) *MockIdempotencyRequestRepository_BeginKey_Call {
-	return &MockIdempotencyRequestRepository_BeginKey_Call{Call: _e.mock.On("BeginKey", ctx, tx, key, endpoint, payloadHash)}
+//   - expiresAt
+func (_e *MockIdempotencyRequestRepository_Expecter) Claim(ctx interface{}, tx interface{}, key interface{}, lease interface{}, payloadHash interface{}, expiresAt interface{}) *MockIdempotencyRequestRepository_Claim_Call {
+	return &MockIdempotencyRequestRepository_Claim_Call{Call: _e.mock.On("Claim", ctx, tx, key, lease, payloadHash, expiresAt)}
 }
 
-func (_c *MockIdempotencyRequestRepository_BeginKey_Call) Run(run func(ctx context.Context, tx *sql.Tx, key string, endpoint string, payloadHash string)) *MockIdempotencyRequestRepository_BeginKey_Call {
+func (_c *MockIdempotencyRequestRepository_Claim_Call) Run(run func(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time)) *MockIdempotencyRequestRepository_Claim_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(string), args[3].(string), args[4].(string))
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(domain.IdempotencyKey), args[3].(uuid.UUID), args[4].(string), args[5].(time.Time))
 	})
 	return _c
 }
 
-func (_c *MockIdempotencyRequestRepository_BeginKey_Call) Return(isNew bool, err error) *MockIdempotencyRequestRepository_BeginKey_Call {
-	_c.Call.Return(isNew, err)
+func (_c *MockIdempotencyRequestRepository_Claim_Call) Return(b bool, err error) *MockIdempotencyRequestRepository_Claim_Call {
+	_c.Call.Return(b, err)
 	return _c
 }
 
-func (_c *MockIdempotencyRequestRepository_BeginKey_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, key string, endpoint string, payloadHash string) (bool, error)) *MockIdempotencyRequestRepository_BeginKey_Call {
+func (_c *MockIdempotencyRequestRepository_Claim_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time) (bool, error)) *MockIdempotencyRequestRepository_Claim_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// LoadIfExists provides a mock function for the type MockIdempotencyRequestRepository
-func (_mock *MockIdempotencyRequestRepository) LoadIfExists(ctx context.Context, tx *sql.Tx, key string, endpoint string) (string, *uuid.UUID, []byte, bool, error) {
-	ret := _mock.Called(ctx, tx, key, endpoint)
+// Complete provides a mock function for the type MockIdempotencyRequestRepository
+func (_mock *MockIdempotencyRequestRepository) Complete(ctx context.Context, key domain.IdempotencyKey, lease uuid.UUID, response domain.IdempotentResponse) error {
+	ret := _mock.Called(ctx, key, lease, response)
 
 	if len(ret) == 0 {
-		panic("no return value specified for LoadIfExists")
+		panic("no return value specified for Complete")
 	}
 
-	var r0 string
-	var r1 *uuid.UUID
-	var r2 []byte
-	var r3 bool
-	var r4 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, string) (string, *uuid.UUID, []byte, bool, error)); ok {
-		return returnFunc(ctx, tx, key, endpoint)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.IdempotencyKey, uuid.UUID, domain.IdempotentResponse) error); ok {
+		r0 = returnFunc(ctx, key, lease, response)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockIdempotencyRequestRepository_Complete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Complete'
+type MockIdempotencyRequestRepository_Complete_Call struct {
+	*mock.Call
+}
+
+// Complete is a helper method to define mock.On call
+//   - ctx
+//   - key
+//   - lease
+//   - response
+func (_e *MockIdempotencyRequestRepository_Expecter) Complete(ctx interface{}, key interface{}, lease interface{}, response interface{}) *MockIdempotencyRequestRepository_Complete_Call {
+	return &MockIdempotencyRequestRepository_Complete_Call{Call: _e.mock.On("Complete", ctx, key, lease, response)}
+}
+
+func (_c *MockIdempotencyRequestRepository_Complete_Call) Run(run func(ctx context.Context, key domain.IdempotencyKey, lease uuid.UUID, response domain.IdempotentResponse)) *MockIdempotencyRequestRepository_Complete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.IdempotencyKey), args[2].(uuid.UUID), args[3].(domain.IdempotentResponse))
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyRequestRepository_Complete_Call) Return(err error) *MockIdempotencyRequestRepository_Complete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockIdempotencyRequestRepository_Complete_Call) RunAndReturn(run func(ctx context.Context, key domain.IdempotencyKey, lease uuid.UUID, response domain.IdempotentResponse) error) *MockIdempotencyRequestRepository_Complete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpired provides a mock function for the type MockIdempotencyRequestRepository
+func (_mock *MockIdempotencyRequestRepository) DeleteExpired(ctx context.Context, limit int) (int, error) {
+	ret := _mock.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpired")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return returnFunc(ctx, limit)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, string) string); ok {
-		r0 = returnFunc(ctx, tx, key, endpoint)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = returnFunc(ctx, limit)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(int)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, string) *uuid.UUID); ok {
-		r1 = returnFunc(ctx, tx, key, endpoint)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, limit)
 	} else {
-		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*uuid.UUID)
-		}
+		r1 = ret.Error(1)
 	}
-	if returnFunc, ok := ret.Get(2).(func(context.Context, *sql.Tx, string, string) []byte); ok {
-		r2 = returnFunc(ctx, tx, key, endpoint)
+	return r0, r1
+}
+
+// MockIdempotencyRequestRepository_DeleteExpired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpired'
+type MockIdempotencyRequestRepository_DeleteExpired_Call struct {
+	*mock.Call
+}
+
+// DeleteExpired is a helper method to define mock.On call
+//   - ctx
+//   - limit
+func (_e *MockIdempotencyRequestRepository_Expecter) DeleteExpired(ctx interface{}, limit interface{}) *MockIdempotencyRequestRepository_DeleteExpired_Call {
+	return &MockIdempotencyRequestRepository_DeleteExpired_Call{Call: _e.mock.On("DeleteExpired", ctx, limit)}
+}
+
+func (_c *MockIdempotencyRequestRepository_DeleteExpired_Call) Run(run func(ctx context.Context, limit int)) *MockIdempotencyRequestRepository_DeleteExpired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyRequestRepository_DeleteExpired_Call) Return(n int, err error) *MockIdempotencyRequestRepository_DeleteExpired_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockIdempotencyRequestRepository_DeleteExpired_Call) RunAndReturn(run func(ctx context.Context, limit int) (int, error)) *MockIdempotencyRequestRepository_DeleteExpired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetForUpdate provides a mock function for the type MockIdempotencyRequestRepository
+func (_mock *MockIdempotencyRequestRepository) GetForUpdate(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey) (*domain.IdempotencyRecord, error) {
+	ret := _mock.Called(ctx, tx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetForUpdate")
+	}
+
+	var r0 *domain.IdempotencyRecord
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, domain.IdempotencyKey) (*domain.IdempotencyRecord, error)); ok {
+		return returnFunc(ctx, tx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, domain.IdempotencyKey) *domain.IdempotencyRecord); ok {
+		r0 = returnFunc(ctx, tx, key)
 	} else {
-		if ret.Get(2) != nil {
-			r2 = ret.Get(2).([]byte)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.IdempotencyRecord)
 		}
 	}
-	if returnFunc, ok := ret.Get(3).(func(context.Context, *sql.Tx, string, string) bool); ok {
-		r3 = returnFunc(ctx, tx, key, endpoint)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, domain.IdempotencyKey) error); ok {
+		r1 = returnFunc(ctx, tx, key)
 	} else {
-		r3 = ret.Get(3).(bool)
+		r1 = ret.Error(1)
 	}
-	if returnFunc, ok := ret.Get(4).(func(context.Context, *sql.Tx, string, string) error); ok {
-		r4 = returnFunc(ctx, tx, key, endpoint)
+	return r0, r1
+}
+
+// MockIdempotencyRequestRepository_GetForUpdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetForUpdate'
+type MockIdempotencyRequestRepository_GetForUpdate_Call struct {
+	*mock.Call
+}
+
+// GetForUpdate is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - key
+func (_e *MockIdempotencyRequestRepository_Expecter) GetForUpdate(ctx interface{}, tx interface{}, key interface{}) *MockIdempotencyRequestRepository_GetForUpdate_Call {
+	return &MockIdempotencyRequestRepository_GetForUpdate_Call{Call: _e.mock.On("GetForUpdate", ctx, tx, key)}
+}
+
+func (_c *MockIdempotencyRequestRepository_GetForUpdate_Call) Run(run func(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey)) *MockIdempotencyRequestRepository_GetForUpdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(domain.IdempotencyKey))
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyRequestRepository_GetForUpdate_Call) Return(idempotencyRecord *domain.IdempotencyRecord, err error) *MockIdempotencyRequestRepository_GetForUpdate_Call {
+	_c.Call.Return(idempotencyRecord, err)
+	return _c
+}
+
+func (_c *MockIdempotencyRequestRepository_GetForUpdate_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey) (*domain.IdempotencyRecord, error)) *MockIdempotencyRequestRepository_GetForUpdate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reclaim provides a mock function for the type MockIdempotencyRequestRepository
+func (_mock *MockIdempotencyRequestRepository) Reclaim(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time) error {
+	ret := _mock.Called(ctx, tx, key, lease, payloadHash, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reclaim")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, domain.IdempotencyKey, uuid.UUID, string, time.Time) error); ok {
+		r0 = returnFunc(ctx, tx, key, lease, payloadHash, expiresAt)
 	} else {
-		r4 = ret.Error(4)
+		r0 = ret.Error(0)
 	}
-	return r0, r1, r2, r3, r4
+	return r0
 }
 
-// MockIdempotencyRequestRepository_LoadIfExists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoadIfExists'
-type MockIdempotencyRequestRepository_LoadIfExists_Call struct {
+// MockIdempotencyRequestRepository_Reclaim_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reclaim'
+type MockIdempotencyRequestRepository_Reclaim_Call struct {
 	*mock.Call
 }
 
-// LoadIfExists is a helper method to define mock.On call
+// Reclaim is a helper method to define mock.On call
 //   - ctx
 //   - tx
 //   - key
-//   - endpoint
-func (_e *MockIdempotencyRequestRepository_Expecter) LoadIfExists(ctx interface{}, tx interface{}, key interface{}, endpoint interface{}) *MockIdempotencyRequestRepository_LoadIfExists_Call {
-	return &MockIdempotencyRequestRepository_LoadIfExists_Call{Call: _e.mock.On("LoadIfExists", ctx, tx, key, endpoint)}
+//   - lease
+//   - payloadHash
+//   - expiresAt
+func (_e *MockIdempotencyRequestRepository_Expecter) Reclaim(ctx interface{}, tx interface{}, key interface{}, lease interface{}, payloadHash interface{}, expiresAt interface{}) *MockIdempotencyRequestRepository_Reclaim_Call {
+	return &MockIdempotencyRequestRepository_Reclaim_Call{Call: _e.mock.On("Reclaim", ctx, tx, key, lease, payloadHash, expiresAt)}
 }
 
-func (_c *MockIdempotencyRequestRepository_LoadIfExists_Call) Run(run func(ctx context.Context, tx *sql.Tx, key string, endpoint string)) *MockIdempotencyRequestRepository_LoadIfExists_Call {
+func (_c *MockIdempotencyRequestRepository_Reclaim_Call) Run(run func(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time)) *MockIdempotencyRequestRepository_Reclaim_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(string), args[3].(string))
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(domain.IdempotencyKey), args[3].(uuid.UUID), args[4].(string), args[5].(time.Time))
 	})
 	return _c
 }
 
-func (_c *MockIdempotencyRequestRepository_LoadIfExists_Call) Return(payloadHash string, orderID *uuid.UUID, responseJSON []byte, exists bool, err error) *MockIdempotencyRequestRepository_LoadIfExists_Call {
-	_c.Call.Return(payloadHash, orderID, responseJSON, exists, err)
+func (_c *MockIdempotencyRequestRepository_Reclaim_Call) Return(err error) *MockIdempotencyRequestRepository_Reclaim_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockIdempotencyRequestRepository_LoadIfExists_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, key string, endpoint string) (string, *uuid.UUID, []byte, bool, error)) *MockIdempotencyRequestRepository_LoadIfExists_Call {
+func (_c *MockIdempotencyRequestRepository_Reclaim_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time) error) *MockIdempotencyRequestRepository_Reclaim_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SaveResponse provides a mock function for the type MockIdempotencyRequestRepository
-func (_mock *MockIdempotencyRequestRepository) SaveResponse(ctx context.Context, tx *sql.Tx, key string, endpoint string, orderID uuid.UUID, responseJSON []byte) error {
-	ret := _mock.Called(ctx, tx, key, endpoint, orderID, responseJSON)
+// Release provides a mock function for the type MockIdempotencyRequestRepository
+func (_mock *MockIdempotencyRequestRepository) Release(ctx context.Context, key domain.IdempotencyKey, lease uuid.UUID) error {
+	ret := _mock.Called(ctx, key, lease)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SaveResponse")
+		panic("no return value specified for Release")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, string, uuid.UUID, []byte) error); ok {
-		r0 = returnFunc(ctx, tx, key, endpoint, orderID, responseJSON)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.IdempotencyKey, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, key, lease)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockIdempotencyRequestRepository_SaveResponse_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveResponse'
-type MockIdempotencyRequestRepository_SaveResponse_Call struct {
+// MockIdempotencyRequestRepository_Release_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Release'
+type MockIdempotencyRequestRepository_Release_Call struct {
 	*mock.Call
 }
 
-// SaveResponse is a helper method to define mock.On call
+// Release is a helper method to define mock.On call
 //   - ctx
-//   - tx
 //   - key
-//   - endpoint
-//   - orderID
-//   - responseJSON
-func (_e *MockIdempotencyRequestRepository_Expecter) SaveResponse(ctx interface{}, tx interface{}, key interface{}, endpoint interface{}, orderID interface{}, responseJSON interface{}) *MockIdempotencyRequestRepository_SaveResponse_Call {
-	return &MockIdempotencyRequestRepository_SaveResponse_Call{Call: _e.mock.On("SaveResponse", ctx, tx, key, endpoint, orderID, responseJSON)}
+//   - lease
+func (_e *MockIdempotencyRequestRepository_Expecter) Release(ctx interface{}, key interface{}, lease interface{}) *MockIdempotencyRequestRepository_Release_Call {
+	return &MockIdempotencyRequestRepository_Release_Call{Call: _e.mock.On("Release", ctx, key, lease)}
 }
 
-func (_c *MockIdempotencyRequestRepository_SaveResponse_Call) Run(run func(ctx context.Context, tx *sql.Tx, key string, endpoint string, orderID uuid.UUID, responseJSON []byte)) *MockIdempotencyRequestRepository_SaveResponse_Call {
+func (_c *MockIdempotencyRequestRepository_Release_Call) Run(run func(ctx context.Context, key domain.IdempotencyKey, lease uuid.UUID)) *MockIdempotencyRequestRepository_Release_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(string), args[3].(string), args[4].(uuid.UUID), args[5].([]byte))
+		run(args[0].(context.Context), args[1].(domain.IdempotencyKey), args[2].(uuid.UUID))
 	})
 	return _c
 }
 
-func (_c *MockIdempotencyRequestRepository_SaveResponse_Call) Return(err error) *MockIdempotencyRequestRepository_SaveResponse_Call {
+func (_c *MockIdempotencyRequestRepository_Release_Call) Return(err error) *MockIdempotencyRequestRepository_Release_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockIdempotencyRequestRepository_SaveResponse_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, key string, endpoint string, orderID uuid.UUID, responseJSON []byte) error) *MockIdempotencyRequestRepository_SaveResponse_Call {
+func (_c *MockIdempotencyRequestRepository_Release_Call) RunAndReturn(run func(ctx context.Context, key domain.IdempotencyKey, lease uuid.UUID) error) *MockIdempotencyRequestRepository_Release_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -9,6 +9,7 @@ import (
 	"database/sql"
 
 	"github.com/dyaksa/warehouse/domain"
+	"github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -85,3 +86,61 @@ func (_c *MockOrderItemRepository_BulkInsert_Call) RunAndReturn(run func(ctx con
 	_c.Call.Return(run)
 	return _c
 }
+
+// GetByOrderID provides a mock function for the type MockOrderItemRepository
+func (_mock *MockOrderItemRepository) GetByOrderID(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]domain.OrderItem, error) {
+	ret := _mock.Called(ctx, tx, orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByOrderID")
+	}
+
+	var r0 []domain.OrderItem
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) ([]domain.OrderItem, error)); ok {
+		return returnFunc(ctx, tx, orderID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) []domain.OrderItem); ok {
+		r0 = returnFunc(ctx, tx, orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.OrderItem)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, tx, orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderItemRepository_GetByOrderID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByOrderID'
+type MockOrderItemRepository_GetByOrderID_Call struct {
+	*mock.Call
+}
+
+// GetByOrderID is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - orderID
+func (_e *MockOrderItemRepository_Expecter) GetByOrderID(ctx interface{}, tx interface{}, orderID interface{}) *MockOrderItemRepository_GetByOrderID_Call {
+	return &MockOrderItemRepository_GetByOrderID_Call{Call: _e.mock.On("GetByOrderID", ctx, tx, orderID)}
+}
+
+func (_c *MockOrderItemRepository_GetByOrderID_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID)) *MockOrderItemRepository_GetByOrderID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockOrderItemRepository_GetByOrderID_Call) Return(orderItems []domain.OrderItem, err error) *MockOrderItemRepository_GetByOrderID_Call {
+	_c.Call.Return(orderItems, err)
+	return _c
+}
+
+func (_c *MockOrderItemRepository_GetByOrderID_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]domain.OrderItem, error)) *MockOrderItemRepository_GetByOrderID_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -7,6 +7,7 @@ package domain
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/dyaksa/warehouse/domain"
 	"github.com/google/uuid"
@@ -87,6 +88,54 @@ func (_c *MockOrderRepository_Create_Call) RunAndReturn(run func(ctx context.Con
 	return _c
 }
 
+// ExtendReservation provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) ExtendReservation(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, expiresAt time.Time) error {
+	ret := _mock.Called(ctx, tx, orderID, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExtendReservation")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID, time.Time) error); ok {
+		r0 = returnFunc(ctx, tx, orderID, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrderRepository_ExtendReservation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExtendReservation'
+type MockOrderRepository_ExtendReservation_Call struct {
+	*mock.Call
+}
+
+// ExtendReservation is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - orderID
+//   - expiresAt
+func (_e *MockOrderRepository_Expecter) ExtendReservation(ctx interface{}, tx interface{}, orderID interface{}, expiresAt interface{}) *MockOrderRepository_ExtendReservation_Call {
+	return &MockOrderRepository_ExtendReservation_Call{Call: _e.mock.On("ExtendReservation", ctx, tx, orderID, expiresAt)}
+}
+
+func (_c *MockOrderRepository_ExtendReservation_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, expiresAt time.Time)) *MockOrderRepository_ExtendReservation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_ExtendReservation_Call) Return(err error) *MockOrderRepository_ExtendReservation_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrderRepository_ExtendReservation_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, expiresAt time.Time) error) *MockOrderRepository_ExtendReservation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByID provides a mock function for the type MockOrderRepository
 func (_mock *MockOrderRepository) GetByID(ctx context.Context, orderID uuid.UUID) (*domain.Order, error) {
 	ret := _mock.Called(ctx, orderID)
@@ -144,6 +193,129 @@ func (_c *MockOrderRepository_GetByID_Call) RunAndReturn(run func(ctx context.Co
 	return _c
 }
 
+// GetByIDForUpdate provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetByIDForUpdate(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (*domain.Order, error) {
+	ret := _mock.Called(ctx, tx, orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDForUpdate")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) (*domain.Order, error)); ok {
+		return returnFunc(ctx, tx, orderID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) *domain.Order); ok {
+		r0 = returnFunc(ctx, tx, orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, tx, orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_GetByIDForUpdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByIDForUpdate'
+type MockOrderRepository_GetByIDForUpdate_Call struct {
+	*mock.Call
+}
+
+// GetByIDForUpdate is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - orderID
+func (_e *MockOrderRepository_Expecter) GetByIDForUpdate(ctx interface{}, tx interface{}, orderID interface{}) *MockOrderRepository_GetByIDForUpdate_Call {
+	return &MockOrderRepository_GetByIDForUpdate_Call{Call: _e.mock.On("GetByIDForUpdate", ctx, tx, orderID)}
+}
+
+func (_c *MockOrderRepository_GetByIDForUpdate_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID)) *MockOrderRepository_GetByIDForUpdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetByIDForUpdate_Call) Return(order *domain.Order, err error) *MockOrderRepository_GetByIDForUpdate_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetByIDForUpdate_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (*domain.Order, error)) *MockOrderRepository_GetByIDForUpdate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByShopID provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetByShopID(ctx context.Context, shopID uuid.UUID, limit int, offset int) ([]domain.OrderListItem, int, error) {
+	ret := _mock.Called(ctx, shopID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByShopID")
+	}
+
+	var r0 []domain.OrderListItem
+	var r1 int
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, int, int) ([]domain.OrderListItem, int, error)); ok {
+		return returnFunc(ctx, shopID, limit, offset)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, int, int) []domain.OrderListItem); ok {
+		r0 = returnFunc(ctx, shopID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.OrderListItem)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, int, int) int); ok {
+		r1 = returnFunc(ctx, shopID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, uuid.UUID, int, int) error); ok {
+		r2 = returnFunc(ctx, shopID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockOrderRepository_GetByShopID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByShopID'
+type MockOrderRepository_GetByShopID_Call struct {
+	*mock.Call
+}
+
+// GetByShopID is a helper method to define mock.On call
+//   - ctx
+//   - shopID
+//   - limit
+//   - offset
+func (_e *MockOrderRepository_Expecter) GetByShopID(ctx interface{}, shopID interface{}, limit interface{}, offset interface{}) *MockOrderRepository_GetByShopID_Call {
+	return &MockOrderRepository_GetByShopID_Call{Call: _e.mock.On("GetByShopID", ctx, shopID, limit, offset)}
+}
+
+func (_c *MockOrderRepository_GetByShopID_Call) Run(run func(ctx context.Context, shopID uuid.UUID, limit int, offset int)) *MockOrderRepository_GetByShopID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetByShopID_Call) Return(orderListItems []domain.OrderListItem, n int, err error) *MockOrderRepository_GetByShopID_Call {
+	_c.Call.Return(orderListItems, n, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetByShopID_Call) RunAndReturn(run func(ctx context.Context, shopID uuid.UUID, limit int, offset int) ([]domain.OrderListItem, int, error)) *MockOrderRepository_GetByShopID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByUserID provides a mock function for the type MockOrderRepository
 func (_mock *MockOrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]domain.OrderListItem, int, error) {
 	ret := _mock.Called(ctx, userID, limit, offset)
@@ -210,16 +382,16 @@ func (_c *MockOrderRepository_GetByUserID_Call) RunAndReturn(run func(ctx contex
 }
 
 // Updatestatus provides a mock function for the type MockOrderRepository
-func (_mock *MockOrderRepository) Updatestatus(ctx context.Context, orderID uuid.UUID, status domain.OrderStatus) error {
-	ret := _mock.Called(ctx, orderID, status)
+func (_mock *MockOrderRepository) Updatestatus(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, status domain.OrderStatus) error {
+	ret := _mock.Called(ctx, tx, orderID, status)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Updatestatus")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, domain.OrderStatus) error); ok {
-		r0 = returnFunc(ctx, orderID, status)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID, domain.OrderStatus) error); ok {
+		r0 = returnFunc(ctx, tx, orderID, status)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -233,15 +405,16 @@ type MockOrderRepository_Updatestatus_Call struct {
 
 // Updatestatus is a helper method to define mock.On call
 //   - ctx
+//   - tx
 //   - orderID
 //   - status
-func (_e *MockOrderRepository_Expecter) Updatestatus(ctx interface{}, orderID interface{}, status interface{}) *MockOrderRepository_Updatestatus_Call {
-	return &MockOrderRepository_Updatestatus_Call{Call: _e.mock.On("Updatestatus", ctx, orderID, status)}
+func (_e *MockOrderRepository_Expecter) Updatestatus(ctx interface{}, tx interface{}, orderID interface{}, status interface{}) *MockOrderRepository_Updatestatus_Call {
+	return &MockOrderRepository_Updatestatus_Call{Call: _e.mock.On("Updatestatus", ctx, tx, orderID, status)}
 }
 
-func (_c *MockOrderRepository_Updatestatus_Call) Run(run func(ctx context.Context, orderID uuid.UUID, status domain.OrderStatus)) *MockOrderRepository_Updatestatus_Call {
+func (_c *MockOrderRepository_Updatestatus_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, status domain.OrderStatus)) *MockOrderRepository_Updatestatus_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(domain.OrderStatus))
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID), args[3].(domain.OrderStatus))
 	})
 	return _c
 }
@@ -251,7 +424,7 @@ func (_c *MockOrderRepository_Updatestatus_Call) Return(err error) *MockOrderRep
 	return _c
 }
 
-func (_c *MockOrderRepository_Updatestatus_Call) RunAndReturn(run func(ctx context.Context, orderID uuid.UUID, status domain.OrderStatus) error) *MockOrderRepository_Updatestatus_Call {
+func (_c *MockOrderRepository_Updatestatus_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, status domain.OrderStatus) error) *MockOrderRepository_Updatestatus_Call {
 	_c.Call.Return(run)
 	return _c
 }
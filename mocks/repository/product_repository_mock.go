@@ -153,3 +153,62 @@ func (_c *MockProductRepository_RetrieveAll_Call) RunAndReturn(run func(ctx cont
 	_c.Call.Return(run)
 	return _c
 }
+
+// RetrieveByShop provides a mock function for the type MockProductRepository
+func (_mock *MockProductRepository) RetrieveByShop(ctx context.Context, shopID uuid.UUID, limit int, offset int) ([]domain.RetrieveProduct, error) {
+	ret := _mock.Called(ctx, shopID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveByShop")
+	}
+
+	var r0 []domain.RetrieveProduct
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, int, int) ([]domain.RetrieveProduct, error)); ok {
+		return returnFunc(ctx, shopID, limit, offset)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, int, int) []domain.RetrieveProduct); ok {
+		r0 = returnFunc(ctx, shopID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RetrieveProduct)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, int, int) error); ok {
+		r1 = returnFunc(ctx, shopID, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductRepository_RetrieveByShop_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetrieveByShop'
+type MockProductRepository_RetrieveByShop_Call struct {
+	*mock.Call
+}
+
+// RetrieveByShop is a helper method to define mock.On call
+//   - ctx
+//   - shopID
+//   - limit
+//   - offset
+func (_e *MockProductRepository_Expecter) RetrieveByShop(ctx interface{}, shopID interface{}, limit interface{}, offset interface{}) *MockProductRepository_RetrieveByShop_Call {
+	return &MockProductRepository_RetrieveByShop_Call{Call: _e.mock.On("RetrieveByShop", ctx, shopID, limit, offset)}
+}
+
+func (_c *MockProductRepository_RetrieveByShop_Call) Run(run func(ctx context.Context, shopID uuid.UUID, limit int, offset int)) *MockProductRepository_RetrieveByShop_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockProductRepository_RetrieveByShop_Call) Return(retrieveProducts []domain.RetrieveProduct, err error) *MockProductRepository_RetrieveByShop_Call {
+	_c.Call.Return(retrieveProducts, err)
+	return _c
+}
+
+func (_c *MockProductRepository_RetrieveByShop_Call) RunAndReturn(run func(ctx context.Context, shopID uuid.UUID, limit int, offset int) ([]domain.RetrieveProduct, error)) *MockProductRepository_RetrieveByShop_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -7,6 +7,7 @@ package domain
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/dyaksa/warehouse/domain"
 	"github.com/google/uuid"
@@ -87,6 +88,54 @@ func (_c *MockReservationRepository_CreateMany_Call) RunAndReturn(run func(ctx c
 	return _c
 }
 
+// ExtendPending provides a mock function for the type MockReservationRepository
+func (_mock *MockReservationRepository) ExtendPending(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, expiresAt time.Time) error {
+	ret := _mock.Called(ctx, tx, orderID, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExtendPending")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID, time.Time) error); ok {
+		r0 = returnFunc(ctx, tx, orderID, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockReservationRepository_ExtendPending_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExtendPending'
+type MockReservationRepository_ExtendPending_Call struct {
+	*mock.Call
+}
+
+// ExtendPending is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - orderID
+//   - expiresAt
+func (_e *MockReservationRepository_Expecter) ExtendPending(ctx interface{}, tx interface{}, orderID interface{}, expiresAt interface{}) *MockReservationRepository_ExtendPending_Call {
+	return &MockReservationRepository_ExtendPending_Call{Call: _e.mock.On("ExtendPending", ctx, tx, orderID, expiresAt)}
+}
+
+func (_c *MockReservationRepository_ExtendPending_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, expiresAt time.Time)) *MockReservationRepository_ExtendPending_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockReservationRepository_ExtendPending_Call) Return(err error) *MockReservationRepository_ExtendPending_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockReservationRepository_ExtendPending_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, expiresAt time.Time) error) *MockReservationRepository_ExtendPending_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByOrderID provides a mock function for the type MockReservationRepository
 func (_mock *MockReservationRepository) GetByOrderID(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]domain.Reservation, error) {
 	ret := _mock.Called(ctx, tx, orderID)
@@ -145,153 +194,357 @@ func (_c *MockReservationRepository_GetByOrderID_Call) RunAndReturn(run func(ctx
 	return _c
 }
 
-// MarkCommitted provides a mock function for the type MockReservationRepository
-func (_mock *MockReservationRepository) MarkCommitted(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error {
+// GetQuarantinedForUpdate provides a mock function for the type MockReservationRepository
+func (_mock *MockReservationRepository) GetQuarantinedForUpdate(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Reservation, error) {
+	ret := _mock.Called(ctx, tx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuarantinedForUpdate")
+	}
+
+	var r0 *domain.Reservation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) (*domain.Reservation, error)); ok {
+		return returnFunc(ctx, tx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) *domain.Reservation); ok {
+		r0 = returnFunc(ctx, tx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Reservation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, tx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockReservationRepository_GetQuarantinedForUpdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetQuarantinedForUpdate'
+type MockReservationRepository_GetQuarantinedForUpdate_Call struct {
+	*mock.Call
+}
+
+// GetQuarantinedForUpdate is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - id
+func (_e *MockReservationRepository_Expecter) GetQuarantinedForUpdate(ctx interface{}, tx interface{}, id interface{}) *MockReservationRepository_GetQuarantinedForUpdate_Call {
+	return &MockReservationRepository_GetQuarantinedForUpdate_Call{Call: _e.mock.On("GetQuarantinedForUpdate", ctx, tx, id)}
+}
+
+func (_c *MockReservationRepository_GetQuarantinedForUpdate_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID)) *MockReservationRepository_GetQuarantinedForUpdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockReservationRepository_GetQuarantinedForUpdate_Call) Return(reservation *domain.Reservation, err error) *MockReservationRepository_GetQuarantinedForUpdate_Call {
+	_c.Call.Return(reservation, err)
+	return _c
+}
+
+func (_c *MockReservationRepository_GetQuarantinedForUpdate_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Reservation, error)) *MockReservationRepository_GetQuarantinedForUpdate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HeldCountByOrder provides a mock function for the type MockReservationRepository
+func (_mock *MockReservationRepository) HeldCountByOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, int, error) {
 	ret := _mock.Called(ctx, tx, orderID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for MarkCommitted")
+		panic("no return value specified for HeldCountByOrder")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+	var r0 int
+	var r1 int
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) (int, int, error)); ok {
+		return returnFunc(ctx, tx, orderID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) int); ok {
 		r0 = returnFunc(ctx, tx, orderID)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(int)
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, uuid.UUID) int); ok {
+		r1 = returnFunc(ctx, tx, orderID)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+		r2 = returnFunc(ctx, tx, orderID)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
 }
 
-// MockReservationRepository_MarkCommitted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkCommitted'
-type MockReservationRepository_MarkCommitted_Call struct {
+// MockReservationRepository_HeldCountByOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HeldCountByOrder'
+type MockReservationRepository_HeldCountByOrder_Call struct {
 	*mock.Call
 }
 
-// MarkCommitted is a helper method to define mock.On call
+// HeldCountByOrder is a helper method to define mock.On call
 //   - ctx
 //   - tx
 //   - orderID
-func (_e *MockReservationRepository_Expecter) MarkCommitted(ctx interface{}, tx interface{}, orderID interface{}) *MockReservationRepository_MarkCommitted_Call {
-	return &MockReservationRepository_MarkCommitted_Call{Call: _e.mock.On("MarkCommitted", ctx, tx, orderID)}
+func (_e *MockReservationRepository_Expecter) HeldCountByOrder(ctx interface{}, tx interface{}, orderID interface{}) *MockReservationRepository_HeldCountByOrder_Call {
+	return &MockReservationRepository_HeldCountByOrder_Call{Call: _e.mock.On("HeldCountByOrder", ctx, tx, orderID)}
 }
 
-func (_c *MockReservationRepository_MarkCommitted_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID)) *MockReservationRepository_MarkCommitted_Call {
+func (_c *MockReservationRepository_HeldCountByOrder_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID)) *MockReservationRepository_HeldCountByOrder_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
 	})
 	return _c
 }
 
-func (_c *MockReservationRepository_MarkCommitted_Call) Return(err error) *MockReservationRepository_MarkCommitted_Call {
-	_c.Call.Return(err)
+func (_c *MockReservationRepository_HeldCountByOrder_Call) Return(pending int, quarantined int, err error) *MockReservationRepository_HeldCountByOrder_Call {
+	_c.Call.Return(pending, quarantined, err)
 	return _c
 }
 
-func (_c *MockReservationRepository_MarkCommitted_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error) *MockReservationRepository_MarkCommitted_Call {
+func (_c *MockReservationRepository_HeldCountByOrder_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, int, error)) *MockReservationRepository_HeldCountByOrder_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// MarkExpired provides a mock function for the type MockReservationRepository
-func (_mock *MockReservationRepository) MarkExpired(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
-	ret := _mock.Called(ctx, tx, id)
+// ListQuarantined provides a mock function for the type MockReservationRepository
+func (_mock *MockReservationRepository) ListQuarantined(ctx context.Context, limit int, offset int) ([]domain.QuarantinedReservation, int, error) {
+	ret := _mock.Called(ctx, limit, offset)
 
 	if len(ret) == 0 {
-		panic("no return value specified for MarkExpired")
+		panic("no return value specified for ListQuarantined")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
-		r0 = returnFunc(ctx, tx, id)
+	var r0 []domain.QuarantinedReservation
+	var r1 int
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) ([]domain.QuarantinedReservation, int, error)); ok {
+		return returnFunc(ctx, limit, offset)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) []domain.QuarantinedReservation); ok {
+		r0 = returnFunc(ctx, limit, offset)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.QuarantinedReservation)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int) int); ok {
+		r1 = returnFunc(ctx, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
+		r2 = returnFunc(ctx, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
 }
 
-// MockReservationRepository_MarkExpired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkExpired'
-type MockReservationRepository_MarkExpired_Call struct {
+// MockReservationRepository_ListQuarantined_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListQuarantined'
+type MockReservationRepository_ListQuarantined_Call struct {
 	*mock.Call
 }
 
-// MarkExpired is a helper method to define mock.On call
+// ListQuarantined is a helper method to define mock.On call
+//   - ctx
+//   - limit
+//   - offset
+func (_e *MockReservationRepository_Expecter) ListQuarantined(ctx interface{}, limit interface{}, offset interface{}) *MockReservationRepository_ListQuarantined_Call {
+	return &MockReservationRepository_ListQuarantined_Call{Call: _e.mock.On("ListQuarantined", ctx, limit, offset)}
+}
+
+func (_c *MockReservationRepository_ListQuarantined_Call) Run(run func(ctx context.Context, limit int, offset int)) *MockReservationRepository_ListQuarantined_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockReservationRepository_ListQuarantined_Call) Return(quarantinedReservations []domain.QuarantinedReservation, n int, err error) *MockReservationRepository_ListQuarantined_Call {
+	_c.Call.Return(quarantinedReservations, n, err)
+	return _c
+}
+
+func (_c *MockReservationRepository_ListQuarantined_Call) RunAndReturn(run func(ctx context.Context, limit int, offset int) ([]domain.QuarantinedReservation, int, error)) *MockReservationRepository_ListQuarantined_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LockPendingByOrder provides a mock function for the type MockReservationRepository
+func (_mock *MockReservationRepository) LockPendingByOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]domain.Reservation, error) {
+	ret := _mock.Called(ctx, tx, orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockPendingByOrder")
+	}
+
+	var r0 []domain.Reservation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) ([]domain.Reservation, error)); ok {
+		return returnFunc(ctx, tx, orderID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) []domain.Reservation); ok {
+		r0 = returnFunc(ctx, tx, orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Reservation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, tx, orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockReservationRepository_LockPendingByOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LockPendingByOrder'
+type MockReservationRepository_LockPendingByOrder_Call struct {
+	*mock.Call
+}
+
+// LockPendingByOrder is a helper method to define mock.On call
 //   - ctx
 //   - tx
-//   - id
-func (_e *MockReservationRepository_Expecter) MarkExpired(ctx interface{}, tx interface{}, id interface{}) *MockReservationRepository_MarkExpired_Call {
-	return &MockReservationRepository_MarkExpired_Call{Call: _e.mock.On("MarkExpired", ctx, tx, id)}
+//   - orderID
+func (_e *MockReservationRepository_Expecter) LockPendingByOrder(ctx interface{}, tx interface{}, orderID interface{}) *MockReservationRepository_LockPendingByOrder_Call {
+	return &MockReservationRepository_LockPendingByOrder_Call{Call: _e.mock.On("LockPendingByOrder", ctx, tx, orderID)}
 }
 
-func (_c *MockReservationRepository_MarkExpired_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID)) *MockReservationRepository_MarkExpired_Call {
+func (_c *MockReservationRepository_LockPendingByOrder_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID)) *MockReservationRepository_LockPendingByOrder_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
 	})
 	return _c
 }
 
-func (_c *MockReservationRepository_MarkExpired_Call) Return(err error) *MockReservationRepository_MarkExpired_Call {
-	_c.Call.Return(err)
+func (_c *MockReservationRepository_LockPendingByOrder_Call) Return(reservations []domain.Reservation, err error) *MockReservationRepository_LockPendingByOrder_Call {
+	_c.Call.Return(reservations, err)
 	return _c
 }
 
-func (_c *MockReservationRepository_MarkExpired_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID) error) *MockReservationRepository_MarkExpired_Call {
+func (_c *MockReservationRepository_LockPendingByOrder_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]domain.Reservation, error)) *MockReservationRepository_LockPendingByOrder_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// MarkReleased provides a mock function for the type MockReservationRepository
-func (_mock *MockReservationRepository) MarkReleased(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error {
+// MarkCommitted provides a mock function for the type MockReservationRepository
+func (_mock *MockReservationRepository) MarkCommitted(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error) {
 	ret := _mock.Called(ctx, tx, orderID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for MarkReleased")
+		panic("no return value specified for MarkCommitted")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) (int, error)); ok {
+		return returnFunc(ctx, tx, orderID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) int); ok {
+		r0 = returnFunc(ctx, tx, orderID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, tx, orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockReservationRepository_MarkCommitted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkCommitted'
+type MockReservationRepository_MarkCommitted_Call struct {
+	*mock.Call
+}
+
+// MarkCommitted is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - orderID
+func (_e *MockReservationRepository_Expecter) MarkCommitted(ctx interface{}, tx interface{}, orderID interface{}) *MockReservationRepository_MarkCommitted_Call {
+	return &MockReservationRepository_MarkCommitted_Call{Call: _e.mock.On("MarkCommitted", ctx, tx, orderID)}
+}
+
+func (_c *MockReservationRepository_MarkCommitted_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID)) *MockReservationRepository_MarkCommitted_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockReservationRepository_MarkCommitted_Call) Return(n int, err error) *MockReservationRepository_MarkCommitted_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockReservationRepository_MarkCommitted_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error)) *MockReservationRepository_MarkCommitted_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkExpired provides a mock function for the type MockReservationRepository
+func (_mock *MockReservationRepository) MarkExpired(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	ret := _mock.Called(ctx, tx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkExpired")
 	}
 
 	var r0 error
 	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
-		r0 = returnFunc(ctx, tx, orderID)
+		r0 = returnFunc(ctx, tx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockReservationRepository_MarkReleased_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkReleased'
-type MockReservationRepository_MarkReleased_Call struct {
+// MockReservationRepository_MarkExpired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkExpired'
+type MockReservationRepository_MarkExpired_Call struct {
 	*mock.Call
 }
 
-// MarkReleased is a helper method to define mock.On call
+// MarkExpired is a helper method to define mock.On call
 //   - ctx
 //   - tx
-//   - orderID
-func (_e *MockReservationRepository_Expecter) MarkReleased(ctx interface{}, tx interface{}, orderID interface{}) *MockReservationRepository_MarkReleased_Call {
-	return &MockReservationRepository_MarkReleased_Call{Call: _e.mock.On("MarkReleased", ctx, tx, orderID)}
+//   - id
+func (_e *MockReservationRepository_Expecter) MarkExpired(ctx interface{}, tx interface{}, id interface{}) *MockReservationRepository_MarkExpired_Call {
+	return &MockReservationRepository_MarkExpired_Call{Call: _e.mock.On("MarkExpired", ctx, tx, id)}
 }
 
-func (_c *MockReservationRepository_MarkReleased_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID)) *MockReservationRepository_MarkReleased_Call {
+func (_c *MockReservationRepository_MarkExpired_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID)) *MockReservationRepository_MarkExpired_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
 	})
 	return _c
 }
 
-func (_c *MockReservationRepository_MarkReleased_Call) Return(err error) *MockReservationRepository_MarkReleased_Call {
+func (_c *MockReservationRepository_MarkExpired_Call) Return(err error) *MockReservationRepository_MarkExpired_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockReservationRepository_MarkReleased_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error) *MockReservationRepository_MarkReleased_Call {
+func (_c *MockReservationRepository_MarkExpired_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID) error) *MockReservationRepository_MarkExpired_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// PendingCountByOrder provides a mock function for the type MockReservationRepository
-func (_mock *MockReservationRepository) PendingCountByOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error) {
+// MarkReleased provides a mock function for the type MockReservationRepository
+func (_mock *MockReservationRepository) MarkReleased(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error) {
 	ret := _mock.Called(ctx, tx, orderID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for PendingCountByOrder")
+		panic("no return value specified for MarkReleased")
 	}
 
 	var r0 int
@@ -312,32 +565,32 @@ func (_mock *MockReservationRepository) PendingCountByOrder(ctx context.Context,
 	return r0, r1
 }
 
-// MockReservationRepository_PendingCountByOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PendingCountByOrder'
-type MockReservationRepository_PendingCountByOrder_Call struct {
+// MockReservationRepository_MarkReleased_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkReleased'
+type MockReservationRepository_MarkReleased_Call struct {
 	*mock.Call
 }
 
-// PendingCountByOrder is a helper method to define mock.On call
+// MarkReleased is a helper method to define mock.On call
 //   - ctx
 //   - tx
 //   - orderID
-func (_e *MockReservationRepository_Expecter) PendingCountByOrder(ctx interface{}, tx interface{}, orderID interface{}) *MockReservationRepository_PendingCountByOrder_Call {
-	return &MockReservationRepository_PendingCountByOrder_Call{Call: _e.mock.On("PendingCountByOrder", ctx, tx, orderID)}
+func (_e *MockReservationRepository_Expecter) MarkReleased(ctx interface{}, tx interface{}, orderID interface{}) *MockReservationRepository_MarkReleased_Call {
+	return &MockReservationRepository_MarkReleased_Call{Call: _e.mock.On("MarkReleased", ctx, tx, orderID)}
 }
 
-func (_c *MockReservationRepository_PendingCountByOrder_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID)) *MockReservationRepository_PendingCountByOrder_Call {
+func (_c *MockReservationRepository_MarkReleased_Call) Run(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID)) *MockReservationRepository_MarkReleased_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
 	})
 	return _c
 }
 
-func (_c *MockReservationRepository_PendingCountByOrder_Call) Return(n int, err error) *MockReservationRepository_PendingCountByOrder_Call {
+func (_c *MockReservationRepository_MarkReleased_Call) Return(n int, err error) *MockReservationRepository_MarkReleased_Call {
 	_c.Call.Return(n, err)
 	return _c
 }
 
-func (_c *MockReservationRepository_PendingCountByOrder_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error)) *MockReservationRepository_PendingCountByOrder_Call {
+func (_c *MockReservationRepository_MarkReleased_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error)) *MockReservationRepository_MarkReleased_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -400,6 +653,113 @@ func (_c *MockReservationRepository_PickExpiredForUpdate_Call) RunAndReturn(run
 	return _c
 }
 
+// RecordReleaseFailure provides a mock function for the type MockReservationRepository
+func (_mock *MockReservationRepository) RecordReleaseFailure(ctx context.Context, tx *sql.Tx, id uuid.UUID, reason string, maxAttempts int) (*domain.Reservation, error) {
+	ret := _mock.Called(ctx, tx, id, reason, maxAttempts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordReleaseFailure")
+	}
+
+	var r0 *domain.Reservation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID, string, int) (*domain.Reservation, error)); ok {
+		return returnFunc(ctx, tx, id, reason, maxAttempts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID, string, int) *domain.Reservation); ok {
+		r0 = returnFunc(ctx, tx, id, reason, maxAttempts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Reservation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sql.Tx, uuid.UUID, string, int) error); ok {
+		r1 = returnFunc(ctx, tx, id, reason, maxAttempts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockReservationRepository_RecordReleaseFailure_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordReleaseFailure'
+type MockReservationRepository_RecordReleaseFailure_Call struct {
+	*mock.Call
+}
+
+// RecordReleaseFailure is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - id
+//   - reason
+//   - maxAttempts
+func (_e *MockReservationRepository_Expecter) RecordReleaseFailure(ctx interface{}, tx interface{}, id interface{}, reason interface{}, maxAttempts interface{}) *MockReservationRepository_RecordReleaseFailure_Call {
+	return &MockReservationRepository_RecordReleaseFailure_Call{Call: _e.mock.On("RecordReleaseFailure", ctx, tx, id, reason, maxAttempts)}
+}
+
+func (_c *MockReservationRepository_RecordReleaseFailure_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID, reason string, maxAttempts int)) *MockReservationRepository_RecordReleaseFailure_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID), args[3].(string), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *MockReservationRepository_RecordReleaseFailure_Call) Return(reservation *domain.Reservation, err error) *MockReservationRepository_RecordReleaseFailure_Call {
+	_c.Call.Return(reservation, err)
+	return _c
+}
+
+func (_c *MockReservationRepository_RecordReleaseFailure_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID, reason string, maxAttempts int) (*domain.Reservation, error)) *MockReservationRepository_RecordReleaseFailure_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequeueQuarantined provides a mock function for the type MockReservationRepository
+func (_mock *MockReservationRepository) RequeueQuarantined(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	ret := _mock.Called(ctx, tx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequeueQuarantined")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, tx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockReservationRepository_RequeueQuarantined_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequeueQuarantined'
+type MockReservationRepository_RequeueQuarantined_Call struct {
+	*mock.Call
+}
+
+// RequeueQuarantined is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - id
+func (_e *MockReservationRepository_Expecter) RequeueQuarantined(ctx interface{}, tx interface{}, id interface{}) *MockReservationRepository_RequeueQuarantined_Call {
+	return &MockReservationRepository_RequeueQuarantined_Call{Call: _e.mock.On("RequeueQuarantined", ctx, tx, id)}
+}
+
+func (_c *MockReservationRepository_RequeueQuarantined_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID)) *MockReservationRepository_RequeueQuarantined_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockReservationRepository_RequeueQuarantined_Call) Return(err error) *MockReservationRepository_RequeueQuarantined_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockReservationRepository_RequeueQuarantined_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID) error) *MockReservationRepository_RequeueQuarantined_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Retrieve provides a mock function for the type MockReservationRepository
 func (_mock *MockReservationRepository) Retrieve(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Reservation, error) {
 	ret := _mock.Called(ctx, tx, id)
@@ -6,8 +6,11 @@ package domain
 
 import (
 	"context"
+	"database/sql"
 
+	"github.com/dyaksa/encryption-pii/crypto/types"
 	"github.com/dyaksa/warehouse/domain"
+	"github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -84,8 +87,66 @@ func (_c *MockUserRepository_CreateUser_Call) RunAndReturn(run func(ctx context.
 	return _c
 }
 
+// GetByID provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID, fn func(data *domain.User) error) (*domain.User, error) {
+	ret := _mock.Called(ctx, id, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, func(data *domain.User) error) (*domain.User, error)); ok {
+		return returnFunc(ctx, id, fn)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, func(data *domain.User) error) *domain.User); ok {
+		r0 = returnFunc(ctx, id, fn)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, func(data *domain.User) error) error); ok {
+		r1 = returnFunc(ctx, id, fn)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockUserRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - fn
+func (_e *MockUserRepository_Expecter) GetByID(ctx interface{}, id interface{}, fn interface{}) *MockUserRepository_GetByID_Call {
+	return &MockUserRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id, fn)}
+}
+
+func (_c *MockUserRepository_GetByID_Call) Run(run func(ctx context.Context, id uuid.UUID, fn func(data *domain.User) error)) *MockUserRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(func(data *domain.User) error))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_GetByID_Call) Return(user *domain.User, err error) *MockUserRepository_GetByID_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockUserRepository_GetByID_Call) RunAndReturn(run func(ctx context.Context, id uuid.UUID, fn func(data *domain.User) error) (*domain.User, error)) *MockUserRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetMailOrPhone provides a mock function for the type MockUserRepository
-func (_mock *MockUserRepository) GetMailOrPhone(ctx context.Context, email_bidx string, phone_bidx string, fn func(data *domain.User)) (*domain.User, error) {
+func (_mock *MockUserRepository) GetMailOrPhone(ctx context.Context, email_bidx string, phone_bidx string, fn func(data *domain.User) error) (*domain.User, error) {
 	ret := _mock.Called(ctx, email_bidx, phone_bidx, fn)
 
 	if len(ret) == 0 {
@@ -94,17 +155,17 @@ func (_mock *MockUserRepository) GetMailOrPhone(ctx context.Context, email_bidx
 
 	var r0 *domain.User
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, func(data *domain.User)) (*domain.User, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, func(data *domain.User) error) (*domain.User, error)); ok {
 		return returnFunc(ctx, email_bidx, phone_bidx, fn)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, func(data *domain.User)) *domain.User); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, func(data *domain.User) error) *domain.User); ok {
 		r0 = returnFunc(ctx, email_bidx, phone_bidx, fn)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.User)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, func(data *domain.User)) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, func(data *domain.User) error) error); ok {
 		r1 = returnFunc(ctx, email_bidx, phone_bidx, fn)
 	} else {
 		r1 = ret.Error(1)
@@ -126,9 +187,9 @@ func (_e *MockUserRepository_Expecter) GetMailOrPhone(ctx interface{}, email_bid
 	return &MockUserRepository_GetMailOrPhone_Call{Call: _e.mock.On("GetMailOrPhone", ctx, email_bidx, phone_bidx, fn)}
 }
 
-func (_c *MockUserRepository_GetMailOrPhone_Call) Run(run func(ctx context.Context, email_bidx string, phone_bidx string, fn func(data *domain.User))) *MockUserRepository_GetMailOrPhone_Call {
+func (_c *MockUserRepository_GetMailOrPhone_Call) Run(run func(ctx context.Context, email_bidx string, phone_bidx string, fn func(data *domain.User) error)) *MockUserRepository_GetMailOrPhone_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(func(data *domain.User)))
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(func(data *domain.User) error))
 	})
 	return _c
 }
@@ -138,13 +199,13 @@ func (_c *MockUserRepository_GetMailOrPhone_Call) Return(user *domain.User, err
 	return _c
 }
 
-func (_c *MockUserRepository_GetMailOrPhone_Call) RunAndReturn(run func(ctx context.Context, email_bidx string, phone_bidx string, fn func(data *domain.User)) (*domain.User, error)) *MockUserRepository_GetMailOrPhone_Call {
+func (_c *MockUserRepository_GetMailOrPhone_Call) RunAndReturn(run func(ctx context.Context, email_bidx string, phone_bidx string, fn func(data *domain.User) error) (*domain.User, error)) *MockUserRepository_GetMailOrPhone_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // GetUserByEmail provides a mock function for the type MockUserRepository
-func (_mock *MockUserRepository) GetUserByEmail(ctx context.Context, email_bidx string, fn func(data *domain.User)) (*domain.User, error) {
+func (_mock *MockUserRepository) GetUserByEmail(ctx context.Context, email_bidx string, fn func(data *domain.User) error) (*domain.User, error) {
 	ret := _mock.Called(ctx, email_bidx, fn)
 
 	if len(ret) == 0 {
@@ -153,17 +214,17 @@ func (_mock *MockUserRepository) GetUserByEmail(ctx context.Context, email_bidx
 
 	var r0 *domain.User
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, func(data *domain.User)) (*domain.User, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, func(data *domain.User) error) (*domain.User, error)); ok {
 		return returnFunc(ctx, email_bidx, fn)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, func(data *domain.User)) *domain.User); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, func(data *domain.User) error) *domain.User); ok {
 		r0 = returnFunc(ctx, email_bidx, fn)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.User)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string, func(data *domain.User)) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, func(data *domain.User) error) error); ok {
 		r1 = returnFunc(ctx, email_bidx, fn)
 	} else {
 		r1 = ret.Error(1)
@@ -184,9 +245,9 @@ func (_e *MockUserRepository_Expecter) GetUserByEmail(ctx interface{}, email_bid
 	return &MockUserRepository_GetUserByEmail_Call{Call: _e.mock.On("GetUserByEmail", ctx, email_bidx, fn)}
 }
 
-func (_c *MockUserRepository_GetUserByEmail_Call) Run(run func(ctx context.Context, email_bidx string, fn func(data *domain.User))) *MockUserRepository_GetUserByEmail_Call {
+func (_c *MockUserRepository_GetUserByEmail_Call) Run(run func(ctx context.Context, email_bidx string, fn func(data *domain.User) error)) *MockUserRepository_GetUserByEmail_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(func(data *domain.User)))
+		run(args[0].(context.Context), args[1].(string), args[2].(func(data *domain.User) error))
 	})
 	return _c
 }
@@ -196,13 +257,13 @@ func (_c *MockUserRepository_GetUserByEmail_Call) Return(user *domain.User, err
 	return _c
 }
 
-func (_c *MockUserRepository_GetUserByEmail_Call) RunAndReturn(run func(ctx context.Context, email_bidx string, fn func(data *domain.User)) (*domain.User, error)) *MockUserRepository_GetUserByEmail_Call {
+func (_c *MockUserRepository_GetUserByEmail_Call) RunAndReturn(run func(ctx context.Context, email_bidx string, fn func(data *domain.User) error) (*domain.User, error)) *MockUserRepository_GetUserByEmail_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // GetUserByPhone provides a mock function for the type MockUserRepository
-func (_mock *MockUserRepository) GetUserByPhone(ctx context.Context, phone_bidx string, fn func(data *domain.User)) (*domain.User, error) {
+func (_mock *MockUserRepository) GetUserByPhone(ctx context.Context, phone_bidx string, fn func(data *domain.User) error) (*domain.User, error) {
 	ret := _mock.Called(ctx, phone_bidx, fn)
 
 	if len(ret) == 0 {
@@ -211,17 +272,17 @@ func (_mock *MockUserRepository) GetUserByPhone(ctx context.Context, phone_bidx
 
 	var r0 *domain.User
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, func(data *domain.User)) (*domain.User, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, func(data *domain.User) error) (*domain.User, error)); ok {
 		return returnFunc(ctx, phone_bidx, fn)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, func(data *domain.User)) *domain.User); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, func(data *domain.User) error) *domain.User); ok {
 		r0 = returnFunc(ctx, phone_bidx, fn)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.User)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string, func(data *domain.User)) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, func(data *domain.User) error) error); ok {
 		r1 = returnFunc(ctx, phone_bidx, fn)
 	} else {
 		r1 = ret.Error(1)
@@ -242,9 +303,9 @@ func (_e *MockUserRepository_Expecter) GetUserByPhone(ctx interface{}, phone_bid
 	return &MockUserRepository_GetUserByPhone_Call{Call: _e.mock.On("GetUserByPhone", ctx, phone_bidx, fn)}
 }
 
-func (_c *MockUserRepository_GetUserByPhone_Call) Run(run func(ctx context.Context, phone_bidx string, fn func(data *domain.User))) *MockUserRepository_GetUserByPhone_Call {
+func (_c *MockUserRepository_GetUserByPhone_Call) Run(run func(ctx context.Context, phone_bidx string, fn func(data *domain.User) error)) *MockUserRepository_GetUserByPhone_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(func(data *domain.User)))
+		run(args[0].(context.Context), args[1].(string), args[2].(func(data *domain.User) error))
 	})
 	return _c
 }
@@ -254,7 +315,351 @@ func (_c *MockUserRepository_GetUserByPhone_Call) Return(user *domain.User, err
 	return _c
 }
 
-func (_c *MockUserRepository_GetUserByPhone_Call) RunAndReturn(run func(ctx context.Context, phone_bidx string, fn func(data *domain.User)) (*domain.User, error)) *MockUserRepository_GetUserByPhone_Call {
+func (_c *MockUserRepository_GetUserByPhone_Call) RunAndReturn(run func(ctx context.Context, phone_bidx string, fn func(data *domain.User) error) (*domain.User, error)) *MockUserRepository_GetUserByPhone_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsActive provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) IsActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsActive")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) bool); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserRepository_IsActive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsActive'
+type MockUserRepository_IsActive_Call struct {
+	*mock.Call
+}
+
+// IsActive is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockUserRepository_Expecter) IsActive(ctx interface{}, id interface{}) *MockUserRepository_IsActive_Call {
+	return &MockUserRepository_IsActive_Call{Call: _e.mock.On("IsActive", ctx, id)}
+}
+
+func (_c *MockUserRepository_IsActive_Call) Run(run func(ctx context.Context, id uuid.UUID)) *MockUserRepository_IsActive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_IsActive_Call) Return(b bool, err error) *MockUserRepository_IsActive_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockUserRepository_IsActive_Call) RunAndReturn(run func(ctx context.Context, id uuid.UUID) (bool, error)) *MockUserRepository_IsActive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkEmailVerified provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) MarkEmailVerified(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	ret := _mock.Called(ctx, tx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkEmailVerified")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, tx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserRepository_MarkEmailVerified_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkEmailVerified'
+type MockUserRepository_MarkEmailVerified_Call struct {
+	*mock.Call
+}
+
+// MarkEmailVerified is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - id
+func (_e *MockUserRepository_Expecter) MarkEmailVerified(ctx interface{}, tx interface{}, id interface{}) *MockUserRepository_MarkEmailVerified_Call {
+	return &MockUserRepository_MarkEmailVerified_Call{Call: _e.mock.On("MarkEmailVerified", ctx, tx, id)}
+}
+
+func (_c *MockUserRepository_MarkEmailVerified_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID)) *MockUserRepository_MarkEmailVerified_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_MarkEmailVerified_Call) Return(err error) *MockUserRepository_MarkEmailVerified_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserRepository_MarkEmailVerified_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID) error) *MockUserRepository_MarkEmailVerified_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkPhoneVerified provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) MarkPhoneVerified(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	ret := _mock.Called(ctx, tx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkPhoneVerified")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, tx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserRepository_MarkPhoneVerified_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkPhoneVerified'
+type MockUserRepository_MarkPhoneVerified_Call struct {
+	*mock.Call
+}
+
+// MarkPhoneVerified is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - id
+func (_e *MockUserRepository_Expecter) MarkPhoneVerified(ctx interface{}, tx interface{}, id interface{}) *MockUserRepository_MarkPhoneVerified_Call {
+	return &MockUserRepository_MarkPhoneVerified_Call{Call: _e.mock.On("MarkPhoneVerified", ctx, tx, id)}
+}
+
+func (_c *MockUserRepository_MarkPhoneVerified_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID)) *MockUserRepository_MarkPhoneVerified_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_MarkPhoneVerified_Call) Return(err error) *MockUserRepository_MarkPhoneVerified_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserRepository_MarkPhoneVerified_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID) error) *MockUserRepository_MarkPhoneVerified_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Shred provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) Shred(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	ret := _mock.Called(ctx, tx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Shred")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, tx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserRepository_Shred_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Shred'
+type MockUserRepository_Shred_Call struct {
+	*mock.Call
+}
+
+// Shred is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - id
+func (_e *MockUserRepository_Expecter) Shred(ctx interface{}, tx interface{}, id interface{}) *MockUserRepository_Shred_Call {
+	return &MockUserRepository_Shred_Call{Call: _e.mock.On("Shred", ctx, tx, id)}
+}
+
+func (_c *MockUserRepository_Shred_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID)) *MockUserRepository_Shred_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_Shred_Call) Return(err error) *MockUserRepository_Shred_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserRepository_Shred_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID) error) *MockUserRepository_Shred_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateEmail provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) UpdateEmail(ctx context.Context, tx *sql.Tx, id uuid.UUID, email types.AESCipher, emailBidx string, keyVersion int) error {
+	ret := _mock.Called(ctx, tx, id, email, emailBidx, keyVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID, types.AESCipher, string, int) error); ok {
+		r0 = returnFunc(ctx, tx, id, email, emailBidx, keyVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserRepository_UpdateEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateEmail'
+type MockUserRepository_UpdateEmail_Call struct {
+	*mock.Call
+}
+
+// UpdateEmail is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - id
+//   - email
+//   - emailBidx
+//   - keyVersion
+func (_e *MockUserRepository_Expecter) UpdateEmail(ctx interface{}, tx interface{}, id interface{}, email interface{}, emailBidx interface{}, keyVersion interface{}) *MockUserRepository_UpdateEmail_Call {
+	return &MockUserRepository_UpdateEmail_Call{Call: _e.mock.On("UpdateEmail", ctx, tx, id, email, emailBidx, keyVersion)}
+}
+
+func (_c *MockUserRepository_UpdateEmail_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID, email types.AESCipher, emailBidx string, keyVersion int)) *MockUserRepository_UpdateEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID), args[3].(types.AESCipher), args[4].(string), args[5].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_UpdateEmail_Call) Return(err error) *MockUserRepository_UpdateEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserRepository_UpdateEmail_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID, email types.AESCipher, emailBidx string, keyVersion int) error) *MockUserRepository_UpdateEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePassword provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) UpdatePassword(ctx context.Context, tx *sql.Tx, id uuid.UUID, passwordHash string) error {
+	ret := _mock.Called(ctx, tx, id, passwordHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePassword")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID, string) error); ok {
+		r0 = returnFunc(ctx, tx, id, passwordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserRepository_UpdatePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePassword'
+type MockUserRepository_UpdatePassword_Call struct {
+	*mock.Call
+}
+
+// UpdatePassword is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - id
+//   - passwordHash
+func (_e *MockUserRepository_Expecter) UpdatePassword(ctx interface{}, tx interface{}, id interface{}, passwordHash interface{}) *MockUserRepository_UpdatePassword_Call {
+	return &MockUserRepository_UpdatePassword_Call{Call: _e.mock.On("UpdatePassword", ctx, tx, id, passwordHash)}
+}
+
+func (_c *MockUserRepository_UpdatePassword_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID, passwordHash string)) *MockUserRepository_UpdatePassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_UpdatePassword_Call) Return(err error) *MockUserRepository_UpdatePassword_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserRepository_UpdatePassword_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID, passwordHash string) error) *MockUserRepository_UpdatePassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePhone provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) UpdatePhone(ctx context.Context, tx *sql.Tx, id uuid.UUID, phone types.AESCipher, phoneBidx string, keyVersion int) error {
+	ret := _mock.Called(ctx, tx, id, phone, phoneBidx, keyVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePhone")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sql.Tx, uuid.UUID, types.AESCipher, string, int) error); ok {
+		r0 = returnFunc(ctx, tx, id, phone, phoneBidx, keyVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserRepository_UpdatePhone_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePhone'
+type MockUserRepository_UpdatePhone_Call struct {
+	*mock.Call
+}
+
+// UpdatePhone is a helper method to define mock.On call
+//   - ctx
+//   - tx
+//   - id
+//   - phone
+//   - phoneBidx
+//   - keyVersion
+func (_e *MockUserRepository_Expecter) UpdatePhone(ctx interface{}, tx interface{}, id interface{}, phone interface{}, phoneBidx interface{}, keyVersion interface{}) *MockUserRepository_UpdatePhone_Call {
+	return &MockUserRepository_UpdatePhone_Call{Call: _e.mock.On("UpdatePhone", ctx, tx, id, phone, phoneBidx, keyVersion)}
+}
+
+func (_c *MockUserRepository_UpdatePhone_Call) Run(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID, phone types.AESCipher, phoneBidx string, keyVersion int)) *MockUserRepository_UpdatePhone_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*sql.Tx), args[2].(uuid.UUID), args[3].(types.AESCipher), args[4].(string), args[5].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_UpdatePhone_Call) Return(err error) *MockUserRepository_UpdatePhone_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserRepository_UpdatePhone_Call) RunAndReturn(run func(ctx context.Context, tx *sql.Tx, id uuid.UUID, phone types.AESCipher, phoneBidx string, keyVersion int) error) *MockUserRepository_UpdatePhone_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,58 @@
+package log
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID stores the request or correlation ID in ctx.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID stored by WithRequestID, or an empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithContext returns a logger that adds the request ID and the trace ID carried by ctx to every entry.
+// When ctx carries a module, the logger follows that module's level.
+func WithContext(ctx context.Context, l Logger) Logger {
+	l = ForModule(l, Module(ctx))
+
+	var fields []LoggerContextFn
+
+	if id := RequestID(ctx); id != "" {
+		fields = append(fields, String("request_id", id))
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields, String("trace_id", sc.TraceID().String()), String("span_id", sc.SpanID().String()))
+	}
+
+	if len(fields) == 0 {
+		return l
+	}
+
+	return &contextLogger{logger: l, fields: fields}
+}
+
+type contextLogger struct {
+	logger Logger
+	fields []LoggerContextFn
+}
+
+func (c *contextLogger) with(fn []LoggerContextFn) []LoggerContextFn {
+	return append(append(make([]LoggerContextFn, 0, len(c.fields)+len(fn)), c.fields...), fn...)
+}
+
+func (c *contextLogger) Info(msg string, fn ...LoggerContextFn)  { c.logger.Info(msg, c.with(fn)...) }
+func (c *contextLogger) Error(msg string, fn ...LoggerContextFn) { c.logger.Error(msg, c.with(fn)...) }
+func (c *contextLogger) Warn(msg string, fn ...LoggerContextFn)  { c.logger.Warn(msg, c.with(fn)...) }
+func (c *contextLogger) Debug(msg string, fn ...LoggerContextFn) { c.logger.Debug(msg, c.with(fn)...) }
+func (c *contextLogger) Fatal(msg string, fn ...LoggerContextFn) { c.logger.Fatal(msg, c.with(fn)...) }
+func (c *contextLogger) Panic(msg string, fn ...LoggerContextFn) { c.logger.Panic(msg, c.with(fn)...) }
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type recordingContext map[string]any
+
+func (r recordingContext) Any(key string, value any)                { r[key] = value }
+func (r recordingContext) Bool(key string, value bool)              { r[key] = value }
+func (r recordingContext) Bytes(key string, value []byte)           { r[key] = value }
+func (r recordingContext) String(key string, value string)          { r[key] = value }
+func (r recordingContext) Float64(key string, value float64)        { r[key] = value }
+func (r recordingContext) Int64(key string, value int64)            { r[key] = value }
+func (r recordingContext) Uint64(key string, value uint64)          { r[key] = value }
+func (r recordingContext) Time(key string, value time.Time)         { r[key] = value }
+func (r recordingContext) Duration(key string, value time.Duration) { r[key] = value }
+func (r recordingContext) Error(key string, err error)              { r[key] = err }
+
+type recordingLogger struct {
+	nopLogger
+	fields recordingContext
+}
+
+func (r *recordingLogger) Info(_ string, fn ...LoggerContextFn) {
+	r.fields = recordingContext{}
+	for _, f := range fn {
+		f(r.fields)
+	}
+}
+
+func TestWithContext_AddsRequestID(t *testing.T) {
+	base := &recordingLogger{}
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	WithContext(ctx, base).Info("hello", String("k", "v"))
+
+	if base.fields["request_id"] != "req-1" {
+		t.Fatalf("request_id = %v, want req-1", base.fields["request_id"])
+	}
+	if base.fields["k"] != "v" {
+		t.Fatalf("k = %v, want v", base.fields["k"])
+	}
+}
+
+func TestWithContext_NoRequestID(t *testing.T) {
+	base := &recordingLogger{}
+
+	if got := WithContext(context.Background(), base); got != Logger(base) {
+		t.Fatalf("expected the base logger when ctx carries nothing")
+	}
+}
@@ -0,0 +1,232 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level orders log severities from most to least verbose.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+	LevelPanic
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	case LevelPanic:
+		return "panic"
+	}
+
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug", "trace":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	case "panic":
+		return LevelPanic, nil
+	}
+
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+// ModuleLogger is implemented by backends that can filter by module. Use ForModule rather than calling it directly.
+type ModuleLogger interface {
+	ForModule(module string) Logger
+}
+
+// ForModule returns a logger whose level follows the module's override, when the backend supports it.
+func ForModule(l Logger, module string) Logger {
+	if m, ok := l.(ModuleLogger); ok && module != "" {
+		return m.ForModule(module)
+	}
+
+	return l
+}
+
+type moduleKey struct{}
+
+// WithModule tags ctx with a module; WithContext then logs through that module's level.
+func WithModule(ctx context.Context, module string) context.Context {
+	return context.WithValue(ctx, moduleKey{}, module)
+}
+
+// Module returns the module stored by WithModule, or an empty string.
+func Module(ctx context.Context) string {
+	module, _ := ctx.Value(moduleKey{}).(string)
+	return module
+}
+
+// LevelOverride is a temporary level for the whole process (empty Module) or one module.
+type LevelOverride struct {
+	Module    string    `json:"module,omitempty"`
+	Level     string    `json:"level"`
+	RevertsAt time.Time `json:"reverts_at"`
+}
+
+// LevelState describes the effective levels.
+type LevelState struct {
+	Base      string          `json:"base"`
+	Modules   []string        `json:"modules"`
+	Overrides []LevelOverride `json:"overrides"`
+}
+
+type override struct {
+	level     Level
+	revertsAt time.Time
+	timer     *time.Timer
+}
+
+// Levels holds the configured base level and temporary overrides that revert on their own.
+// It is safe for concurrent use and shared by every logger built with it.
+type Levels struct {
+	mu        sync.RWMutex
+	base      Level
+	modules   map[string]bool
+	global    *override
+	overrides map[string]*override
+}
+
+// NewLevels returns levels starting at base. Only the listed modules may be overridden individually.
+func NewLevels(base Level, modules ...string) *Levels {
+	known := make(map[string]bool, len(modules))
+	for _, m := range modules {
+		known[m] = true
+	}
+
+	return &Levels{base: base, modules: known, overrides: map[string]*override{}}
+}
+
+// Enabled reports whether an entry at lvl from module should be written.
+func (l *Levels) Enabled(module string, lvl Level) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	threshold := l.base
+	if l.global != nil {
+		threshold = l.global.level
+	}
+	if o, ok := l.overrides[module]; ok && module != "" {
+		threshold = o.level
+	}
+
+	return lvl >= threshold
+}
+
+// Set overrides the level of module, or of the whole process when module is empty, until ttl elapses.
+func (l *Levels) Set(module string, lvl Level, ttl time.Duration) (LevelOverride, error) {
+	if module != "" && !l.modules[module] {
+		return LevelOverride{}, fmt.Errorf("unknown log module %q", module)
+	}
+	if ttl <= 0 {
+		return LevelOverride{}, fmt.Errorf("revert delay must be positive")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopLocked(module)
+
+	o := &override{level: lvl, revertsAt: time.Now().Add(ttl)}
+	o.timer = time.AfterFunc(ttl, func() { l.revert(module, o) })
+
+	if module == "" {
+		l.global = o
+	} else {
+		l.overrides[module] = o
+	}
+
+	return LevelOverride{Module: module, Level: lvl.String(), RevertsAt: o.revertsAt}, nil
+}
+
+// Reset drops the override of module, or the process-wide override when module is empty.
+func (l *Levels) Reset(module string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopLocked(module)
+}
+
+// State returns the base level and the active overrides.
+func (l *Levels) State() LevelState {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	state := LevelState{Base: l.base.String(), Modules: []string{}, Overrides: []LevelOverride{}}
+	for m := range l.modules {
+		state.Modules = append(state.Modules, m)
+	}
+	sort.Strings(state.Modules)
+
+	if l.global != nil {
+		state.Overrides = append(state.Overrides, LevelOverride{Level: l.global.level.String(), RevertsAt: l.global.revertsAt})
+	}
+	for _, m := range state.Modules {
+		if o, ok := l.overrides[m]; ok {
+			state.Overrides = append(state.Overrides, LevelOverride{Module: m, Level: o.level.String(), RevertsAt: o.revertsAt})
+		}
+	}
+
+	return state
+}
+
+// revert removes o if it is still the active override; a newer Set for the same module wins.
+func (l *Levels) revert(module string, o *override) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if module == "" {
+		if l.global == o {
+			l.global = nil
+		}
+		return
+	}
+
+	if l.overrides[module] == o {
+		delete(l.overrides, module)
+	}
+}
+
+func (l *Levels) stopLocked(module string) {
+	if module == "" {
+		if l.global != nil {
+			l.global.timer.Stop()
+			l.global = nil
+		}
+		return
+	}
+
+	if o, ok := l.overrides[module]; ok {
+		o.timer.Stop()
+		delete(l.overrides, module)
+	}
+}
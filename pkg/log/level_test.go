@@ -0,0 +1,86 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLevels_ModuleOverride(t *testing.T) {
+	levels := NewLevels(LevelInfo, "order", "worker")
+
+	if levels.Enabled("order", LevelDebug) {
+		t.Fatal("debug enabled before override")
+	}
+
+	if _, err := levels.Set("order", LevelDebug, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if !levels.Enabled("order", LevelDebug) {
+		t.Error("debug disabled for overridden module")
+	}
+	if levels.Enabled("worker", LevelDebug) || levels.Enabled("", LevelDebug) {
+		t.Error("override leaked to other modules")
+	}
+
+	levels.Reset("order")
+	if levels.Enabled("order", LevelDebug) {
+		t.Error("override kept after reset")
+	}
+}
+
+func TestLevels_GlobalOverride(t *testing.T) {
+	levels := NewLevels(LevelInfo, "order")
+
+	if _, err := levels.Set("", LevelError, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if levels.Enabled("", LevelWarn) || levels.Enabled("order", LevelWarn) {
+		t.Error("warn enabled under error override")
+	}
+}
+
+func TestLevels_Reverts(t *testing.T) {
+	levels := NewLevels(LevelInfo, "order")
+
+	if _, err := levels.Set("order", LevelDebug, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for levels.Enabled("order", LevelDebug) {
+		if time.Now().After(deadline) {
+			t.Fatal("override did not revert")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if len(levels.State().Overrides) != 0 {
+		t.Error("reverted override still listed")
+	}
+}
+
+func TestLevels_NewerOverrideSurvivesOldTimer(t *testing.T) {
+	levels := NewLevels(LevelInfo, "order")
+
+	if _, err := levels.Set("order", LevelWarn, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := levels.Set("order", LevelDebug, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !levels.Enabled("order", LevelDebug) {
+		t.Error("newer override reverted by the previous timer")
+	}
+}
+
+func TestLevels_UnknownModule(t *testing.T) {
+	levels := NewLevels(LevelInfo, "order")
+
+	if _, err := levels.Set("billing", LevelDebug, time.Minute); err == nil {
+		t.Error("expected error for unknown module")
+	}
+}
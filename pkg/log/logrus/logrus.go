@@ -61,6 +61,23 @@ func WithJSONFormatter() Opts {
 	}
 }
 
+// WithRedactor scrubs messages and fields with r before they are written.
+func WithRedactor(r *log.Redactor) Opts {
+	return func(ll *logrusLogger) error {
+		ll.redactor = r
+		return nil
+	}
+}
+
+// WithLevels filters entries through levels, so the level can change at runtime and per module.
+// It takes precedence over WithLevel.
+func WithLevels(levels *log.Levels) Opts {
+	return func(ll *logrusLogger) error {
+		ll.levels = levels
+		return nil
+	}
+}
+
 func WithCaller(status bool) Opts {
 	return func(ll *logrusLogger) error {
 		ll.caller = status
@@ -69,9 +86,12 @@ func WithCaller(status bool) Opts {
 }
 
 type logrusLogger struct {
-	logrus *logrus.Logger
-	caller bool
-	level  Level
+	logrus   *logrus.Logger
+	caller   bool
+	level    Level
+	redactor *log.Redactor
+	levels   *log.Levels
+	module   string
 }
 
 type loggerContext struct {
@@ -89,58 +109,115 @@ func New(opts ...Opts) (*logrusLogger, error) {
 		}
 	}
 
-	log.logrus.SetLevel(logrus.Level(log.level))
+	if log.levels != nil {
+		// Filtering happens in enabled; logrus itself must let every level through.
+		log.logrus.SetLevel(logrus.TraceLevel)
+	} else {
+		log.logrus.SetLevel(logrus.Level(log.level))
+	}
 	log.logrus.SetReportCaller(log.caller)
 
 	return log, nil
 }
 
 func (l *logrusLogger) Info(msg string, fn ...log.LoggerContextFn) {
-	if l.level > InfoLevel {
+	if !l.enabled(InfoLevel) {
 		return
 	}
 
-	l.logrus.WithFields(newLoggerContext(fn...).fields).Info(msg)
+	msg, fields := l.prepare(msg, fn)
+	l.logrus.WithFields(fields).Info(msg)
 }
 
 func (l *logrusLogger) Error(msg string, fn ...log.LoggerContextFn) {
-	if l.level > ErrorLevel {
+	if !l.enabled(ErrorLevel) {
 		return
 	}
 
-	l.logrus.WithFields(newLoggerContext(fn...).fields).Error(msg)
+	msg, fields := l.prepare(msg, fn)
+	l.logrus.WithFields(fields).Error(msg)
 }
 
 func (l *logrusLogger) Warn(msg string, fn ...log.LoggerContextFn) {
-	if l.level > WarnLevel {
+	if !l.enabled(WarnLevel) {
 		return
 	}
 
-	l.logrus.WithFields(newLoggerContext(fn...).fields).Warn(msg)
+	msg, fields := l.prepare(msg, fn)
+	l.logrus.WithFields(fields).Warn(msg)
 }
 
 func (l *logrusLogger) Debug(msg string, fn ...log.LoggerContextFn) {
-	if l.level > DebugLevel {
+	if !l.enabled(DebugLevel) {
 		return
 	}
 
-	l.logrus.WithFields(newLoggerContext(fn...).fields).Debug(msg)
+	msg, fields := l.prepare(msg, fn)
+	l.logrus.WithFields(fields).Debug(msg)
 }
 
 func (l *logrusLogger) Fatal(msg string, fn ...log.LoggerContextFn) {
-	if l.level > FatalLevel {
+	if !l.enabled(FatalLevel) {
 		return
 	}
 
-	l.logrus.WithFields(newLoggerContext(fn...).fields).Fatal(msg)
+	msg, fields := l.prepare(msg, fn)
+	l.logrus.WithFields(fields).Fatal(msg)
 }
 
 func (l *logrusLogger) Panic(msg string, fn ...log.LoggerContextFn) {
-	if l.level > PanicLevel {
+	if !l.enabled(PanicLevel) {
 		return
 	}
 
-	l.logrus.WithFields(newLoggerContext(fn...).fields).Panic(msg)
+	msg, fields := l.prepare(msg, fn)
+	l.logrus.WithFields(fields).Panic(msg)
+}
+
+// ForModule implements log.ModuleLogger.
+func (l *logrusLogger) ForModule(module string) log.Logger {
+	clone := *l
+	clone.module = module
+	return &clone
+}
+
+func (l *logrusLogger) enabled(lvl Level) bool {
+	if l.levels != nil {
+		return l.levels.Enabled(l.module, toLogLevel(lvl))
+	}
+
+	return lvl <= l.level
+}
+
+func toLogLevel(lvl Level) log.Level {
+	switch lvl {
+	case PanicLevel:
+		return log.LevelPanic
+	case FatalLevel:
+		return log.LevelFatal
+	case ErrorLevel:
+		return log.LevelError
+	case WarnLevel:
+		return log.LevelWarn
+	case InfoLevel:
+		return log.LevelInfo
+	}
+
+	return log.LevelDebug
+}
+
+func (l *logrusLogger) prepare(msg string, fn []log.LoggerContextFn) (string, logrus.Fields) {
+	if l.redactor != nil {
+		msg = l.redactor.String(msg)
+		fn = l.redactor.Fields(fn)
+	}
+
+	fields := newLoggerContext(fn...).fields
+	if l.module != "" {
+		fields["module"] = l.module
+	}
+
+	return msg, fields
 }
 
 func newLoggerContext(fn ...log.LoggerContextFn) *loggerContext {
@@ -0,0 +1,15 @@
+package log
+
+// Nop returns a logger that discards every entry.
+func Nop() Logger {
+	return nopLogger{}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...LoggerContextFn)  {}
+func (nopLogger) Error(string, ...LoggerContextFn) {}
+func (nopLogger) Warn(string, ...LoggerContextFn)  {}
+func (nopLogger) Debug(string, ...LoggerContextFn) {}
+func (nopLogger) Fatal(string, ...LoggerContextFn) {}
+func (nopLogger) Panic(string, ...LoggerContextFn) {}
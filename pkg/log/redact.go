@@ -0,0 +1,265 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// RedactPattern replaces every match of Regexp in logged strings with Replacement.
+type RedactPattern struct {
+	Name        string
+	Regexp      *regexp.Regexp
+	Replacement string
+}
+
+// Built-in patterns. Bearer runs before JWT so the whole header value is replaced.
+var (
+	RedactBearer = RedactPattern{
+		Name:        "bearer",
+		Regexp:      regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+		Replacement: "Bearer " + redacted,
+	}
+	RedactJWT = RedactPattern{
+		Name:        "jwt",
+		Regexp:      regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		Replacement: "[REDACTED:jwt]",
+	}
+	RedactEmail = RedactPattern{
+		Name:        "email",
+		Regexp:      regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		Replacement: "[REDACTED:email]",
+	}
+	RedactPhone = RedactPattern{
+		Name:        "phone",
+		Regexp:      regexp.MustCompile(`\+[1-9][0-9]{7,14}\b`),
+		Replacement: "[REDACTED:phone]",
+	}
+)
+
+// DefaultRedactPatterns is used by NewRedactor when no patterns are given.
+var DefaultRedactPatterns = []RedactPattern{RedactBearer, RedactJWT, RedactEmail, RedactPhone}
+
+// RedactPatternsByName returns the built-in patterns with the given names, in their default order.
+func RedactPatternsByName(names ...string) ([]RedactPattern, error) {
+	want := make(map[string]bool, len(names))
+	for _, name := range names {
+		want[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	var patterns []RedactPattern
+	for _, p := range DefaultRedactPatterns {
+		if want[p.Name] {
+			patterns = append(patterns, p)
+			delete(want, p.Name)
+		}
+	}
+
+	for name := range want {
+		if name != "" {
+			return nil, fmt.Errorf("unknown redact pattern %q", name)
+		}
+	}
+
+	return patterns, nil
+}
+
+// sensitiveKeys are field names whose values are dropped entirely, whatever they contain.
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "cookie", "api_key", "otp"}
+
+// Redactor scrubs PII and credentials from log messages and fields.
+type Redactor struct {
+	patterns []RedactPattern
+}
+
+func NewRedactor(patterns ...RedactPattern) *Redactor {
+	if len(patterns) == 0 {
+		patterns = DefaultRedactPatterns
+	}
+
+	return &Redactor{patterns: patterns}
+}
+
+// String applies every pattern to s.
+func (r *Redactor) String(s string) string {
+	for _, p := range r.patterns {
+		s = p.Regexp.ReplaceAllString(s, p.Replacement)
+	}
+
+	return s
+}
+
+// Fields wraps field functions so each value is scrubbed before it reaches the logger implementation.
+func (r *Redactor) Fields(fn []LoggerContextFn) []LoggerContextFn {
+	wrapped := make([]LoggerContextFn, len(fn))
+	for i, f := range fn {
+		f := f
+		wrapped[i] = func(lc LoggerContext) {
+			f(&redactingContext{next: lc, r: r})
+		}
+	}
+
+	return wrapped
+}
+
+// Value scrubs an arbitrary field value. Loggable values are scrubbed by their AsLog form,
+// and composite values are walked through their JSON representation.
+func (r *Redactor) Value(v any) any {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case Loggable:
+		return r.Value(t.AsLog())
+	case string:
+		return r.String(t)
+	case error:
+		return r.String(t.Error())
+	case time.Time, time.Duration, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case fmt.Stringer:
+		return r.String(t.String())
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			if isSensitiveKey(k) {
+				out[k] = redacted
+				continue
+			}
+			out[k] = r.Value(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = r.Value(val)
+		}
+		return out
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return v
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return redacted
+		}
+
+		var generic any
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return redacted
+		}
+
+		return r.Value(generic)
+	}
+
+	return r.String(fmt.Sprint(v))
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type redactingContext struct {
+	next LoggerContext
+	r    *Redactor
+}
+
+func (c *redactingContext) Any(key string, value any) {
+	if isSensitiveKey(key) {
+		c.next.String(key, redacted)
+		return
+	}
+
+	c.next.Any(key, c.r.Value(value))
+}
+
+func (c *redactingContext) Bool(key string, value bool) { c.next.Bool(key, value) }
+
+func (c *redactingContext) Bytes(key string, value []byte) {
+	if isSensitiveKey(key) {
+		c.next.String(key, redacted)
+		return
+	}
+
+	c.next.Bytes(key, []byte(c.r.String(string(value))))
+}
+
+func (c *redactingContext) String(key string, value string) {
+	if isSensitiveKey(key) {
+		value = redacted
+	}
+
+	c.next.String(key, c.r.String(value))
+}
+
+func (c *redactingContext) Float64(key string, value float64)        { c.next.Float64(key, value) }
+func (c *redactingContext) Int64(key string, value int64)            { c.next.Int64(key, value) }
+func (c *redactingContext) Uint64(key string, value uint64)          { c.next.Uint64(key, value) }
+func (c *redactingContext) Time(key string, value time.Time)         { c.next.Time(key, value) }
+func (c *redactingContext) Duration(key string, value time.Duration) { c.next.Duration(key, value) }
+
+func (c *redactingContext) Error(key string, err error) {
+	if err == nil {
+		c.next.Error(key, nil)
+		return
+	}
+
+	c.next.Error(key, redactedError(c.r.String(err.Error())))
+}
+
+type redactedError string
+
+func (e redactedError) Error() string { return string(e) }
+
+// Redact wraps any Logger so messages and fields are scrubbed by r before they are written.
+func Redact(l Logger, r *Redactor) Logger {
+	return &redactingLogger{logger: l, r: r}
+}
+
+type redactingLogger struct {
+	logger Logger
+	r      *Redactor
+}
+
+// ForModule implements ModuleLogger.
+func (l *redactingLogger) ForModule(module string) Logger {
+	return &redactingLogger{logger: ForModule(l.logger, module), r: l.r}
+}
+
+func (l *redactingLogger) Info(msg string, fn ...LoggerContextFn) {
+	l.logger.Info(l.r.String(msg), l.r.Fields(fn)...)
+}
+
+func (l *redactingLogger) Error(msg string, fn ...LoggerContextFn) {
+	l.logger.Error(l.r.String(msg), l.r.Fields(fn)...)
+}
+
+func (l *redactingLogger) Warn(msg string, fn ...LoggerContextFn) {
+	l.logger.Warn(l.r.String(msg), l.r.Fields(fn)...)
+}
+
+func (l *redactingLogger) Debug(msg string, fn ...LoggerContextFn) {
+	l.logger.Debug(l.r.String(msg), l.r.Fields(fn)...)
+}
+
+func (l *redactingLogger) Fatal(msg string, fn ...LoggerContextFn) {
+	l.logger.Fatal(l.r.String(msg), l.r.Fields(fn)...)
+}
+
+func (l *redactingLogger) Panic(msg string, fn ...LoggerContextFn) {
+	l.logger.Panic(l.r.String(msg), l.r.Fields(fn)...)
+}
@@ -0,0 +1,237 @@
+package slog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+// slog has no fatal or panic levels; they are logged above error and renamed by replaceLevel.
+const (
+	levelFatal = slog.LevelError + 4
+	levelPanic = slog.LevelError + 8
+)
+
+type Opts func(*slogLogger) error
+
+func WithLevel(lvl string) Opts {
+	return func(sl *slogLogger) error {
+		level, err := log.ParseLevel(lvl)
+		if err != nil {
+			return err
+		}
+
+		sl.level = level
+		return nil
+	}
+}
+
+func WithJSONFormatter() Opts {
+	return func(sl *slogLogger) error {
+		sl.json = true
+		return nil
+	}
+}
+
+func WithWriter(w io.Writer) Opts {
+	return func(sl *slogLogger) error {
+		sl.writer = w
+		return nil
+	}
+}
+
+func WithCaller(status bool) Opts {
+	return func(sl *slogLogger) error {
+		sl.caller = status
+		return nil
+	}
+}
+
+// WithRedactor scrubs messages and fields with r before they are written.
+func WithRedactor(r *log.Redactor) Opts {
+	return func(sl *slogLogger) error {
+		sl.redactor = r
+		return nil
+	}
+}
+
+// WithLevels filters entries through levels, so the level can change at runtime and per module.
+// It takes precedence over WithLevel.
+func WithLevels(levels *log.Levels) Opts {
+	return func(sl *slogLogger) error {
+		sl.levels = levels
+		return nil
+	}
+}
+
+type slogLogger struct {
+	logger   *slog.Logger
+	writer   io.Writer
+	json     bool
+	caller   bool
+	level    log.Level
+	redactor *log.Redactor
+	levels   *log.Levels
+	module   string
+}
+
+type loggerContext struct {
+	attrs []slog.Attr
+}
+
+func New(opts ...Opts) (*slogLogger, error) {
+	sl := &slogLogger{
+		writer: os.Stderr,
+		level:  log.LevelInfo,
+	}
+
+	for _, opt := range opts {
+		if err := opt(sl); err != nil {
+			return nil, err
+		}
+	}
+
+	// Filtering happens in enabled; the handler itself must let every level through.
+	handlerOpts := &slog.HandlerOptions{
+		AddSource:   sl.caller,
+		Level:       slog.LevelDebug,
+		ReplaceAttr: replaceLevel,
+	}
+
+	var handler slog.Handler = slog.NewTextHandler(sl.writer, handlerOpts)
+	if sl.json {
+		handler = slog.NewJSONHandler(sl.writer, handlerOpts)
+	}
+
+	sl.logger = slog.New(handler)
+
+	return sl, nil
+}
+
+func (l *slogLogger) Info(msg string, fn ...log.LoggerContextFn) {
+	l.log(log.LevelInfo, slog.LevelInfo, msg, fn)
+}
+
+func (l *slogLogger) Error(msg string, fn ...log.LoggerContextFn) {
+	l.log(log.LevelError, slog.LevelError, msg, fn)
+}
+
+func (l *slogLogger) Warn(msg string, fn ...log.LoggerContextFn) {
+	l.log(log.LevelWarn, slog.LevelWarn, msg, fn)
+}
+
+func (l *slogLogger) Debug(msg string, fn ...log.LoggerContextFn) {
+	l.log(log.LevelDebug, slog.LevelDebug, msg, fn)
+}
+
+// Fatal logs and exits, matching the logrus adapter.
+func (l *slogLogger) Fatal(msg string, fn ...log.LoggerContextFn) {
+	l.log(log.LevelFatal, levelFatal, msg, fn)
+	os.Exit(1)
+}
+
+// Panic logs and panics with msg, matching the logrus adapter.
+func (l *slogLogger) Panic(msg string, fn ...log.LoggerContextFn) {
+	l.log(log.LevelPanic, levelPanic, msg, fn)
+	panic(msg)
+}
+
+// ForModule implements log.ModuleLogger.
+func (l *slogLogger) ForModule(module string) log.Logger {
+	clone := *l
+	clone.module = module
+	return &clone
+}
+
+func (l *slogLogger) enabled(lvl log.Level) bool {
+	if l.levels != nil {
+		return l.levels.Enabled(l.module, lvl)
+	}
+
+	return lvl >= l.level
+}
+
+func (l *slogLogger) log(lvl log.Level, slogLevel slog.Level, msg string, fn []log.LoggerContextFn) {
+	if !l.enabled(lvl) {
+		return
+	}
+
+	if l.redactor != nil {
+		msg = l.redactor.String(msg)
+		fn = l.redactor.Fields(fn)
+	}
+
+	lc := &loggerContext{}
+	if l.module != "" {
+		lc.attrs = append(lc.attrs, slog.String("module", l.module))
+	}
+	for _, f := range fn {
+		f(lc)
+	}
+
+	l.logger.LogAttrs(context.Background(), slogLevel, msg, lc.attrs...)
+}
+
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if a.Key != slog.LevelKey || len(groups) > 0 {
+		return a
+	}
+
+	switch a.Value.Any().(slog.Level) {
+	case levelFatal:
+		a.Value = slog.StringValue("FATAL")
+	case levelPanic:
+		a.Value = slog.StringValue("PANIC")
+	}
+
+	return a
+}
+
+func (lc *loggerContext) Any(key string, value any) {
+	lc.attrs = append(lc.attrs, slog.Any(key, value))
+}
+
+func (lc *loggerContext) Bool(key string, value bool) {
+	lc.attrs = append(lc.attrs, slog.Bool(key, value))
+}
+
+func (lc *loggerContext) Bytes(key string, value []byte) {
+	lc.attrs = append(lc.attrs, slog.Any(key, value))
+}
+
+func (lc *loggerContext) String(key string, value string) {
+	lc.attrs = append(lc.attrs, slog.String(key, value))
+}
+
+func (lc *loggerContext) Float64(key string, value float64) {
+	lc.attrs = append(lc.attrs, slog.Float64(key, value))
+}
+
+func (lc *loggerContext) Int64(key string, value int64) {
+	lc.attrs = append(lc.attrs, slog.Int64(key, value))
+}
+
+func (lc *loggerContext) Uint64(key string, value uint64) {
+	lc.attrs = append(lc.attrs, slog.Uint64(key, value))
+}
+
+func (lc *loggerContext) Time(key string, value time.Time) {
+	lc.attrs = append(lc.attrs, slog.Time(key, value))
+}
+
+func (lc *loggerContext) Duration(key string, value time.Duration) {
+	lc.attrs = append(lc.attrs, slog.Duration(key, value))
+}
+
+func (lc *loggerContext) Error(key string, err error) {
+	if err == nil {
+		lc.attrs = append(lc.attrs, slog.Any(key, nil))
+		return
+	}
+
+	lc.attrs = append(lc.attrs, slog.String(key, err.Error()))
+}
@@ -0,0 +1,51 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+func TestSlogLogger_LevelsAndModule(t *testing.T) {
+	var buf bytes.Buffer
+	levels := log.NewLevels(log.LevelInfo, "order")
+
+	l, err := New(WithWriter(&buf), WithJSONFormatter(), WithLevels(levels), WithRedactor(log.NewRedactor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := log.ForModule(l, "order")
+	order.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("debug written at info level: %s", buf.String())
+	}
+
+	if _, err := levels.Set("order", log.LevelDebug, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	order.Debug("checkout", log.String("email", "a@b.co"), log.Int64("items", 2))
+	l.Debug("still hidden")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one entry, got %d: %s", len(lines), buf.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry["msg"] != "checkout" || entry["module"] != "order" || entry["level"] != "DEBUG" {
+		t.Errorf("entry = %v", entry)
+	}
+	if entry["email"] != "[REDACTED:email]" || entry["items"] != float64(2) {
+		t.Errorf("fields = %v", entry)
+	}
+}
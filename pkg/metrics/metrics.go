@@ -0,0 +1,182 @@
+// Package metrics defines the Prometheus collectors exported on /metrics.
+// Collectors are registered on the default registry, which also carries the Go runtime and process collectors.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "warehouse"
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBTransactionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "transaction_duration_seconds",
+		Help:      "Duration of database transactions by outcome (commit, rollback, begin_error, commit_error).",
+		Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"outcome"})
+
+	Checkouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkouts_total",
+		Help:      "Checkout attempts by result (success, out_of_stock, rejected, error).",
+	}, []string{"result"})
+
+	OutOfStockRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "out_of_stock_rejections_total",
+		Help:      "Checkouts rejected because a product had insufficient stock.",
+	})
+
+	Reservations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reservations_total",
+		Help:      "Stock reservation lifecycle events (created, committed, released, expired, quarantined, extended).",
+	}, []string{"event"})
+
+	Transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Warehouse transfers entering each status.",
+	}, []string{"status"})
+
+	StockReleaseBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "stock_release",
+		Name:      "batch_size",
+		Help:      "Expired reservations processed per stock release batch.",
+		Buckets:   []float64{0, 1, 5, 10, 25, 50, 100, 250, 500},
+	})
+
+	StockReleaseBatchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "stock_release",
+		Name:      "batch_duration_seconds",
+		Help:      "Duration of stock release batches by result (success, error).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	OutboxDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "deliveries_total",
+		Help:      "Outbox event delivery attempts by event type and result (published, failed).",
+	}, []string{"event_type", "result"})
+
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "deliveries_total",
+		Help:      "Webhook delivery attempts by result (succeeded, retry, dead).",
+	}, []string{"result"})
+
+	IdempotentRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "idempotency",
+		Name:      "requests_total",
+		Help:      "Requests carrying an Idempotency-Key by result (processed, replayed, in_flight, conflict).",
+	}, []string{"result"})
+
+	IdempotencyKeysPurged = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "idempotency",
+		Name:      "keys_purged_total",
+		Help:      "Expired idempotency keys deleted by the purge worker.",
+	})
+
+	Jobs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "jobs",
+		Name:      "runs_total",
+		Help:      "Background job runs by type and result (succeeded, retry, dead).",
+	}, []string{"type", "result"})
+
+	JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "jobs",
+		Name:      "run_duration_seconds",
+		Help:      "Duration of background job runs by type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type"})
+
+	Leader = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "worker",
+		Name:      "leader",
+		Help:      "1 while this instance leads the election, 0 otherwise.",
+	}, []string{"election"})
+
+	PaymentEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "payment",
+		Name:      "events_total",
+		Help:      "Payment provider webhook events by type and result (applied, duplicate, ignored, rejected, refund_required, manual_review, error).",
+	}, []string{"type", "result"})
+)
+
+// Reservation lifecycle events.
+const (
+	ReservationCreated   = "created"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+	// ReservationQuarantined counts expired reservations that failed to release too many times; alert on any increase.
+	ReservationQuarantined = "quarantined"
+	ReservationExtended    = "extended"
+)
+
+// DBStatter is implemented by *sql.DB and pqsql.Client.
+type DBStatter interface {
+	Stats() sql.DBStats
+}
+
+// RegisterDBStats exports connection pool statistics of db, read at scrape time.
+func RegisterDBStats(db DBStatter) {
+	gauge := func(name, help string, fn func(sql.DBStats) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "db_pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return fn(db.Stats()) })
+	}
+
+	counter := func(name, help string, fn func(sql.DBStats) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db_pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return fn(db.Stats()) })
+	}
+
+	prometheus.MustRegister(
+		gauge("max_open_connections", "Maximum number of open connections.", func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }),
+		gauge("open_connections", "Established connections, in use and idle.", func(s sql.DBStats) float64 { return float64(s.OpenConnections) }),
+		gauge("in_use_connections", "Connections currently in use.", func(s sql.DBStats) float64 { return float64(s.InUse) }),
+		gauge("idle_connections", "Idle connections.", func(s sql.DBStats) float64 { return float64(s.Idle) }),
+		counter("wait_count_total", "Connections waited for.", func(s sql.DBStats) float64 { return float64(s.WaitCount) }),
+		counter("wait_duration_seconds_total", "Time blocked waiting for a connection.", func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }),
+		counter("max_idle_closed_total", "Connections closed due to SetMaxIdleConns.", func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }),
+		counter("max_lifetime_closed_total", "Connections closed due to SetConnMaxLifetime.", func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }),
+	)
+}
+
+// Handler serves the default registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import "time"
+
+// Both stores use the generic cell rate algorithm, a token bucket kept as a single timestamp: the
+// theoretical arrival time (TAT) at which the bucket would be full again. Each request moves it
+// forward by one emission interval, Rate/Limit, and is allowed while the TAT stays within Rate of
+// now. Requests are spread over a sliding window rather than reset at window boundaries, with the
+// precision of time.Duration.
+
+// emissionInterval is the time one request takes out of the bucket.
+func emissionInterval(quota Quota) time.Duration {
+	return quota.Rate / time.Duration(quota.Limit)
+}
+
+// allowedInfo describes an allowed request that moved the bucket's TAT to tat.
+func allowedInfo(tat, now time.Time, quota Quota) Info {
+	return Info{
+		Limit:         quota.Limit,
+		RateLimited:   false,
+		ResetTime:     tat,
+		RemainingHits: uint(max(quota.Rate-tat.Sub(now), 0) / emissionInterval(quota)),
+	}
+}
+
+// limitedInfo describes a rejected request to a bucket whose TAT is tat.
+func limitedInfo(tat, now time.Time, quota Quota) Info {
+	info := Info{
+		Limit:       quota.Limit,
+		RateLimited: true,
+		ResetTime:   tat,
+		RetryAfter:  quota.Rate,
+	}
+	if quota.Limit > 0 {
+		info.RetryAfter = tat.Add(emissionInterval(quota) - quota.Rate).Sub(now)
+	}
+
+	return info
+}
+
+// take applies a request arriving at now to a bucket with the given TAT. It returns the new TAT,
+// which is unchanged when the request is rejected.
+func take(tat, now time.Time, quota Quota) (time.Time, Info) {
+	if quota.Limit == 0 {
+		return tat, limitedInfo(tat, now, quota)
+	}
+
+	if tat.Before(now) {
+		tat = now
+	}
+
+	next := tat.Add(emissionInterval(quota))
+	if now.Before(next.Add(-quota.Rate)) {
+		return tat, limitedInfo(tat, now, quota)
+	}
+
+	return next, allowedInfo(next, now, quota)
+}
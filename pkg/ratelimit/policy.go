@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc picks the requests that are counted together.
+type KeyFunc func(c *gin.Context) string
+
+// Policy limits one group of routes. Requests made with a shop's API key are counted per shop, and
+// against the shop's own quota when it has one.
+type Policy struct {
+	Name       string // reported in X-RateLimit-Policy and namespaces the policy's keys
+	Quota      Quota
+	Key        KeyFunc
+	ShopQuotas map[string]Quota // by shop ID
+}
+
+// Resolve returns the key and quota that apply to the request.
+func (p Policy) Resolve(c *gin.Context) (string, Quota) {
+	if shopID := c.GetString("x-shop-id"); shopID != "" && c.GetString("x-api-key-id") != "" {
+		quota, ok := p.ShopQuotas[shopID]
+		if !ok {
+			quota = p.Quota
+		}
+		return p.Name + ":shop:" + shopID, quota
+	}
+
+	return p.Name + ":" + p.Key(c), p.Quota
+}
+
+// ByIP counts requests per client IP.
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser counts requests per authenticated user, falling back to the client IP. It must run after
+// the authentication middleware.
+func ByUser(c *gin.Context) string {
+	if userID := c.GetString("x-user-id"); userID != "" {
+		return "user:" + userID
+	}
+
+	return ByIP(c)
+}
+
+// maxJSONFieldBodyBytes bounds the body ByJSONField reads; it runs before authentication.
+const maxJSONFieldBodyBytes = 64 << 10
+
+// ByJSONField counts requests per value of a top-level string field of the JSON body, such as the
+// identifier of a login, falling back to the client IP. The body is restored for the handler.
+func ByJSONField(field string) KeyFunc {
+	return func(c *gin.Context) string {
+		limited := http.MaxBytesReader(c.Writer, c.Request.Body, maxJSONFieldBodyBytes)
+		body, err := io.ReadAll(limited)
+		if err != nil {
+			// The handler gets the same error when it reads the body.
+			c.Request.Body = limited
+			return ByIP(c)
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var fields map[string]any
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return ByIP(c)
+		}
+
+		value, _ := fields[field].(string)
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			return ByIP(c)
+		}
+
+		return field + ":" + value
+	}
+}
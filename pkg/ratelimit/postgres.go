@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dyaksa/warehouse/pkg/log"
+)
+
+// takeQuery applies one request to the bucket of $1 in a single atomic upsert, using the database
+// clock so every instance agrees on the time. $2 is the emission interval and $3 the rate, both in
+// microseconds. The update only happens when the request is allowed; otherwise the current TAT is
+// read instead. It may be missing when another instance created the bucket concurrently, which is
+// treated as allowed.
+const takeQuery = `
+WITH clock AS (
+    SELECT now() AS ts
+), upsert AS (
+    INSERT INTO rate_limits AS r (key, tat)
+    SELECT $1, clock.ts + $2 * INTERVAL '1 microsecond' FROM clock
+    ON CONFLICT (key) DO UPDATE
+    SET tat = GREATEST(r.tat, (SELECT ts FROM clock)) + $2 * INTERVAL '1 microsecond'
+    WHERE GREATEST(r.tat, (SELECT ts FROM clock)) + ($2 - $3) * INTERVAL '1 microsecond' <= (SELECT ts FROM clock)
+    RETURNING r.tat
+)
+SELECT
+    (SELECT ts FROM clock),
+    (SELECT tat FROM upsert),
+    (SELECT tat FROM rate_limits WHERE key = $1)`
+
+// DB is the subset of a database handle PostgresStore needs.
+type DB interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+type postgresStore struct {
+	db     DB
+	logger log.Logger
+}
+
+func (p *postgresStore) Limit(ctx context.Context, key string, quota Quota) Info {
+	if quota.Limit == 0 {
+		return limitedInfo(time.Now(), time.Now(), quota)
+	}
+
+	var (
+		now      time.Time
+		taken    sql.NullTime
+		existing sql.NullTime
+	)
+
+	err := p.db.QueryRowContext(ctx, takeQuery, key, emissionInterval(quota).Microseconds(), quota.Rate.Microseconds()).
+		Scan(&now, &taken, &existing)
+	if err != nil {
+		log.WithContext(ctx, p.logger).Error("failed to take from rate limit bucket, allowing request", log.String("key", key), log.Error("error", err))
+		return Info{Limit: quota.Limit, ResetTime: time.Now(), RemainingHits: quota.Limit}
+	}
+
+	if taken.Valid {
+		return allowedInfo(taken.Time, now, quota)
+	}
+	if !existing.Valid {
+		return allowedInfo(now.Add(emissionInterval(quota)), now, quota)
+	}
+
+	return limitedInfo(existing.Time, now, quota)
+}
+
+// clearInBackground deletes buckets that are full again, as a missing key is a full bucket.
+func (p *postgresStore) clearInBackground() {
+	for {
+		time.Sleep(time.Minute)
+
+		if _, err := p.db.ExecContext(context.Background(), "DELETE FROM rate_limits WHERE tat < now()"); err != nil {
+			p.logger.Error("failed to clear rate limit buckets", log.Error("error", err))
+		}
+	}
+}
+
+// PostgresStore keeps counters in the rate_limits table, so quotas hold across every instance and
+// survive deploys. Requests are allowed when the database cannot be reached.
+func PostgresStore(db DB, logger log.Logger) Store {
+	store := &postgresStore{db: db, logger: logger}
+	go store.clearInBackground()
+	return store
+}
@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInMemoryStoreLimitsPerWindow(t *testing.T) {
+	store := InMemoryStore()
+	quota := Quota{Limit: 2, Rate: 50 * time.Millisecond}
+
+	for i := 0; i < 2; i++ {
+		if info := store.Limit(context.Background(), "k", quota); info.RateLimited {
+			t.Fatalf("hit %d: expected to be allowed", i+1)
+		}
+	}
+
+	info := store.Limit(context.Background(), "k", quota)
+	if !info.RateLimited || info.RemainingHits != 0 {
+		t.Fatalf("expected third hit to be limited, got %+v", info)
+	}
+
+	if info := store.Limit(context.Background(), "other", quota); info.RateLimited || info.RemainingHits != 1 {
+		t.Fatalf("expected another key to be counted separately, got %+v", info)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if info := store.Limit(context.Background(), "k", quota); info.RateLimited {
+		t.Fatal("expected a new window to allow the key again")
+	}
+}
+
+func TestPolicyResolve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	shopQuota := Quota{Limit: 600, Rate: time.Minute}
+	policy := Policy{
+		Name:       "checkout",
+		Quota:      Quota{Limit: 10, Rate: time.Minute},
+		Key:        ByUser,
+		ShopQuotas: map[string]Quota{"shop-1": shopQuota},
+	}
+
+	newContext := func(values map[string]string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/", nil)
+		for k, v := range values {
+			c.Set(k, v)
+		}
+		return c
+	}
+
+	key, quota := policy.Resolve(newContext(map[string]string{"x-user-id": "user-1"}))
+	if key != "checkout:user:user-1" || quota != policy.Quota {
+		t.Fatalf("user request: got %q %+v", key, quota)
+	}
+
+	key, quota = policy.Resolve(newContext(map[string]string{"x-user-id": "user-1", "x-shop-id": "shop-1", "x-api-key-id": "key-1"}))
+	if key != "checkout:shop:shop-1" || quota != shopQuota {
+		t.Fatalf("api key request: got %q %+v", key, quota)
+	}
+
+	key, quota = policy.Resolve(newContext(map[string]string{"x-user-id": "user-1", "x-shop-id": "shop-2", "x-api-key-id": "key-2"}))
+	if key != "checkout:shop:shop-2" || quota != policy.Quota {
+		t.Fatalf("api key request without quota: got %q %+v", key, quota)
+	}
+}
+
+func TestByJSONFieldRestoresBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"identifier":" User@Example.com ","password":"secret"}`
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+
+	if key := ByJSONField("identifier")(c); key != "identifier:user@example.com" {
+		t.Fatalf("unexpected key %q", key)
+	}
+
+	var payload struct {
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Password != "secret" {
+		t.Fatalf("expected body to be restored, got %v %+v", err, payload)
+	}
+}
+
+func TestByJSONFieldOversizedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"identifier":"user@example.com","padding":"` + strings.Repeat("x", maxJSONFieldBodyBytes) + `"}`
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+	c.Request.RemoteAddr = "203.0.113.7:1234"
+
+	if key := ByJSONField("identifier")(c); key != "ip:203.0.113.7" {
+		t.Fatalf("expected the client IP key, got %q", key)
+	}
+
+	var payload map[string]any
+	if err := c.ShouldBindJSON(&payload); err == nil {
+		t.Fatalf("expected the handler to fail reading an oversized body")
+	}
+}
+
+func TestTakeSpreadsSubSecondQuota(t *testing.T) {
+	quota := Quota{Limit: 10, Rate: 100 * time.Millisecond}
+	now := time.Unix(1700000000, 0)
+
+	var (
+		tat  time.Time
+		info Info
+	)
+	for i := 0; i < 10; i++ {
+		tat, info = take(tat, now, quota)
+		if info.RateLimited {
+			t.Fatalf("hit %d: expected the burst to be allowed", i+1)
+		}
+	}
+	if info.RemainingHits != 0 || !info.ResetTime.Equal(now.Add(100*time.Millisecond)) {
+		t.Fatalf("unexpected info after burst: %+v", info)
+	}
+
+	tat, info = take(tat, now, quota)
+	if !info.RateLimited || info.RetryAfter != 10*time.Millisecond {
+		t.Fatalf("expected to be limited for one emission interval, got %+v", info)
+	}
+
+	// One request is allowed back per emission interval rather than the whole quota at once
+	_, info = take(tat, now.Add(10*time.Millisecond), quota)
+	if info.RateLimited || info.RemainingHits != 0 {
+		t.Fatalf("expected one request to be allowed after 10ms, got %+v", info)
+	}
+}
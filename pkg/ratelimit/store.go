@@ -1,103 +1,68 @@
 package ratelimit
 
 import (
+	"context"
 	"sync"
 	"time"
-
-	"github.com/gin-gonic/gin"
 )
 
+// Quota allows Limit requests per Rate.
+type Quota struct {
+	Limit uint
+	Rate  time.Duration
+}
+
 type Info struct {
 	Limit         uint
 	RateLimited   bool
-	ResetTime     time.Time
-	RemainingHits uint
+	ResetTime     time.Time     // when the full quota is available again
+	RemainingHits uint          // requests allowed right now
+	RetryAfter    time.Duration // until the next request is allowed, when RateLimited
 }
 
+// Store counts requests sharing a key against quota. Stores fail open: a request is allowed when
+// its counter cannot be read.
 type Store interface {
-	Limit(key string, c *gin.Context) Info
-}
-
-type Options struct {
-	ErrorHandler   func(*gin.Context, Info)
-	KeyFunc        func(*gin.Context) string
-	BeforeResponse func(c *gin.Context, info Info)
-}
-
-type user struct {
-	ts     int64
-	tokens uint
+	Limit(ctx context.Context, key string, quota Quota) Info
 }
 
 type inMemoryStoreType struct {
-	rate  int64
-	limit uint
-	data  *sync.Map
-	skip  func(ctx *gin.Context) bool
+	mu   sync.Mutex
+	data map[string]time.Time // TAT by key
 }
 
-func (i *inMemoryStoreType) Limit(key string, c *gin.Context) Info {
-	var u user
-	m, ok := i.data.Load(key)
-	if !ok {
-		u = user{ts: time.Now().Unix(), tokens: i.limit}
-	} else {
-		u = m.(user)
-	}
-	if u.ts+i.rate <= time.Now().Unix() {
-		u.tokens = i.limit
-	}
+func (i *inMemoryStoreType) Limit(ctx context.Context, key string, quota Quota) Info {
+	now := time.Now()
 
-	if i.skip != nil && i.skip(c) {
-		return Info{
-			Limit:         i.limit,
-			RateLimited:   false,
-			ResetTime:     time.Now().Add(time.Duration((i.rate - (time.Now().Unix() - u.ts)) * time.Second.Nanoseconds())),
-			RemainingHits: u.tokens,
-		}
-	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-	if u.tokens <= 0 {
-		return Info{
-			Limit:         i.limit,
-			RateLimited:   true,
-			ResetTime:     time.Now().Add(time.Duration((i.rate - (time.Now().Unix() - u.ts)) * time.Second.Nanoseconds())),
-			RemainingHits: 0,
-		}
-	}
-	u.tokens--
-	u.ts = time.Now().Unix()
-	i.data.Store(key, u)
+	tat, info := take(i.data[key], now, quota)
+	i.data[key] = tat
 
-	return Info{
-		Limit:         i.limit,
-		RateLimited:   false,
-		ResetTime:     time.Now().Add(time.Duration((i.rate - (time.Now().Unix() - u.ts)) * time.Second.Nanoseconds())),
-		RemainingHits: u.tokens,
-	}
+	return info
 }
 
-func clearMemoryInBackground(data *sync.Map, rate int64) {
+// clearInBackground forgets buckets that are full again, as a missing key is a full bucket.
+func (i *inMemoryStoreType) clearInBackground() {
 	for {
-		data.Range(func(key, value any) bool {
-			if value.(user).ts+rate < time.Now().Unix() {
-				data.Delete(key)
-			}
-			return true
-		})
 		time.Sleep(time.Minute)
-	}
-}
 
-type InMemoryOptions struct {
-	Rate  time.Duration
-	Limit uint
-	Skip  func(*gin.Context) bool
+		now := time.Now()
+		i.mu.Lock()
+		for key, tat := range i.data {
+			if tat.Before(now) {
+				delete(i.data, key)
+			}
+		}
+		i.mu.Unlock()
+	}
 }
 
-func InMemoryStore(options *InMemoryOptions) Store {
-	data := &sync.Map{}
-	store := inMemoryStoreType{int64(options.Rate.Seconds()), options.Limit, data, options.Skip}
-	go clearMemoryInBackground(data, store.rate)
-	return &store
+// InMemoryStore keeps counters in the process, so each instance enforces quotas on its own and
+// counters reset on restart. Use PostgresStore when running several instances.
+func InMemoryStore() Store {
+	store := &inMemoryStoreType{data: map[string]time.Time{}}
+	go store.clearInBackground()
+	return store
 }
@@ -0,0 +1,34 @@
+package tokenutils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognise in logs and scanners.
+const APIKeyPrefix = "whk"
+
+// NewAPIKey generates a key of the form whk_<id>_<secret>. The returned prefix (whk_<id>) is
+// stored and displayed to identify the key; only HashToken(key) is persisted for verification.
+func NewAPIKey() (key string, prefix string, err error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix = APIKeyPrefix + "_" + hex.EncodeToString(id)
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
+
+// IsAPIKey reports whether key has the shape produced by NewAPIKey.
+func IsAPIKey(key string) bool {
+	parts := strings.SplitN(key, "_", 3)
+	return len(parts) == 3 && parts[0] == APIKeyPrefix && len(parts[1]) == 12 && parts[2] != ""
+}
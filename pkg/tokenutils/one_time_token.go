@@ -0,0 +1,74 @@
+package tokenutils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var ErrMalformedToken = errors.New("malformed token")
+
+// NewOneTimeToken generates a random token signed with secret. The returned hash
+// is what gets persisted; the token itself is only ever handed to the user.
+func NewOneTimeToken(secret string) (token string, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	token = payload + "." + sign(payload, secret)
+
+	return token, HashToken(token), nil
+}
+
+// VerifyOneTimeToken checks the token signature so forged tokens are rejected
+// before hitting the database, and returns the hash used for lookup.
+func VerifyOneTimeToken(token string, secret string) (string, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || payload == "" || sig == "" {
+		return "", ErrMalformedToken
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(sign(payload, secret))) {
+		return "", ErrMalformedToken
+	}
+
+	return HashToken(token), nil
+}
+
+// HashToken returns the hex encoded sha256 of a token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewOTP generates a numeric one-time password with the given number of digits.
+func NewOTP(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// HashOTP binds a code to its scope (e.g. the token row ID) so equal codes never share a hash.
+func HashOTP(code string, scope string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(scope + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func sign(payload string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
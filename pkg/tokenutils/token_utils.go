@@ -5,6 +5,7 @@ import (
 
 	"github.com/dyaksa/warehouse/domain"
 	jwt "github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 func CreateAccessToken(user *domain.User, secret string, expiry int) (string, error) {
@@ -32,6 +33,22 @@ func CreateRefreshAccessToken(user *domain.User, secret string, expiry int) (str
 	return token.SignedString([]byte(secret))
 }
 
+// ChallengeScope marks tokens that only prove the password step of a two-factor login.
+const ChallengeScope = "2fa_challenge"
+
+func CreateChallengeToken(userID uuid.UUID, secret string, ttl time.Duration) (string, error) {
+	claims := &domain.JwtCustomClaims{
+		ID:    userID,
+		Scope: ChallengeScope,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(ttl).Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
 func IsAuthorized(requestToken string, secret string) (bool, error) {
 	_, err := jwt.Parse(requestToken, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -47,7 +64,17 @@ func IsAuthorized(requestToken string, secret string) (bool, error) {
 	return true, nil
 }
 
+// ExtractIDFromToken returns the user ID of an access token. Scoped tokens are rejected.
 func ExtractIDFromToken(requestToken string, secret string) (string, error) {
+	return extractID(requestToken, secret, "")
+}
+
+// ExtractIDFromChallengeToken returns the user ID of a two-factor challenge token.
+func ExtractIDFromChallengeToken(requestToken string, secret string) (string, error) {
+	return extractID(requestToken, secret, ChallengeScope)
+}
+
+func extractID(requestToken string, secret string, scope string) (string, error) {
 	token, err := jwt.Parse(requestToken, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrInvalidKey
@@ -65,7 +92,12 @@ func ExtractIDFromToken(requestToken string, secret string) (string, error) {
 		return "", jwt.ErrInvalidKey
 	}
 
-	userID, ok := claims["uuid"].(string)
+	tokenScope, _ := claims["scope"].(string)
+	if tokenScope != scope {
+		return "", jwt.ErrInvalidKey
+	}
+
+	userID, ok := claims["id"].(string)
 	if !ok {
 		return "", jwt.ErrInvalidKey
 	}
@@ -0,0 +1,29 @@
+package tokenutils
+
+import (
+	"testing"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/google/uuid"
+)
+
+func TestAccessTokenRoundTrip(t *testing.T) {
+	user := &domain.User{ID: uuid.New()}
+
+	token, err := CreateAccessToken(user, "access-secret", 1)
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+
+	id, err := ExtractIDFromToken(token, "access-secret")
+	if err != nil {
+		t.Fatalf("extract id: %v", err)
+	}
+	if id != user.ID.String() {
+		t.Errorf("expected %s got %s", user.ID, id)
+	}
+
+	if _, err := ExtractIDFromToken(token, "other-secret"); err == nil {
+		t.Error("expected a token signed with another secret to be rejected")
+	}
+}
@@ -0,0 +1,19 @@
+package tokenutils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// WebhookSecretPrefix marks webhook signing secrets, as APIKeyPrefix does for API keys.
+const WebhookSecretPrefix = "whsec"
+
+// NewWebhookSecret generates a signing secret for a webhook subscription.
+func NewWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return WebhookSecretPrefix + "_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
@@ -0,0 +1,166 @@
+// Package tracing configures the OpenTelemetry tracer provider and offers helpers for starting spans.
+// Spans are exported over OTLP/HTTP (endpoint from the standard OTEL_EXPORTER_OTLP_* variables),
+// to stdout or to a file for local runs, and W3C trace context is propagated on incoming requests.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/dyaksa/warehouse"
+
+// Exporters supported by Init.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+type Config struct {
+	ServiceName string
+	Environment string
+	Exporter    string
+	FilePath    string
+}
+
+// Init installs the global tracer provider and propagator. The returned function flushes pending spans
+// and must be called on shutdown. With ExporterNone (or an empty exporter) spans are created but dropped.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, closer, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.DeploymentEnvironment(cfg.Environment),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, io.Closer, error) {
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return nil, nil, nil
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx)
+		return exporter, nil, err
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, nil, err
+	case ExporterFile:
+		if err := os.MkdirAll(filepath.Dir(cfg.FilePath), 0o755); err != nil {
+			return nil, nil, err
+		}
+
+		f, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, err
+		}
+
+		return exporter, f, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+// Start starts a span named after the component and method, e.g. "OrderUsecase.Checkout".
+// When no provider is installed the span only mirrors its parent, and ctx is returned unchanged.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	spanCtx, span := otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+	if span.SpanContext().Equal(trace.SpanContextFromContext(ctx)) {
+		return ctx, span
+	}
+
+	return spanCtx, span
+}
+
+// RecordError marks the span as failed. It is a no-op for a nil error.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+func OrderID(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("order.id", id.String())
+}
+
+func TransferID(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("transfer.id", id.String())
+}
+
+func WarehouseID(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("warehouse.id", id.String())
+}
+
+// SourceWarehouseID and DestinationWarehouseID label the two sides of a transfer.
+func SourceWarehouseID(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("warehouse.source_id", id.String())
+}
+
+func DestinationWarehouseID(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("warehouse.destination_id", id.String())
+}
+
+func ShopID(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("shop.id", id.String())
+}
+
+func UserID(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("user.id", id.String())
+}
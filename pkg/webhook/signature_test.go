@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"order.payment_confirmed"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := Sign("whsec_test", now, body)
+
+	if err := Verify("whsec_test", sig, ts, body, now, 5*time.Minute); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		secret string
+		sig    string
+		ts     string
+		body   []byte
+		want   error
+	}{
+		{"wrong secret", "other", sig, ts, body, ErrInvalidSignature},
+		{"tampered body", "whsec_test", sig, ts, []byte(`{}`), ErrInvalidSignature},
+		{"tampered timestamp", "whsec_test", sig, strconv.FormatInt(now.Unix()+1, 10), body, ErrInvalidSignature},
+		{"missing prefix", "whsec_test", sig[len(signaturePrefix):], ts, body, ErrInvalidSignature},
+		{"stale", "whsec_test", sig, strconv.FormatInt(now.Add(-time.Hour).Unix(), 10), body, ErrExpiredTimestamp},
+	}
+	for _, c := range cases {
+		if err := Verify(c.secret, c.sig, c.ts, c.body, now, 5*time.Minute); err != c.want {
+			t.Errorf("%s: expected %v got %v", c.name, c.want, err)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type apiKeyRepository struct {
+	db pqsql.Client
+}
+
+var apiKeyColumns = []string{"id", "shop_id", "user_id", "name", "prefix", "key_hash", "scopes", "expires_at", "last_used_at", "revoked_at", "created_at"}
+
+// Create implements domain.APIKeyRepository.
+func (r *apiKeyRepository) Create(ctx context.Context, tx *sql.Tx, key *domain.APIKey) error {
+	query := sq.Insert("api_keys").
+		Columns("id", "shop_id", "user_id", "name", "prefix", "key_hash", "scopes", "expires_at").
+		Values(key.ID, key.ShopID, key.UserID, key.Name, key.Prefix, key.KeyHash, pq.Array(scopeStrings(key.Scopes)), key.ExpiresAt).
+		Suffix("RETURNING created_at").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRowContext(ctx, q, args...).Scan(&key.CreatedAt)
+}
+
+// GetByHash implements domain.APIKeyRepository.
+// Revoked and expired keys are filtered out, so any match is usable.
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	query := sq.Select(apiKeyColumns...).
+		From("api_keys").
+		Where(sq.And{
+			sq.Eq{"key_hash": keyHash},
+			sq.Eq{"revoked_at": nil},
+			sq.Or{
+				sq.Eq{"expires_at": nil},
+				sq.Expr("expires_at > now()"),
+			},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAPIKey(r.db.Database().QueryRowContext(ctx, q, args...))
+}
+
+// Get implements domain.APIKeyRepository.
+func (r *apiKeyRepository) Get(ctx context.Context, tx *sql.Tx, shopID uuid.UUID, userID uuid.UUID, id uuid.UUID) (*domain.APIKey, error) {
+	query := sq.Select(apiKeyColumns...).
+		From("api_keys").
+		Where(sq.And{
+			sq.Eq{"id": id},
+			sq.Eq{"shop_id": shopID},
+			sq.Eq{"user_id": userID},
+		}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAPIKey(tx.QueryRowContext(ctx, q, args...))
+}
+
+// ListByShop implements domain.APIKeyRepository.
+func (r *apiKeyRepository) ListByShop(ctx context.Context, shopID uuid.UUID, userID uuid.UUID) ([]domain.APIKey, error) {
+	query := sq.Select(apiKeyColumns...).
+		From("api_keys").
+		Where(sq.And{
+			sq.Eq{"shop_id": shopID},
+			sq.Eq{"user_id": userID},
+		}).
+		OrderBy("created_at DESC").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Database().QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke implements domain.APIKeyRepository.
+func (r *apiKeyRepository) Revoke(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	query := sq.Update("api_keys").
+		Set("revoked_at", sq.Expr("now()")).
+		Where(sq.And{
+			sq.Eq{"id": id},
+			sq.Eq{"revoked_at": nil},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("api key already revoked")
+	}
+
+	return nil
+}
+
+// RevokeByUser implements domain.APIKeyRepository.
+func (r *apiKeyRepository) RevokeByUser(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	query := sq.Update("api_keys").
+		Set("revoked_at", sq.Expr("now()")).
+		Where(sq.And{
+			sq.Eq{"user_id": userID},
+			sq.Eq{"revoked_at": nil},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// MarkRotated implements domain.APIKeyRepository.
+// The old key keeps working until expiresAt so clients can switch over; an earlier expiry is kept.
+func (r *apiKeyRepository) MarkRotated(ctx context.Context, tx *sql.Tx, id uuid.UUID, rotatedTo uuid.UUID, expiresAt time.Time) error {
+	query := sq.Update("api_keys").
+		Set("rotated_to", rotatedTo).
+		Set("expires_at", sq.Expr("LEAST(COALESCE(expires_at, ?), ?)", expiresAt, expiresAt)).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
+// TouchLastUsed implements domain.APIKeyRepository.
+// Updates are throttled to one per minute per key to keep hot keys from turning every request into a write.
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	query := sq.Update("api_keys").
+		Set("last_used_at", sq.Expr("now()")).
+		Where(sq.And{
+			sq.Eq{"id": id},
+			sq.Or{
+				sq.Eq{"last_used_at": nil},
+				sq.Expr("last_used_at < now() - interval '1 minute'"),
+			},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Database().ExecContext(ctx, q, args...)
+	return err
+}
+
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes []string
+
+	if err := row.Scan(&key.ID, &key.ShopID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, pq.Array(&scopes), &key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	key.Scopes = make([]domain.APIKeyScope, 0, len(scopes))
+	for _, s := range scopes {
+		key.Scopes = append(key.Scopes, domain.APIKeyScope(s))
+	}
+
+	return &key, nil
+}
+
+func scopeStrings(scopes []domain.APIKeyScope) []string {
+	out := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		out = append(out, string(s))
+	}
+
+	return out
+}
+
+func NewAPIKeyRepository(db pqsql.Client) domain.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
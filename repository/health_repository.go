@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+)
+
+type healthRepository struct {
+	db           pqsql.Client
+	versionTable string
+}
+
+// Ping implements domain.HealthRepository.
+func (h *healthRepository) Ping(ctx context.Context) error {
+	return h.db.PingContext(ctx)
+}
+
+// MigrationVersion implements domain.HealthRepository.
+// It reads the goose version table used by the migration CLI (GOOSE_TABLE). Rollbacks are recorded as new rows
+// with is_applied=false, so only the latest row of each version counts.
+func (h *healthRepository) MigrationVersion(ctx context.Context) (int64, error) {
+	latest := sq.Select("version_id", "is_applied").
+		Options("DISTINCT ON (version_id)").
+		From(h.versionTable).
+		OrderBy("version_id", "id DESC")
+
+	query := sq.Select("COALESCE(MAX(version_id), 0)").
+		FromSelect(latest, "v").
+		Where(sq.Eq{"is_applied": true}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+	if err := h.db.Database().QueryRowContext(ctx, q, args...).Scan(&version); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func NewHealthRepository(db pqsql.Client, versionTable string) domain.HealthRepository {
+	if versionTable == "" {
+		versionTable = "goose_db_version"
+	}
+
+	return &healthRepository{db: db, versionTable: versionTable}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/dyaksa/warehouse/domain"
@@ -14,11 +15,12 @@ type idempotencyRequestRepository struct {
 	db pqsql.Client
 }
 
-// BeginKey implements domain.IdempotencyRequestRepository.
-func (i *idempotencyRequestRepository) BeginKey(ctx context.Context, tx *sql.Tx, key string, endpoint string, payloadHash string) (isNew bool, err error) {
+// Claim implements domain.IdempotencyRequestRepository.
+func (i *idempotencyRequestRepository) Claim(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time) (bool, error) {
 	query := sq.Insert("idempotency_requests").
-		Columns("key", "endpoint", "payload_hash").Values(key, endpoint, payloadHash).
-		Suffix("ON CONFLICT (key, endpoint) DO NOTHING").
+		Columns("key", "endpoint", "principal", "lease_token", "payload_hash", "expires_at").
+		Values(key.Key, key.Endpoint, key.Principal, lease, payloadHash, expiresAt).
+		Suffix("ON CONFLICT (key, endpoint, principal) DO NOTHING").
 		PlaceholderFormat(sq.Dollar)
 
 	q, args, err := query.ToSql()
@@ -39,53 +41,99 @@ func (i *idempotencyRequestRepository) BeginKey(ctx context.Context, tx *sql.Tx,
 	return rowsAffected > 0, nil
 }
 
-// LoadIfExists implements domain.IdempotencyRequestRepository.
-func (i *idempotencyRequestRepository) LoadIfExists(ctx context.Context, tx *sql.Tx, key string, endpoint string) (payloadHash string, orderID *uuid.UUID, responseJSON []byte, exists bool, err error) {
-	query := sq.Select("payload_hash", "order_id", "response_body").
+// GetForUpdate implements domain.IdempotencyRequestRepository.
+func (i *idempotencyRequestRepository) GetForUpdate(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey) (*domain.IdempotencyRecord, error) {
+	query := sq.Select("payload_hash", "status_code", "COALESCE(content_type, '')", "response_body", "locked_at", "expires_at").
 		From("idempotency_requests").
-		Where(sq.And{
-			sq.Eq{"key": key},
-			sq.Eq{"endpoint": endpoint},
+		Where(keyFilter(key)).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	record := domain.IdempotencyRecord{IdempotencyKey: key}
+	err = tx.QueryRowContext(ctx, q, args...).
+		Scan(&record.PayloadHash, &record.StatusCode, &record.ContentType, &record.ResponseBody, &record.LockedAt, &record.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Reclaim implements domain.IdempotencyRequestRepository.
+func (i *idempotencyRequestRepository) Reclaim(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time) error {
+	query := sq.Update("idempotency_requests").
+		SetMap(map[string]any{
+			"lease_token":   lease,
+			"payload_hash":  payloadHash,
+			"status_code":   nil,
+			"content_type":  nil,
+			"response_body": nil,
+			"locked_at":     sq.Expr("now()"),
+			"completed_at":  nil,
+			"expires_at":    expiresAt,
 		}).
+		Where(keyFilter(key)).
 		PlaceholderFormat(sq.Dollar)
 
 	q, args, err := query.ToSql()
 	if err != nil {
-		return "", nil, nil, false, err
+		return err
 	}
 
-	var oID sql.NullString
-	var respJSON []byte
-	err = tx.QueryRowContext(ctx, q, args...).Scan(&payloadHash, &oID, &respJSON)
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// Complete implements domain.IdempotencyRequestRepository.
+func (i *idempotencyRequestRepository) Complete(ctx context.Context, key domain.IdempotencyKey, lease uuid.UUID, response domain.IdempotentResponse) error {
+	query := sq.Update("idempotency_requests").
+		SetMap(map[string]any{
+			"status_code":   response.StatusCode,
+			"content_type":  response.ContentType,
+			"response_body": response.Body,
+			"completed_at":  sq.Expr("now()"),
+		}).
+		Where(sq.And{
+			keyFilter(key),
+			sq.Eq{"lease_token": lease},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
 
-	switch {
-	case err == sql.ErrNoRows:
-		return "", nil, nil, false, nil
-	case err != nil:
-		return "", nil, nil, false, err
+	res, err := i.db.Database().ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
 	}
 
-	if oID.Valid {
-		parsedID, err := uuid.Parse(oID.String)
-		if err != nil {
-			return "", nil, nil, false, err
-		}
-		orderID = &parsedID
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
 	}
 
-	responseJSON = respJSON
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
 
-	return payloadHash, orderID, responseJSON, true, nil
+	return nil
 }
 
-// SaveResponse implements domain.IdempotencyRequestRepository.
-func (i *idempotencyRequestRepository) SaveResponse(ctx context.Context, tx *sql.Tx, key string, endpoint string, orderID uuid.UUID, responseJSON []byte) error {
-	query := sq.Update("idempotency_requests").
-		Set("order_id", orderID).
-		Set("response_body", responseJSON).
+// Release implements domain.IdempotencyRequestRepository.
+func (i *idempotencyRequestRepository) Release(ctx context.Context, key domain.IdempotencyKey, lease uuid.UUID) error {
+	query := sq.Delete("idempotency_requests").
 		Where(sq.And{
-			sq.Eq{"key": key},
-			sq.Eq{"endpoint": endpoint},
+			keyFilter(key),
+			sq.Eq{"lease_token": lease},
+			sq.Eq{"status_code": nil},
 		}).
 		PlaceholderFormat(sq.Dollar)
 
@@ -94,11 +142,50 @@ func (i *idempotencyRequestRepository) SaveResponse(ctx context.Context, tx *sql
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, q, args...)
+	_, err = i.db.Database().ExecContext(ctx, q, args...)
 
 	return err
 }
 
+// DeleteExpired implements domain.IdempotencyRequestRepository.
+func (i *idempotencyRequestRepository) DeleteExpired(ctx context.Context, limit int) (int, error) {
+	expired := sq.Select("id").
+		From("idempotency_requests").
+		Where(sq.Expr("expires_at < now()")).
+		OrderBy("expires_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	query := sq.Delete("idempotency_requests").
+		Where(sq.Expr("id IN (?)", expired)).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := i.db.Database().ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
+func keyFilter(key domain.IdempotencyKey) sq.And {
+	return sq.And{
+		sq.Eq{"key": key.Key},
+		sq.Eq{"endpoint": key.Endpoint},
+		sq.Eq{"principal": key.Principal},
+	}
+}
+
 func NewIdempotencyRequestRepository(db pqsql.Client) domain.IdempotencyRequestRepository {
 	return &idempotencyRequestRepository{db: db}
 }
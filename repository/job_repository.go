@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+)
+
+type jobRepository struct {
+	db pqsql.Client
+}
+
+var jobColumns = []string{"id", "type", "payload", "status", "run_at", "attempts", "max_attempts", "last_error", "completed_at", "locked_until", "created_at", "updated_at"}
+
+// Create implements domain.JobRepository.
+func (r *jobRepository) Create(ctx context.Context, tx *sql.Tx, job *domain.Job) error {
+	query := sq.Insert("jobs").
+		Columns("id", "type", "payload", "status", "run_at", "max_attempts").
+		Values(job.ID, string(job.Type), []byte(job.Payload), job.Status, job.RunAt, job.MaxAttempts).
+		Suffix("RETURNING created_at, updated_at").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRowContext(ctx, q, args...).Scan(&job.CreatedAt, &job.UpdatedAt)
+}
+
+// GetForUpdate implements domain.JobRepository.
+func (r *jobRepository) GetForUpdate(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Job, error) {
+	query := sq.Select(jobColumns...).
+		From("jobs").
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var job domain.Job
+	if err := tx.QueryRowContext(ctx, q, args...).Scan(jobFields(&job)...); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// List implements domain.JobRepository.
+func (r *jobRepository) List(ctx context.Context, filter domain.JobFilter, limit, offset int) ([]domain.Job, int, error) {
+	where := sq.And{}
+	if filter.Status != "" {
+		where = append(where, sq.Eq{"status": filter.Status})
+	}
+	if filter.Type != "" {
+		where = append(where, sq.Eq{"type": filter.Type})
+	}
+
+	var total int
+
+	countQuery := sq.Select("COUNT(*)").
+		From("jobs").
+		Where(where).
+		PlaceholderFormat(sq.Dollar)
+
+	countSql, countArgs, err := countQuery.ToSql()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Database().QueryRowContext(ctx, countSql, countArgs...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := sq.Select(jobColumns...).
+		From("jobs").
+		Where(where).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Database().QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	jobs := []domain.Job{}
+	for rows.Next() {
+		var job domain.Job
+		if err := rows.Scan(jobFields(&job)...); err != nil {
+			return nil, 0, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, total, rows.Err()
+}
+
+// ClaimDue implements domain.JobRepository.
+func (r *jobRepository) ClaimDue(ctx context.Context, tx *sql.Tx, types []domain.JobType, lease time.Duration) (*domain.Job, error) {
+	// A lease that ran out on the last attempt means the worker died running it; another run would
+	// go past max_attempts.
+	if _, err := r.update(ctx, tx, sq.And{
+		sq.Eq{"status": domain.JobPending},
+		sq.Eq{"type": types},
+		sq.Expr("locked_until < now()"),
+		sq.Expr("attempts >= max_attempts"),
+	}, map[string]any{
+		"status":       domain.JobDead,
+		"last_error":   "lease expired on the last attempt",
+		"locked_until": nil,
+	}); err != nil {
+		return nil, err
+	}
+
+	due := sq.Select("id").
+		From("jobs").
+		Where(sq.And{
+			sq.Eq{"status": domain.JobPending},
+			sq.Eq{"type": types},
+			sq.Expr("run_at <= now()"),
+			sq.Or{sq.Eq{"locked_until": nil}, sq.Expr("locked_until < now()")},
+		}).
+		OrderBy("run_at").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	query := sq.Update("jobs").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("locked_until", sq.Expr("now() + make_interval(secs => ?)", lease.Seconds())).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Expr("id = (?)", due)).
+		Suffix("RETURNING " + strings.Join(jobColumns, ", ")).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var job domain.Job
+	if err := tx.QueryRowContext(ctx, q, args...).Scan(jobFields(&job)...); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// MarkSucceeded implements domain.JobRepository.
+func (r *jobRepository) MarkSucceeded(ctx context.Context, tx *sql.Tx, claim *domain.Job) error {
+	return r.finish(ctx, tx, claim, map[string]any{
+		"status":       domain.JobSucceeded,
+		"last_error":   nil,
+		"completed_at": sq.Expr("now()"),
+	})
+}
+
+// MarkRetry implements domain.JobRepository.
+func (r *jobRepository) MarkRetry(ctx context.Context, tx *sql.Tx, claim *domain.Job, lastError string, runAt time.Time) error {
+	return r.finish(ctx, tx, claim, map[string]any{
+		"last_error": lastError,
+		"run_at":     runAt,
+	})
+}
+
+// MarkDead implements domain.JobRepository.
+func (r *jobRepository) MarkDead(ctx context.Context, tx *sql.Tx, claim *domain.Job, lastError string) error {
+	return r.finish(ctx, tx, claim, map[string]any{
+		"status":     domain.JobDead,
+		"last_error": lastError,
+	})
+}
+
+// Requeue implements domain.JobRepository.
+func (r *jobRepository) Requeue(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	_, err := r.update(ctx, tx, sq.Eq{"id": id}, map[string]any{
+		"status":       domain.JobPending,
+		"attempts":     0,
+		"run_at":       sq.Expr("now()"),
+		"completed_at": nil,
+		"locked_until": nil,
+	})
+	return err
+}
+
+// Cancel implements domain.JobRepository.
+func (r *jobRepository) Cancel(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	_, err := r.update(ctx, tx, sq.Eq{"id": id}, map[string]any{
+		"status":       domain.JobCancelled,
+		"completed_at": sq.Expr("now()"),
+	})
+	return err
+}
+
+// finish records the outcome of a claimed job and releases its lease. Every claim counts an attempt
+// and sets a new lease, so the pair identifies the claim that ran the job.
+func (r *jobRepository) finish(ctx context.Context, tx *sql.Tx, claim *domain.Job, values map[string]any) error {
+	values["locked_until"] = nil
+
+	updated, err := r.update(ctx, tx, sq.Eq{
+		"id":           claim.ID,
+		"status":       domain.JobPending,
+		"attempts":     claim.Attempts,
+		"locked_until": claim.LockedUntil,
+	}, values)
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *jobRepository) update(ctx context.Context, tx *sql.Tx, where sq.Sqlizer, values map[string]any) (int64, error) {
+	values["updated_at"] = sq.Expr("now()")
+
+	query := sq.Update("jobs").
+		SetMap(values).
+		Where(where).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func jobFields(job *domain.Job) []any {
+	return []any{&job.ID, &job.Type, &job.Payload, &job.Status, &job.RunAt, &job.Attempts, &job.MaxAttempts, &job.LastError, &job.CompletedAt, &job.LockedUntil, &job.CreatedAt, &job.UpdatedAt}
+}
+
+func NewJobRepository(db pqsql.Client) domain.JobRepository {
+	return &jobRepository{db: db}
+}
@@ -7,6 +7,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
 )
 
 type orderItemRepository struct {
@@ -35,6 +36,37 @@ func (o *orderItemRepository) BulkInsert(ctx context.Context, tx *sql.Tx, items
 	return err
 }
 
+// GetByOrderID implements domain.OrderItemRepository.
+func (o *orderItemRepository) GetByOrderID(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]domain.OrderItem, error) {
+	query := sq.Select("id", "order_id", "product_id", "qty", "CAST(price AS BIGINT) as price").
+		From("order_items").
+		Where(sq.Eq{"order_id": orderID}).
+		OrderBy("created_at", "id").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.OrderItem
+	for rows.Next() {
+		var item domain.OrderItem
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Qty, &item.Price); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
 func NewOrderItemRepository(db pqsql.Client) domain.OrderItemRepository {
 	return &orderItemRepository{db: db}
 }
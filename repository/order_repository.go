@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/dyaksa/warehouse/domain"
@@ -17,8 +18,8 @@ type orderRepository struct {
 // Create implements domain.OrderRepository.
 func (or *orderRepository) Create(ctx context.Context, tx *sql.Tx, o *domain.Order) error {
 	query := sq.Insert("orders").
-		Columns("id", "user_id", "shop_id", "status", "total_amount").
-		Values(o.ID, o.UserID, o.ShopID, o.Status, o.Total).
+		Columns("id", "user_id", "shop_id", "status", "total_amount", "reservation_expires_at").
+		Values(o.ID, o.UserID, o.ShopID, o.Status, o.Total, o.ReservedUntil).
 		PlaceholderFormat(sq.Dollar)
 
 	q, args, err := query.ToSql()
@@ -33,7 +34,7 @@ func (or *orderRepository) Create(ctx context.Context, tx *sql.Tx, o *domain.Ord
 
 // GetByID implements domain.OrderRepository.
 func (or *orderRepository) GetByID(ctx context.Context, orderID uuid.UUID) (*domain.Order, error) {
-	query := sq.Select("id", "user_id", "shop_id", "status", "CAST(total_amount AS BIGINT) as total_amount", "created_at", "updated_at").
+	query := sq.Select("id", "user_id", "shop_id", "status", "CAST(total_amount AS BIGINT) as total_amount", "reservation_expires_at", "reservation_extensions", "created_at", "updated_at").
 		From("orders").
 		Where(sq.Eq{"id": orderID}).
 		PlaceholderFormat(sq.Dollar)
@@ -45,7 +46,7 @@ func (or *orderRepository) GetByID(ctx context.Context, orderID uuid.UUID) (*dom
 
 	var o domain.Order
 	err = or.db.Database().QueryRowContext(ctx, q, args...).
-		Scan(&o.ID, &o.UserID, &o.ShopID, &o.Status, &o.Total, &o.CreatedAt, &o.UpdatedAt)
+		Scan(&o.ID, &o.UserID, &o.ShopID, &o.Status, &o.Total, &o.ReservedUntil, &o.ReservationExtensions, &o.CreatedAt, &o.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -53,8 +54,49 @@ func (or *orderRepository) GetByID(ctx context.Context, orderID uuid.UUID) (*dom
 	return &o, nil
 }
 
+// GetByIDForUpdate implements domain.OrderRepository.
+func (or *orderRepository) GetByIDForUpdate(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (*domain.Order, error) {
+	query := sq.Select("id", "user_id", "shop_id", "status", "CAST(total_amount AS BIGINT) as total_amount", "reservation_expires_at", "reservation_extensions", "created_at", "updated_at").
+		From("orders").
+		Where(sq.Eq{"id": orderID}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var o domain.Order
+	err = tx.QueryRowContext(ctx, q, args...).
+		Scan(&o.ID, &o.UserID, &o.ShopID, &o.Status, &o.Total, &o.ReservedUntil, &o.ReservationExtensions, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// ExtendReservation implements domain.OrderRepository.
+func (or *orderRepository) ExtendReservation(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, expiresAt time.Time) error {
+	query := sq.Update("orders").
+		Set("reservation_expires_at", expiresAt).
+		Set("reservation_extensions", sq.Expr("reservation_extensions + 1")).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": orderID}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
 // Updatestatus implements domain.OrderRepository.
-func (or *orderRepository) Updatestatus(ctx context.Context, orderID uuid.UUID, status domain.OrderStatus) error {
+func (or *orderRepository) Updatestatus(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, status domain.OrderStatus) error {
 	query := sq.Update("orders").
 		Set("status", status).
 		Set("updated_at", sq.Expr("now()")).
@@ -65,20 +107,29 @@ func (or *orderRepository) Updatestatus(ctx context.Context, orderID uuid.UUID,
 		return err
 	}
 
-	_, err = or.db.Database().ExecContext(ctx, q, args...)
+	_, err = tx.ExecContext(ctx, q, args...)
 
 	return err
 }
 
 // GetByUserID implements domain.OrderRepository.
 func (or *orderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]domain.OrderListItem, int, error) {
+	return or.list(ctx, "user_id", userID, limit, offset)
+}
+
+// GetByShopID implements domain.OrderRepository.
+func (or *orderRepository) GetByShopID(ctx context.Context, shopID uuid.UUID, limit, offset int) ([]domain.OrderListItem, int, error) {
+	return or.list(ctx, "shop_id", shopID, limit, offset)
+}
+
+func (or *orderRepository) list(ctx context.Context, column string, id uuid.UUID, limit, offset int) ([]domain.OrderListItem, int, error) {
 	var orders []domain.OrderListItem
 	var totalCount int
 
 	// First, get the total count for pagination
 	countQuery := sq.Select("COUNT(*)").
 		From("orders").
-		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{column: id}).
 		PlaceholderFormat(sq.Dollar)
 
 	countSql, countArgs, err := countQuery.ToSql()
@@ -102,7 +153,7 @@ func (or *orderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, li
 	).
 		From("orders o").
 		LeftJoin("order_items oi ON oi.order_id = o.id").
-		Where(sq.Eq{"o.user_id": userID}).
+		Where(sq.Eq{"o." + column: id}).
 		GroupBy("o.id", "o.total_amount", "o.status", "o.reservation_expires_at", "o.created_at").
 		OrderBy("o.created_at DESC").
 		Limit(uint64(limit)).
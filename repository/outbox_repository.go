@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+)
+
+type outboxRepository struct {
+	db pqsql.Client
+}
+
+// Append implements domain.OutboxRepository.
+func (r *outboxRepository) Append(ctx context.Context, tx *sql.Tx, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	query := sq.Insert("outbox_events").
+		Columns("id", "aggregate_type", "aggregate_id", "event_type", "payload").
+		Values(uuid.New(), event.AggregateType(), event.AggregateID(), string(event.EventType()), payload).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// PickPending implements domain.OutboxRepository.
+// An event is deliverable only when no older event of its aggregate is still pending, which keeps
+// per-aggregate order even when several relays run: the older event is either locked by another
+// relay or waiting for its retry, and in both cases it is still unpublished.
+func (r *outboxRepository) PickPending(ctx context.Context, tx *sql.Tx, limit int) ([]domain.OutboxEvent, error) {
+	query := sq.Select("e.id", "e.seq", "e.aggregate_type", "e.aggregate_id", "e.event_type", "e.payload", "e.attempts", "e.created_at").
+		From("outbox_events e").
+		Where(sq.And{
+			sq.Eq{"e.published_at": nil},
+			sq.Expr("e.next_attempt_at <= now()"),
+			sq.Expr(`NOT EXISTS (
+				SELECT 1 FROM outbox_events p
+				WHERE p.aggregate_id = e.aggregate_id AND p.published_at IS NULL AND p.seq < e.seq
+			)`),
+		}).
+		OrderBy("e.seq").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.OutboxEvent
+	for rows.Next() {
+		var event domain.OutboxEvent
+		if err := rows.Scan(&event.ID, &event.Seq, &event.AggregateType, &event.AggregateID, &event.EventType,
+			&event.Payload, &event.Attempts, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// MarkPublished implements domain.OutboxRepository.
+func (r *outboxRepository) MarkPublished(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	query := sq.Update("outbox_events").
+		Set("published_at", sq.Expr("now()")).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", nil).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// MarkFailed implements domain.OutboxRepository.
+func (r *outboxRepository) MarkFailed(ctx context.Context, tx *sql.Tx, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	query := sq.Update("outbox_events").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", lastError).
+		Set("next_attempt_at", nextAttemptAt).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+func NewOutboxRepository(db pqsql.Client) domain.OutboxRepository {
+	return &outboxRepository{db: db}
+}
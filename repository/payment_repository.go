@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+)
+
+type paymentRepository struct {
+	db pqsql.Client
+}
+
+var paymentIntentColumns = []string{"id", "order_id", "provider", "provider_ref", "amount", "status", "checkout_url", "created_at", "updated_at"}
+
+// CreateIntent implements domain.PaymentRepository.
+func (r *paymentRepository) CreateIntent(ctx context.Context, tx *sql.Tx, intent *domain.PaymentIntent) error {
+	query := sq.Insert("payment_intents").
+		Columns("id", "order_id", "provider", "provider_ref", "amount", "status", "checkout_url").
+		Values(intent.ID, intent.OrderID, intent.Provider, intent.ProviderRef, intent.Amount, intent.Status, intent.CheckoutURL).
+		Suffix("RETURNING created_at, updated_at").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRowContext(ctx, q, args...).Scan(&intent.CreatedAt, &intent.UpdatedAt)
+}
+
+// GetOpenIntent implements domain.PaymentRepository.
+func (r *paymentRepository) GetOpenIntent(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (*domain.PaymentIntent, error) {
+	query := sq.Select(paymentIntentColumns...).
+		From("payment_intents").
+		Where(sq.And{
+			sq.Eq{"order_id": orderID},
+			sq.Eq{"status": domain.PaymentRequiresPayment},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPaymentIntent(tx.QueryRowContext(ctx, q, args...))
+}
+
+// GetPaidIntent implements domain.PaymentRepository.
+func (r *paymentRepository) GetPaidIntent(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (*domain.PaymentIntent, error) {
+	query := sq.Select(paymentIntentColumns...).
+		From("payment_intents").
+		Where(sq.And{
+			sq.Eq{"order_id": orderID},
+			sq.Eq{"status": domain.PaymentSucceeded},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPaymentIntent(tx.QueryRowContext(ctx, q, args...))
+}
+
+// GetIntentForUpdate implements domain.PaymentRepository.
+func (r *paymentRepository) GetIntentForUpdate(ctx context.Context, tx *sql.Tx, provider string, providerRef string) (*domain.PaymentIntent, error) {
+	query := sq.Select(paymentIntentColumns...).
+		From("payment_intents").
+		Where(sq.And{
+			sq.Eq{"provider": provider},
+			sq.Eq{"provider_ref": providerRef},
+		}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPaymentIntent(tx.QueryRowContext(ctx, q, args...))
+}
+
+// UpdateIntentStatus implements domain.PaymentRepository.
+func (r *paymentRepository) UpdateIntentStatus(ctx context.Context, tx *sql.Tx, id uuid.UUID, status domain.PaymentIntentStatus) error {
+	query := sq.Update("payment_intents").
+		SetMap(map[string]any{
+			"status":     status,
+			"updated_at": sq.Expr("now()"),
+		}).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// RecordEvent implements domain.PaymentRepository.
+func (r *paymentRepository) RecordEvent(ctx context.Context, tx *sql.Tx, provider string, event domain.PaymentEvent, intentID uuid.UUID) (bool, error) {
+	query := sq.Insert("payment_events").
+		Columns("provider", "event_id", "event_type", "payment_intent_id").
+		Values(provider, event.ID, event.Type, intentID).
+		Suffix("ON CONFLICT (provider, event_id) DO NOTHING").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func scanPaymentIntent(row interface{ Scan(dest ...any) error }) (*domain.PaymentIntent, error) {
+	var intent domain.PaymentIntent
+	if err := row.Scan(&intent.ID, &intent.OrderID, &intent.Provider, &intent.ProviderRef, &intent.Amount,
+		&intent.Status, &intent.CheckoutURL, &intent.CreatedAt, &intent.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return &intent, nil
+}
+
+func NewPaymentRepository(db pqsql.Client) domain.PaymentRepository {
+	return &paymentRepository{db: db}
+}
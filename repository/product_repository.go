@@ -15,7 +15,6 @@ type productRepository struct {
 }
 
 func (p *productRepository) RetrieveAll(ctx context.Context, limit, offset int) ([]domain.RetrieveProduct, error) {
-	var results []domain.RetrieveProduct
 	avail := sq.Select("s.product_id", "SUM(s.on_hand - s.reserved) AS available", "w.name AS warehouse_name", "w.shop_id AS shop_id").
 		From("product_stock s").
 		Join("warehouses w ON w.id = s.warehouse_id").
@@ -23,23 +22,51 @@ func (p *productRepository) RetrieveAll(ctx context.Context, limit, offset int)
 
 	availSql, availArgs, err := avail.ToSql()
 	if err != nil {
-		return results, err
+		return nil, err
 	}
 
 	list := sq.Select("p.id", "p.sku", "p.name", "COALESCE(a.available,0) AS available", "a.warehouse_name").
 		From("products p").
-		LeftJoin("("+availSql+") AS a ON a.product_id = p.id").
+		LeftJoin("("+availSql+") AS a ON a.product_id = p.id", availArgs...).
 		OrderBy("p.created_at DESC", "p.id DESC").
 		Limit(uint64(limit)).
 		Offset(uint64(offset))
 
+	return p.list(ctx, list)
+}
+
+// RetrieveByShop implements domain.ProductRepository.
+func (p *productRepository) RetrieveByShop(ctx context.Context, shopID uuid.UUID, limit, offset int) ([]domain.RetrieveProduct, error) {
+	avail := sq.Select("s.product_id", "SUM(s.on_hand - s.reserved) AS available", "w.name AS warehouse_name").
+		From("product_stock s").
+		Join("warehouses w ON w.id = s.warehouse_id").
+		Where(sq.Eq{"w.shop_id": shopID}).
+		GroupBy("s.product_id", "w.name")
+
+	availSql, availArgs, err := avail.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	list := sq.Select("p.id", "p.sku", "p.name", "a.available", "a.warehouse_name").
+		From("products p").
+		Join("("+availSql+") AS a ON a.product_id = p.id", availArgs...).
+		OrderBy("p.created_at DESC", "p.id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(sq.Dollar)
+
+	return p.list(ctx, list)
+}
+
+func (p *productRepository) list(ctx context.Context, list sq.SelectBuilder) ([]domain.RetrieveProduct, error) {
+	var results []domain.RetrieveProduct
+
 	q, args, err := list.ToSql()
 	if err != nil {
 		return results, err
 	}
 
-	args = append(args, availArgs...)
-
 	rows, err := p.db.Database().QueryContext(ctx, q, args...)
 	if err != nil {
 		return results, err
@@ -53,7 +80,7 @@ func (p *productRepository) RetrieveAll(ctx context.Context, limit, offset int)
 		}
 		results = append(results, r)
 	}
-	return results, nil
+	return results, rows.Err()
 }
 
 // Create implements domain.ProductRepository.
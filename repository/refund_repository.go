@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+)
+
+type refundRepository struct {
+	db pqsql.Client
+}
+
+// Create implements domain.RefundRepository.
+func (r *refundRepository) Create(ctx context.Context, tx *sql.Tx, refund *domain.Refund) error {
+	query := sq.Insert("order_refunds").
+		Columns("id", "order_id", "amount", "reason", "restock", "status").
+		Values(refund.ID, refund.OrderID, refund.Amount, refund.Reason, refund.Restock, refund.Status).
+		Suffix("RETURNING created_at").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if err := tx.QueryRowContext(ctx, q, args...).Scan(&refund.CreatedAt); err != nil {
+		return err
+	}
+
+	if len(refund.Items) == 0 {
+		return nil
+	}
+
+	itemsQuery := sq.Insert("order_refund_items").
+		Columns("id", "refund_id", "order_item_id", "qty", "amount", "warehouse_id").
+		PlaceholderFormat(sq.Dollar)
+
+	for _, item := range refund.Items {
+		itemsQuery = itemsQuery.Values(item.ID, refund.ID, item.OrderItemID, item.Qty, item.Amount, item.WarehouseID)
+	}
+
+	q, args, err = itemsQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// Get implements domain.RefundRepository.
+func (r *refundRepository) Get(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Refund, error) {
+	query := sq.Select("id", "order_id", "amount", "reason", "restock", "status", "created_at").
+		From("order_refunds").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var refund domain.Refund
+	if err := tx.QueryRowContext(ctx, q, args...).Scan(&refund.ID, &refund.OrderID, &refund.Amount, &refund.Reason,
+		&refund.Restock, &refund.Status, &refund.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+// UpdateStatus implements domain.RefundRepository.
+func (r *refundRepository) UpdateStatus(ctx context.Context, tx *sql.Tx, id uuid.UUID, status domain.RefundStatus) error {
+	query := sq.Update("order_refunds").
+		Set("status", status).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// RefundedQty implements domain.RefundRepository.
+func (r *refundRepository) RefundedQty(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (map[uuid.UUID]int, error) {
+	query := sq.Select("ri.order_item_id", "SUM(ri.qty)").
+		From("order_refund_items ri").
+		Join("order_refunds r ON r.id = ri.refund_id").
+		Where(sq.Eq{"r.order_id": orderID}).
+		GroupBy("ri.order_item_id").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunded := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var (
+			itemID uuid.UUID
+			qty    int
+		)
+		if err := rows.Scan(&itemID, &qty); err != nil {
+			return nil, err
+		}
+		refunded[itemID] = qty
+	}
+
+	return refunded, rows.Err()
+}
+
+// ListByOrder implements domain.RefundRepository.
+func (r *refundRepository) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]domain.Refund, error) {
+	query := sq.Select("r.id", "r.order_id", "r.amount", "r.reason", "r.restock", "r.status", "r.created_at",
+		"ri.id", "ri.order_item_id", "oi.product_id", "ri.qty", "ri.amount", "ri.warehouse_id").
+		From("order_refunds r").
+		Join("order_refund_items ri ON ri.refund_id = r.id").
+		Join("order_items oi ON oi.id = ri.order_item_id").
+		Where(sq.Eq{"r.order_id": orderID}).
+		OrderBy("r.created_at", "r.id", "ri.id").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Database().QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := []domain.Refund{}
+	for rows.Next() {
+		var (
+			refund domain.Refund
+			item   domain.RefundItem
+		)
+		if err := rows.Scan(&refund.ID, &refund.OrderID, &refund.Amount, &refund.Reason, &refund.Restock, &refund.Status, &refund.CreatedAt,
+			&item.ID, &item.OrderItemID, &item.ProductID, &item.Qty, &item.Amount, &item.WarehouseID); err != nil {
+			return nil, err
+		}
+		item.RefundID = refund.ID
+
+		if n := len(refunds); n > 0 && refunds[n-1].ID == refund.ID {
+			refunds[n-1].Items = append(refunds[n-1].Items, item)
+			continue
+		}
+
+		refund.Items = []domain.RefundItem{item}
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, rows.Err()
+}
+
+func NewRefundRepository(db pqsql.Client) domain.RefundRepository {
+	return &refundRepository{db: db}
+}
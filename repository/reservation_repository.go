@@ -42,7 +42,7 @@ func (r *reservationRepository) MarkExpired(ctx context.Context, tx *sql.Tx, id
 		Set("updated_at", time.Now()).
 		Where(sq.And{
 			sq.Eq{"id": id},
-			sq.Eq{"status": "PENDING"},
+			sq.Eq{"status": []string{"PENDING", "QUARANTINED"}},
 		}).
 		PlaceholderFormat(sq.Dollar)
 
@@ -56,38 +56,142 @@ func (r *reservationRepository) MarkExpired(ctx context.Context, tx *sql.Tx, id
 	return err
 }
 
-// PendingCountByOrder implements domain.ReservationRepository.
-func (r *reservationRepository) PendingCountByOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error) {
-	var n int
-	query := sq.Select("COUNT(*)").
+// HeldCountByOrder implements domain.ReservationRepository.
+func (r *reservationRepository) HeldCountByOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, int, error) {
+	var pending, quarantined int
+	query := sq.Select(
+		"COUNT(*) FILTER (WHERE status = 'PENDING')",
+		"COUNT(*) FILTER (WHERE status = 'QUARANTINED')",
+	).
+		From("stock_reservations").
+		Where(sq.Eq{"order_id": orderID}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = tx.QueryRowContext(ctx, q, args...).Scan(&pending, &quarantined)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return pending, quarantined, nil
+}
+
+// ListQuarantined implements domain.ReservationRepository.
+func (r *reservationRepository) ListQuarantined(ctx context.Context, limit, offset int) ([]domain.QuarantinedReservation, int, error) {
+	where := sq.Eq{"status": "QUARANTINED"}
+
+	var total int
+
+	countQuery := sq.Select("COUNT(*)").
+		From("stock_reservations").
+		Where(where).
+		PlaceholderFormat(sq.Dollar)
+
+	countSql, countArgs, err := countQuery.ToSql()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Database().QueryRowContext(ctx, countSql, countArgs...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := sq.Select("id", "order_id", "product_id", "warehouse_id", "qty", "expires_at", "release_attempts", "last_release_error", "updated_at").
+		From("stock_reservations").
+		Where(where).
+		OrderBy("updated_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Database().QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reservations := []domain.QuarantinedReservation{}
+	for rows.Next() {
+		var res domain.QuarantinedReservation
+		err := rows.Scan(&res.ID, &res.OrderID, &res.ProductID, &res.WarehouseID,
+			&res.Qty, &res.ExpiresAt, &res.ReleaseAttempts, &res.LastReleaseError, &res.UpdatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		reservations = append(reservations, res)
+	}
+
+	return reservations, total, rows.Err()
+}
+
+// GetQuarantinedForUpdate implements domain.ReservationRepository.
+func (r *reservationRepository) GetQuarantinedForUpdate(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Reservation, error) {
+	var reservation domain.Reservation
+	query := sq.Select("id", "order_id", "product_id", "warehouse_id", "qty", "status", "expires_at", "release_attempts").
 		From("stock_reservations").
 		Where(sq.And{
-			sq.Eq{"order_id": orderID},
-			sq.Eq{"status": "PENDING"},
+			sq.Eq{"id": id},
+			sq.Eq{"status": "QUARANTINED"},
 		}).
+		Suffix("FOR UPDATE").
 		PlaceholderFormat(sq.Dollar)
 
 	q, args, err := query.ToSql()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	err = tx.QueryRowContext(ctx, q, args...).Scan(&n)
+	err = tx.QueryRowContext(ctx, q, args...).
+		Scan(&reservation.ID, &reservation.OrderID, &reservation.ProductID, &reservation.WarehouseID,
+			&reservation.Qty, &reservation.Status, &reservation.ExpiresAt, &reservation.ReleaseAttempts)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	return n, nil
+	return &reservation, nil
+}
+
+// RequeueQuarantined implements domain.ReservationRepository.
+func (r *reservationRepository) RequeueQuarantined(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	query := sq.Update("stock_reservations").
+		Set("status", "PENDING").
+		Set("release_attempts", 0).
+		Set("last_release_error", nil).
+		Set("updated_at", time.Now()).
+		Where(sq.And{
+			sq.Eq{"id": id},
+			sq.Eq{"status": "QUARANTINED"},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
 }
 
 // PickExpiredForUpdate implements domain.ReservationRepository.
 func (r *reservationRepository) PickExpiredForUpdate(ctx context.Context, tx *sql.Tx, limit int) ([]domain.Reservation, error) {
-	query := sq.Select("id", "order_id", "product_id", "warehouse_id", "qty", "status", "expires_at").
+	query := sq.Select("id", "order_id", "product_id", "warehouse_id", "qty", "status", "expires_at", "release_attempts").
 		From("stock_reservations").
 		Where(sq.And{
 			sq.Eq{"status": "PENDING"},
 			sq.Expr("expires_at <= now()"),
 		}).
+		OrderBy("release_attempts", "expires_at").
 		Limit(uint64(limit)).
 		Suffix("FOR UPDATE SKIP LOCKED").
 		PlaceholderFormat(sq.Dollar)
@@ -107,7 +211,7 @@ func (r *reservationRepository) PickExpiredForUpdate(ctx context.Context, tx *sq
 	for rows.Next() {
 		var res domain.Reservation
 		err := rows.Scan(&res.ID, &res.OrderID, &res.ProductID, &res.WarehouseID,
-			&res.Qty, &res.Status, &res.ExpiresAt)
+			&res.Qty, &res.Status, &res.ExpiresAt, &res.ReleaseAttempts)
 
 		if err != nil {
 			return nil, err
@@ -119,6 +223,36 @@ func (r *reservationRepository) PickExpiredForUpdate(ctx context.Context, tx *sq
 	return reservations, rows.Err()
 }
 
+// RecordReleaseFailure implements domain.ReservationRepository.
+func (r *reservationRepository) RecordReleaseFailure(ctx context.Context, tx *sql.Tx, id uuid.UUID, reason string, maxAttempts int) (*domain.Reservation, error) {
+	var reservation domain.Reservation
+	query := sq.Update("stock_reservations").
+		Set("release_attempts", sq.Expr("release_attempts + 1")).
+		Set("last_release_error", reason).
+		Set("status", sq.Expr("CASE WHEN release_attempts + 1 >= ? THEN 'QUARANTINED'::reservation_status ELSE status END", maxAttempts)).
+		Set("updated_at", time.Now()).
+		Where(sq.And{
+			sq.Eq{"id": id},
+			sq.Eq{"status": "PENDING"},
+		}).
+		Suffix("RETURNING id, order_id, product_id, warehouse_id, qty, status, expires_at, release_attempts").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, q, args...).
+		Scan(&reservation.ID, &reservation.OrderID, &reservation.ProductID, &reservation.WarehouseID,
+			&reservation.Qty, &reservation.Status, &reservation.ExpiresAt, &reservation.ReleaseAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
 // Retrieve implements domain.ReservationRepository.
 func (r *reservationRepository) Retrieve(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Reservation, error) {
 	var reservation domain.Reservation
@@ -143,7 +277,7 @@ func (r *reservationRepository) Retrieve(ctx context.Context, tx *sql.Tx, id uui
 }
 
 // MarkCommitted implements domain.ReservationRepository.
-func (r *reservationRepository) MarkCommitted(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error {
+func (r *reservationRepository) MarkCommitted(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error) {
 	query := sq.Update("stock_reservations").
 		Set("status", "COMMITTED").
 		Set("updated_at", time.Now()).
@@ -155,15 +289,24 @@ func (r *reservationRepository) MarkCommitted(ctx context.Context, tx *sql.Tx, o
 
 	q, args, err := query.ToSql()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	_, err = tx.ExecContext(ctx, q, args...)
-	return err
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
 }
 
 // MarkReleased implements domain.ReservationRepository.
-func (r *reservationRepository) MarkReleased(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error {
+func (r *reservationRepository) MarkReleased(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error) {
 	query := sq.Update("stock_reservations").
 		Set("status", "RELEASED").
 		Set("updated_at", time.Now()).
@@ -173,6 +316,72 @@ func (r *reservationRepository) MarkReleased(ctx context.Context, tx *sql.Tx, or
 		}).
 		PlaceholderFormat(sq.Dollar)
 
+	q, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// LockPendingByOrder implements domain.ReservationRepository.
+func (r *reservationRepository) LockPendingByOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]domain.Reservation, error) {
+	query := sq.Select("id", "order_id", "product_id", "warehouse_id", "qty", "status", "expires_at").
+		From("stock_reservations").
+		Where(sq.And{
+			sq.Eq{"order_id": orderID},
+			sq.Eq{"status": "PENDING"},
+		}).
+		OrderBy("id").
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []domain.Reservation
+	for rows.Next() {
+		var res domain.Reservation
+		err := rows.Scan(&res.ID, &res.OrderID, &res.ProductID, &res.WarehouseID,
+			&res.Qty, &res.Status, &res.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, res)
+	}
+
+	return reservations, rows.Err()
+}
+
+// ExtendPending implements domain.ReservationRepository.
+func (r *reservationRepository) ExtendPending(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, expiresAt time.Time) error {
+	query := sq.Update("stock_reservations").
+		Set("expires_at", expiresAt).
+		Set("updated_at", time.Now()).
+		Where(sq.And{
+			sq.Eq{"order_id": orderID},
+			sq.Eq{"status": "PENDING"},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
 	q, args, err := query.ToSql()
 	if err != nil {
 		return err
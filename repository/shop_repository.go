@@ -19,8 +19,8 @@ func (s *shopRepository) Create(ctx context.Context, shop *domain.Shop) (uuid.UU
 	var id uuid.UUID
 
 	query := sq.Insert("shops").
-		Columns("name", "created_at").
-		Values(&shop.Name, time.Now()).
+		Columns("name", "owner_id", "created_at").
+		Values(&shop.Name, shop.OwnerID, time.Now()).
 		Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
 
 	q, args, err := query.ToSql()
@@ -54,8 +54,9 @@ func (s *shopRepository) Delete(ctx context.Context, id uuid.UUID) error {
 // Retrieve implements domain.ShopRepository.
 func (s *shopRepository) Retrieve(ctx context.Context, id uuid.UUID) (*domain.Shop, error) {
 	var shop domain.Shop
+	var ownerID uuid.NullUUID
 
-	query := sq.Select("id", "name", "created_at").
+	query := sq.Select("id", "name", "owner_id", "created_at").
 		From("shops").
 		Where(sq.Eq{"id": id}).
 		PlaceholderFormat(sq.Dollar)
@@ -65,10 +66,14 @@ func (s *shopRepository) Retrieve(ctx context.Context, id uuid.UUID) (*domain.Sh
 		return nil, err
 	}
 
-	if err := s.db.Database().QueryRowContext(ctx, q, args...).Scan(&shop.ID, &shop.Name, &shop.CreatedAt); err != nil {
+	if err := s.db.Database().QueryRowContext(ctx, q, args...).Scan(&shop.ID, &shop.Name, &ownerID, &shop.CreatedAt); err != nil {
 		return nil, err
 	}
 
+	if ownerID.Valid {
+		shop.OwnerID = &ownerID.UUID
+	}
+
 	return &shop, nil
 }
 
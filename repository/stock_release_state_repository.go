@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+)
+
+type stockReleaseStateRepository struct {
+	db pqsql.Client
+}
+
+// Get implements domain.StockReleaseStateRepository.
+func (r *stockReleaseStateRepository) Get(ctx context.Context) (*domain.StockReleaseState, error) {
+	query := sq.Select("paused", "last_run_at", "last_error", "last_released", "total_released").
+		From("stock_release_state").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var state domain.StockReleaseState
+	if err := r.db.Database().QueryRowContext(ctx, q, args...).Scan(
+		&state.Paused,
+		&state.LastRunAt,
+		&state.LastError,
+		&state.LastReleased,
+		&state.TotalReleased,
+	); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// SetPaused implements domain.StockReleaseStateRepository.
+func (r *stockReleaseStateRepository) SetPaused(ctx context.Context, paused bool) (bool, error) {
+	query := sq.Update("stock_release_state").
+		Set("paused", paused).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.NotEq{"paused": paused}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.db.Database().ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RecordRun implements domain.StockReleaseStateRepository.
+func (r *stockReleaseStateRepository) RecordRun(ctx context.Context, released int, lastError *string) error {
+	query := sq.Update("stock_release_state").
+		Set("last_run_at", sq.Expr("now()")).
+		Set("last_error", lastError).
+		Set("last_released", released).
+		Set("total_released", sq.Expr("total_released + ?", released)).
+		Set("updated_at", sq.Expr("now()")).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Database().ExecContext(ctx, q, args...)
+
+	return err
+}
+
+func NewStockReleaseStateRepository(db pqsql.Client) domain.StockReleaseStateRepository {
+	return &stockReleaseStateRepository{db: db}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/encryption-pii/crypto/types"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+)
+
+type twoFactorRepository struct {
+	db pqsql.Client
+}
+
+// Get implements domain.TwoFactorRepository.
+// Users that never started enrollment have no secret and yield sql.ErrNoRows.
+func (r *twoFactorRepository) Get(ctx context.Context, tx *sql.Tx, userID uuid.UUID, fn func(data *domain.TwoFactor) error) (*domain.TwoFactor, error) {
+	var tf domain.TwoFactor
+	var secret any
+
+	query := sq.Select("id", "totp_secret", "totp_key_version", "totp_enabled_at", "totp_last_step").
+		From("users").
+		Where(sq.And{
+			sq.Eq{"id": userID},
+			sq.NotEq{"totp_secret": nil},
+		}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, q, args...).Scan(&tf.UserID, &secret, &tf.KeyVersion, &tf.EnabledAt, &tf.LastStep)
+	if err != nil {
+		return nil, err
+	}
+
+	// fn runs after the key version is known so it can pick the matching decryption key.
+	if fn != nil {
+		if err := fn(&tf); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tf.Secret.Scan(secret); err != nil {
+		return nil, err
+	}
+
+	return &tf, nil
+}
+
+// SaveSecret implements domain.TwoFactorRepository.
+func (r *twoFactorRepository) SaveSecret(ctx context.Context, tx *sql.Tx, userID uuid.UUID, secret types.AESCipher, keyVersion int) error {
+	return r.update(ctx, tx, userID, map[string]any{
+		"totp_secret":      secret,
+		"totp_key_version": keyVersion,
+		"totp_enabled_at":  nil,
+		"totp_last_step":   0,
+	})
+}
+
+// Enable implements domain.TwoFactorRepository.
+func (r *twoFactorRepository) Enable(ctx context.Context, tx *sql.Tx, userID uuid.UUID, step int64) error {
+	return r.update(ctx, tx, userID, map[string]any{
+		"totp_enabled_at": sq.Expr("now()"),
+		"totp_last_step":  step,
+	})
+}
+
+// Disable implements domain.TwoFactorRepository.
+func (r *twoFactorRepository) Disable(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	if err := r.update(ctx, tx, userID, map[string]any{
+		"totp_secret":     nil,
+		"totp_enabled_at": nil,
+		"totp_last_step":  0,
+	}); err != nil {
+		return err
+	}
+
+	return r.deleteRecoveryCodes(ctx, tx, userID)
+}
+
+// UpdateLastStep implements domain.TwoFactorRepository.
+func (r *twoFactorRepository) UpdateLastStep(ctx context.Context, tx *sql.Tx, userID uuid.UUID, step int64) error {
+	return r.update(ctx, tx, userID, map[string]any{
+		"totp_last_step": step,
+	})
+}
+
+// ReplaceRecoveryCodes implements domain.TwoFactorRepository.
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codeHashes []string) error {
+	if err := r.deleteRecoveryCodes(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	query := sq.Insert("user_recovery_codes").
+		Columns("user_id", "code_hash").
+		PlaceholderFormat(sq.Dollar)
+
+	for _, hash := range codeHashes {
+		query = query.Values(userID, hash)
+	}
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
+// UseRecoveryCode implements domain.TwoFactorRepository.
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codeHash string) (bool, error) {
+	query := sq.Update("user_recovery_codes").
+		Set("used_at", sq.Expr("now()")).
+		Where(sq.And{
+			sq.Eq{"user_id": userID},
+			sq.Eq{"code_hash": codeHash},
+			sq.Eq{"used_at": nil},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *twoFactorRepository) update(ctx context.Context, tx *sql.Tx, userID uuid.UUID, values map[string]any) error {
+	query := sq.Update("users").
+		SetMap(values).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
+func (r *twoFactorRepository) deleteRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	query := sq.Delete("user_recovery_codes").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
+func NewTwoFactorRepository(db pqsql.Client) domain.TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
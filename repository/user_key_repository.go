@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+)
+
+type userKeyRepository struct {
+	db pqsql.Client
+}
+
+// stale matches live users with at least one value written under another key version.
+func stale(keyVersion int) sq.Sqlizer {
+	return sq.And{
+		sq.Eq{"deleted_at": nil},
+		sq.Or{
+			sq.NotEq{"email_key_version": keyVersion},
+			sq.NotEq{"phone_key_version": keyVersion},
+			sq.And{
+				sq.NotEq{"totp_secret": nil},
+				sq.NotEq{"totp_key_version": keyVersion},
+			},
+		},
+	}
+}
+
+// ListStale implements domain.UserKeyRepository.
+// Rows are locked and rows already locked by live traffic are skipped, so the
+// re-key can run while the service is online; skipped rows are picked up on the next pass.
+func (r *userKeyRepository) ListStale(ctx context.Context, tx *sql.Tx, keyVersion int, afterID uuid.UUID, limit int) ([]domain.UserCiphertexts, error) {
+	query := sq.Select("id", "email", "email_key_version", "phone", "phone_key_version", "totp_secret", "totp_key_version").
+		From("users").
+		Where(sq.And{
+			sq.Gt{"id": afterID},
+			stale(keyVersion),
+		}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.UserCiphertexts
+	for rows.Next() {
+		var u domain.UserCiphertexts
+		if err := rows.Scan(&u.ID, &u.Email, &u.EmailKeyVersion, &u.Phone, &u.PhoneKeyVersion, &u.TOTPSecret, &u.TOTPKeyVersion); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+// Rekey implements domain.UserKeyRepository.
+func (r *userKeyRepository) Rekey(ctx context.Context, tx *sql.Tx, user domain.RekeyedUser) error {
+	values := map[string]any{
+		"email_key_version": user.KeyVersion,
+		"phone_key_version": user.KeyVersion,
+		"updated_at":        sq.Expr("now()"),
+	}
+
+	if user.Email != nil {
+		values["email"] = *user.Email
+		values["email_bidx"] = user.EmailBidx
+	}
+
+	if user.Phone != nil {
+		values["phone"] = *user.Phone
+		values["phone_bidx"] = user.PhoneBidx
+	}
+
+	if user.TOTPSecret != nil {
+		values["totp_secret"] = *user.TOTPSecret
+		values["totp_key_version"] = user.KeyVersion
+	}
+
+	query := sq.Update("users").
+		SetMap(values).
+		Where(sq.Eq{"id": user.ID}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
+// CountStale implements domain.UserKeyRepository.
+func (r *userKeyRepository) CountStale(ctx context.Context, keyVersion int) (int, error) {
+	query := sq.Select("COUNT(*)").
+		From("users").
+		Where(stale(keyVersion)).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.db.Database().QueryRowContext(ctx, q, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func NewUserKeyRepository(db pqsql.Client) domain.UserKeyRepository {
+	return &userKeyRepository{db: db}
+}
@@ -6,8 +6,12 @@ import (
 	"errors"
 	"fmt"
 
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/encryption-pii/crypto/types"
 	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type useRepository struct {
@@ -15,32 +19,34 @@ type useRepository struct {
 }
 
 // GetMailOrPhone implements domain.UserRepository.
-func (ur *useRepository) GetMailOrPhone(ctx context.Context, email_bidx string, phone_bidx string, fn func(data *domain.User)) (*domain.User, error) {
+func (ur *useRepository) GetMailOrPhone(ctx context.Context, email_bidx string, phone_bidx string, fn func(data *domain.User) error) (*domain.User, error) {
 	var user domain.User
-	if fn != nil {
-		fn(&user)
-	}
+	var email, phone any
 
-	query := `SELECT id, email, phone, password_hash FROM users WHERE email_bidx = $1 OR phone_bidx = $2 LIMIT 1`
+	query := `SELECT id, email, phone, email_key_version, phone_key_version, password_hash, totp_enabled_at FROM users WHERE email_bidx = $1 OR phone_bidx = $2 LIMIT 1`
 	err := ur.database.Database().QueryRowContext(ctx, query, email_bidx, phone_bidx).Scan(
-		&user.ID, &user.Email, &user.Phone, &user.PasswordHash,
+		&user.ID, &email, &phone, &user.EmailKeyVersion, &user.PhoneKeyVersion, &user.PasswordHash, &user.TOTPEnabledAt,
 	)
 
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
 	case err != nil:
 		return nil, err
 	}
 
+	if err := bindCiphers(&user, fn, email, phone); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
 func (ur *useRepository) CreateUser(ctx context.Context, user *domain.User) error {
-	query := `INSERT INTO users (email, email_bidx, phone, phone_bidx, password_hash) VALUES ($1, $2, $3, $4, $5)`
+	query := `INSERT INTO users (email, email_bidx, phone, phone_bidx, password_hash, email_key_version, phone_key_version) VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
 	_, err := ur.database.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
-		_, err := tx.ExecContext(ctx, query, user.Email, user.EmailBidx, user.Phone, user.PhoneBidx, user.PasswordHash)
+		_, err := tx.ExecContext(ctx, query, user.Email, user.EmailBidx, user.Phone, user.PhoneBidx, user.PasswordHash, user.EmailKeyVersion, user.PhoneKeyVersion)
 		return nil, err
 	})
 
@@ -52,43 +58,259 @@ func (ur *useRepository) CreateUser(ctx context.Context, user *domain.User) erro
 	return nil
 }
 
-func (ur *useRepository) GetUserByPhone(ctx context.Context, phone_bidx string, fn func(data *domain.User)) (*domain.User, error) {
+func (ur *useRepository) GetUserByPhone(ctx context.Context, phone_bidx string, fn func(data *domain.User) error) (*domain.User, error) {
 	var user domain.User
+	var email, phone any
 
-	if fn != nil {
-		fn(&user)
-	}
-
-	query := `SELECT id, email, phone FROM users WHERE phone_bidx = $1 LIMIT 1`
-	err := ur.database.Database().QueryRowContext(ctx, query, nil, &domain.User{}, phone_bidx).Scan(
-		&user.ID, &user.Email, &user.Phone, &user.Phone,
+	query := `SELECT id, email, phone, email_key_version, phone_key_version FROM users WHERE phone_bidx = $1 LIMIT 1`
+	err := ur.database.Database().QueryRowContext(ctx, query, phone_bidx).Scan(
+		&user.ID, &email, &phone, &user.EmailKeyVersion, &user.PhoneKeyVersion,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := bindCiphers(&user, fn, email, phone); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
-func (ur *useRepository) GetUserByEmail(ctx context.Context, email_bidx string, fn func(data *domain.User)) (*domain.User, error) {
+func (ur *useRepository) GetUserByEmail(ctx context.Context, email_bidx string, fn func(data *domain.User) error) (*domain.User, error) {
 	var existingUser domain.User
+	var email, phone any
 
-	if fn != nil {
-		fn(&existingUser)
-	}
-
-	query := `SELECT id, email, phone FROM users WHERE email_bidx = $1 LIMIT 1`
+	query := `SELECT id, email, phone, email_key_version, phone_key_version FROM users WHERE email_bidx = $1 LIMIT 1`
 	err := ur.database.Database().QueryRowContext(ctx, query, email_bidx).Scan(
-		&existingUser.ID, &existingUser.Email, &existingUser.Phone,
+		&existingUser.ID, &email, &phone, &existingUser.EmailKeyVersion, &existingUser.PhoneKeyVersion,
 	)
 	if err != nil {
 		fmt.Println("Error fetching user by email:", err)
 		return nil, err
 	}
 
+	if err := bindCiphers(&existingUser, fn, email, phone); err != nil {
+		return nil, err
+	}
+
 	return &existingUser, nil
 }
 
+// GetByID implements domain.UserRepository.
+func (ur *useRepository) GetByID(ctx context.Context, id uuid.UUID, fn func(data *domain.User) error) (*domain.User, error) {
+	var user domain.User
+	var email, phone any
+
+	query := sq.Select("id", "email", "phone", "email_key_version", "phone_key_version", "email_bidx", "phone_bidx", "password_hash", "email_verified_at", "phone_verified_at", "totp_enabled_at").
+		From("users").
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var emailBidx, phoneBidx sql.NullString
+	err = ur.database.Database().QueryRowContext(ctx, q, args...).Scan(
+		&user.ID, &email, &phone, &user.EmailKeyVersion, &user.PhoneKeyVersion, &emailBidx, &phoneBidx, &user.PasswordHash, &user.EmailVerifiedAt, &user.PhoneVerifiedAt, &user.TOTPEnabledAt,
+	)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, domain.ErrUserNotFound
+	case err != nil:
+		return nil, err
+	}
+
+	if err := bindCiphers(&user, fn, email, phone); err != nil {
+		return nil, err
+	}
+
+	user.EmailBidx = emailBidx.String
+	user.PhoneBidx = phoneBidx.String
+
+	return &user, nil
+}
+
+// IsActive implements domain.UserRepository.
+func (ur *useRepository) IsActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	q, args, err := sq.Select("1").
+		From("users").
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var active bool
+	if err := ur.database.Database().QueryRowContext(ctx, q, args...).Scan(&active); err != nil {
+		return false, err
+	}
+
+	return active, nil
+}
+
+// MarkEmailVerified implements domain.UserRepository.
+func (ur *useRepository) MarkEmailVerified(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	return ur.touch(ctx, tx, id, "email_verified_at")
+}
+
+// MarkPhoneVerified implements domain.UserRepository.
+func (ur *useRepository) MarkPhoneVerified(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	return ur.touch(ctx, tx, id, "phone_verified_at")
+}
+
+// UpdatePassword implements domain.UserRepository.
+func (ur *useRepository) UpdatePassword(ctx context.Context, tx *sql.Tx, id uuid.UUID, passwordHash string) error {
+	query := sq.Update("users").
+		Set("password_hash", passwordHash).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
+// UpdateEmail implements domain.UserRepository.
+// The new address starts unverified.
+func (ur *useRepository) UpdateEmail(ctx context.Context, tx *sql.Tx, id uuid.UUID, email types.AESCipher, emailBidx string, keyVersion int) error {
+	return ur.updateIdentifier(ctx, tx, id, "email", email, emailBidx, keyVersion)
+}
+
+// UpdatePhone implements domain.UserRepository.
+// The new number starts unverified.
+func (ur *useRepository) UpdatePhone(ctx context.Context, tx *sql.Tx, id uuid.UUID, phone types.AESCipher, phoneBidx string, keyVersion int) error {
+	return ur.updateIdentifier(ctx, tx, id, "phone", phone, phoneBidx, keyVersion)
+}
+
+// updateIdentifier writes the ciphertext, its blind index and their key version in a single statement so they never drift apart.
+func (ur *useRepository) updateIdentifier(ctx context.Context, tx *sql.Tx, id uuid.UUID, column string, value types.AESCipher, bidx string, keyVersion int) error {
+	query := sq.Update("users").
+		Set(column, value).
+		Set(column+"_bidx", bidx).
+		Set(column+"_key_version", keyVersion).
+		Set(column+"_verified_at", nil).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	if isUniqueViolation(err) {
+		return domain.ErrIdentifierTaken
+	}
+
+	return err
+}
+
+// Shred implements domain.UserRepository.
+// It erases every piece of PII and credential material but keeps the row, so orders and
+// movements still reference a valid, now pseudonymous, user ID.
+func (ur *useRepository) Shred(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	query := sq.Update("users").
+		SetMap(map[string]any{
+			"email":             nil,
+			"email_bidx":        nil,
+			"phone":             nil,
+			"phone_bidx":        nil,
+			"password_hash":     "",
+			"email_verified_at": nil,
+			"phone_verified_at": nil,
+			"totp_secret":       nil,
+			"totp_enabled_at":   nil,
+			"totp_last_step":    0,
+			"deleted_at":        sq.Expr("now()"),
+			"updated_at":        sq.Expr("now()"),
+		}).
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	for _, table := range []string{"user_tokens", "user_recovery_codes"} {
+		q, args, err := sq.Delete(table).Where(sq.Eq{"user_id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ur *useRepository) touch(ctx context.Context, tx *sql.Tx, id uuid.UUID, column string) error {
+	query := sq.Update("users").
+		Set(column, sq.Expr("now()")).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// bindCiphers runs fn once the key versions are known, so it can prepare ciphers for the
+// matching decryption key, then feeds them the raw ciphertexts.
+func bindCiphers(user *domain.User, fn func(data *domain.User) error, email, phone any) error {
+	if fn != nil {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	if err := user.Email.Scan(email); err != nil {
+		return err
+	}
+
+	return user.Phone.Scan(phone)
+}
+
 func NewUserRepository(db pqsql.Client) domain.UserRepository {
 	return &useRepository{
 		database: db,
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+)
+
+type userTokenRepository struct {
+	db pqsql.Client
+}
+
+// Create implements domain.UserTokenRepository.
+func (u *userTokenRepository) Create(ctx context.Context, tx *sql.Tx, token *domain.UserToken) error {
+	query := sq.Insert("user_tokens").
+		Columns("id", "user_id", "purpose", "token_hash", "expires_at").
+		Values(token.ID, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
+// RevokeActive implements domain.UserTokenRepository.
+func (u *userTokenRepository) RevokeActive(ctx context.Context, tx *sql.Tx, userID uuid.UUID, purpose domain.TokenPurpose) error {
+	query := sq.Update("user_tokens").
+		Set("used_at", sq.Expr("now()")).
+		Where(sq.And{
+			sq.Eq{"user_id": userID},
+			sq.Eq{"purpose": purpose},
+			sq.Eq{"used_at": nil},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
+// FindActiveByHash implements domain.UserTokenRepository.
+func (u *userTokenRepository) FindActiveByHash(ctx context.Context, tx *sql.Tx, purpose domain.TokenPurpose, tokenHash string) (*domain.UserToken, error) {
+	return u.findActive(ctx, tx, sq.And{
+		sq.Eq{"purpose": purpose},
+		sq.Eq{"token_hash": tokenHash},
+	})
+}
+
+// FindActiveByUser implements domain.UserTokenRepository.
+func (u *userTokenRepository) FindActiveByUser(ctx context.Context, tx *sql.Tx, userID uuid.UUID, purpose domain.TokenPurpose) (*domain.UserToken, error) {
+	return u.findActive(ctx, tx, sq.And{
+		sq.Eq{"user_id": userID},
+		sq.Eq{"purpose": purpose},
+	})
+}
+
+// IncrementAttempts implements domain.UserTokenRepository.
+func (u *userTokenRepository) IncrementAttempts(ctx context.Context, tx *sql.Tx, id uuid.UUID) (int, error) {
+	query := sq.Update("user_tokens").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING attempts").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var attempts int
+	if err := tx.QueryRowContext(ctx, q, args...).Scan(&attempts); err != nil {
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// MarkUsed implements domain.UserTokenRepository.
+func (u *userTokenRepository) MarkUsed(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	query := sq.Update("user_tokens").
+		Set("used_at", sq.Expr("now()")).
+		Where(sq.And{
+			sq.Eq{"id": id},
+			sq.Eq{"used_at": nil},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("token already used")
+	}
+
+	return nil
+}
+
+func (u *userTokenRepository) findActive(ctx context.Context, tx *sql.Tx, where sq.Sqlizer) (*domain.UserToken, error) {
+	query := sq.Select("id", "user_id", "purpose", "token_hash", "attempts", "expires_at", "used_at", "created_at").
+		From("user_tokens").
+		Where(sq.And{
+			where,
+			sq.Eq{"used_at": nil},
+			sq.Expr("expires_at > now()"),
+		}).
+		OrderBy("created_at DESC").
+		Limit(1).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var t domain.UserToken
+	err = tx.QueryRowContext(ctx, q, args...).
+		Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.Attempts, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func NewUserTokenRepository(db pqsql.Client) domain.UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/encryption-pii/crypto/types"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+)
+
+type webhookKeyRepository struct {
+	db pqsql.Client
+}
+
+// ListStale implements domain.WebhookKeyRepository.
+// Like the user re-key, rows locked elsewhere are skipped and picked up on the next pass.
+func (r *webhookKeyRepository) ListStale(ctx context.Context, tx *sql.Tx, keyVersion int, afterID uuid.UUID, limit int) ([]domain.WebhookSecretCiphertext, error) {
+	query := sq.Select("id", "secret", "secret_key_version").
+		From("webhook_subscriptions").
+		Where(sq.And{
+			sq.Gt{"id": afterID},
+			sq.NotEq{"secret_key_version": keyVersion},
+		}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []domain.WebhookSecretCiphertext
+	for rows.Next() {
+		var s domain.WebhookSecretCiphertext
+		if err := rows.Scan(&s.ID, &s.Secret, &s.KeyVersion); err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+
+	return subs, rows.Err()
+}
+
+// Rekey implements domain.WebhookKeyRepository.
+func (r *webhookKeyRepository) Rekey(ctx context.Context, tx *sql.Tx, id uuid.UUID, secret types.AESCipher, keyVersion int) error {
+	query := sq.Update("webhook_subscriptions").
+		Set("secret", secret).
+		Set("secret_key_version", keyVersion).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+	return err
+}
+
+// CountStale implements domain.WebhookKeyRepository.
+func (r *webhookKeyRepository) CountStale(ctx context.Context, keyVersion int) (int, error) {
+	query := sq.Select("COUNT(*)").
+		From("webhook_subscriptions").
+		Where(sq.NotEq{"secret_key_version": keyVersion}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.db.Database().QueryRowContext(ctx, q, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func NewWebhookKeyRepository(db pqsql.Client) domain.WebhookKeyRepository {
+	return &webhookKeyRepository{db: db}
+}
@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type webhookRepository struct {
+	db pqsql.Client
+}
+
+var (
+	webhookColumns         = []string{"id", "shop_id", "user_id", "url", "event_types", "created_at"}
+	webhookDeliveryColumns = []string{"d.id", "d.subscription_id", "d.event_id", "d.event_type", "d.payload", "d.status", "d.attempts", "d.next_attempt_at", "d.last_status_code", "d.last_error", "d.delivered_at", "d.created_at"}
+)
+
+// Create implements domain.WebhookRepository.
+func (r *webhookRepository) Create(ctx context.Context, tx *sql.Tx, sub *domain.WebhookSubscription) error {
+	query := sq.Insert("webhook_subscriptions").
+		Columns("id", "shop_id", "user_id", "url", "secret", "secret_key_version", "event_types").
+		Values(sub.ID, sub.ShopID, sub.UserID, sub.URL, sub.Secret, sub.SecretKeyVersion, pq.Array(eventTypeStrings(sub.EventTypes))).
+		Suffix("RETURNING created_at").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRowContext(ctx, q, args...).Scan(&sub.CreatedAt)
+}
+
+// Get implements domain.WebhookRepository.
+func (r *webhookRepository) Get(ctx context.Context, shopID uuid.UUID, userID uuid.UUID, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	query := sq.Select(webhookColumns...).
+		From("webhook_subscriptions").
+		Where(sq.And{
+			sq.Eq{"id": id},
+			sq.Eq{"shop_id": shopID},
+			sq.Eq{"user_id": userID},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWebhook(r.db.Database().QueryRowContext(ctx, q, args...))
+}
+
+// ListByShop implements domain.WebhookRepository.
+func (r *webhookRepository) ListByShop(ctx context.Context, shopID uuid.UUID, userID uuid.UUID) ([]domain.WebhookSubscription, error) {
+	query := sq.Select(webhookColumns...).
+		From("webhook_subscriptions").
+		Where(sq.And{
+			sq.Eq{"shop_id": shopID},
+			sq.Eq{"user_id": userID},
+		}).
+		OrderBy("created_at DESC").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Database().QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []domain.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// Delete implements domain.WebhookRepository. Its delivery log is removed with it.
+func (r *webhookRepository) Delete(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	query := sq.Delete("webhook_subscriptions").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// DeleteByUser implements domain.WebhookRepository.
+func (r *webhookRepository) DeleteByUser(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	query := sq.Delete("webhook_subscriptions").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// SubscriberIDs implements domain.WebhookRepository.
+func (r *webhookRepository) SubscriberIDs(ctx context.Context, shopID uuid.UUID, eventType domain.EventType) ([]uuid.UUID, error) {
+	query := sq.Select("id").
+		From("webhook_subscriptions").
+		Where(sq.And{
+			sq.Eq{"shop_id": shopID},
+			sq.Expr("? = ANY(event_types)", string(eventType)),
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Database().QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// EnqueueDelivery implements domain.WebhookRepository.
+func (r *webhookRepository) EnqueueDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := sq.Insert("webhook_deliveries").
+		Columns("id", "subscription_id", "event_id", "event_type", "payload").
+		Values(delivery.ID, delivery.SubscriptionID, delivery.EventID, string(delivery.EventType), []byte(delivery.Payload)).
+		Suffix("ON CONFLICT (subscription_id, event_id) DO NOTHING").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Database().ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// ListDeliveries implements domain.WebhookRepository.
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]domain.WebhookDelivery, int, error) {
+	var total int
+
+	countQuery := sq.Select("COUNT(*)").
+		From("webhook_deliveries").
+		Where(sq.Eq{"subscription_id": subscriptionID}).
+		PlaceholderFormat(sq.Dollar)
+
+	countSql, countArgs, err := countQuery.ToSql()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Database().QueryRowContext(ctx, countSql, countArgs...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := sq.Select(webhookDeliveryColumns...).
+		From("webhook_deliveries d").
+		Where(sq.Eq{"d.subscription_id": subscriptionID}).
+		OrderBy("d.created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Database().QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		if err := rows.Scan(deliveryFields(&d)...); err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, total, rows.Err()
+}
+
+// ClaimDueDeliveries implements domain.WebhookRepository.
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, tx *sql.Tx, limit int, lease time.Duration, fn func(dispatch *domain.WebhookDispatch) error) ([]domain.WebhookDispatch, error) {
+	query := sq.Select(append(webhookDeliveryColumns, "s.url", "s.secret", "s.secret_key_version")...).
+		From("webhook_deliveries d").
+		Join("webhook_subscriptions s ON s.id = d.subscription_id").
+		Where(sq.And{
+			sq.Eq{"d.status": domain.WebhookDeliveryPending},
+			sq.Expr("d.next_attempt_at <= now()"),
+		}).
+		OrderBy("d.next_attempt_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE OF d SKIP LOCKED").
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dispatches []domain.WebhookDispatch
+	for rows.Next() {
+		var d domain.WebhookDispatch
+		var secret any
+
+		if err := rows.Scan(append(deliveryFields(&d.WebhookDelivery), &d.URL, &secret, &d.SecretKeyVersion)...); err != nil {
+			return nil, err
+		}
+
+		if fn != nil {
+			if err := fn(&d); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := d.Secret.Scan(secret); err != nil {
+			return nil, err
+		}
+
+		dispatches = append(dispatches, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(dispatches) == 0 {
+		return dispatches, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(dispatches))
+	for _, d := range dispatches {
+		ids = append(ids, d.ID)
+	}
+
+	q, args, err = sq.Update("webhook_deliveries").
+		Set("next_attempt_at", sq.Expr("now() + make_interval(secs => ?)", lease.Seconds())).
+		Where(sq.Eq{"id": ids}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+		return nil, err
+	}
+
+	return dispatches, nil
+}
+
+// MarkSucceeded implements domain.WebhookRepository.
+func (r *webhookRepository) MarkSucceeded(ctx context.Context, tx *sql.Tx, id uuid.UUID, statusCode int) error {
+	return r.updateDelivery(ctx, tx, id, map[string]any{
+		"status":           domain.WebhookDeliverySucceeded,
+		"attempts":         sq.Expr("attempts + 1"),
+		"last_status_code": statusCode,
+		"last_error":       nil,
+		"delivered_at":     sq.Expr("now()"),
+	})
+}
+
+// MarkRetry implements domain.WebhookRepository.
+func (r *webhookRepository) MarkRetry(ctx context.Context, tx *sql.Tx, id uuid.UUID, statusCode *int, lastError string, nextAttemptAt time.Time) error {
+	return r.updateDelivery(ctx, tx, id, map[string]any{
+		"attempts":         sq.Expr("attempts + 1"),
+		"last_status_code": statusCode,
+		"last_error":       lastError,
+		"next_attempt_at":  nextAttemptAt,
+	})
+}
+
+// MarkDead implements domain.WebhookRepository.
+func (r *webhookRepository) MarkDead(ctx context.Context, tx *sql.Tx, id uuid.UUID, statusCode *int, lastError string) error {
+	return r.updateDelivery(ctx, tx, id, map[string]any{
+		"status":           domain.WebhookDeliveryDead,
+		"attempts":         sq.Expr("attempts + 1"),
+		"last_status_code": statusCode,
+		"last_error":       lastError,
+	})
+}
+
+// Redeliver implements domain.WebhookRepository. Unknown deliveries yield sql.ErrNoRows.
+func (r *webhookRepository) Redeliver(ctx context.Context, tx *sql.Tx, subscriptionID uuid.UUID, id uuid.UUID) error {
+	query := sq.Update("webhook_deliveries").
+		SetMap(map[string]any{
+			"status":          domain.WebhookDeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": sq.Expr("now()"),
+		}).
+		Where(sq.And{
+			sq.Eq{"id": id},
+			sq.Eq{"subscription_id": subscriptionID},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) updateDelivery(ctx context.Context, tx *sql.Tx, id uuid.UUID, values map[string]any) error {
+	query := sq.Update("webhook_deliveries").
+		SetMap(values).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+func scanWebhook(row interface{ Scan(dest ...any) error }) (*domain.WebhookSubscription, error) {
+	var sub domain.WebhookSubscription
+	var eventTypes []string
+
+	if err := row.Scan(&sub.ID, &sub.ShopID, &sub.UserID, &sub.URL, pq.Array(&eventTypes), &sub.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	sub.EventTypes = make([]domain.EventType, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		sub.EventTypes = append(sub.EventTypes, domain.EventType(t))
+	}
+
+	return &sub, nil
+}
+
+func deliveryFields(d *domain.WebhookDelivery) []any {
+	return []any{&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt}
+}
+
+func eventTypeStrings(types []domain.EventType) []string {
+	out := make([]string, 0, len(types))
+	for _, t := range types {
+		out = append(out, string(t))
+	}
+
+	return out
+}
+
+func NewWebhookRepository(db pqsql.Client) domain.WebhookRepository {
+	return &webhookRepository{db: db}
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/tokenutils"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+type apiKeyUsecase struct {
+	db         pqsql.Database
+	apiKeyRepo domain.APIKeyRepository
+	shopRepo   domain.ShopRepository
+	env        *bootstrap.Env
+}
+
+// Create implements domain.APIKeyUsecase.
+func (a *apiKeyUsecase) Create(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, payload domain.CreateAPIKeyRequest) (domain.APIKeyCreated, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUsecase.Create", tracing.UserID(userID), tracing.ShopID(shopID))
+	defer span.End()
+
+	var created domain.APIKeyCreated
+
+	scopes, err := normalizeScopes(payload.Scopes)
+	if err != nil {
+		return created, errx.E(errx.CodeValidation, err.Error(), errx.Op("apiKeyUsecase.Create"), err)
+	}
+
+	if err := ensureShopOwner(ctx, a.shopRepo, shopID, userID, "apiKeyUsecase.Create"); err != nil {
+		return created, err
+	}
+
+	key := domain.APIKey{
+		ShopID: shopID,
+		UserID: userID,
+		Name:   payload.Name,
+		Scopes: scopes,
+	}
+
+	if payload.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, payload.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	_, err = a.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		created, err = a.issue(ctx, tx, key, "apiKeyUsecase.Create")
+		return nil, err
+	})
+	if err != nil {
+		return domain.APIKeyCreated{}, err
+	}
+
+	return created, nil
+}
+
+// List implements domain.APIKeyUsecase.
+func (a *apiKeyUsecase) List(ctx context.Context, userID uuid.UUID, shopID uuid.UUID) ([]domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUsecase.List", tracing.UserID(userID), tracing.ShopID(shopID))
+	defer span.End()
+
+	keys, err := a.apiKeyRepo.ListByShop(ctx, shopID, userID)
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to list api keys", errx.Op("apiKeyUsecase.List"), err)
+	}
+
+	return keys, nil
+}
+
+// Revoke implements domain.APIKeyUsecase.
+func (a *apiKeyUsecase) Revoke(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "APIKeyUsecase.Revoke", tracing.UserID(userID), tracing.ShopID(shopID))
+	defer span.End()
+
+	_, err := a.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		key, err := a.load(ctx, tx, userID, shopID, id, "apiKeyUsecase.Revoke")
+		if err != nil {
+			return nil, err
+		}
+
+		if key.RevokedAt != nil {
+			return nil, errx.E(errx.CodeConflict, "api key already revoked", errx.Op("apiKeyUsecase.Revoke"))
+		}
+
+		if err := a.apiKeyRepo.Revoke(ctx, tx, id); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to revoke api key", errx.Op("apiKeyUsecase.Revoke"), err)
+		}
+
+		return nil, nil
+	})
+
+	return err
+}
+
+// Rotate implements domain.APIKeyUsecase.
+// The replacement inherits the name, scopes and remaining lifetime of the old key; the old key
+// stays valid for the configured grace period so clients can deploy the new one without downtime.
+func (a *apiKeyUsecase) Rotate(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, id uuid.UUID) (domain.APIKeyCreated, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUsecase.Rotate", tracing.UserID(userID), tracing.ShopID(shopID))
+	defer span.End()
+
+	var created domain.APIKeyCreated
+
+	_, err := a.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		old, err := a.load(ctx, tx, userID, shopID, id, "apiKeyUsecase.Rotate")
+		if err != nil {
+			return nil, err
+		}
+
+		if old.RevokedAt != nil || (old.ExpiresAt != nil && !old.ExpiresAt.After(time.Now())) {
+			return nil, errx.E(errx.CodePrecondition, "api key is no longer active", errx.Op("apiKeyUsecase.Rotate"))
+		}
+
+		created, err = a.issue(ctx, tx, domain.APIKey{
+			ShopID:    old.ShopID,
+			UserID:    old.UserID,
+			Name:      old.Name,
+			Scopes:    old.Scopes,
+			ExpiresAt: old.ExpiresAt,
+		}, "apiKeyUsecase.Rotate")
+		if err != nil {
+			return nil, err
+		}
+
+		grace := time.Now().Add(minutesOr(a.env.APIKeyRotationGrace, 24*60))
+		if err := a.apiKeyRepo.MarkRotated(ctx, tx, old.ID, created.ID, grace); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to rotate api key", errx.Op("apiKeyUsecase.Rotate"), err)
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return domain.APIKeyCreated{}, err
+	}
+
+	return created, nil
+}
+
+// Authenticate implements domain.APIKeyUsecase.
+func (a *apiKeyUsecase) Authenticate(ctx context.Context, key string) (*domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUsecase.Authenticate")
+	defer span.End()
+
+	if !tokenutils.IsAPIKey(key) {
+		return nil, errx.E(errx.CodeUnauthenticated, "invalid api key", errx.Op("apiKeyUsecase.Authenticate"))
+	}
+
+	apiKey, err := a.apiKeyRepo.GetByHash(ctx, tokenutils.HashToken(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errx.E(errx.CodeUnauthenticated, "invalid api key", errx.Op("apiKeyUsecase.Authenticate"), err)
+		}
+		return nil, errx.E(errx.CodeInternal, "failed to verify api key", errx.Op("apiKeyUsecase.Authenticate"), err)
+	}
+
+	// Last-used tracking is best effort and must not fail the request.
+	_ = a.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID)
+
+	return apiKey, nil
+}
+
+func (a *apiKeyUsecase) issue(ctx context.Context, tx *sql.Tx, key domain.APIKey, op string) (domain.APIKeyCreated, error) {
+	raw, prefix, err := tokenutils.NewAPIKey()
+	if err != nil {
+		return domain.APIKeyCreated{}, errx.E(errx.CodeInternal, "failed to generate api key", errx.Op(op), err)
+	}
+
+	key.ID = uuid.New()
+	key.Prefix = prefix
+	key.KeyHash = tokenutils.HashToken(raw)
+
+	if err := a.apiKeyRepo.Create(ctx, tx, &key); err != nil {
+		return domain.APIKeyCreated{}, errx.E(errx.CodeInternal, "failed to save api key", errx.Op(op), err)
+	}
+
+	return domain.APIKeyCreated{APIKey: key, Key: raw}, nil
+}
+
+func (a *apiKeyUsecase) load(ctx context.Context, tx *sql.Tx, userID uuid.UUID, shopID uuid.UUID, id uuid.UUID, op string) (*domain.APIKey, error) {
+	key, err := a.apiKeyRepo.Get(ctx, tx, shopID, userID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errx.E(errx.CodeNotFound, "api key not found", errx.Op(op), err)
+		}
+		return nil, errx.E(errx.CodeInternal, "failed to load api key", errx.Op(op), err)
+	}
+
+	return key, nil
+}
+
+// normalizeScopes rejects unknown scopes and drops duplicates.
+func normalizeScopes(scopes []domain.APIKeyScope) ([]domain.APIKeyScope, error) {
+	out := make([]domain.APIKeyScope, 0, len(scopes))
+	for _, s := range scopes {
+		if !slices.Contains(domain.APIKeyScopes, s) {
+			return nil, errors.New("unknown scope " + string(s))
+		}
+
+		if !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+
+	return out, nil
+}
+
+func NewAPIKeyUsecase(
+	db pqsql.Database,
+	apiKeyRepo domain.APIKeyRepository,
+	shopRepo domain.ShopRepository,
+	env *bootstrap.Env,
+) domain.APIKeyUsecase {
+	return &apiKeyUsecase{
+		db:         db,
+		apiKeyRepo: apiKeyRepo,
+		shopRepo:   shopRepo,
+		env:        env,
+	}
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/tokenutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAPIKeyUsecase_Create_Success(t *testing.T) {
+	ctx := context.Background()
+	apiKeyRepo := mocks.NewMockAPIKeyRepository(t)
+	shopRepo := mocks.NewMockShopRepository(t)
+	uc := NewAPIKeyUsecase(&fakeDB{}, apiKeyRepo, shopRepo, &bootstrap.Env{})
+	userID, shopID := uuid.New(), uuid.New()
+
+	shopRepo.EXPECT().Retrieve(ctx, shopID).Return(&domain.Shop{ID: shopID, OwnerID: &userID}, nil)
+
+	var saved *domain.APIKey
+	apiKeyRepo.EXPECT().Create(ctx, mock.Anything, mock.Anything).Run(func(_ context.Context, _ *sql.Tx, key *domain.APIKey) {
+		saved = key
+	}).Return(nil)
+
+	created, err := uc.Create(ctx, userID, shopID, domain.CreateAPIKeyRequest{
+		Name:          "ERP",
+		Scopes:        []domain.APIKeyScope{domain.ScopeOrdersRead, domain.ScopeOrdersRead, domain.ScopeOrdersWrite},
+		ExpiresInDays: 30,
+	})
+	assert.NoError(t, err)
+	assert.True(t, tokenutils.IsAPIKey(created.Key))
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix+"_"))
+	assert.Equal(t, tokenutils.HashToken(created.Key), saved.KeyHash)
+	assert.NotContains(t, saved.KeyHash, created.Key)
+	assert.Equal(t, []domain.APIKeyScope{domain.ScopeOrdersRead, domain.ScopeOrdersWrite}, saved.Scopes)
+	assert.Equal(t, userID, saved.UserID)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 30), *saved.ExpiresAt, time.Minute)
+}
+
+func TestAPIKeyUsecase_Create_UnknownScope(t *testing.T) {
+	ctx := context.Background()
+	uc := NewAPIKeyUsecase(&fakeDB{}, mocks.NewMockAPIKeyRepository(t), mocks.NewMockShopRepository(t), &bootstrap.Env{})
+
+	_, err := uc.Create(ctx, uuid.New(), uuid.New(), domain.CreateAPIKeyRequest{
+		Name:   "ERP",
+		Scopes: []domain.APIKeyScope{"admin"},
+	})
+	assert.True(t, errx.IsCode(err, errx.CodeValidation))
+}
+
+func TestAPIKeyUsecase_Create_NotShopOwner(t *testing.T) {
+	ctx := context.Background()
+	shopRepo := mocks.NewMockShopRepository(t)
+	uc := NewAPIKeyUsecase(&fakeDB{}, mocks.NewMockAPIKeyRepository(t), shopRepo, &bootstrap.Env{})
+	ownerID, shopID := uuid.New(), uuid.New()
+
+	shopRepo.EXPECT().Retrieve(ctx, shopID).Return(&domain.Shop{ID: shopID, OwnerID: &ownerID}, nil)
+
+	_, err := uc.Create(ctx, uuid.New(), shopID, domain.CreateAPIKeyRequest{
+		Name:   "ERP",
+		Scopes: []domain.APIKeyScope{domain.ScopeOrdersRead},
+	})
+	assert.True(t, errx.IsCode(err, errx.CodePermission))
+}
+
+func TestAPIKeyUsecase_Authenticate_Success(t *testing.T) {
+	ctx := context.Background()
+	apiKeyRepo := mocks.NewMockAPIKeyRepository(t)
+	uc := NewAPIKeyUsecase(&fakeDB{}, apiKeyRepo, mocks.NewMockShopRepository(t), &bootstrap.Env{})
+
+	raw, _, err := tokenutils.NewAPIKey()
+	assert.NoError(t, err)
+
+	key := &domain.APIKey{ID: uuid.New(), ShopID: uuid.New(), Scopes: []domain.APIKeyScope{domain.ScopeOrdersRead}}
+	apiKeyRepo.EXPECT().GetByHash(ctx, tokenutils.HashToken(raw)).Return(key, nil)
+	apiKeyRepo.EXPECT().TouchLastUsed(ctx, key.ID).Return(nil)
+
+	got, err := uc.Authenticate(ctx, raw)
+	assert.NoError(t, err)
+	assert.Equal(t, key, got)
+	assert.True(t, got.HasScope(domain.ScopeOrdersRead))
+	assert.False(t, got.HasScope(domain.ScopeOrdersWrite))
+}
+
+func TestAPIKeyUsecase_Authenticate_Unknown(t *testing.T) {
+	ctx := context.Background()
+	apiKeyRepo := mocks.NewMockAPIKeyRepository(t)
+	uc := NewAPIKeyUsecase(&fakeDB{}, apiKeyRepo, mocks.NewMockShopRepository(t), &bootstrap.Env{})
+
+	raw, _, err := tokenutils.NewAPIKey()
+	assert.NoError(t, err)
+
+	apiKeyRepo.EXPECT().GetByHash(ctx, tokenutils.HashToken(raw)).Return(nil, sql.ErrNoRows)
+
+	_, err = uc.Authenticate(ctx, raw)
+	assert.True(t, errx.IsCode(err, errx.CodeUnauthenticated))
+}
+
+func TestAPIKeyUsecase_Authenticate_Malformed(t *testing.T) {
+	uc := NewAPIKeyUsecase(&fakeDB{}, mocks.NewMockAPIKeyRepository(t), mocks.NewMockShopRepository(t), &bootstrap.Env{})
+
+	_, err := uc.Authenticate(context.Background(), "not-a-key")
+	assert.True(t, errx.IsCode(err, errx.CodeUnauthenticated))
+}
+
+func TestAPIKeyUsecase_Rotate_Success(t *testing.T) {
+	ctx := context.Background()
+	apiKeyRepo := mocks.NewMockAPIKeyRepository(t)
+	uc := NewAPIKeyUsecase(&fakeDB{}, apiKeyRepo, mocks.NewMockShopRepository(t), &bootstrap.Env{APIKeyRotationGrace: 60})
+	userID, shopID, id := uuid.New(), uuid.New(), uuid.New()
+
+	old := &domain.APIKey{ID: id, ShopID: shopID, UserID: userID, Name: "ERP", Scopes: []domain.APIKeyScope{domain.ScopeProductsRead}}
+	apiKeyRepo.EXPECT().Get(ctx, mock.Anything, shopID, userID, id).Return(old, nil)
+	apiKeyRepo.EXPECT().Create(ctx, mock.Anything, mock.MatchedBy(func(key *domain.APIKey) bool {
+		return key.ID != id && key.Name == "ERP" && key.ShopID == shopID
+	})).Return(nil)
+	apiKeyRepo.EXPECT().MarkRotated(ctx, mock.Anything, id, mock.Anything, mock.MatchedBy(func(at time.Time) bool {
+		return at.Sub(time.Now()) > 59*time.Minute && at.Sub(time.Now()) <= time.Hour
+	})).Return(nil)
+
+	created, err := uc.Rotate(ctx, userID, shopID, id)
+	assert.NoError(t, err)
+	assert.NotEqual(t, id, created.ID)
+	assert.Equal(t, old.Scopes, created.Scopes)
+	assert.True(t, tokenutils.IsAPIKey(created.Key))
+}
+
+func TestAPIKeyUsecase_Rotate_Revoked(t *testing.T) {
+	ctx := context.Background()
+	apiKeyRepo := mocks.NewMockAPIKeyRepository(t)
+	uc := NewAPIKeyUsecase(&fakeDB{}, apiKeyRepo, mocks.NewMockShopRepository(t), &bootstrap.Env{})
+	userID, shopID, id := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	apiKeyRepo.EXPECT().Get(ctx, mock.Anything, shopID, userID, id).Return(&domain.APIKey{ID: id, RevokedAt: &now}, nil)
+
+	_, err := uc.Rotate(ctx, userID, shopID, id)
+	assert.True(t, errx.IsCode(err, errx.CodePrecondition))
+}
+
+func TestAPIKeyUsecase_Revoke_NotFound(t *testing.T) {
+	ctx := context.Background()
+	apiKeyRepo := mocks.NewMockAPIKeyRepository(t)
+	uc := NewAPIKeyUsecase(&fakeDB{}, apiKeyRepo, mocks.NewMockShopRepository(t), &bootstrap.Env{})
+	userID, shopID, id := uuid.New(), uuid.New(), uuid.New()
+
+	apiKeyRepo.EXPECT().Get(ctx, mock.Anything, shopID, userID, id).Return(nil, sql.ErrNoRows)
+
+	err := uc.Revoke(ctx, userID, shopID, id)
+	assert.True(t, errx.IsCode(err, errx.CodeNotFound))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/dyaksa/warehouse/pkg/helper"
 	"github.com/dyaksa/warehouse/pkg/passwordutils"
 	"github.com/dyaksa/warehouse/pkg/tokenutils"
+	"github.com/dyaksa/warehouse/pkg/tracing"
 )
 
 type authUsecase struct {
@@ -20,42 +21,58 @@ type authUsecase struct {
 }
 
 // Login implements domain.AuthUsecase.
-func (a *authUsecase) Login(ctx context.Context, payload domain.AuthLoginRequest) (string, error) {
+// Accounts with two-factor authentication get a short-lived challenge token instead of an access token.
+func (a *authUsecase) Login(ctx context.Context, payload domain.AuthLoginRequest) (domain.LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.Login")
+	defer span.End()
+
+	var result domain.LoginResult
+
 	_, norm, ok := helper.NormalizeIdentifier(payload.Identifier)
 	if !ok {
-		return "", errx.E(errx.CodeValidation, "invalid identifier", errx.Op("authUsecase.Login"))
+		return result, errx.E(errx.CodeValidation, "invalid identifier", errx.Op("authUsecase.Login"))
 	}
 
-	existsUser, err := a.userRepo.GetMailOrPhone(ctx, a.crypto.HashString(norm), a.crypto.HashString(norm), func(data *domain.User) {
-		data.Email = a.crypto.Decrypt("")
-		data.Phone = a.crypto.Decrypt("")
-	})
-
-	if ok := passwordutils.VerifyPassword(payload.Password, existsUser.PasswordHash); !ok {
-		return "", errors.New("invalid credentials")
-	}
+	existsUser, err := findUserByIdentifier(ctx, a.userRepo, a.crypto, norm)
 
 	if err != nil {
-		return "", errx.E(errx.CodeNotFound, "user not found", errx.Op("authUsecase.Login"), err)
+		return result, errx.E(errx.CodeNotFound, "user not found", errx.Op("authUsecase.Login"), err)
 	}
 
 	if ok := passwordutils.VerifyPassword(payload.Password, existsUser.PasswordHash); !ok {
-		return "", errx.E(errx.CodeUnauthorized, "invalid credentials", errx.Op("authUsecase.Login"), errors.New("password mismatch"))
+		return result, errx.E(errx.CodeUnauthorized, "invalid credentials", errx.Op("authUsecase.Login"), errors.New("password mismatch"))
+	}
+
+	if existsUser.TOTPEnabledAt != nil {
+		challengeToken, err := tokenutils.CreateChallengeToken(existsUser.ID, a.env.JwtSecret, minutesOr(a.env.TwoFactorChallengeTTL, 5))
+		if err != nil {
+			return result, errx.E(errx.CodeInternal, "failed to create challenge token", errx.Op("authUsecase.Login"), err)
+		}
+
+		result.TwoFactorRequired = true
+		result.ChallengeToken = challengeToken
+		return result, nil
 	}
 
 	accessToken, err := tokenutils.CreateAccessToken(existsUser, a.env.JwtSecret, a.env.JwtExpiry)
 	if err != nil {
-		return "", err
+		return result, err
 	}
 
-	return accessToken, nil
+	result.AccessToken = accessToken
+	return result, nil
 }
 
 // Register implements domain.AuthUsecase.
 func (a *authUsecase) Register(ctx context.Context, payload domain.AuthRegisterRequest) (domain.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.Register")
+	defer span.End()
+
 	var user domain.User
 	user.Email = a.crypto.Encrypt(payload.Email)
 	user.Phone = a.crypto.Encrypt(payload.MustFormattedPhone())
+	user.EmailKeyVersion = a.crypto.KeyVersion()
+	user.PhoneKeyVersion = a.crypto.KeyVersion()
 
 	passwordHash, err := passwordutils.HashPassword(payload.Password)
 	if err != nil {
@@ -76,6 +93,37 @@ func (a *authUsecase) Register(ctx context.Context, payload domain.AuthRegisterR
 	return user, nil
 }
 
+// findUserByIdentifier looks a user up by the blind index of every key version, newest first,
+// so identifiers keep resolving while rows are being re-keyed.
+func findUserByIdentifier(ctx context.Context, userRepo domain.UserRepository, c crypto.Crypto, normalized string) (*domain.User, error) {
+	for _, bidx := range c.HashStringVersions(normalized) {
+		user, err := userRepo.GetMailOrPhone(ctx, bidx, bidx, decryptUser(c))
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return user, err
+		}
+	}
+
+	return nil, domain.ErrUserNotFound
+}
+
+// decryptUser prepares the email and phone ciphers for the key version each was written with.
+func decryptUser(c crypto.Crypto) func(data *domain.User) error {
+	return func(data *domain.User) error {
+		email, err := c.DecryptVersion(data.EmailKeyVersion, "")
+		if err != nil {
+			return err
+		}
+
+		phone, err := c.DecryptVersion(data.PhoneKeyVersion, "")
+		if err != nil {
+			return err
+		}
+
+		data.Email, data.Phone = email, phone
+		return nil
+	}
+}
+
 func NewAuthUsecase(
 	userRepo domain.UserRepository,
 	crypto crypto.Crypto,
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/dyaksa/encryption-pii/crypto/aesx"
@@ -28,6 +29,16 @@ func (s simpleCryptoStub) Decrypt(def string) aesx.AES[string, core.PrimitiveAES
 }
 func (s simpleCryptoStub) BindHeap(entity any) error  { return nil }
 func (s simpleCryptoStub) HashString(v string) string { return "hash(" + v + ")" }
+func (s simpleCryptoStub) KeyVersion() int            { return 1 }
+func (s simpleCryptoStub) DecryptVersion(version int, def string) (aesx.AES[string, core.PrimitiveAES], error) {
+	if version > s.KeyVersion() {
+		return aesx.AES[string, core.PrimitiveAES]{}, fmt.Errorf("crypto key version %d is not configured", version)
+	}
+	return s.Decrypt(def), nil
+}
+func (s simpleCryptoStub) HashStringVersions(v string) []string             { return []string{s.HashString(v)} }
+func (s simpleCryptoStub) PingHeap(ctx context.Context) error               { return nil }
+func (s simpleCryptoStub) UnbindHeap(ctx context.Context, entity any) error { return nil }
 
 func TestAuthUsecase_Login_InvalidIdentifier(t *testing.T) {
 	ctx := context.Background()
@@ -72,3 +83,10 @@ func TestAuthUsecase_Register_CreateError(t *testing.T) {
 	_, err := uc.Register(ctx, reg)
 	assert.ErrorIs(t, err, expectedErr)
 }
+
+func TestDecryptUser_UnknownKeyVersion(t *testing.T) {
+	user := &domain.User{EmailKeyVersion: 1, PhoneKeyVersion: 2}
+
+	err := decryptUser(simpleCryptoStub{})(user)
+	assert.ErrorContains(t, err, "key version 2")
+}
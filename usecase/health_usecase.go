@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/crypto"
+)
+
+const (
+	// workerStaleIntervals is how many worker intervals may pass without a successful batch before readiness fails.
+	workerStaleIntervals = 3
+	// readyCheckTimeout bounds dependency checks so a hung database fails the probe instead of stalling it.
+	readyCheckTimeout = 2 * time.Second
+)
+
+type healthUsecase struct {
+	healthRepo       domain.HealthRepository
+	crypto           crypto.Crypto
+	worker           domain.WorkerStatus
+	migrationVersion int64
+	startedAt        time.Time
+	shuttingDown     atomic.Bool
+	now              func() time.Time
+}
+
+// Ready implements domain.HealthUsecase.
+func (h *healthUsecase) Ready(ctx context.Context) domain.HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+	defer cancel()
+
+	report := domain.HealthReport{Status: domain.HealthOK}
+
+	add := func(name string, err error) {
+		result := domain.HealthCheckResult{Name: name, Status: domain.HealthOK}
+		if err != nil {
+			result.Status = domain.HealthFail
+			result.Error = err.Error()
+			report.Status = domain.HealthFail
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	if h.shuttingDown.Load() {
+		add("shutdown", fmt.Errorf("server is shutting down"))
+	}
+
+	add("postgres", h.healthRepo.Ping(ctx))
+	add("crypto_heap", h.crypto.PingHeap(ctx))
+	add("migrations", h.checkMigrations(ctx))
+	add("stock_release_worker", h.checkWorker())
+
+	return report
+}
+
+// SetShuttingDown implements domain.HealthUsecase.
+func (h *healthUsecase) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// checkMigrations fails when the database schema is behind the migrations shipped with this build.
+// A newer schema is accepted so a rolling deploy can migrate before old instances are replaced.
+func (h *healthUsecase) checkMigrations(ctx context.Context) error {
+	version, err := h.healthRepo.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version < h.migrationVersion {
+		return fmt.Errorf("schema version %d is behind expected %d", version, h.migrationVersion)
+	}
+
+	return nil
+}
+
+// checkWorker fails when the stock release worker has not completed a batch recently.
+// Until the first batch runs, the grace period counts from startup.
+func (h *healthUsecase) checkWorker() error {
+	last := h.worker.LastSuccess()
+	if last.IsZero() {
+		last = h.startedAt
+	}
+
+	maxAge := workerStaleIntervals * h.worker.Interval()
+	if age := h.now().Sub(last); age > maxAge {
+		return fmt.Errorf("no successful run for %s", age.Round(time.Second))
+	}
+
+	return nil
+}
+
+func NewHealthUsecase(
+	healthRepo domain.HealthRepository,
+	crypto crypto.Crypto,
+	worker domain.WorkerStatus,
+	migrationVersion int64,
+) domain.HealthUsecase {
+	return &healthUsecase{
+		healthRepo:       healthRepo,
+		crypto:           crypto,
+		worker:           worker,
+		migrationVersion: migrationVersion,
+		startedAt:        time.Now(),
+		now:              time.Now,
+	}
+}
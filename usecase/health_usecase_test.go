@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	cryptomocks "github.com/dyaksa/warehouse/mocks/crypto"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeWorkerStatus struct {
+	lastSuccess time.Time
+	interval    time.Duration
+}
+
+func (f fakeWorkerStatus) LastSuccess() time.Time  { return f.lastSuccess }
+func (f fakeWorkerStatus) Interval() time.Duration { return f.interval }
+
+func newTestHealthUsecase(t *testing.T, dbVersion int64, worker fakeWorkerStatus) *healthUsecase {
+	healthRepo := mocks.NewMockHealthRepository(t)
+	crypto := cryptomocks.NewMockCrypto(t)
+
+	healthRepo.EXPECT().Ping(mock.Anything).Return(nil)
+	healthRepo.EXPECT().MigrationVersion(mock.Anything).Return(dbVersion, nil)
+	crypto.EXPECT().PingHeap(mock.Anything).Return(nil)
+
+	return NewHealthUsecase(healthRepo, crypto, worker, 22).(*healthUsecase)
+}
+
+func checkStatus(report domain.HealthReport, name string) domain.HealthStatus {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check.Status
+		}
+	}
+
+	return ""
+}
+
+func TestHealthUsecase_Ready_OK(t *testing.T) {
+	uc := newTestHealthUsecase(t, 22, fakeWorkerStatus{lastSuccess: time.Now(), interval: 30 * time.Second})
+
+	report := uc.Ready(context.Background())
+	assert.Equal(t, domain.HealthOK, report.Status)
+	assert.Len(t, report.Checks, 4)
+}
+
+func TestHealthUsecase_Ready_ShuttingDown(t *testing.T) {
+	uc := newTestHealthUsecase(t, 22, fakeWorkerStatus{lastSuccess: time.Now(), interval: 30 * time.Second})
+	uc.SetShuttingDown()
+
+	report := uc.Ready(context.Background())
+	assert.Equal(t, domain.HealthFail, report.Status)
+	assert.Equal(t, domain.HealthFail, checkStatus(report, "shutdown"))
+}
+
+func TestHealthUsecase_Ready_MigrationBehind(t *testing.T) {
+	uc := newTestHealthUsecase(t, 21, fakeWorkerStatus{lastSuccess: time.Now(), interval: 30 * time.Second})
+
+	report := uc.Ready(context.Background())
+	assert.Equal(t, domain.HealthFail, report.Status)
+	assert.Equal(t, domain.HealthFail, checkStatus(report, "migrations"))
+	assert.Equal(t, domain.HealthOK, checkStatus(report, "postgres"))
+}
+
+func TestHealthUsecase_Ready_StaleWorker(t *testing.T) {
+	uc := newTestHealthUsecase(t, 23, fakeWorkerStatus{lastSuccess: time.Now().Add(-2 * time.Minute), interval: 30 * time.Second})
+
+	report := uc.Ready(context.Background())
+	assert.Equal(t, domain.HealthFail, report.Status)
+	assert.Equal(t, domain.HealthFail, checkStatus(report, "stock_release_worker"))
+	assert.Equal(t, domain.HealthOK, checkStatus(report, "migrations"))
+}
+
+func TestHealthUsecase_Ready_WorkerNotStartedWithinGrace(t *testing.T) {
+	uc := newTestHealthUsecase(t, 22, fakeWorkerStatus{interval: 30 * time.Second})
+
+	report := uc.Ready(context.Background())
+	assert.Equal(t, domain.HealthOK, checkStatus(report, "stock_release_worker"))
+
+	uc.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	report = uc.Ready(context.Background())
+	assert.Equal(t, domain.HealthFail, checkStatus(report, "stock_release_worker"))
+}
+
+func TestHealthUsecase_Ready_PostgresDown(t *testing.T) {
+	healthRepo := mocks.NewMockHealthRepository(t)
+	crypto := cryptomocks.NewMockCrypto(t)
+
+	healthRepo.EXPECT().Ping(mock.Anything).Return(errors.New("connection refused"))
+	healthRepo.EXPECT().MigrationVersion(mock.Anything).Return(0, errors.New("connection refused"))
+	crypto.EXPECT().PingHeap(mock.Anything).Return(nil)
+
+	uc := NewHealthUsecase(healthRepo, crypto, fakeWorkerStatus{lastSuccess: time.Now(), interval: time.Minute}, 22)
+
+	report := uc.Ready(context.Background())
+	assert.Equal(t, domain.HealthFail, report.Status)
+	assert.Equal(t, domain.HealthFail, checkStatus(report, "postgres"))
+}
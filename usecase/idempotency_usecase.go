@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/metrics"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+// idempotencyLease is how long a key stays in flight without a response. A request still
+// unanswered after it is assumed abandoned, e.g. by a crashed instance, and may be taken over.
+const idempotencyLease = time.Minute
+
+type idempotencyUsecase struct {
+	db       pqsql.Database
+	idemRepo domain.IdempotencyRequestRepository
+}
+
+// Begin implements domain.IdempotencyUsecase. Each claim and takeover gets a new lease, so a request
+// whose key was taken over after its lease ran out can no longer settle it.
+func (i *idempotencyUsecase) Begin(ctx context.Context, key domain.IdempotencyKey, payloadHash string, retention time.Duration) (uuid.UUID, *domain.IdempotentResponse, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyUsecase.Begin")
+	defer span.End()
+
+	result := "processed"
+	lease := uuid.New()
+	expiresAt := time.Now().Add(retention)
+
+	replay, err := i.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		claimed, err := i.idemRepo.Claim(ctx, tx, key, lease, payloadHash, expiresAt)
+		if err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to claim idempotency key", errx.Op("IdempotencyUsecase.Begin"), err)
+		}
+		if claimed {
+			return nil, nil
+		}
+
+		record, err := i.idemRepo.GetForUpdate(ctx, tx, key)
+		if err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to load idempotency key", errx.Op("IdempotencyUsecase.Begin"), err)
+		}
+
+		if time.Now().After(record.ExpiresAt) {
+			if err := i.idemRepo.Reclaim(ctx, tx, key, lease, payloadHash, expiresAt); err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to reclaim expired idempotency key", errx.Op("IdempotencyUsecase.Begin"), err)
+			}
+			return nil, nil
+		}
+
+		if record.PayloadHash != payloadHash {
+			result = "conflict"
+			return nil, errx.E(errx.CodeValidation, "idempotency key was used with a different request body", errx.Op("IdempotencyUsecase.Begin"), domain.ErrIdempotencyConflict)
+		}
+
+		if record.StatusCode != nil {
+			result = "replayed"
+			return &domain.IdempotentResponse{
+				StatusCode:  *record.StatusCode,
+				ContentType: record.ContentType,
+				Body:        record.ResponseBody,
+			}, nil
+		}
+
+		if time.Since(record.LockedAt) < idempotencyLease {
+			result = "in_flight"
+			return nil, errx.E(errx.CodeConflict, "a request with this idempotency key is still in progress", errx.Op("IdempotencyUsecase.Begin"), domain.ErrIdempotencyInFlight)
+		}
+
+		if err := i.idemRepo.Reclaim(ctx, tx, key, lease, payloadHash, expiresAt); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to take over idempotency key", errx.Op("IdempotencyUsecase.Begin"), err)
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		if result != "processed" {
+			metrics.IdempotentRequests.WithLabelValues(result).Inc()
+		}
+		return uuid.Nil, nil, err
+	}
+
+	metrics.IdempotentRequests.WithLabelValues(result).Inc()
+
+	if response, ok := replay.(*domain.IdempotentResponse); ok && response != nil {
+		return uuid.Nil, response, nil
+	}
+
+	return lease, nil, nil
+}
+
+// Complete implements domain.IdempotencyUsecase.
+func (i *idempotencyUsecase) Complete(ctx context.Context, key domain.IdempotencyKey, lease uuid.UUID, response domain.IdempotentResponse) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyUsecase.Complete")
+	defer span.End()
+
+	if err := i.idemRepo.Complete(ctx, key, lease, response); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errx.E(errx.CodeConflict, "idempotency key was taken over by another request", errx.Op("IdempotencyUsecase.Complete"), err)
+		}
+		return errx.E(errx.CodeInternal, "failed to store idempotent response", errx.Op("IdempotencyUsecase.Complete"), err)
+	}
+
+	return nil
+}
+
+// Release implements domain.IdempotencyUsecase.
+func (i *idempotencyUsecase) Release(ctx context.Context, key domain.IdempotencyKey, lease uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyUsecase.Release")
+	defer span.End()
+
+	if err := i.idemRepo.Release(ctx, key, lease); err != nil {
+		return errx.E(errx.CodeInternal, "failed to release idempotency key", errx.Op("IdempotencyUsecase.Release"), err)
+	}
+
+	return nil
+}
+
+// PurgeExpired implements domain.IdempotencyUsecase.
+func (i *idempotencyUsecase) PurgeExpired(ctx context.Context, batchSize int) (int, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyUsecase.PurgeExpired")
+	defer span.End()
+
+	deleted, err := i.idemRepo.DeleteExpired(ctx, batchSize)
+	if err != nil {
+		return 0, errx.E(errx.CodeInternal, "failed to purge expired idempotency keys", errx.Op("IdempotencyUsecase.PurgeExpired"), err)
+	}
+
+	metrics.IdempotencyKeysPurged.Add(float64(deleted))
+
+	return deleted, nil
+}
+
+func NewIdempotencyUsecase(db pqsql.Database, idemRepo domain.IdempotencyRequestRepository) domain.IdempotencyUsecase {
+	return &idempotencyUsecase{db: db, idemRepo: idemRepo}
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testIdempotencyKey = domain.IdempotencyKey{Key: "key-1", Endpoint: "POST /api/order/checkout", Principal: "user-1"}
+
+func TestIdempotencyUsecase_Begin_Claimed(t *testing.T) {
+	ctx := context.Background()
+	idemRepo := mocks.NewMockIdempotencyRequestRepository(t)
+	uc := NewIdempotencyUsecase(&fakeDB{}, idemRepo)
+
+	var claimed uuid.UUID
+	idemRepo.EXPECT().Claim(ctx, mock.Anything, testIdempotencyKey, mock.Anything, "hash", mock.Anything).
+		Run(func(ctx context.Context, tx *sql.Tx, key domain.IdempotencyKey, lease uuid.UUID, payloadHash string, expiresAt time.Time) {
+			claimed = lease
+		}).
+		Return(true, nil)
+
+	lease, replay, err := uc.Begin(ctx, testIdempotencyKey, "hash", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, replay)
+	assert.NotEqual(t, uuid.Nil, lease)
+	assert.Equal(t, claimed, lease)
+}
+
+func TestIdempotencyUsecase_Begin_ReplaysCompletedResponse(t *testing.T) {
+	ctx := context.Background()
+	idemRepo := mocks.NewMockIdempotencyRequestRepository(t)
+	uc := NewIdempotencyUsecase(&fakeDB{}, idemRepo)
+
+	status := 200
+	idemRepo.EXPECT().Claim(ctx, mock.Anything, testIdempotencyKey, mock.Anything, "hash", mock.Anything).Return(false, nil)
+	idemRepo.EXPECT().GetForUpdate(ctx, mock.Anything, testIdempotencyKey).Return(&domain.IdempotencyRecord{
+		IdempotencyKey: testIdempotencyKey,
+		PayloadHash:    "hash",
+		StatusCode:     &status,
+		ContentType:    "application/json",
+		ResponseBody:   []byte(`{"status":"success"}`),
+		LockedAt:       time.Now().Add(-time.Hour),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}, nil)
+
+	_, replay, err := uc.Begin(ctx, testIdempotencyKey, "hash", time.Hour)
+	assert.NoError(t, err)
+	if assert.NotNil(t, replay) {
+		assert.Equal(t, 200, replay.StatusCode)
+		assert.Equal(t, "application/json", replay.ContentType)
+		assert.Equal(t, `{"status":"success"}`, string(replay.Body))
+	}
+}
+
+func TestIdempotencyUsecase_Begin_InFlight(t *testing.T) {
+	ctx := context.Background()
+	idemRepo := mocks.NewMockIdempotencyRequestRepository(t)
+	uc := NewIdempotencyUsecase(&fakeDB{}, idemRepo)
+
+	idemRepo.EXPECT().Claim(ctx, mock.Anything, testIdempotencyKey, mock.Anything, "hash", mock.Anything).Return(false, nil)
+	idemRepo.EXPECT().GetForUpdate(ctx, mock.Anything, testIdempotencyKey).Return(&domain.IdempotencyRecord{
+		IdempotencyKey: testIdempotencyKey,
+		PayloadHash:    "hash",
+		LockedAt:       time.Now().Add(-time.Second),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}, nil)
+
+	_, replay, err := uc.Begin(ctx, testIdempotencyKey, "hash", time.Hour)
+	assert.Nil(t, replay)
+	assert.ErrorIs(t, err, domain.ErrIdempotencyInFlight)
+	assert.True(t, errx.IsCode(err, errx.CodeConflict))
+}
+
+func TestIdempotencyUsecase_Begin_PayloadMismatch(t *testing.T) {
+	ctx := context.Background()
+	idemRepo := mocks.NewMockIdempotencyRequestRepository(t)
+	uc := NewIdempotencyUsecase(&fakeDB{}, idemRepo)
+
+	idemRepo.EXPECT().Claim(ctx, mock.Anything, testIdempotencyKey, mock.Anything, "other", mock.Anything).Return(false, nil)
+	idemRepo.EXPECT().GetForUpdate(ctx, mock.Anything, testIdempotencyKey).Return(&domain.IdempotencyRecord{
+		IdempotencyKey: testIdempotencyKey,
+		PayloadHash:    "hash",
+		LockedAt:       time.Now(),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}, nil)
+
+	_, replay, err := uc.Begin(ctx, testIdempotencyKey, "other", time.Hour)
+	assert.Nil(t, replay)
+	assert.ErrorIs(t, err, domain.ErrIdempotencyConflict)
+}
+
+func TestIdempotencyUsecase_Begin_TakesOverExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	idemRepo := mocks.NewMockIdempotencyRequestRepository(t)
+	uc := NewIdempotencyUsecase(&fakeDB{}, idemRepo)
+
+	idemRepo.EXPECT().Claim(ctx, mock.Anything, testIdempotencyKey, mock.Anything, "hash", mock.Anything).Return(false, nil)
+	idemRepo.EXPECT().GetForUpdate(ctx, mock.Anything, testIdempotencyKey).Return(&domain.IdempotencyRecord{
+		IdempotencyKey: testIdempotencyKey,
+		PayloadHash:    "hash",
+		LockedAt:       time.Now().Add(-2 * idempotencyLease),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}, nil)
+	idemRepo.EXPECT().Reclaim(ctx, mock.Anything, testIdempotencyKey, mock.Anything, "hash", mock.Anything).Return(nil)
+
+	_, replay, err := uc.Begin(ctx, testIdempotencyKey, "hash", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, replay)
+}
+
+func TestIdempotencyUsecase_Begin_ExpiredKeyIsNew(t *testing.T) {
+	ctx := context.Background()
+	idemRepo := mocks.NewMockIdempotencyRequestRepository(t)
+	uc := NewIdempotencyUsecase(&fakeDB{}, idemRepo)
+
+	status := 200
+	idemRepo.EXPECT().Claim(ctx, mock.Anything, testIdempotencyKey, mock.Anything, "other", mock.Anything).Return(false, nil)
+	idemRepo.EXPECT().GetForUpdate(ctx, mock.Anything, testIdempotencyKey).Return(&domain.IdempotencyRecord{
+		IdempotencyKey: testIdempotencyKey,
+		PayloadHash:    "hash",
+		StatusCode:     &status,
+		LockedAt:       time.Now().Add(-48 * time.Hour),
+		ExpiresAt:      time.Now().Add(-time.Hour),
+	}, nil)
+	idemRepo.EXPECT().Reclaim(ctx, mock.Anything, testIdempotencyKey, mock.Anything, "other", mock.MatchedBy(func(expiresAt time.Time) bool {
+		return time.Until(expiresAt) > 59*time.Minute
+	})).Return(nil)
+
+	_, replay, err := uc.Begin(ctx, testIdempotencyKey, "other", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, replay)
+}
+
+func TestIdempotencyUsecase_Complete_TakenOver(t *testing.T) {
+	ctx := context.Background()
+	idemRepo := mocks.NewMockIdempotencyRequestRepository(t)
+	uc := NewIdempotencyUsecase(&fakeDB{}, idemRepo)
+
+	lease := uuid.New()
+	response := domain.IdempotentResponse{StatusCode: 201, ContentType: "application/json", Body: []byte(`{}`)}
+	// another request took the key over after this one's lease ran out
+	idemRepo.EXPECT().Complete(ctx, testIdempotencyKey, lease, response).Return(sql.ErrNoRows)
+
+	err := uc.Complete(ctx, testIdempotencyKey, lease, response)
+	assert.True(t, errx.IsCode(err, errx.CodeConflict))
+}
+
+func TestIdempotencyUsecase_Release_UsesLease(t *testing.T) {
+	ctx := context.Background()
+	idemRepo := mocks.NewMockIdempotencyRequestRepository(t)
+	uc := NewIdempotencyUsecase(&fakeDB{}, idemRepo)
+
+	lease := uuid.New()
+	idemRepo.EXPECT().Release(ctx, testIdempotencyKey, lease).Return(nil)
+
+	assert.NoError(t, uc.Release(ctx, testIdempotencyKey, lease))
+}
+
+func TestIdempotencyUsecase_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	idemRepo := mocks.NewMockIdempotencyRequestRepository(t)
+	uc := NewIdempotencyUsecase(&fakeDB{}, idemRepo)
+
+	idemRepo.EXPECT().DeleteExpired(ctx, 100).Return(42, nil)
+
+	deleted, err := uc.PurgeExpired(ctx, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, deleted)
+}
@@ -0,0 +1,287 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/metrics"
+	"github.com/dyaksa/warehouse/pkg/paginator"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+const (
+	jobBaseBackoff = 10 * time.Second
+	jobMaxBackoff  = time.Hour
+	// jobLease is how long a claimed job is hidden from other workers. A job still running after it
+	// may be picked up again, so handlers must be idempotent.
+	jobLease = 5 * time.Minute
+)
+
+type jobUsecase struct {
+	db       pqsql.Database
+	jobRepo  domain.JobRepository
+	handlers domain.JobHandlers
+	types    []domain.JobType
+	logger   log.Logger
+}
+
+// Enqueue implements domain.JobUsecase.
+func (j *jobUsecase) Enqueue(ctx context.Context, tx *sql.Tx, payload domain.JobPayload, options domain.EnqueueJobOptions) (*domain.Job, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to encode job payload", errx.Op("jobUsecase.Enqueue"), err)
+	}
+
+	job := &domain.Job{
+		ID:          uuid.New(),
+		Type:        payload.JobType(),
+		Payload:     raw,
+		Status:      domain.JobPending,
+		RunAt:       options.RunAt,
+		MaxAttempts: options.MaxAttempts,
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = domain.DefaultJobMaxAttempts
+	}
+
+	if err := j.jobRepo.Create(ctx, tx, job); err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to enqueue job", errx.Op("jobUsecase.Enqueue"), err)
+	}
+
+	return job, nil
+}
+
+// RunBatch implements domain.JobUsecase. Each job is claimed in a short transaction that leases it,
+// run outside any transaction, and its outcome recorded in a second one, so a slow handler holds no
+// locks and a failure to record one job leaves the outcome of the others in place.
+func (j *jobUsecase) RunBatch(ctx context.Context, limit int) (int, error) {
+	ctx, span := tracing.Start(ctx, "JobUsecase.RunBatch")
+	defer span.End()
+
+	if len(j.types) == 0 {
+		return 0, nil
+	}
+
+	picked := 0
+	for picked < limit {
+		job, err := j.claim(ctx)
+		if err != nil {
+			return picked, err
+		}
+		if job == nil {
+			break
+		}
+		picked++
+
+		start := time.Now()
+		runErr := j.run(ctx, *job)
+		metrics.JobDuration.WithLabelValues(string(job.Type)).Observe(time.Since(start).Seconds())
+
+		if err := j.record(ctx, *job, runErr); err != nil {
+			return picked, err
+		}
+	}
+
+	return picked, nil
+}
+
+// claim leases the next due job, or returns nil when none is due.
+func (j *jobUsecase) claim(ctx context.Context) (*domain.Job, error) {
+	job, err := j.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		job, err := j.jobRepo.ClaimDue(ctx, tx, j.types, jobLease)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return (*domain.Job)(nil), nil
+			}
+			return nil, errx.E(errx.CodeInternal, "failed to claim job", errx.Op("jobUsecase.claim"), err)
+		}
+
+		return job, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return job.(*domain.Job), nil
+}
+
+// record stores the outcome of a run: success, a retry with backoff, or dead once attempts run out.
+// The outcome is dropped when the claim no longer holds the job, which was then cancelled or claimed
+// again by another worker after the lease ran out.
+func (j *jobUsecase) record(ctx context.Context, job domain.Job, runErr error) error {
+	l := log.WithContext(ctx, j.logger)
+
+	outcome := "succeeded"
+	switch {
+	case runErr == nil:
+	case job.Attempts >= job.MaxAttempts:
+		outcome = "dead"
+	default:
+		outcome = "retry"
+	}
+
+	lost := false
+	_, err := j.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		var err error
+		switch outcome {
+		case "succeeded":
+			err = j.jobRepo.MarkSucceeded(ctx, tx, &job)
+		case "dead":
+			err = j.jobRepo.MarkDead(ctx, tx, &job, runErr.Error())
+		default:
+			runAt := time.Now().Add(backoff(job.Attempts, jobBaseBackoff, jobMaxBackoff))
+			err = j.jobRepo.MarkRetry(ctx, tx, &job, runErr.Error(), runAt)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			lost = true
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to record job outcome", errx.Op("jobUsecase.record"), err)
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fields := []log.LoggerContextFn{
+		log.String("job_id", job.ID.String()),
+		log.String("job_type", string(job.Type)),
+		log.Int64("attempt", int64(job.Attempts)),
+	}
+	if runErr != nil {
+		fields = append(fields, log.Error("error", runErr))
+	}
+
+	switch {
+	case lost:
+		l.Warn("job lease lost, outcome not recorded", fields...)
+		return nil
+	case outcome == "dead":
+		l.Error("job exhausted its retries", fields...)
+	case outcome == "retry":
+		l.Warn("job failed, retrying", fields...)
+	}
+
+	metrics.Jobs.WithLabelValues(string(job.Type), outcome).Inc()
+
+	return nil
+}
+
+// run calls the handler of job, turning a panic into an error so one job cannot stop the worker.
+func (j *jobUsecase) run(ctx context.Context, job domain.Job) (err error) {
+	ctx, span := tracing.Start(ctx, "JobUsecase.run")
+	defer span.End()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return j.handlers[job.Type](ctx, job.Payload)
+}
+
+// List implements domain.JobUsecase.
+func (j *jobUsecase) List(ctx context.Context, filter domain.JobFilter, pagination paginator.PaginationRequest) (*paginator.PaginationResult[domain.Job], error) {
+	ctx, span := tracing.Start(ctx, "JobUsecase.List")
+	defer span.End()
+
+	pagination.ValidateAndSetDefault()
+
+	jobs, total, err := j.jobRepo.List(ctx, filter, pagination.Limit, pagination.GetOffset())
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to list jobs", errx.Op("jobUsecase.List"), err)
+	}
+
+	return paginator.NewPaginationResult(jobs, total, pagination), nil
+}
+
+// Retry implements domain.JobUsecase.
+func (j *jobUsecase) Retry(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	ctx, span := tracing.Start(ctx, "JobUsecase.Retry")
+	defer span.End()
+
+	return j.transition(ctx, id, "jobUsecase.Retry", []domain.JobStatus{domain.JobDead, domain.JobCancelled}, j.jobRepo.Requeue)
+}
+
+// Cancel implements domain.JobUsecase.
+func (j *jobUsecase) Cancel(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	ctx, span := tracing.Start(ctx, "JobUsecase.Cancel")
+	defer span.End()
+
+	return j.transition(ctx, id, "jobUsecase.Cancel", []domain.JobStatus{domain.JobPending}, j.jobRepo.Cancel)
+}
+
+// transition applies update to a job in one of the from statuses and returns the updated job.
+func (j *jobUsecase) transition(
+	ctx context.Context,
+	id uuid.UUID,
+	op string,
+	from []domain.JobStatus,
+	update func(ctx context.Context, tx *sql.Tx, id uuid.UUID) error,
+) (*domain.Job, error) {
+	job, err := j.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		// A job being run is only leased, so it can be cancelled mid-run; the worker then leaves it cancelled
+		job, err := j.jobRepo.GetForUpdate(ctx, tx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errx.E(errx.CodeNotFound, "job not found", errx.Op(op), err)
+			}
+			return nil, errx.E(errx.CodeInternal, "failed to get job", errx.Op(op), err)
+		}
+
+		if !slices.Contains(from, job.Status) {
+			return nil, errx.E(errx.CodePrecondition, "job is "+string(job.Status), errx.Op(op))
+		}
+
+		if err := update(ctx, tx, id); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to update job", errx.Op(op), err)
+		}
+
+		updated, err := j.jobRepo.GetForUpdate(ctx, tx, id)
+		if err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to get job", errx.Op(op), err)
+		}
+
+		return updated, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return job.(*domain.Job), nil
+}
+
+// NewJobUsecase creates a job queue running handlers. Jobs of types without a handler are left for
+// other workers.
+func NewJobUsecase(db pqsql.Database, jobRepo domain.JobRepository, handlers domain.JobHandlers, logger log.Logger) domain.JobUsecase {
+	types := make([]domain.JobType, 0, len(handlers))
+	for jobType := range handlers {
+		types = append(types, jobType)
+	}
+	slices.Sort(types)
+
+	return &jobUsecase{
+		db:       db,
+		jobRepo:  jobRepo,
+		handlers: handlers,
+		types:    types,
+		logger:   logger,
+	}
+}
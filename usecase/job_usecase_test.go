@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testJobType domain.JobType = "test.echo"
+
+type testJobPayload struct {
+	Message string `json:"message"`
+}
+
+func (testJobPayload) JobType() domain.JobType { return testJobType }
+
+func TestJobUsecase_Enqueue_Defaults(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := mocks.NewMockJobRepository(t)
+	uc := NewJobUsecase(&fakeDB{}, jobRepo, nil, log.Nop())
+
+	jobRepo.EXPECT().Create(ctx, mock.Anything, mock.MatchedBy(func(job *domain.Job) bool {
+		return job.Type == testJobType &&
+			string(job.Payload) == `{"message":"hi"}` &&
+			job.Status == domain.JobPending &&
+			job.MaxAttempts == domain.DefaultJobMaxAttempts &&
+			time.Since(job.RunAt) < time.Second
+	})).Return(nil)
+
+	job, err := uc.Enqueue(ctx, nil, testJobPayload{Message: "hi"}, domain.EnqueueJobOptions{})
+	assert.NoError(t, err)
+	assert.NotNil(t, job)
+}
+
+func TestJobUsecase_RunBatch_DecodesTypedPayload(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := mocks.NewMockJobRepository(t)
+
+	var received string
+	uc := NewJobUsecase(&fakeDB{}, jobRepo, domain.JobHandlers{
+		testJobType: domain.HandleJob(func(ctx context.Context, payload testJobPayload) error {
+			received = payload.Message
+			return nil
+		}),
+	}, log.Nop())
+
+	job := domain.Job{ID: uuid.New(), Type: testJobType, Payload: json.RawMessage(`{"message":"hi"}`), Attempts: 1, MaxAttempts: 3}
+	jobRepo.EXPECT().ClaimDue(ctx, mock.Anything, []domain.JobType{testJobType}, jobLease).Return(&job, nil).Once()
+	jobRepo.EXPECT().ClaimDue(ctx, mock.Anything, []domain.JobType{testJobType}, jobLease).Return(nil, sql.ErrNoRows).Once()
+	jobRepo.EXPECT().MarkSucceeded(ctx, mock.Anything, &job).Return(nil)
+
+	picked, err := uc.RunBatch(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, picked)
+	assert.Equal(t, "hi", received)
+}
+
+func TestJobUsecase_RunBatch_RetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := mocks.NewMockJobRepository(t)
+	uc := NewJobUsecase(&fakeDB{}, jobRepo, domain.JobHandlers{
+		testJobType: func(ctx context.Context, payload json.RawMessage) error { return errors.New("boom") },
+	}, log.Nop())
+
+	job := domain.Job{ID: uuid.New(), Type: testJobType, Attempts: 2, MaxAttempts: 3}
+	jobRepo.EXPECT().ClaimDue(ctx, mock.Anything, mock.Anything, jobLease).Return(&job, nil).Once()
+	jobRepo.EXPECT().ClaimDue(ctx, mock.Anything, mock.Anything, jobLease).Return(nil, sql.ErrNoRows).Once()
+	jobRepo.EXPECT().MarkRetry(ctx, mock.Anything, &job, "boom", mock.MatchedBy(func(runAt time.Time) bool {
+		// second attempt failed: twice the base backoff
+		return time.Until(runAt) > 19*time.Second && time.Until(runAt) <= 20*time.Second
+	})).Return(nil)
+
+	_, err := uc.RunBatch(ctx, 10)
+	assert.NoError(t, err)
+}
+
+func TestJobUsecase_RunBatch_DeadLettersPanickingJob(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := mocks.NewMockJobRepository(t)
+	uc := NewJobUsecase(&fakeDB{}, jobRepo, domain.JobHandlers{
+		testJobType: func(ctx context.Context, payload json.RawMessage) error { panic("nil map") },
+	}, log.Nop())
+
+	job := domain.Job{ID: uuid.New(), Type: testJobType, Attempts: 3, MaxAttempts: 3}
+	jobRepo.EXPECT().ClaimDue(ctx, mock.Anything, mock.Anything, jobLease).Return(&job, nil).Once()
+	jobRepo.EXPECT().ClaimDue(ctx, mock.Anything, mock.Anything, jobLease).Return(nil, sql.ErrNoRows).Once()
+	jobRepo.EXPECT().MarkDead(ctx, mock.Anything, &job, "job panicked: nil map").Return(nil)
+
+	_, err := uc.RunBatch(ctx, 10)
+	assert.NoError(t, err)
+}
+
+func TestJobUsecase_RunBatch_KeepsEarlierOutcomes(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := mocks.NewMockJobRepository(t)
+
+	runs := 0
+	uc := NewJobUsecase(&fakeDB{}, jobRepo, domain.JobHandlers{
+		testJobType: func(ctx context.Context, payload json.RawMessage) error {
+			runs++
+			return nil
+		},
+	}, log.Nop())
+
+	first := domain.Job{ID: uuid.New(), Type: testJobType, Attempts: 1, MaxAttempts: 3}
+	second := domain.Job{ID: uuid.New(), Type: testJobType, Attempts: 1, MaxAttempts: 3}
+	jobRepo.EXPECT().ClaimDue(ctx, mock.Anything, mock.Anything, jobLease).Return(&first, nil).Once()
+	jobRepo.EXPECT().MarkSucceeded(ctx, mock.Anything, &first).Return(nil)
+	jobRepo.EXPECT().ClaimDue(ctx, mock.Anything, mock.Anything, jobLease).Return(&second, nil).Once()
+	jobRepo.EXPECT().MarkSucceeded(ctx, mock.Anything, &second).Return(errors.New("connection reset"))
+
+	picked, err := uc.RunBatch(ctx, 10)
+	assert.Error(t, err)
+	assert.Equal(t, 2, picked)
+	// the first outcome was committed on its own; only the second job is left to rerun
+	assert.Equal(t, 2, runs)
+}
+
+func TestJobUsecase_RunBatch_DropsOutcomeOfLostLease(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := mocks.NewMockJobRepository(t)
+	uc := NewJobUsecase(&fakeDB{}, jobRepo, domain.JobHandlers{
+		testJobType: func(ctx context.Context, payload json.RawMessage) error { return nil },
+	}, log.Nop())
+
+	lockedUntil := time.Now().Add(-time.Second)
+	job := domain.Job{ID: uuid.New(), Type: testJobType, Attempts: 1, MaxAttempts: 3, LockedUntil: &lockedUntil}
+	jobRepo.EXPECT().ClaimDue(ctx, mock.Anything, mock.Anything, jobLease).Return(&job, nil).Once()
+	jobRepo.EXPECT().ClaimDue(ctx, mock.Anything, mock.Anything, jobLease).Return(nil, sql.ErrNoRows).Once()
+	// the lease ran out and another worker claimed the job again
+	jobRepo.EXPECT().MarkSucceeded(ctx, mock.Anything, &job).Return(sql.ErrNoRows)
+
+	picked, err := uc.RunBatch(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, picked)
+}
+
+func TestJobUsecase_RunBatch_NoHandlers(t *testing.T) {
+	uc := NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), domain.JobHandlers{}, log.Nop())
+
+	picked, err := uc.RunBatch(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, picked)
+}
+
+func TestJobUsecase_Retry(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := mocks.NewMockJobRepository(t)
+	uc := NewJobUsecase(&fakeDB{}, jobRepo, nil, log.Nop())
+
+	id := uuid.New()
+	jobRepo.EXPECT().GetForUpdate(ctx, mock.Anything, id).Return(&domain.Job{ID: id, Status: domain.JobDead}, nil).Once()
+	jobRepo.EXPECT().Requeue(ctx, mock.Anything, id).Return(nil)
+	jobRepo.EXPECT().GetForUpdate(ctx, mock.Anything, id).Return(&domain.Job{ID: id, Status: domain.JobPending}, nil).Once()
+
+	job, err := uc.Retry(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.JobPending, job.Status)
+}
+
+func TestJobUsecase_Cancel_NotPending(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := mocks.NewMockJobRepository(t)
+	uc := NewJobUsecase(&fakeDB{}, jobRepo, nil, log.Nop())
+
+	id := uuid.New()
+	jobRepo.EXPECT().GetForUpdate(ctx, mock.Anything, id).Return(&domain.Job{ID: id, Status: domain.JobSucceeded}, nil)
+
+	job, err := uc.Cancel(ctx, id)
+	assert.Nil(t, job)
+	assert.True(t, errx.IsCode(err, errx.CodePrecondition))
+}
@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/dyaksa/encryption-pii/crypto/types"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/crypto"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/helper"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+type keyRotationUsecase struct {
+	db             pqsql.Database
+	userKeyRepo    domain.UserKeyRepository
+	webhookKeyRepo domain.WebhookKeyRepository
+	crypto         crypto.Crypto
+}
+
+// RekeyBatch implements domain.KeyRotationUsecase.
+// Users after afterID still holding values from an older key version are decrypted with
+// that version, then re-encrypted and re-indexed with the active one in a single transaction.
+func (k *keyRotationUsecase) RekeyBatch(ctx context.Context, afterID uuid.UUID, limit int) (domain.RekeyBatchResult, error) {
+	ctx, span := tracing.Start(ctx, "KeyRotationUsecase.RekeyBatch")
+	defer span.End()
+
+	result := domain.RekeyBatchResult{LastID: afterID}
+	version := k.crypto.KeyVersion()
+
+	_, err := k.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		users, err := k.userKeyRepo.ListStale(ctx, tx, version, afterID, limit)
+		if err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to list users to re-key", errx.Op("keyRotationUsecase.RekeyBatch"), err)
+		}
+
+		for _, user := range users {
+			rekeyed, err := k.rekey(user, version)
+			if err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to decrypt user", errx.Op("keyRotationUsecase.RekeyBatch"), err)
+			}
+
+			if err := k.userKeyRepo.Rekey(ctx, tx, rekeyed); err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to re-key user", errx.Op("keyRotationUsecase.RekeyBatch"), err)
+			}
+
+			result.Processed++
+			result.LastID = user.ID
+		}
+
+		result.Done = len(users) < limit
+
+		return nil, nil
+	})
+	if err != nil {
+		return domain.RekeyBatchResult{LastID: afterID}, err
+	}
+
+	return result, nil
+}
+
+// Remaining implements domain.KeyRotationUsecase.
+func (k *keyRotationUsecase) Remaining(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "KeyRotationUsecase.Remaining")
+	defer span.End()
+
+	count, err := k.userKeyRepo.CountStale(ctx, k.crypto.KeyVersion())
+	if err != nil {
+		return 0, errx.E(errx.CodeInternal, "failed to count users to re-key", errx.Op("keyRotationUsecase.Remaining"), err)
+	}
+
+	return count, nil
+}
+
+// RekeyWebhookBatch implements domain.KeyRotationUsecase.
+func (k *keyRotationUsecase) RekeyWebhookBatch(ctx context.Context, afterID uuid.UUID, limit int) (domain.RekeyBatchResult, error) {
+	ctx, span := tracing.Start(ctx, "KeyRotationUsecase.RekeyWebhookBatch")
+	defer span.End()
+
+	result := domain.RekeyBatchResult{LastID: afterID}
+	version := k.crypto.KeyVersion()
+
+	_, err := k.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		subs, err := k.webhookKeyRepo.ListStale(ctx, tx, version, afterID, limit)
+		if err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to list webhooks to re-key", errx.Op("keyRotationUsecase.RekeyWebhookBatch"), err)
+		}
+
+		for _, sub := range subs {
+			secret, err := k.decrypt(sub.KeyVersion, sub.Secret)
+			if err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to decrypt webhook secret", errx.Op("keyRotationUsecase.RekeyWebhookBatch"), err)
+			}
+
+			if err := k.webhookKeyRepo.Rekey(ctx, tx, sub.ID, *k.encrypt(secret), version); err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to re-key webhook secret", errx.Op("keyRotationUsecase.RekeyWebhookBatch"), err)
+			}
+
+			result.Processed++
+			result.LastID = sub.ID
+		}
+
+		result.Done = len(subs) < limit
+
+		return nil, nil
+	})
+	if err != nil {
+		return domain.RekeyBatchResult{LastID: afterID}, err
+	}
+
+	return result, nil
+}
+
+// RemainingWebhooks implements domain.KeyRotationUsecase.
+func (k *keyRotationUsecase) RemainingWebhooks(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "KeyRotationUsecase.RemainingWebhooks")
+	defer span.End()
+
+	count, err := k.webhookKeyRepo.CountStale(ctx, k.crypto.KeyVersion())
+	if err != nil {
+		return 0, errx.E(errx.CodeInternal, "failed to count webhooks to re-key", errx.Op("keyRotationUsecase.RemainingWebhooks"), err)
+	}
+
+	return count, nil
+}
+
+func (k *keyRotationUsecase) rekey(user domain.UserCiphertexts, version int) (domain.RekeyedUser, error) {
+	rekeyed := domain.RekeyedUser{ID: user.ID, KeyVersion: version}
+
+	if user.Email != nil {
+		email, err := k.decrypt(user.EmailKeyVersion, user.Email)
+		if err != nil {
+			return rekeyed, err
+		}
+		rekeyed.Email = k.encrypt(email)
+		rekeyed.EmailBidx = k.bidx(email)
+	}
+
+	// Phones are stored without the leading "+" but indexed in E.164 form.
+	if user.Phone != nil {
+		phone, err := k.decrypt(user.PhoneKeyVersion, user.Phone)
+		if err != nil {
+			return rekeyed, err
+		}
+		rekeyed.Phone = k.encrypt(phone)
+		rekeyed.PhoneBidx = k.bidx("+" + strings.TrimPrefix(phone, "+"))
+	}
+
+	if user.TOTPSecret != nil {
+		secret, err := k.decrypt(user.TOTPKeyVersion, user.TOTPSecret)
+		if err != nil {
+			return rekeyed, err
+		}
+		rekeyed.TOTPSecret = k.encrypt(secret)
+	}
+
+	return rekeyed, nil
+}
+
+func (k *keyRotationUsecase) decrypt(version int, ciphertext []byte) (string, error) {
+	c, err := k.crypto.DecryptVersion(version, "")
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.Scan(ciphertext); err != nil {
+		return "", err
+	}
+
+	return c.To(), nil
+}
+
+func (k *keyRotationUsecase) encrypt(plain string) *types.AESCipher {
+	c := k.crypto.Encrypt(plain)
+	return &c
+}
+
+// bidx indexes the identifier the same way lookups normalise it.
+func (k *keyRotationUsecase) bidx(identifier string) string {
+	if _, norm, ok := helper.NormalizeIdentifier(identifier); ok {
+		return k.crypto.HashString(norm)
+	}
+
+	return k.crypto.HashString(identifier)
+}
+
+func NewKeyRotationUsecase(
+	db pqsql.Database,
+	userKeyRepo domain.UserKeyRepository,
+	webhookKeyRepo domain.WebhookKeyRepository,
+	crypto crypto.Crypto,
+) domain.KeyRotationUsecase {
+	return &keyRotationUsecase{
+		db:             db,
+		userKeyRepo:    userKeyRepo,
+		webhookKeyRepo: webhookKeyRepo,
+		crypto:         crypto,
+	}
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dyaksa/warehouse/domain"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestKeyRotationUsecase_RekeyBatch_Empty(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockUserKeyRepository(t)
+	uc := NewKeyRotationUsecase(&fakeDB{}, repo, mocks.NewMockWebhookKeyRepository(t), simpleCryptoStub{})
+	after := uuid.New()
+
+	repo.EXPECT().ListStale(ctx, mock.Anything, 1, after, 50).Return(nil, nil)
+
+	result, err := uc.RekeyBatch(ctx, after, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Processed)
+	assert.Equal(t, after, result.LastID)
+	assert.True(t, result.Done)
+}
+
+func TestKeyRotationUsecase_RekeyBatch_AdvancesCursor(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockUserKeyRepository(t)
+	uc := NewKeyRotationUsecase(&fakeDB{}, repo, mocks.NewMockWebhookKeyRepository(t), simpleCryptoStub{})
+	first, second := uuid.New(), uuid.New()
+
+	// Erased users carry no ciphertexts; only their key versions are stamped.
+	repo.EXPECT().ListStale(ctx, mock.Anything, 1, uuid.Nil, 2).Return([]domain.UserCiphertexts{
+		{ID: first, EmailKeyVersion: 2, PhoneKeyVersion: 2},
+		{ID: second, EmailKeyVersion: 2, PhoneKeyVersion: 2},
+	}, nil)
+	repo.EXPECT().Rekey(ctx, mock.Anything, domain.RekeyedUser{ID: first, KeyVersion: 1}).Return(nil)
+	repo.EXPECT().Rekey(ctx, mock.Anything, domain.RekeyedUser{ID: second, KeyVersion: 1}).Return(nil)
+
+	result, err := uc.RekeyBatch(ctx, uuid.Nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Processed)
+	assert.Equal(t, second, result.LastID)
+	assert.False(t, result.Done)
+}
+
+func TestKeyRotationUsecase_RekeyBatch_RekeyError(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockUserKeyRepository(t)
+	uc := NewKeyRotationUsecase(&fakeDB{}, repo, mocks.NewMockWebhookKeyRepository(t), simpleCryptoStub{})
+	after := uuid.New()
+	id := uuid.New()
+
+	repo.EXPECT().ListStale(ctx, mock.Anything, 1, after, 10).Return([]domain.UserCiphertexts{{ID: id, EmailKeyVersion: 2}}, nil)
+	repo.EXPECT().Rekey(ctx, mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+	result, err := uc.RekeyBatch(ctx, after, 10)
+	assert.True(t, errx.IsCode(err, errx.CodeInternal))
+	assert.Equal(t, after, result.LastID)
+	assert.Equal(t, 0, result.Processed)
+}
+
+func TestKeyRotationUsecase_Remaining(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockUserKeyRepository(t)
+	uc := NewKeyRotationUsecase(&fakeDB{}, repo, mocks.NewMockWebhookKeyRepository(t), simpleCryptoStub{})
+
+	repo.EXPECT().CountStale(ctx, 1).Return(42, nil)
+
+	count, err := uc.Remaining(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, count)
+}
+
+func TestKeyRotationUsecase_RekeyWebhookBatch(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockWebhookKeyRepository(t)
+	uc := NewKeyRotationUsecase(&fakeDB{}, mocks.NewMockUserKeyRepository(t), repo, simpleCryptoStub{})
+	id := uuid.New()
+
+	repo.EXPECT().ListStale(ctx, mock.Anything, 1, uuid.Nil, 10).Return([]domain.WebhookSecretCiphertext{{ID: id, Secret: []byte("old"), KeyVersion: 0}}, nil)
+	repo.EXPECT().Rekey(ctx, mock.Anything, id, mock.Anything, 1).Return(nil)
+
+	result, err := uc.RekeyWebhookBatch(ctx, uuid.Nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Processed)
+	assert.Equal(t, id, result.LastID)
+	assert.True(t, result.Done)
+}
+
+func TestKeyRotationUsecase_RekeyWebhookBatch_UnknownKeyVersion(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockWebhookKeyRepository(t)
+	uc := NewKeyRotationUsecase(&fakeDB{}, mocks.NewMockUserKeyRepository(t), repo, simpleCryptoStub{})
+	after := uuid.New()
+
+	repo.EXPECT().ListStale(ctx, mock.Anything, 1, after, 10).Return([]domain.WebhookSecretCiphertext{{ID: uuid.New(), Secret: []byte("old"), KeyVersion: 3}}, nil)
+
+	result, err := uc.RekeyWebhookBatch(ctx, after, 10)
+	assert.True(t, errx.IsCode(err, errx.CodeInternal))
+	assert.Equal(t, after, result.LastID)
+}
@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/google/uuid"
+)
+
+// orderLifecycle moves an order between payment states inside a transaction owned by the caller,
+// so order changes can be committed together with whatever triggered them.
+type orderLifecycle struct {
+	orderRepo          domain.OrderRepository
+	orderItemRepo      domain.OrderItemRepository
+	refundRepo         domain.RefundRepository
+	reservationRepo    domain.ReservationRepository
+	movementRepository domain.MovementRepository
+	productStockRepo   domain.ProductStockRepository
+	outboxRepo         domain.OutboxRepository
+}
+
+// lock locks the order's PENDING reservations and then the order itself until tx ends. Every path
+// that confirms, cancels or extends an order takes its locks in this order, the one the stock
+// release worker uses, so none of them can deadlock or act on a status changed meanwhile.
+func (l orderLifecycle) lock(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (*domain.Order, []domain.Reservation, error) {
+	reservations, err := l.reservationRepo.LockPendingByOrder(ctx, tx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order, err := l.orderRepo.GetByIDForUpdate(ctx, tx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return order, reservations, nil
+}
+
+// confirmPayment commits the stock reserved for an order awaiting payment and marks it paid. The
+// order and its PENDING reservations must have been locked by the caller with lock.
+// It returns the number of committed reservations.
+func (l orderLifecycle) confirmPayment(ctx context.Context, tx *sql.Tx, order *domain.Order, reservations []domain.Reservation) (int, error) {
+	// 1. Validate order status
+	if order.Status != domain.StatusAwaitingPayment {
+		return 0, errx.E(errx.CodeValidation, "order cannot be confirmed in current status", errx.Op("OrderUsecase.ConfirmPayment"))
+	}
+
+	// 2. Every reservation of the order must still be pending
+	all, err := l.reservationRepo.GetByOrderID(ctx, tx, order.ID)
+	if err != nil {
+		return 0, errx.E(errx.CodeInternal, "failed to get reservations", errx.Op("OrderUsecase.ConfirmPayment"), err)
+	}
+
+	if len(all) == 0 {
+		return 0, errx.E(errx.CodeValidation, "no reservations found for order", errx.Op("OrderUsecase.ConfirmPayment"))
+	}
+
+	if len(all) != len(reservations) {
+		return 0, errx.E(errx.CodeValidation, "reservation is not in pending status", errx.Op("OrderUsecase.ConfirmPayment"))
+	}
+
+	// 3. Check if reservations are still valid (not expired)
+	now := time.Now()
+	for _, reservation := range reservations {
+		if reservation.ExpiresAt.Before(now) {
+			return 0, errx.E(errx.CodeValidation, "reservation has expired", errx.Op("OrderUsecase.ConfirmPayment"))
+		}
+	}
+
+	// 4. Commit stock for all reservations
+	for _, reservation := range reservations {
+		// Commit the stock (reduce on_hand, reduce reserved)
+		if err := l.productStockRepo.CommitStock(ctx, tx, reservation.ProductID, reservation.WarehouseID, int32(reservation.Qty)); err != nil {
+			return 0, errx.E(errx.CodeInternal, "failed to commit stock for reservation", errx.Op("OrderUsecase.ConfirmPayment"), err)
+		}
+
+		// Log stock movement
+		if err := l.movementRepository.Append(ctx, tx, reservation.ProductID, reservation.WarehouseID,
+			"COMMIT", reservation.Qty, "ORDER_PAYMENT", order.ID); err != nil {
+			return 0, errx.E(errx.CodeInternal, "failed to log stock commit", errx.Op("OrderUsecase.ConfirmPayment"), err)
+		}
+	}
+
+	// 5. Mark all reservations as committed
+	marked, err := l.reservationRepo.MarkCommitted(ctx, tx, order.ID)
+	if err != nil {
+		return 0, errx.E(errx.CodeInternal, "failed to mark reservations as committed", errx.Op("OrderUsecase.ConfirmPayment"), err)
+	}
+	if marked != len(reservations) {
+		return 0, errx.E(errx.CodeInternal, "committed reservations changed while confirming payment", errx.Op("OrderUsecase.ConfirmPayment"))
+	}
+
+	// 6. Update order status to paid
+	if err := l.orderRepo.Updatestatus(ctx, tx, order.ID, domain.StatusPaid); err != nil {
+		return 0, errx.E(errx.CodeInternal, "failed to update order status", errx.Op("OrderUsecase.ConfirmPayment"), err)
+	}
+
+	confirmed := domain.OrderPaymentConfirmed{
+		OrderID:     order.ID,
+		ShopID:      order.ShopID,
+		Total:       order.Total,
+		ConfirmedAt: now,
+	}
+	for _, reservation := range reservations {
+		confirmed.Items = append(confirmed.Items, domain.OrderEventItem{
+			ProductID:   reservation.ProductID,
+			WarehouseID: reservation.WarehouseID,
+			Qty:         reservation.Qty,
+		})
+	}
+
+	if err := l.outboxRepo.Append(ctx, tx, confirmed); err != nil {
+		return 0, errx.E(errx.CodeInternal, "failed to record order event", errx.Op("OrderUsecase.ConfirmPayment"), err)
+	}
+
+	for _, reservation := range reservations {
+		if err := l.outboxRepo.Append(ctx, tx, domain.StockDecreased{
+			ShopID:      order.ShopID,
+			ProductID:   reservation.ProductID,
+			WarehouseID: reservation.WarehouseID,
+			Qty:         reservation.Qty,
+			Reason:      "ORDER_PAYMENT",
+		}); err != nil {
+			return 0, errx.E(errx.CodeInternal, "failed to record stock event", errx.Op("OrderUsecase.ConfirmPayment"), err)
+		}
+	}
+
+	return len(reservations), nil
+}
+
+// cancel releases the stock still reserved for an unpaid order and marks it cancelled. The order and
+// its PENDING reservations must have been locked by the caller with lock.
+// It returns the number of released reservations.
+func (l orderLifecycle) cancel(ctx context.Context, tx *sql.Tx, order *domain.Order, reservations []domain.Reservation) (int, error) {
+	// 1. Validate order can be cancelled
+	if order.Status != domain.StatusAwaitingPayment && order.Status != domain.StatusPending {
+		return 0, errors.New("order cannot be cancelled in current status")
+	}
+
+	// 2. Release stock for all pending reservations
+	for _, reservation := range reservations {
+		// Release the reserved stock
+		if err := l.productStockRepo.ReleaseStock(ctx, tx, reservation.ProductID, reservation.WarehouseID, int32(reservation.Qty)); err != nil {
+			return 0, err
+		}
+
+		// Log stock movement
+		if err := l.movementRepository.Append(ctx, tx, reservation.ProductID, reservation.WarehouseID,
+			"RELEASE", reservation.Qty, "ORDER_CANCELLED", order.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	// 3. Mark reservations as released
+	marked, err := l.reservationRepo.MarkReleased(ctx, tx, order.ID)
+	if err != nil {
+		return 0, err
+	}
+	if marked != len(reservations) {
+		return 0, fmt.Errorf("released %d reservations but marked %d", len(reservations), marked)
+	}
+
+	// 4. Update order status to cancelled
+	if err := l.orderRepo.Updatestatus(ctx, tx, order.ID, domain.StatusCancelled); err != nil {
+		return 0, err
+	}
+
+	if err := l.outboxRepo.Append(ctx, tx, domain.OrderCancelled{OrderID: order.ID, ShopID: order.ShopID, CancelledAt: time.Now()}); err != nil {
+		return 0, err
+	}
+
+	return len(reservations), nil
+}
+
+// unrefunded returns the amount of the items of orderID not refunded yet.
+func (l orderLifecycle) unrefunded(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int64, error) {
+	items, err := l.orderItemRepo.GetByOrderID(ctx, tx, orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	refunded, err := l.refundRepo.RefundedQty(ctx, tx, orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	var amount int64
+	for _, item := range items {
+		amount += int64(item.Qty-refunded[item.ID]) * item.Price
+	}
+
+	return amount, nil
+}
+
+// refund records a refund of a paid order locked by the caller. Without input items, every item
+// not refunded yet is refunded. The order becomes REFUNDED once nothing is left to refund. The
+// refund is recorded as SUCCEEDED unless issue, when non-nil, arranges for the money to be returned
+// later and marks it PENDING.
+func (l orderLifecycle) refund(ctx context.Context, tx *sql.Tx, order *domain.Order, input domain.RefundOrderRequest, issue func(ctx context.Context, tx *sql.Tx, refund *domain.Refund) error) (*domain.Refund, error) {
+	// 1. Validate order status
+	if !slices.Contains(domain.RefundableStatuses, order.Status) {
+		return nil, errx.E(errx.CodePrecondition, "order cannot be refunded in current status", errx.Op("OrderUsecase.RefundOrder"))
+	}
+
+	// 2. Work out what is left to refund per item
+	items, err := l.orderItemRepo.GetByOrderID(ctx, tx, order.ID)
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to get order items", errx.Op("OrderUsecase.RefundOrder"), err)
+	}
+
+	refunded, err := l.refundRepo.RefundedQty(ctx, tx, order.ID)
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to get refunded quantities", errx.Op("OrderUsecase.RefundOrder"), err)
+	}
+
+	remaining := make(map[uuid.UUID]int, len(items))
+	for _, item := range items {
+		remaining[item.ID] = item.Qty - refunded[item.ID]
+	}
+
+	requested := make(map[uuid.UUID]int)
+	if len(input.Items) == 0 {
+		for _, item := range items {
+			if remaining[item.ID] > 0 {
+				requested[item.ID] = remaining[item.ID]
+			}
+		}
+	}
+	for _, in := range input.Items {
+		itemID, err := uuid.Parse(in.OrderItemID)
+		if err != nil {
+			return nil, errx.E(errx.CodeValidation, "invalid order item id", errx.Op("OrderUsecase.RefundOrder"), err)
+		}
+		if _, ok := remaining[itemID]; !ok {
+			return nil, errx.E(errx.CodeValidation, "item does not belong to the order", errx.Op("OrderUsecase.RefundOrder"))
+		}
+		if in.Qty <= 0 {
+			return nil, errx.E(errx.CodeValidation, "refund quantity must be positive", errx.Op("OrderUsecase.RefundOrder"))
+		}
+		requested[itemID] += in.Qty
+	}
+
+	if len(requested) == 0 {
+		return nil, errx.E(errx.CodePrecondition, "order has nothing left to refund", errx.Op("OrderUsecase.RefundOrder"))
+	}
+
+	// 3. Restocked quantities go back to the warehouse the item was committed from
+	warehouses := make(map[uuid.UUID]uuid.UUID)
+	if input.Restock {
+		reservations, err := l.reservationRepo.GetByOrderID(ctx, tx, order.ID)
+		if err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to get reservations", errx.Op("OrderUsecase.RefundOrder"), err)
+		}
+		for _, reservation := range reservations {
+			if _, ok := warehouses[reservation.ProductID]; !ok && reservation.Status == domain.ResvCommitted {
+				warehouses[reservation.ProductID] = reservation.WarehouseID
+			}
+		}
+	}
+
+	refund := &domain.Refund{
+		ID:      uuid.New(),
+		OrderID: order.ID,
+		Reason:  input.Reason,
+		Restock: input.Restock,
+		Status:  domain.RefundSucceeded,
+	}
+	event := domain.OrderRefunded{OrderID: order.ID, ShopID: order.ShopID, RefundID: refund.ID, Restock: input.Restock}
+
+	for _, item := range items {
+		qty, ok := requested[item.ID]
+		if !ok {
+			continue
+		}
+		if qty > remaining[item.ID] {
+			return nil, errx.E(errx.CodeValidation, "refund quantity exceeds the quantity left to refund", errx.Op("OrderUsecase.RefundOrder"))
+		}
+		remaining[item.ID] -= qty
+
+		line := domain.RefundItem{
+			ID:          uuid.New(),
+			RefundID:    refund.ID,
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			Qty:         qty,
+			Amount:      int64(qty) * item.Price,
+		}
+		eventItem := domain.OrderEventItem{ProductID: item.ProductID, Qty: qty, Price: item.Price}
+
+		if input.Restock {
+			warehouseID, ok := warehouses[item.ProductID]
+			if !ok {
+				return nil, errx.E(errx.CodePrecondition, "no committed stock to restock the item into", errx.Op("OrderUsecase.RefundOrder"))
+			}
+
+			if err := l.productStockRepo.AddStock(ctx, tx, item.ProductID, warehouseID, int32(qty)); err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to restock refunded item", errx.Op("OrderUsecase.RefundOrder"), err)
+			}
+
+			if err := l.movementRepository.Append(ctx, tx, item.ProductID, warehouseID, "IN", qty, "ORDER_REFUND", refund.ID); err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to log stock restock", errx.Op("OrderUsecase.RefundOrder"), err)
+			}
+
+			line.WarehouseID = &warehouseID
+			eventItem.WarehouseID = warehouseID
+		}
+
+		refund.Amount += line.Amount
+		refund.Items = append(refund.Items, line)
+		event.Items = append(event.Items, eventItem)
+	}
+
+	// 4. Arrange the money's return, record the refund and move the order to its refund status
+	if issue != nil {
+		if err := issue(ctx, tx, refund); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.refundRepo.Create(ctx, tx, refund); err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to save refund", errx.Op("OrderUsecase.RefundOrder"), err)
+	}
+
+	event.Status = domain.StatusRefunded
+	for _, left := range remaining {
+		if left > 0 {
+			event.Status = domain.StatusPartiallyRefunded
+			break
+		}
+	}
+
+	if err := l.orderRepo.Updatestatus(ctx, tx, order.ID, event.Status); err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to update order status", errx.Op("OrderUsecase.RefundOrder"), err)
+	}
+
+	event.Amount = refund.Amount
+	if err := l.outboxRepo.Append(ctx, tx, event); err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to record order event", errx.Op("OrderUsecase.RefundOrder"), err)
+	}
+
+	return refund, nil
+}
@@ -3,29 +3,32 @@ package usecase
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
 	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/metrics"
 	"github.com/dyaksa/warehouse/pkg/paginator"
+	"github.com/dyaksa/warehouse/pkg/tracing"
 	"github.com/google/uuid"
 )
 
 type orderUsecase struct {
-	db                 pqsql.Database
-	orderRepo          domain.OrderRepository
-	idemRepo           domain.IdempotencyRequestRepository
-	orderItemRepo      domain.OrderItemRepository
-	reservationRepo    domain.ReservationRepository
-	movementRepository domain.MovementRepository
-	productStockRepo   domain.ProductStockRepository
-	pickWarehouseRepo  domain.WarehouseRepository
+	orderLifecycle
+	db                pqsql.Database
+	pickWarehouseRepo domain.WarehouseRepository
+	paymentRepo       domain.PaymentRepository
+	jobUsecase        domain.JobUsecase
+	shopRepo          domain.ShopRepository
+	holdLimits        domain.ReservationHoldLimits
 }
 
 func (o *orderUsecase) Checkout(ctx context.Context, input domain.CheckoutInput) (*domain.CheckoutOutput, error) {
+	ctx, span := tracing.Start(ctx, "OrderUsecase.Checkout")
+	defer span.End()
+
 	if len(input.Items) == 0 {
 		return nil, errx.E(errx.CodeValidation, "order must contain at least one item", errx.Op("OrderUsecase.Checkout"))
 	}
@@ -38,39 +41,9 @@ func (o *orderUsecase) Checkout(ctx context.Context, input domain.CheckoutInput)
 	}
 
 	out := &domain.CheckoutOutput{}
+	reserved := 0
 
 	_, err := o.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
-		if input.IdemKey != "" {
-			isNew, err := o.idemRepo.BeginKey(ctx, tx, input.IdemKey, "checkout", input.PayloadHash)
-			if err != nil {
-				return nil, errx.E(errx.CodeInternal, "failed to begin idempotency key", errx.Op("OrderUsecase.Checkout"), err)
-			}
-
-			if !isNew {
-				payloadHash, orderID, responseJSON, exists, err := o.idemRepo.LoadIfExists(ctx, tx, input.IdemKey, "checkout")
-				if err != nil {
-					return nil, errx.E(errx.CodeInternal, "failed to load idempotency key", errx.Op("OrderUsecase.Checkout"), err)
-				}
-
-				if !exists {
-					return nil, errx.E(errx.CodeInternal, "idempotency key not found", errx.Op("OrderUsecase.Checkout"), domain.ErrIdempotencyConflict)
-				}
-
-				if payloadHash != input.PayloadHash {
-					return nil, errx.E(errx.CodeInternal, "idempotency key payload hash mismatch", errx.Op("OrderUsecase.Checkout"), domain.ErrIdempotencyConflict)
-				}
-
-				if orderID != nil && len(responseJSON) > 0 {
-					var existingOut domain.CheckoutOutput
-					if err = json.Unmarshal(responseJSON, &existingOut); err != nil {
-						return nil, errx.E(errx.CodeInternal, "failed to unmarshal idempotent response", errx.Op("OrderUsecase.Checkout"), err)
-					}
-					*out = existingOut
-					return out, nil
-				}
-			}
-		}
-
 		shopId, err := uuid.Parse(input.ShopID)
 		if err != nil {
 			return nil, errx.E(errx.CodeValidation, "invalid shop ID format", errx.Op("OrderUsecase.Checkout"), err)
@@ -105,7 +78,7 @@ func (o *orderUsecase) Checkout(ctx context.Context, input domain.CheckoutInput)
 			_, err = o.pickWarehouseRepo.Pick(ctx, tx, productId, item.Qty, shopId)
 			if err != nil {
 				if errors.Is(err, domain.ErrOutOfStock) {
-					return nil, errx.E(errx.CodeValidation, "insufficient stock for product", errx.Op("OrderUsecase.Checkout"), errors.New(item.ProductID))
+					return nil, errx.E(errx.CodeValidation, "insufficient stock for product", errx.Op("OrderUsecase.Checkout"), errors.Join(domain.ErrOutOfStock, errors.New(item.ProductID)))
 				}
 				return nil, errx.E(errx.CodeInternal, "failed to validate product stock", errx.Op("OrderUsecase.Checkout"), err)
 			}
@@ -114,13 +87,14 @@ func (o *orderUsecase) Checkout(ctx context.Context, input domain.CheckoutInput)
 		}
 
 		// Step 2: Create order
+		reservationExpiry := time.Now().Add(input.ReservationTTL)
 		order := &domain.Order{
 			ID:            uuid.New(),
 			ShopID:        shopId,
 			UserID:        userId,
 			Total:         total,
 			Status:        domain.StatusAwaitingPayment,
-			ReservedUntil: &time.Time{},
+			ReservedUntil: &reservationExpiry,
 		}
 
 		if err = o.orderRepo.Create(ctx, tx, order); err != nil {
@@ -145,7 +119,6 @@ func (o *orderUsecase) Checkout(ctx context.Context, input domain.CheckoutInput)
 			return nil, err
 		}
 
-		reservationExpiry := time.Now().Add(input.ReservationTTL)
 		var reservations []domain.Reservation
 
 		for _, item := range input.Items {
@@ -185,154 +158,297 @@ func (o *orderUsecase) Checkout(ctx context.Context, input domain.CheckoutInput)
 			return nil, errx.E(errx.CodeInternal, "failed to create reservations", errx.Op("OrderUsecase.Checkout"), err)
 		}
 
-		order.ReservedUntil = &reservationExpiry
+		reserved = len(reservations)
+
+		checkedOut := domain.OrderCheckedOut{
+			OrderID:              order.ID,
+			ShopID:               order.ShopID,
+			UserID:               order.UserID,
+			Total:                order.Total,
+			ReservationExpiresAt: reservationExpiry,
+		}
+		for i, reservation := range reservations {
+			checkedOut.Items = append(checkedOut.Items, domain.OrderEventItem{
+				ProductID:   reservation.ProductID,
+				WarehouseID: reservation.WarehouseID,
+				Qty:         reservation.Qty,
+				Price:       orderItems[i].Price,
+			})
+		}
+
+		if err = o.outboxRepo.Append(ctx, tx, checkedOut); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to record order event", errx.Op("OrderUsecase.Checkout"), err)
+		}
 
 		out.OrderID = order.ID
 		out.Total = order.Total
 		out.Status = string(order.Status)
 		out.ReservationExpiresAt = reservationExpiry
 
-		if input.IdemKey != "" {
-			responseJSON, err := json.Marshal(out)
-			if err != nil {
-				return nil, errx.E(errx.CodeInternal, "failed to marshal response", errx.Op("OrderUsecase.Checkout"), err)
-			}
-
-			if err = o.idemRepo.SaveResponse(ctx, tx, input.IdemKey, "checkout", out.OrderID, responseJSON); err != nil {
-				return nil, errx.E(errx.CodeInternal, "failed to save response", errx.Op("OrderUsecase.Checkout"), err)
-			}
-		}
-
 		return out, nil
 	})
 
+	observeCheckout(err, reserved)
+	if err == nil {
+		span.SetAttributes(tracing.OrderID(out.OrderID))
+	}
+	tracing.RecordError(span, err)
+
 	return out, err
 }
 
+// observeCheckout records the checkout result. Replayed idempotent checkouts reserve nothing.
+func observeCheckout(err error, reserved int) {
+	switch {
+	case err == nil:
+		metrics.Checkouts.WithLabelValues("success").Inc()
+		metrics.Reservations.WithLabelValues(metrics.ReservationCreated).Add(float64(reserved))
+	case errors.Is(err, domain.ErrOutOfStock):
+		metrics.Checkouts.WithLabelValues("out_of_stock").Inc()
+		metrics.OutOfStockRejections.Inc()
+	case errx.IsCode(err, errx.CodeValidation):
+		metrics.Checkouts.WithLabelValues("rejected").Inc()
+	default:
+		metrics.Checkouts.WithLabelValues("error").Inc()
+	}
+}
+
 // ConfirmPayment implements domain.OrderUsecase.
 func (o *orderUsecase) ConfirmPayment(ctx context.Context, orderID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "OrderUsecase.ConfirmPayment", tracing.OrderID(orderID))
+	defer span.End()
+
+	committed := 0
+
 	_, err := o.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
-		// 1. Get order details
-		order, err := o.orderRepo.GetByID(ctx, orderID)
+		order, reservations, err := o.lock(ctx, tx, orderID)
 		if err != nil {
 			return nil, errx.E(errx.CodeInternal, "failed to get order", errx.Op("OrderUsecase.ConfirmPayment"), err)
 		}
 
-		// 2. Validate order status
-		if order.Status != domain.StatusAwaitingPayment {
-			return nil, errx.E(errx.CodeValidation, "order cannot be confirmed in current status", errx.Op("OrderUsecase.ConfirmPayment"))
+		committed, err = o.confirmPayment(ctx, tx, order, reservations)
+		return nil, err
+	})
+	if err == nil {
+		metrics.Reservations.WithLabelValues(metrics.ReservationCommitted).Add(float64(committed))
+	}
+
+	return err
+}
+
+// CancelOrder implements domain.OrderUsecase.
+func (o *orderUsecase) CancelOrder(ctx context.Context, orderID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "OrderUsecase.CancelOrder", tracing.OrderID(orderID))
+	defer span.End()
+
+	released := 0
+
+	_, err := o.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		order, reservations, err := o.lock(ctx, tx, orderID)
+		if err != nil {
+			return nil, err
 		}
 
-		// 3. Get all pending reservations for this order
-		reservations, err := o.reservationRepo.GetByOrderID(ctx, tx, orderID)
+		released, err = o.cancel(ctx, tx, order, reservations)
+		return nil, err
+	})
+	if err == nil {
+		metrics.Reservations.WithLabelValues(metrics.ReservationReleased).Add(float64(released))
+	}
+
+	return err
+}
+
+// ExtendReservation implements domain.OrderUsecase.
+//
+// The PENDING reservations and then the order are locked first, in the stock release worker's lock
+// order: the worker skips locked rows, and if it locked them first this waits and then finds them
+// expired. The new expiry never passes the shop's maximum hold time counted from checkout.
+func (o *orderUsecase) ExtendReservation(ctx context.Context, orderID uuid.UUID, input domain.ExtendReservationRequest) (*domain.ExtendReservationOutput, error) {
+	ctx, span := tracing.Start(ctx, "OrderUsecase.ExtendReservation", tracing.OrderID(orderID))
+	defer span.End()
+
+	extension := time.Duration(input.Minutes) * time.Minute
+	if input.Minutes <= 0 {
+		extension = o.holdLimits.Extension
+	}
+
+	extended := 0
+
+	out, err := o.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		order, reservations, err := o.lock(ctx, tx, orderID)
 		if err != nil {
-			return nil, errx.E(errx.CodeInternal, "failed to get reservations", errx.Op("OrderUsecase.ConfirmPayment"), err)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errx.E(errx.CodeNotFound, "order not found", errx.Op("OrderUsecase.ExtendReservation"), err)
+			}
+			return nil, errx.E(errx.CodeInternal, "failed to get order", errx.Op("OrderUsecase.ExtendReservation"), err)
+		}
+
+		if order.Status != domain.StatusAwaitingPayment && order.Status != domain.StatusPending {
+			return nil, errx.E(errx.CodePrecondition, "only unpaid orders can extend their reservation", errx.Op("OrderUsecase.ExtendReservation"))
+		}
+
+		limit := o.holdLimits.For(order.ShopID)
+		if order.ReservationExtensions >= limit.MaxExtensions {
+			return nil, errx.E(errx.CodePrecondition, "reservation extension limit reached", errx.Op("OrderUsecase.ExtendReservation"))
 		}
 
 		if len(reservations) == 0 {
-			return nil, errx.E(errx.CodeValidation, "no reservations found for order", errx.Op("OrderUsecase.ConfirmPayment"))
+			return nil, errx.E(errx.CodePrecondition, "order has no pending reservations", errx.Op("OrderUsecase.ExtendReservation"))
 		}
 
-		// 4. Check if reservations are still valid (not expired)
+		// Reservations of an order share their expiry; the latest one is extended from so that none
+		// is shortened if they have drifted apart.
 		now := time.Now()
+		current := reservations[0].ExpiresAt
 		for _, reservation := range reservations {
-			if reservation.Status != domain.ResvPending {
-				return nil, errx.E(errx.CodeValidation, "reservation is not in pending status", errx.Op("OrderUsecase.ConfirmPayment"))
+			if !reservation.ExpiresAt.After(now) {
+				return nil, errx.E(errx.CodePrecondition, "reservation has already expired", errx.Op("OrderUsecase.ExtendReservation"))
 			}
-			if reservation.ExpiresAt.Before(now) {
-				return nil, errx.E(errx.CodeValidation, "reservation has expired", errx.Op("OrderUsecase.ConfirmPayment"))
+			if reservation.ExpiresAt.After(current) {
+				current = reservation.ExpiresAt
 			}
 		}
 
-		// 5. Commit stock for all reservations
-		for _, reservation := range reservations {
-			// Commit the stock (reduce on_hand, reduce reserved)
-			if err := o.productStockRepo.CommitStock(ctx, tx, reservation.ProductID, reservation.WarehouseID, int32(reservation.Qty)); err != nil {
-				return nil, errx.E(errx.CodeInternal, "failed to commit stock for reservation", errx.Op("OrderUsecase.ConfirmPayment"), err)
-			}
+		holdUntil := order.CreatedAt.Add(limit.MaxHold)
+		expiresAt := current.Add(extension)
+		if expiresAt.After(holdUntil) {
+			expiresAt = holdUntil
+		}
+		if !expiresAt.After(current) {
+			return nil, errx.E(errx.CodePrecondition, "maximum reservation hold time reached", errx.Op("OrderUsecase.ExtendReservation"))
+		}
 
-			// Log stock movement
-			if err := o.movementRepository.Append(ctx, tx, reservation.ProductID, reservation.WarehouseID,
-				"COMMIT", reservation.Qty, "ORDER_PAYMENT", orderID); err != nil {
-				return nil, errx.E(errx.CodeInternal, "failed to log stock commit", errx.Op("OrderUsecase.ConfirmPayment"), err)
-			}
+		if err := o.reservationRepo.ExtendPending(ctx, tx, orderID, expiresAt); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to extend reservations", errx.Op("OrderUsecase.ExtendReservation"), err)
 		}
 
-		// 6. Mark all reservations as committed
-		if err := o.reservationRepo.MarkCommitted(ctx, tx, orderID); err != nil {
-			return nil, errx.E(errx.CodeInternal, "failed to mark reservations as committed", errx.Op("OrderUsecase.ConfirmPayment"), err)
+		if err := o.orderRepo.ExtendReservation(ctx, tx, orderID, expiresAt); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to extend order reservation", errx.Op("OrderUsecase.ExtendReservation"), err)
 		}
 
-		// 7. Update order status to paid
-		if err := o.orderRepo.Updatestatus(ctx, orderID, domain.StatusPaid); err != nil {
-			return nil, errx.E(errx.CodeInternal, "failed to update order status", errx.Op("OrderUsecase.ConfirmPayment"), err)
+		extensions := order.ReservationExtensions + 1
+		if err := o.outboxRepo.Append(ctx, tx, domain.OrderReservationExtended{
+			OrderID:              orderID,
+			ShopID:               order.ShopID,
+			ReservationExpiresAt: expiresAt,
+			Extensions:           extensions,
+		}); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to record order event", errx.Op("OrderUsecase.ExtendReservation"), err)
 		}
 
-		return nil, nil
+		extended = len(reservations)
+
+		return &domain.ExtendReservationOutput{
+			OrderID:              orderID,
+			ReservationExpiresAt: expiresAt,
+			Extensions:           extensions,
+			ExtensionsLeft:       limit.MaxExtensions - extensions,
+			HoldUntil:            holdUntil,
+		}, nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	metrics.Reservations.WithLabelValues(metrics.ReservationExtended).Add(float64(extended))
+
+	return out.(*domain.ExtendReservationOutput), nil
 }
 
-// CancelOrder implements domain.OrderUsecase.
-func (o *orderUsecase) CancelOrder(ctx context.Context, orderID uuid.UUID) error {
-	_, err := o.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
-		// 1. Get order details
-		order, err := o.orderRepo.GetByID(ctx, orderID)
-		if err != nil {
-			return nil, err
-		}
+// RefundOrder implements domain.OrderUsecase.
+func (o *orderUsecase) RefundOrder(ctx context.Context, orderID uuid.UUID, input domain.RefundOrderRequest) (*domain.Refund, error) {
+	ctx, span := tracing.Start(ctx, "OrderUsecase.RefundOrder", tracing.OrderID(orderID))
+	defer span.End()
 
-		// 2. Validate order can be cancelled
-		if order.Status != domain.StatusAwaitingPayment && order.Status != domain.StatusPending {
-			return nil, errors.New("order cannot be cancelled in current status")
-		}
+	return o.refundOrder(ctx, orderID, input, nil)
+}
 
-		// 3. Get all pending reservations
-		reservations, err := o.reservationRepo.GetByOrderID(ctx, tx, orderID)
-		if err != nil {
-			return nil, err
-		}
+// RefundOrderAsOwner implements domain.OrderUsecase.
+func (o *orderUsecase) RefundOrderAsOwner(ctx context.Context, orderID, userID uuid.UUID, input domain.RefundOrderRequest) (*domain.Refund, error) {
+	ctx, span := tracing.Start(ctx, "OrderUsecase.RefundOrderAsOwner", tracing.OrderID(orderID), tracing.UserID(userID))
+	defer span.End()
 
-		// 4. Release stock for all pending reservations
-		for _, reservation := range reservations {
-			if reservation.Status == domain.ResvPending {
-				// Release the reserved stock
-				if err := o.productStockRepo.ReleaseStock(ctx, tx, reservation.ProductID, reservation.WarehouseID, int32(reservation.Qty)); err != nil {
-					return nil, err
-				}
+	return o.refundOrder(ctx, orderID, input, func(ctx context.Context, order *domain.Order) error {
+		return ensureShopOwner(ctx, o.shopRepo, order.ShopID, userID, "OrderUsecase.RefundOrderAsOwner")
+	})
+}
 
-				// Log stock movement
-				if err := o.movementRepository.Append(ctx, tx, reservation.ProductID, reservation.WarehouseID,
-					"RELEASE", reservation.Qty, "ORDER_CANCELLED", orderID); err != nil {
-					return nil, err
-				}
+// refundOrder locks the order, lets authorize reject the caller and records the refund. Money paid
+// through the payment provider is returned by a job once the refund is committed, so a slow or
+// failing provider neither holds the order lock nor loses track of a refund it already made.
+func (o *orderUsecase) refundOrder(ctx context.Context, orderID uuid.UUID, input domain.RefundOrderRequest, authorize func(ctx context.Context, order *domain.Order) error) (*domain.Refund, error) {
+	refund, err := o.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		// Concurrent refunds of the same order must see each other's quantities
+		order, err := o.orderRepo.GetByIDForUpdate(ctx, tx, orderID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errx.E(errx.CodeNotFound, "order not found", errx.Op("OrderUsecase.RefundOrder"), err)
 			}
+			return nil, errx.E(errx.CodeInternal, "failed to get order", errx.Op("OrderUsecase.RefundOrder"), err)
 		}
 
-		// 5. Mark reservations as released
-		if err := o.reservationRepo.MarkReleased(ctx, tx, orderID); err != nil {
-			return nil, err
-		}
-
-		// 6. Update order status to cancelled
-		if err := o.orderRepo.Updatestatus(ctx, orderID, domain.StatusCancelled); err != nil {
-			return nil, err
+		if authorize != nil {
+			if err := authorize(ctx, order); err != nil {
+				return nil, err
+			}
 		}
 
-		return nil, nil
+		return o.refund(ctx, tx, order, input, o.issueRefund)
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	return refund.(*domain.Refund), nil
+}
+
+// issueRefund leaves the refund PENDING and queues a domain.RefundIssueJob to return it through the
+// provider the order was paid with. Orders confirmed by hand have no paid intent, so their money is
+// returned outside the system.
+func (o *orderUsecase) issueRefund(ctx context.Context, tx *sql.Tx, refund *domain.Refund) error {
+	_, err := o.paymentRepo.GetPaidIntent(ctx, tx, refund.OrderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to get payment intent", errx.Op("OrderUsecase.RefundOrder"), err)
+	}
+
+	refund.Status = domain.RefundPending
+	if _, err := o.jobUsecase.Enqueue(ctx, tx, domain.RefundIssueJob{RefundID: refund.ID}, domain.EnqueueJobOptions{}); err != nil {
+		return errx.E(errx.CodeInternal, "failed to queue refund", errx.Op("OrderUsecase.RefundOrder"), err)
+	}
+
+	return nil
+}
+
+// ListRefunds implements domain.OrderUsecase.
+func (o *orderUsecase) ListRefunds(ctx context.Context, orderID uuid.UUID) ([]domain.Refund, error) {
+	ctx, span := tracing.Start(ctx, "OrderUsecase.ListRefunds", tracing.OrderID(orderID))
+	defer span.End()
+
+	refunds, err := o.refundRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to list refunds", errx.Op("OrderUsecase.ListRefunds"), err)
+	}
+
+	return refunds, nil
 }
 
 // GetOrderDetails implements domain.OrderUsecase.
 func (o *orderUsecase) GetOrderDetails(ctx context.Context, orderID uuid.UUID) (*domain.Order, error) {
+	ctx, span := tracing.Start(ctx, "OrderUsecase.GetOrderDetails", tracing.OrderID(orderID))
+	defer span.End()
+
 	return o.orderRepo.GetByID(ctx, orderID)
 }
 
 // GetUserOrders implements domain.OrderUsecase.
 func (o *orderUsecase) GetUserOrders(ctx context.Context, userID uuid.UUID, pagination paginator.PaginationRequest) (*paginator.PaginationResult[domain.OrderListItem], error) {
+	ctx, span := tracing.Start(ctx, "OrderUsecase.GetUserOrders", tracing.UserID(userID))
+	defer span.End()
+
 	pagination.ValidateAndSetDefault()
 	offset := pagination.GetOffset()
 
@@ -344,23 +460,86 @@ func (o *orderUsecase) GetUserOrders(ctx context.Context, userID uuid.UUID, pagi
 	return paginator.NewPaginationResult(orders, totalCount, pagination), nil
 }
 
+// GetShopOrders implements domain.OrderUsecase.
+func (o *orderUsecase) GetShopOrders(ctx context.Context, shopID uuid.UUID, pagination paginator.PaginationRequest) (*paginator.PaginationResult[domain.OrderListItem], error) {
+	ctx, span := tracing.Start(ctx, "OrderUsecase.GetShopOrders", tracing.ShopID(shopID))
+	defer span.End()
+
+	pagination.ValidateAndSetDefault()
+	offset := pagination.GetOffset()
+
+	orders, totalCount, err := o.orderRepo.GetByShopID(ctx, shopID, pagination.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginator.NewPaginationResult(orders, totalCount, pagination), nil
+}
+
 func NewOrderUsecase(
 	db pqsql.Database,
 	orderRepo domain.OrderRepository,
-	idemRepo domain.IdempotencyRequestRepository,
 	orderItemRepo domain.OrderItemRepository,
 	reservationRepo domain.ReservationRepository,
 	movementRepository domain.MovementRepository,
 	productStockRepo domain.ProductStockRepository,
-	pickWarehouseRepo domain.WarehouseRepository) domain.OrderUsecase {
+	pickWarehouseRepo domain.WarehouseRepository,
+	outboxRepo domain.OutboxRepository,
+	refundRepo domain.RefundRepository,
+	paymentRepo domain.PaymentRepository,
+	jobUsecase domain.JobUsecase,
+	shopRepo domain.ShopRepository,
+	holdLimits domain.ReservationHoldLimits) domain.OrderUsecase {
 	return &orderUsecase{
-		db:                 db,
-		orderRepo:          orderRepo,
-		idemRepo:           idemRepo,
-		orderItemRepo:      orderItemRepo,
-		reservationRepo:    reservationRepo,
-		movementRepository: movementRepository,
-		productStockRepo:   productStockRepo,
-		pickWarehouseRepo:  pickWarehouseRepo,
+		orderLifecycle: orderLifecycle{
+			orderRepo:          orderRepo,
+			orderItemRepo:      orderItemRepo,
+			refundRepo:         refundRepo,
+			reservationRepo:    reservationRepo,
+			movementRepository: movementRepository,
+			productStockRepo:   productStockRepo,
+			outboxRepo:         outboxRepo,
+		},
+		db:                db,
+		pickWarehouseRepo: pickWarehouseRepo,
+		paymentRepo:       paymentRepo,
+		jobUsecase:        jobUsecase,
+		shopRepo:          shopRepo,
+		holdLimits:        holdLimits,
 	}
 }
+
+// NewRefundIssueJobHandler handles domain.RefundIssueJob: it asks the payment provider to return a
+// PENDING refund and marks it SUCCEEDED. The provider treats repeated calls with the same refund ID
+// as one refund, so a job retried after the provider call does no harm.
+func NewRefundIssueJobHandler(db pqsql.Database, refundRepo domain.RefundRepository, paymentRepo domain.PaymentRepository, provider domain.PaymentProvider) domain.JobHandler {
+	return domain.HandleJob(func(ctx context.Context, job domain.RefundIssueJob) error {
+		var (
+			refund *domain.Refund
+			intent *domain.PaymentIntent
+		)
+
+		_, err := db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+			var err error
+			if refund, err = refundRepo.Get(ctx, tx, job.RefundID); err != nil || refund.Status != domain.RefundPending {
+				return nil, err
+			}
+
+			intent, err = paymentRepo.GetPaidIntent(ctx, tx, refund.OrderID)
+			return nil, err
+		})
+		if err != nil || intent == nil {
+			return err
+		}
+
+		if err := provider.Refund(ctx, intent.ProviderRef, refund.ID, refund.Amount); err != nil {
+			return err
+		}
+
+		_, err = db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+			return nil, refundRepo.UpdateStatus(ctx, tx, refund.ID, domain.RefundSucceeded)
+		})
+
+		return err
+	})
+}
@@ -8,13 +8,41 @@ import (
 	"time"
 
 	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/payment"
 	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/metrics"
 	"github.com/dyaksa/warehouse/pkg/paginator"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// refundRecordingProvider records the refund amounts issued through it and fails them with err.
+type refundRecordingProvider struct {
+	*payment.Fake
+	err     error
+	refunds []int64
+}
+
+func (p *refundRecordingProvider) Refund(ctx context.Context, providerRef string, refundID uuid.UUID, amount int64) error {
+	p.refunds = append(p.refunds, amount)
+	return p.err
+}
+
+// expectRefundIssueJob returns a job usecase whose repository expects the refund.issue job of
+// one refund to be enqueued.
+func expectRefundIssueJob(t *testing.T, ctx context.Context) domain.JobUsecase {
+	jobRepo := mocks.NewMockJobRepository(t)
+	jobRepo.EXPECT().Create(ctx, mock.Anything, mock.MatchedBy(func(job *domain.Job) bool {
+		return job.Type == domain.JobRefundIssue
+	})).Return(nil)
+
+	return NewJobUsecase(&fakeDB{}, jobRepo, nil, log.Nop())
+}
+
 // fakeDB implements pqsql.Database minimal methods used by orderUsecase
 type fakeDB struct {
 	txErr error
@@ -37,19 +65,23 @@ func (f *fakeDB) Transaction(ctx context.Context, fn func(ctx context.Context, t
 	return fn(ctx, nil)
 }
 
+func (f *fakeDB) Savepoint(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestOrderUsecase_Checkout_Success(t *testing.T) {
 	ctx := context.Background()
 	db := &fakeDB{}
 
 	orderRepo := mocks.NewMockOrderRepository(t)
-	idemRepo := mocks.NewMockIdempotencyRequestRepository(t)
 	orderItemRepo := mocks.NewMockOrderItemRepository(t)
 	reservationRepo := mocks.NewMockReservationRepository(t)
 	movementRepo := mocks.NewMockMovementRepository(t)
 	productStockRepo := mocks.NewMockProductStockRepository(t)
 	warehouseRepo := mocks.NewMockWarehouseRepository(t)
 
-	uc := NewOrderUsecase(db, orderRepo, idemRepo, orderItemRepo, reservationRepo, movementRepo, productStockRepo, warehouseRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewOrderUsecase(db, orderRepo, orderItemRepo, reservationRepo, movementRepo, productStockRepo, warehouseRepo, outboxRepo, nil, nil, nil, nil, domain.ReservationHoldLimits{})
 
 	shopID := uuid.New()
 	userID := uuid.New()
@@ -60,14 +92,9 @@ func TestOrderUsecase_Checkout_Success(t *testing.T) {
 		ShopID:             shopID.String(),
 		UserID:             userID.String(),
 		Items:              []domain.CheckoutItem{{ProductID: productID.String(), Qty: 2, Price: 500}},
-		IdemKey:            "idem-key-1",
-		PayloadHash:        "hash123",
 		ReservationMinutes: 1,
 	}
 
-	// Idempotency begin -> treat as new
-	idemRepo.EXPECT().BeginKey(ctx, mock.Anything, input.IdemKey, "checkout", input.PayloadHash).Return(true, nil)
-
 	// Order create
 	orderRepo.EXPECT().Create(ctx, mock.Anything, mock.Anything).Return(nil)
 	// Order items bulk insert
@@ -81,8 +108,10 @@ func TestOrderUsecase_Checkout_Success(t *testing.T) {
 	movementRepo.EXPECT().Append(ctx, mock.Anything, productID, warehouseID, "RESERVE", 2, "ORDER_CHECKOUT", mock.Anything).Return(nil)
 	// Reservation create
 	reservationRepo.EXPECT().CreateMany(ctx, mock.Anything, mock.Anything).Return(nil)
-	// Save response
-	idemRepo.EXPECT().SaveResponse(ctx, mock.Anything, input.IdemKey, "checkout", mock.Anything, mock.Anything).Return(nil)
+	// Checked-out event, written in the same transaction
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.MatchedBy(func(e domain.OrderCheckedOut) bool {
+		return e.ShopID == shopID && e.Total == 1000 && len(e.Items) == 1 && e.Items[0].WarehouseID == warehouseID
+	})).Return(nil)
 
 	out, err := uc.Checkout(ctx, input)
 	assert.NoError(t, err)
@@ -95,7 +124,7 @@ func TestOrderUsecase_Checkout_Success(t *testing.T) {
 func TestOrderUsecase_Checkout_EmptyItems(t *testing.T) {
 	ctx := context.Background()
 	db := &fakeDB{}
-	uc := NewOrderUsecase(db, nil, nil, nil, nil, nil, nil, nil)
+	uc := NewOrderUsecase(db, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, domain.ReservationHoldLimits{})
 
 	out, err := uc.Checkout(ctx, domain.CheckoutInput{ShopID: uuid.New().String(), UserID: uuid.New().String(), Items: []domain.CheckoutItem{}})
 	assert.Error(t, err)
@@ -106,7 +135,7 @@ func TestOrderUsecase_GetUserOrders(t *testing.T) {
 	ctx := context.Background()
 	db := &fakeDB{}
 	orderRepo := mocks.NewMockOrderRepository(t)
-	uc := NewOrderUsecase(db, orderRepo, nil, nil, nil, nil, nil, nil)
+	uc := NewOrderUsecase(db, orderRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, domain.ReservationHoldLimits{})
 	userID := uuid.New()
 	orders := []domain.OrderListItem{{ID: uuid.New(), Total: 1000, Status: string(domain.StatusAwaitingPayment)}}
 	orderRepo.EXPECT().GetByUserID(ctx, userID, 10, 0).Return(orders, 1, nil)
@@ -115,3 +144,437 @@ func TestOrderUsecase_GetUserOrders(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, res.TotalItems)
 }
+
+func TestOrderUsecase_GetShopOrders(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{}
+	orderRepo := mocks.NewMockOrderRepository(t)
+	uc := NewOrderUsecase(db, orderRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, domain.ReservationHoldLimits{})
+	shopID := uuid.New()
+	orders := []domain.OrderListItem{{ID: uuid.New(), Total: 1000, Status: string(domain.StatusPaid)}}
+	orderRepo.EXPECT().GetByShopID(ctx, shopID, 10, 0).Return(orders, 1, nil)
+	p := paginator.PaginationRequest{Page: 1, Limit: 10}
+	res, err := uc.GetShopOrders(ctx, shopID, p)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.TotalItems)
+}
+
+func TestObserveCheckout(t *testing.T) {
+	count := func(result string) float64 {
+		return testutil.ToFloat64(metrics.Checkouts.WithLabelValues(result))
+	}
+
+	success, outOfStock, rejected, failed := count("success"), count("out_of_stock"), count("rejected"), count("error")
+	created := testutil.ToFloat64(metrics.Reservations.WithLabelValues(metrics.ReservationCreated))
+	rejections := testutil.ToFloat64(metrics.OutOfStockRejections)
+
+	observeCheckout(nil, 3)
+	observeCheckout(errx.E(errx.CodeValidation, "insufficient stock", domain.ErrOutOfStock), 0)
+	observeCheckout(errx.E(errx.CodeValidation, "invalid shop ID format"), 0)
+	observeCheckout(errors.New("db down"), 0)
+
+	assert.Equal(t, success+1, count("success"))
+	assert.Equal(t, outOfStock+1, count("out_of_stock"))
+	assert.Equal(t, rejected+1, count("rejected"))
+	assert.Equal(t, failed+1, count("error"))
+	assert.Equal(t, created+3, testutil.ToFloat64(metrics.Reservations.WithLabelValues(metrics.ReservationCreated)))
+	assert.Equal(t, rejections+1, testutil.ToFloat64(metrics.OutOfStockRejections))
+}
+
+func TestOrderUsecase_CancelOrder_LocksReservationsBeforeOrder(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	movementRepo := mocks.NewMockMovementRepository(t)
+	productStockRepo := mocks.NewMockProductStockRepository(t)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, nil, reservationRepo, movementRepo, productStockRepo, nil, outboxRepo, nil, nil, nil, nil, domain.ReservationHoldLimits{})
+
+	order := &domain.Order{ID: uuid.New(), ShopID: uuid.New(), Status: domain.StatusAwaitingPayment}
+	reservation := domain.Reservation{ID: uuid.New(), OrderID: order.ID, ProductID: uuid.New(), WarehouseID: uuid.New(), Qty: 2, Status: domain.ResvPending}
+
+	mock.InOrder(
+		reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return([]domain.Reservation{reservation}, nil).Call,
+		orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil).Call,
+	)
+	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, reservation.ProductID, reservation.WarehouseID, int32(2)).Return(nil)
+	movementRepo.EXPECT().Append(ctx, mock.Anything, reservation.ProductID, reservation.WarehouseID, "RELEASE", 2, "ORDER_CANCELLED", order.ID).Return(nil)
+	reservationRepo.EXPECT().MarkReleased(ctx, mock.Anything, order.ID).Return(1, nil)
+	orderRepo.EXPECT().Updatestatus(ctx, mock.Anything, order.ID, domain.StatusCancelled).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.OrderCancelled")).Return(nil)
+
+	assert.NoError(t, uc.CancelOrder(ctx, order.ID))
+}
+
+func TestOrderUsecase_CancelOrder_PaidMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, nil, reservationRepo, nil, nil, nil, nil, nil, nil, nil, nil, domain.ReservationHoldLimits{})
+
+	// A payment committed while this waited for the locks leaves nothing pending to release
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusPaid}
+	reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return(nil, nil)
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+
+	assert.Error(t, uc.CancelOrder(ctx, order.ID))
+}
+
+func TestOrderUsecase_ConfirmPayment_FailsWhenCommittedCountDiffers(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	movementRepo := mocks.NewMockMovementRepository(t)
+	productStockRepo := mocks.NewMockProductStockRepository(t)
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, nil, reservationRepo, movementRepo, productStockRepo, nil, nil, nil, nil, nil, nil, domain.ReservationHoldLimits{})
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusAwaitingPayment}
+	reservation := domain.Reservation{ID: uuid.New(), OrderID: order.ID, ProductID: uuid.New(), WarehouseID: uuid.New(), Qty: 1, Status: domain.ResvPending, ExpiresAt: time.Now().Add(time.Minute)}
+
+	reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return([]domain.Reservation{reservation}, nil)
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	reservationRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.Reservation{reservation}, nil)
+	productStockRepo.EXPECT().CommitStock(ctx, mock.Anything, reservation.ProductID, reservation.WarehouseID, int32(1)).Return(nil)
+	movementRepo.EXPECT().Append(ctx, mock.Anything, reservation.ProductID, reservation.WarehouseID, "COMMIT", 1, "ORDER_PAYMENT", order.ID).Return(nil)
+	reservationRepo.EXPECT().MarkCommitted(ctx, mock.Anything, order.ID).Return(0, nil)
+
+	err := uc.ConfirmPayment(ctx, order.ID)
+	assert.True(t, errx.IsCode(err, errx.CodeInternal))
+}
+
+func TestOrderUsecase_ConfirmPayment_RejectsPartlyPendingOrder(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, nil, reservationRepo, nil, nil, nil, nil, nil, nil, nil, nil, domain.ReservationHoldLimits{})
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusAwaitingPayment}
+	pending := domain.Reservation{ID: uuid.New(), OrderID: order.ID, Status: domain.ResvPending, ExpiresAt: time.Now().Add(time.Minute)}
+	expired := domain.Reservation{ID: uuid.New(), OrderID: order.ID, Status: domain.ResvExpired}
+
+	reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return([]domain.Reservation{pending}, nil)
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	reservationRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.Reservation{pending, expired}, nil)
+
+	err := uc.ConfirmPayment(ctx, order.ID)
+	assert.True(t, errx.IsCode(err, errx.CodeValidation))
+}
+
+func TestOrderUsecase_RefundOrder_PartialWithRestock(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	orderItemRepo := mocks.NewMockOrderItemRepository(t)
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	movementRepo := mocks.NewMockMovementRepository(t)
+	productStockRepo := mocks.NewMockProductStockRepository(t)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	refundRepo := mocks.NewMockRefundRepository(t)
+	paymentRepo := mocks.NewMockPaymentRepository(t)
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, orderItemRepo, reservationRepo, movementRepo, productStockRepo, nil, outboxRepo, refundRepo, paymentRepo, expectRefundIssueJob(t, ctx), nil, domain.ReservationHoldLimits{})
+
+	order := &domain.Order{ID: uuid.New(), ShopID: uuid.New(), Status: domain.StatusPaid, Total: 1500}
+	item := domain.OrderItem{ID: uuid.New(), OrderID: order.ID, ProductID: uuid.New(), Qty: 3, Price: 500}
+	warehouseID := uuid.New()
+
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	orderItemRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.OrderItem{item}, nil)
+	refundRepo.EXPECT().RefundedQty(ctx, mock.Anything, order.ID).Return(map[uuid.UUID]int{item.ID: 1}, nil)
+	reservationRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.Reservation{
+		{ProductID: item.ProductID, WarehouseID: warehouseID, Qty: 3, Status: domain.ResvCommitted},
+	}, nil)
+	productStockRepo.EXPECT().AddStock(ctx, mock.Anything, item.ProductID, warehouseID, int32(1)).Return(nil)
+	movementRepo.EXPECT().Append(ctx, mock.Anything, item.ProductID, warehouseID, "IN", 1, "ORDER_REFUND", mock.Anything).Return(nil)
+	paymentRepo.EXPECT().GetPaidIntent(ctx, mock.Anything, order.ID).Return(&domain.PaymentIntent{ProviderRef: "pi_fake_1"}, nil)
+	refundRepo.EXPECT().Create(ctx, mock.Anything, mock.MatchedBy(func(r *domain.Refund) bool {
+		return r.Amount == 500 && r.Restock && r.Status == domain.RefundPending && len(r.Items) == 1 && *r.Items[0].WarehouseID == warehouseID
+	})).Return(nil)
+	orderRepo.EXPECT().Updatestatus(ctx, mock.Anything, order.ID, domain.StatusPartiallyRefunded).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.MatchedBy(func(e domain.OrderRefunded) bool {
+		return e.Amount == 500 && e.Status == domain.StatusPartiallyRefunded && e.ShopID == order.ShopID
+	})).Return(nil)
+
+	refund, err := uc.RefundOrder(ctx, order.ID, domain.RefundOrderRequest{
+		Items:   []domain.RefundItemRequest{{OrderItemID: item.ID.String(), Qty: 1}},
+		Restock: true,
+		Reason:  "damaged",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), refund.Amount)
+	assert.Equal(t, order.ID, refund.OrderID)
+}
+
+func TestOrderUsecase_RefundOrder_FullWithoutRestock(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	orderItemRepo := mocks.NewMockOrderItemRepository(t)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	refundRepo := mocks.NewMockRefundRepository(t)
+	paymentRepo := mocks.NewMockPaymentRepository(t)
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, orderItemRepo, nil, nil, nil, nil, outboxRepo, refundRepo, paymentRepo, nil, nil, domain.ReservationHoldLimits{})
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusPartiallyRefunded}
+	first := domain.OrderItem{ID: uuid.New(), ProductID: uuid.New(), Qty: 2, Price: 100}
+	second := domain.OrderItem{ID: uuid.New(), ProductID: uuid.New(), Qty: 1, Price: 300}
+
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	orderItemRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.OrderItem{first, second}, nil)
+	refundRepo.EXPECT().RefundedQty(ctx, mock.Anything, order.ID).Return(map[uuid.UUID]int{first.ID: 1}, nil)
+	// Confirmed by hand, so there is no provider payment to refund
+	paymentRepo.EXPECT().GetPaidIntent(ctx, mock.Anything, order.ID).Return(nil, sql.ErrNoRows)
+	refundRepo.EXPECT().Create(ctx, mock.Anything, mock.MatchedBy(func(r *domain.Refund) bool {
+		return r.Amount == 400 && !r.Restock && r.Status == domain.RefundSucceeded && len(r.Items) == 2 && r.Items[0].WarehouseID == nil
+	})).Return(nil)
+	orderRepo.EXPECT().Updatestatus(ctx, mock.Anything, order.ID, domain.StatusRefunded).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.OrderRefunded")).Return(nil)
+
+	refund, err := uc.RefundOrder(ctx, order.ID, domain.RefundOrderRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(400), refund.Amount)
+}
+
+func TestOrderUsecase_RefundOrderAsOwner_RejectsOtherUsers(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	shopRepo := mocks.NewMockShopRepository(t)
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, shopRepo, domain.ReservationHoldLimits{})
+
+	owner := uuid.New()
+	order := &domain.Order{ID: uuid.New(), ShopID: uuid.New(), Status: domain.StatusPaid}
+
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	shopRepo.EXPECT().Retrieve(ctx, order.ShopID).Return(&domain.Shop{ID: order.ShopID, OwnerID: &owner}, nil)
+
+	_, err := uc.RefundOrderAsOwner(ctx, order.ID, uuid.New(), domain.RefundOrderRequest{})
+	assert.True(t, errx.IsCode(err, errx.CodePermission))
+}
+
+func TestOrderUsecase_RefundOrderAsOwner_Success(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	orderItemRepo := mocks.NewMockOrderItemRepository(t)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	refundRepo := mocks.NewMockRefundRepository(t)
+	paymentRepo := mocks.NewMockPaymentRepository(t)
+	shopRepo := mocks.NewMockShopRepository(t)
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, orderItemRepo, nil, nil, nil, nil, outboxRepo, refundRepo, paymentRepo, expectRefundIssueJob(t, ctx), shopRepo, domain.ReservationHoldLimits{})
+
+	owner := uuid.New()
+	order := &domain.Order{ID: uuid.New(), ShopID: uuid.New(), Status: domain.StatusPaid}
+	item := domain.OrderItem{ID: uuid.New(), ProductID: uuid.New(), Qty: 1, Price: 700}
+
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	shopRepo.EXPECT().Retrieve(ctx, order.ShopID).Return(&domain.Shop{ID: order.ShopID, OwnerID: &owner}, nil)
+	orderItemRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.OrderItem{item}, nil)
+	refundRepo.EXPECT().RefundedQty(ctx, mock.Anything, order.ID).Return(map[uuid.UUID]int{}, nil)
+	paymentRepo.EXPECT().GetPaidIntent(ctx, mock.Anything, order.ID).Return(&domain.PaymentIntent{ProviderRef: "pi_fake_1"}, nil)
+	refundRepo.EXPECT().Create(ctx, mock.Anything, mock.Anything).Return(nil)
+	orderRepo.EXPECT().Updatestatus(ctx, mock.Anything, order.ID, domain.StatusRefunded).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.OrderRefunded")).Return(nil)
+
+	refund, err := uc.RefundOrderAsOwner(ctx, order.ID, owner, domain.RefundOrderRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(700), refund.Amount)
+}
+
+func TestRefundIssueJobHandler_IssuesPendingRefund(t *testing.T) {
+	ctx := context.Background()
+	refundRepo := mocks.NewMockRefundRepository(t)
+	paymentRepo := mocks.NewMockPaymentRepository(t)
+	provider := &refundRecordingProvider{Fake: payment.NewFake("secret")}
+	handler := NewRefundIssueJobHandler(&fakeDB{}, refundRepo, paymentRepo, provider)
+
+	refund := &domain.Refund{ID: uuid.New(), OrderID: uuid.New(), Amount: 500, Status: domain.RefundPending}
+
+	refundRepo.EXPECT().Get(ctx, mock.Anything, refund.ID).Return(refund, nil)
+	paymentRepo.EXPECT().GetPaidIntent(ctx, mock.Anything, refund.OrderID).Return(&domain.PaymentIntent{ProviderRef: "pi_fake_1"}, nil)
+	refundRepo.EXPECT().UpdateStatus(ctx, mock.Anything, refund.ID, domain.RefundSucceeded).Return(nil)
+
+	err := handler(ctx, []byte(`{"refund_id":"`+refund.ID.String()+`"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{500}, provider.refunds)
+}
+
+func TestRefundIssueJobHandler_ProviderFailureLeavesRefundPending(t *testing.T) {
+	ctx := context.Background()
+	refundRepo := mocks.NewMockRefundRepository(t)
+	paymentRepo := mocks.NewMockPaymentRepository(t)
+	provider := &refundRecordingProvider{Fake: payment.NewFake("secret"), err: errors.New("provider down")}
+	handler := NewRefundIssueJobHandler(&fakeDB{}, refundRepo, paymentRepo, provider)
+
+	refund := &domain.Refund{ID: uuid.New(), OrderID: uuid.New(), Amount: 500, Status: domain.RefundPending}
+
+	refundRepo.EXPECT().Get(ctx, mock.Anything, refund.ID).Return(refund, nil)
+	paymentRepo.EXPECT().GetPaidIntent(ctx, mock.Anything, refund.OrderID).Return(&domain.PaymentIntent{ProviderRef: "pi_fake_1"}, nil)
+
+	err := handler(ctx, []byte(`{"refund_id":"`+refund.ID.String()+`"}`))
+	assert.Error(t, err)
+	refundRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundIssueJobHandler_SkipsIssuedRefund(t *testing.T) {
+	ctx := context.Background()
+	refundRepo := mocks.NewMockRefundRepository(t)
+	provider := &refundRecordingProvider{Fake: payment.NewFake("secret")}
+	handler := NewRefundIssueJobHandler(&fakeDB{}, refundRepo, nil, provider)
+
+	refund := &domain.Refund{ID: uuid.New(), OrderID: uuid.New(), Amount: 500, Status: domain.RefundSucceeded}
+
+	refundRepo.EXPECT().Get(ctx, mock.Anything, refund.ID).Return(refund, nil)
+
+	err := handler(ctx, []byte(`{"refund_id":"`+refund.ID.String()+`"}`))
+	assert.NoError(t, err)
+	assert.Empty(t, provider.refunds)
+}
+
+func TestOrderUsecase_RefundOrder_ExceedsRemaining(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	orderItemRepo := mocks.NewMockOrderItemRepository(t)
+	refundRepo := mocks.NewMockRefundRepository(t)
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, orderItemRepo, nil, nil, nil, nil, nil, refundRepo, nil, nil, nil, domain.ReservationHoldLimits{})
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusPaid}
+	item := domain.OrderItem{ID: uuid.New(), ProductID: uuid.New(), Qty: 2, Price: 100}
+
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	orderItemRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.OrderItem{item}, nil)
+	refundRepo.EXPECT().RefundedQty(ctx, mock.Anything, order.ID).Return(map[uuid.UUID]int{item.ID: 1}, nil)
+
+	_, err := uc.RefundOrder(ctx, order.ID, domain.RefundOrderRequest{
+		Items: []domain.RefundItemRequest{{OrderItemID: item.ID.String(), Qty: 2}},
+	})
+	assert.True(t, errx.IsCode(err, errx.CodeValidation))
+}
+
+func TestOrderUsecase_RefundOrder_RequiresPaidOrder(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, domain.ReservationHoldLimits{})
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusAwaitingPayment}
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+
+	_, err := uc.RefundOrder(ctx, order.ID, domain.RefundOrderRequest{})
+	assert.True(t, errx.IsCode(err, errx.CodePrecondition))
+}
+
+func TestOrderUsecase_ExtendReservation_Success(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	limits := domain.ReservationHoldLimits{Default: domain.ReservationHoldLimit{MaxHold: time.Hour, MaxExtensions: 3}}
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, nil, reservationRepo, nil, nil, nil, outboxRepo, nil, nil, nil, nil, limits)
+
+	order := &domain.Order{ID: uuid.New(), ShopID: uuid.New(), Status: domain.StatusAwaitingPayment, ReservationExtensions: 1, CreatedAt: time.Now().Add(-10 * time.Minute)}
+	expiresAt := time.Now().Add(5 * time.Minute)
+	want := expiresAt.Add(10 * time.Minute)
+
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return([]domain.Reservation{
+		{ID: uuid.New(), OrderID: order.ID, Status: domain.ResvPending, ExpiresAt: expiresAt},
+		{ID: uuid.New(), OrderID: order.ID, Status: domain.ResvPending, ExpiresAt: expiresAt},
+	}, nil)
+	reservationRepo.EXPECT().ExtendPending(ctx, mock.Anything, order.ID, want).Return(nil)
+	orderRepo.EXPECT().ExtendReservation(ctx, mock.Anything, order.ID, want).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.MatchedBy(func(e domain.OrderReservationExtended) bool {
+		return e.ShopID == order.ShopID && e.ReservationExpiresAt.Equal(want) && e.Extensions == 2
+	})).Return(nil)
+
+	out, err := uc.ExtendReservation(ctx, order.ID, domain.ExtendReservationRequest{Minutes: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, want, out.ReservationExpiresAt)
+	assert.Equal(t, 2, out.Extensions)
+	assert.Equal(t, 1, out.ExtensionsLeft)
+}
+
+func TestOrderUsecase_ExtendReservation_DefaultsToConfiguredExtension(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	limits := domain.ReservationHoldLimits{Default: domain.ReservationHoldLimit{MaxHold: time.Hour, MaxExtensions: 3}, Extension: 20 * time.Minute}
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, nil, reservationRepo, nil, nil, nil, outboxRepo, nil, nil, nil, nil, limits)
+
+	order := &domain.Order{ID: uuid.New(), ShopID: uuid.New(), Status: domain.StatusAwaitingPayment, CreatedAt: time.Now()}
+	expiresAt := time.Now().Add(5 * time.Minute)
+	want := expiresAt.Add(20 * time.Minute)
+
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return([]domain.Reservation{
+		{ID: uuid.New(), OrderID: order.ID, Status: domain.ResvPending, ExpiresAt: expiresAt},
+	}, nil)
+	reservationRepo.EXPECT().ExtendPending(ctx, mock.Anything, order.ID, want).Return(nil)
+	orderRepo.EXPECT().ExtendReservation(ctx, mock.Anything, order.ID, want).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.OrderReservationExtended")).Return(nil)
+
+	out, err := uc.ExtendReservation(ctx, order.ID, domain.ExtendReservationRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, want, out.ReservationExpiresAt)
+}
+
+func TestOrderUsecase_ExtendReservation_CappedAtMaxHold(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := mocks.NewMockOrderRepository(t)
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	shopID := uuid.New()
+	limits := domain.ReservationHoldLimits{
+		Default:   domain.ReservationHoldLimit{MaxHold: time.Hour, MaxExtensions: 3},
+		Shops:     map[uuid.UUID]domain.ReservationHoldLimit{shopID: {MaxHold: 30 * time.Minute, MaxExtensions: 1}},
+		Extension: 15 * time.Minute,
+	}
+	uc := NewOrderUsecase(&fakeDB{}, orderRepo, nil, reservationRepo, nil, nil, nil, outboxRepo, nil, nil, nil, nil, limits)
+
+	order := &domain.Order{ID: uuid.New(), ShopID: shopID, Status: domain.StatusAwaitingPayment, CreatedAt: time.Now().Add(-20 * time.Minute)}
+	holdUntil := order.CreatedAt.Add(30 * time.Minute)
+
+	orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return([]domain.Reservation{
+		{ID: uuid.New(), OrderID: order.ID, Status: domain.ResvPending, ExpiresAt: time.Now().Add(5 * time.Minute)},
+	}, nil)
+	reservationRepo.EXPECT().ExtendPending(ctx, mock.Anything, order.ID, holdUntil).Return(nil)
+	orderRepo.EXPECT().ExtendReservation(ctx, mock.Anything, order.ID, holdUntil).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.OrderReservationExtended")).Return(nil)
+
+	out, err := uc.ExtendReservation(ctx, order.ID, domain.ExtendReservationRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, holdUntil, out.ReservationExpiresAt)
+	assert.Equal(t, 0, out.ExtensionsLeft)
+}
+
+func TestOrderUsecase_ExtendReservation_Rejected(t *testing.T) {
+	limits := domain.ReservationHoldLimits{Default: domain.ReservationHoldLimit{MaxHold: time.Hour, MaxExtensions: 2}}
+	pending := func(expiresAt time.Time) []domain.Reservation {
+		return []domain.Reservation{{ID: uuid.New(), Status: domain.ResvPending, ExpiresAt: expiresAt}}
+	}
+
+	tests := []struct {
+		name         string
+		order        domain.Order
+		reservations []domain.Reservation
+	}{
+		{name: "paid order", order: domain.Order{Status: domain.StatusPaid}, reservations: []domain.Reservation{}},
+		{name: "extension limit", order: domain.Order{Status: domain.StatusAwaitingPayment, ReservationExtensions: 2}, reservations: pending(time.Now().Add(5 * time.Minute))},
+		{name: "no pending reservations", order: domain.Order{Status: domain.StatusAwaitingPayment, CreatedAt: time.Now()}, reservations: []domain.Reservation{}},
+		{name: "already expired", order: domain.Order{Status: domain.StatusAwaitingPayment, CreatedAt: time.Now().Add(-20 * time.Minute)}, reservations: pending(time.Now().Add(-time.Second))},
+		{name: "max hold reached", order: domain.Order{Status: domain.StatusAwaitingPayment, CreatedAt: time.Now().Add(-55 * time.Minute)}, reservations: pending(time.Now().Add(5 * time.Minute))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			orderRepo := mocks.NewMockOrderRepository(t)
+			reservationRepo := mocks.NewMockReservationRepository(t)
+			uc := NewOrderUsecase(&fakeDB{}, orderRepo, nil, reservationRepo, nil, nil, nil, nil, nil, nil, nil, nil, limits)
+
+			order := tt.order
+			order.ID = uuid.New()
+			reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return(tt.reservations, nil)
+			orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(&order, nil)
+
+			_, err := uc.ExtendReservation(ctx, order.ID, domain.ExtendReservationRequest{Minutes: 10})
+			assert.True(t, errx.IsCode(err, errx.CodePrecondition))
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/metrics"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+)
+
+const (
+	outboxBaseBackoff = 5 * time.Second
+	outboxMaxBackoff  = 10 * time.Minute
+)
+
+type outboxRelayUsecase struct {
+	db         pqsql.Database
+	outboxRepo domain.OutboxRepository
+	sinks      []domain.EventSink
+	logger     log.Logger
+}
+
+// RelayBatch implements domain.OutboxRelayUsecase. Events are published while their rows are locked
+// and marked afterwards, so a crash between the two re-delivers rather than loses the event.
+func (o *outboxRelayUsecase) RelayBatch(ctx context.Context, limit int) (int, error) {
+	ctx, span := tracing.Start(ctx, "OutboxRelayUsecase.RelayBatch")
+	defer span.End()
+
+	published := 0
+	l := log.WithContext(ctx, o.logger)
+
+	_, err := o.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		events, err := o.outboxRepo.PickPending(ctx, tx, limit)
+		if err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to pick pending events", errx.Op("OutboxRelayUsecase.RelayBatch"), err)
+		}
+
+		for _, event := range events {
+			if err := o.publish(ctx, event); err != nil {
+				metrics.OutboxDeliveries.WithLabelValues(string(event.EventType), "failed").Inc()
+
+				attempts := event.Attempts + 1
+				nextAttemptAt := time.Now().Add(backoff(attempts, outboxBaseBackoff, outboxMaxBackoff))
+				l.Warn("failed to publish outbox event",
+					log.String("event_id", event.ID.String()),
+					log.String("event_type", string(event.EventType)),
+					log.Int64("attempts", int64(attempts)),
+					log.Error("error", err),
+				)
+
+				if err := o.outboxRepo.MarkFailed(ctx, tx, event.ID, err.Error(), nextAttemptAt); err != nil {
+					return nil, errx.E(errx.CodeInternal, "failed to mark event as failed", errx.Op("OutboxRelayUsecase.RelayBatch"), err)
+				}
+				continue
+			}
+
+			if err := o.outboxRepo.MarkPublished(ctx, tx, event.ID); err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to mark event as published", errx.Op("OutboxRelayUsecase.RelayBatch"), err)
+			}
+
+			metrics.OutboxDeliveries.WithLabelValues(string(event.EventType), "published").Inc()
+			published++
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return published, nil
+}
+
+// publish hands the event to every sink. A failing sink fails the event, and the sinks that
+// already succeeded will see it again on retry.
+func (o *outboxRelayUsecase) publish(ctx context.Context, event domain.OutboxEvent) error {
+	for _, sink := range o.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return fmt.Errorf("sink %s: %w", sink.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// backoff doubles base per attempt after the first, capped at max.
+func backoff(attempts int, base time.Duration, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+
+	return min(delay, max)
+}
+
+func NewOutboxRelayUsecase(
+	db pqsql.Database,
+	outboxRepo domain.OutboxRepository,
+	sinks []domain.EventSink,
+	logger log.Logger,
+) domain.OutboxRelayUsecase {
+	return &outboxRelayUsecase{
+		db:         db,
+		outboxRepo: outboxRepo,
+		sinks:      sinks,
+		logger:     logger,
+	}
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeSink struct {
+	err       error
+	published []domain.OutboxEvent
+}
+
+func (f *fakeSink) Name() string { return "fake" }
+
+func (f *fakeSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.published = append(f.published, event)
+	return nil
+}
+
+func TestOutboxRelay_RelayBatch_Published(t *testing.T) {
+	ctx := context.Background()
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	sink := &fakeSink{}
+	uc := NewOutboxRelayUsecase(&fakeDB{}, outboxRepo, []domain.EventSink{sink}, log.Nop())
+
+	events := []domain.OutboxEvent{
+		{ID: uuid.New(), Seq: 1, EventType: domain.EventOrderCheckedOut},
+		{ID: uuid.New(), Seq: 2, EventType: domain.EventTransferCompleted},
+	}
+
+	outboxRepo.EXPECT().PickPending(ctx, mock.Anything, 10).Return(events, nil)
+	outboxRepo.EXPECT().MarkPublished(ctx, mock.Anything, events[0].ID).Return(nil)
+	outboxRepo.EXPECT().MarkPublished(ctx, mock.Anything, events[1].ID).Return(nil)
+
+	published, err := uc.RelayBatch(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, events, sink.published)
+}
+
+func TestOutboxRelay_RelayBatch_SinkFailure(t *testing.T) {
+	ctx := context.Background()
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	sink := &fakeSink{err: errors.New("broker unavailable")}
+	uc := NewOutboxRelayUsecase(&fakeDB{}, outboxRepo, []domain.EventSink{sink}, log.Nop())
+
+	event := domain.OutboxEvent{ID: uuid.New(), Seq: 1, EventType: domain.EventOrderCancelled, Attempts: 2}
+
+	outboxRepo.EXPECT().PickPending(ctx, mock.Anything, 10).Return([]domain.OutboxEvent{event}, nil)
+	outboxRepo.EXPECT().MarkFailed(ctx, mock.Anything, event.ID, "sink fake: broker unavailable", mock.MatchedBy(func(next time.Time) bool {
+		// third attempt waits 4x the base backoff
+		return next.Sub(time.Now()) > 15*time.Second && next.Sub(time.Now()) <= 20*time.Second
+	})).Return(nil)
+
+	published, err := uc.RelayBatch(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published)
+}
+
+func TestOutboxRelay_RelayBatch_PickError(t *testing.T) {
+	ctx := context.Background()
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewOutboxRelayUsecase(&fakeDB{}, outboxRepo, []domain.EventSink{&fakeSink{}}, log.Nop())
+
+	outboxRepo.EXPECT().PickPending(ctx, mock.Anything, 10).Return(nil, errors.New("db down"))
+
+	_, err := uc.RelayBatch(ctx, 10)
+	assert.Error(t, err)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, backoff(1, outboxBaseBackoff, outboxMaxBackoff))
+	assert.Equal(t, 10*time.Second, backoff(2, outboxBaseBackoff, outboxMaxBackoff))
+	assert.Equal(t, 40*time.Second, backoff(4, outboxBaseBackoff, outboxMaxBackoff))
+	assert.Equal(t, 10*time.Minute, backoff(20, outboxBaseBackoff, outboxMaxBackoff))
+}
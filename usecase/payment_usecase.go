@@ -0,0 +1,306 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/metrics"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+type paymentUsecase struct {
+	orderLifecycle
+	db          pqsql.Database
+	paymentRepo domain.PaymentRepository
+	provider    domain.PaymentProvider
+	logger      log.Logger
+}
+
+// CreateIntent implements domain.PaymentUsecase.
+func (p *paymentUsecase) CreateIntent(ctx context.Context, orderID uuid.UUID) (*domain.PaymentIntent, error) {
+	ctx, span := tracing.Start(ctx, "PaymentUsecase.CreateIntent", tracing.OrderID(orderID))
+	defer span.End()
+
+	intent, err := p.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		order, err := p.orderRepo.GetByID(ctx, orderID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errx.E(errx.CodeNotFound, "order not found", errx.Op("paymentUsecase.CreateIntent"), err)
+			}
+			return nil, errx.E(errx.CodeInternal, "failed to get order", errx.Op("paymentUsecase.CreateIntent"), err)
+		}
+
+		if order.Status != domain.StatusAwaitingPayment {
+			return nil, errx.E(errx.CodePrecondition, "order is not awaiting payment", errx.Op("paymentUsecase.CreateIntent"))
+		}
+
+		open, err := p.paymentRepo.GetOpenIntent(ctx, tx, orderID)
+		if err == nil {
+			return open, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, errx.E(errx.CodeInternal, "failed to get payment intent", errx.Op("paymentUsecase.CreateIntent"), err)
+		}
+
+		intent := &domain.PaymentIntent{
+			ID:       uuid.New(),
+			OrderID:  orderID,
+			Provider: p.provider.Name(),
+			Amount:   order.Total,
+			Status:   domain.PaymentRequiresPayment,
+		}
+
+		intent.ProviderRef, intent.CheckoutURL, err = p.provider.CreateIntent(ctx, intent.ID, order)
+		if err != nil {
+			return nil, errx.E(errx.CodeUnavailable, "payment provider is unavailable", errx.Op("paymentUsecase.CreateIntent"), err)
+		}
+
+		if err := p.paymentRepo.CreateIntent(ctx, tx, intent); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to save payment intent", errx.Op("paymentUsecase.CreateIntent"), err)
+		}
+
+		return intent, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return intent.(*domain.PaymentIntent), nil
+}
+
+// HandleWebhook implements domain.PaymentUsecase. Events that cannot be applied any more, such as a
+// payment succeeding after its order or reservations expired or a partial refund made on the
+// provider side, are acknowledged and logged for manual follow-up so the provider stops redelivering
+// them. Refunded events of refunds made through RefundOrder only confirm the recorded refund.
+func (p *paymentUsecase) HandleWebhook(ctx context.Context, header http.Header, body []byte) (bool, error) {
+	ctx, span := tracing.Start(ctx, "PaymentUsecase.HandleWebhook")
+	defer span.End()
+
+	event, err := p.provider.ParseEvent(header, body)
+	if err != nil {
+		metrics.PaymentEvents.WithLabelValues("unknown", "rejected").Inc()
+		if errors.Is(err, domain.ErrInvalidPaymentSignature) {
+			return false, errx.E(errx.CodeUnauthenticated, "invalid payment webhook signature", errx.Op("paymentUsecase.HandleWebhook"), err)
+		}
+		return false, errx.E(errx.CodeValidation, "invalid payment webhook payload", errx.Op("paymentUsecase.HandleWebhook"), err)
+	}
+
+	l := log.WithContext(ctx, p.logger)
+	fields := []log.LoggerContextFn{
+		log.String("provider", p.provider.Name()),
+		log.String("event_id", event.ID),
+		log.String("provider_ref", event.ProviderRef),
+	}
+
+	if event.Type == "" {
+		metrics.PaymentEvents.WithLabelValues("unknown", "ignored").Inc()
+		l.Debug("ignoring payment event of no interest", fields...)
+		return false, nil
+	}
+
+	duplicate := false
+	result := "applied"
+	committed, released := 0, 0
+
+	_, err = p.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		intent, err := p.paymentRepo.GetIntentForUpdate(ctx, tx, p.provider.Name(), event.ProviderRef)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errx.E(errx.CodeNotFound, "payment intent not found", errx.Op("paymentUsecase.HandleWebhook"), err)
+			}
+			return nil, errx.E(errx.CodeInternal, "failed to get payment intent", errx.Op("paymentUsecase.HandleWebhook"), err)
+		}
+
+		isNew, err := p.paymentRepo.RecordEvent(ctx, tx, p.provider.Name(), event, intent.ID)
+		if err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to record payment event", errx.Op("paymentUsecase.HandleWebhook"), err)
+		}
+		if !isNew {
+			duplicate = true
+			result = "duplicate"
+			return nil, nil
+		}
+
+		order, reservations, err := p.lock(ctx, tx, intent.OrderID)
+		if err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to get order", errx.Op("paymentUsecase.HandleWebhook"), err)
+		}
+
+		fields = append(fields, log.String("order_id", order.ID.String()))
+		unpaid := order.Status == domain.StatusAwaitingPayment || order.Status == domain.StatusPending
+
+		switch event.Type {
+		case domain.PaymentEventSucceeded:
+			if intent.Status != domain.PaymentRequiresPayment {
+				result = "ignored"
+				l.Warn("payment succeeded for an intent no longer open", append(fields, log.String("intent_status", string(intent.Status)))...)
+				return nil, nil
+			}
+
+			if event.Amount != intent.Amount {
+				result = "rejected"
+				l.Error("payment amount does not match the intent", append(fields,
+					log.Int64("amount", event.Amount),
+					log.Int64("expected", intent.Amount),
+				)...)
+				return nil, nil
+			}
+
+			if err := p.paymentRepo.UpdateIntentStatus(ctx, tx, intent.ID, domain.PaymentSucceeded); err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to update payment intent", errx.Op("paymentUsecase.HandleWebhook"), err)
+			}
+
+			if order.Status != domain.StatusAwaitingPayment {
+				result = "refund_required"
+				l.Error("payment succeeded for an order no longer awaiting payment, refund required", append(fields, log.String("order_status", string(order.Status)))...)
+				return nil, nil
+			}
+
+			committed, err = p.confirmPayment(ctx, tx, order, reservations)
+			if errx.IsCode(err, errx.CodeValidation) {
+				// confirmPayment rejects the order before writing anything, so the payment and the event
+				// are still committed and the provider stops redelivering it
+				result = "refund_required"
+				l.Error("payment succeeded for an order that can no longer be confirmed, refund required", append(fields, log.Error("error", err))...)
+				return nil, nil
+			}
+			return nil, err
+
+		case domain.PaymentEventFailed:
+			if intent.Status != domain.PaymentRequiresPayment {
+				result = "ignored"
+				return nil, nil
+			}
+
+			if err := p.paymentRepo.UpdateIntentStatus(ctx, tx, intent.ID, domain.PaymentFailed); err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to update payment intent", errx.Op("paymentUsecase.HandleWebhook"), err)
+			}
+
+			if !unpaid {
+				result = "ignored"
+				return nil, nil
+			}
+
+			released, err = p.cancel(ctx, tx, order, reservations)
+			return nil, err
+
+		case domain.PaymentEventRefunded:
+			if unpaid {
+				if err := p.paymentRepo.UpdateIntentStatus(ctx, tx, intent.ID, domain.PaymentRefunded); err != nil {
+					return nil, errx.E(errx.CodeInternal, "failed to update payment intent", errx.Op("paymentUsecase.HandleWebhook"), err)
+				}
+
+				released, err = p.cancel(ctx, tx, order, reservations)
+				return nil, err
+			}
+
+			// Refunds requested through RefundOrder are recorded already; their event only confirms
+			// that the money went back
+			if event.RefundID != uuid.Nil {
+				refund, err := p.refundRepo.Get(ctx, tx, event.RefundID)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					return nil, errx.E(errx.CodeInternal, "failed to get refund", errx.Op("paymentUsecase.HandleWebhook"), err)
+				}
+				if err == nil && refund.OrderID == order.ID {
+					if refund.Status == domain.RefundPending {
+						if err := p.refundRepo.UpdateStatus(ctx, tx, refund.ID, domain.RefundSucceeded); err != nil {
+							return nil, errx.E(errx.CodeInternal, "failed to update refund", errx.Op("paymentUsecase.HandleWebhook"), err)
+						}
+					}
+
+					return nil, p.markRefunded(ctx, tx, intent, order.Status)
+				}
+			}
+
+			if !slices.Contains(domain.RefundableStatuses, order.Status) {
+				result = "ignored"
+				l.Warn("payment refunded by the provider for an order that cannot be refunded", append(fields, log.String("order_status", string(order.Status)))...)
+				return nil, nil
+			}
+
+			unrefunded, err := p.unrefunded(ctx, tx, order.ID)
+			if err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to get the amount left to refund", errx.Op("paymentUsecase.HandleWebhook"), err)
+			}
+
+			// A partial amount cannot be traced back to the items it was for
+			if event.Amount < unrefunded {
+				result = "manual_review"
+				l.Error("payment partially refunded by the provider, manual review required", append(fields,
+					log.Int64("amount", event.Amount),
+					log.Int64("unrefunded", unrefunded),
+				)...)
+				return nil, nil
+			}
+
+			// Money went back outside the shop's control, so nothing is restocked automatically
+			if _, err := p.refund(ctx, tx, order, domain.RefundOrderRequest{Reason: "refunded by payment provider"}, nil); err != nil {
+				return nil, err
+			}
+
+			return nil, p.markRefunded(ctx, tx, intent, domain.StatusRefunded)
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		metrics.PaymentEvents.WithLabelValues(string(event.Type), "error").Inc()
+		return false, err
+	}
+
+	metrics.PaymentEvents.WithLabelValues(string(event.Type), result).Inc()
+	metrics.Reservations.WithLabelValues(metrics.ReservationCommitted).Add(float64(committed))
+	metrics.Reservations.WithLabelValues(metrics.ReservationReleased).Add(float64(released))
+
+	return duplicate, nil
+}
+
+// markRefunded moves intent to REFUNDED once its order, now in status, is refunded in full.
+func (p *paymentUsecase) markRefunded(ctx context.Context, tx *sql.Tx, intent *domain.PaymentIntent, status domain.OrderStatus) error {
+	if status != domain.StatusRefunded {
+		return nil
+	}
+
+	if err := p.paymentRepo.UpdateIntentStatus(ctx, tx, intent.ID, domain.PaymentRefunded); err != nil {
+		return errx.E(errx.CodeInternal, "failed to update payment intent", errx.Op("paymentUsecase.HandleWebhook"), err)
+	}
+
+	return nil
+}
+
+func NewPaymentUsecase(
+	db pqsql.Database,
+	paymentRepo domain.PaymentRepository,
+	provider domain.PaymentProvider,
+	orderRepo domain.OrderRepository,
+	orderItemRepo domain.OrderItemRepository,
+	refundRepo domain.RefundRepository,
+	reservationRepo domain.ReservationRepository,
+	movementRepository domain.MovementRepository,
+	productStockRepo domain.ProductStockRepository,
+	outboxRepo domain.OutboxRepository,
+	logger log.Logger) domain.PaymentUsecase {
+	return &paymentUsecase{
+		orderLifecycle: orderLifecycle{
+			orderRepo:          orderRepo,
+			orderItemRepo:      orderItemRepo,
+			refundRepo:         refundRepo,
+			reservationRepo:    reservationRepo,
+			movementRepository: movementRepository,
+			productStockRepo:   productStockRepo,
+			outboxRepo:         outboxRepo,
+		},
+		db:          db,
+		paymentRepo: paymentRepo,
+		provider:    provider,
+		logger:      logger,
+	}
+}
@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/payment"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type paymentTestDeps struct {
+	paymentRepo      *mocks.MockPaymentRepository
+	orderRepo        *mocks.MockOrderRepository
+	orderItemRepo    *mocks.MockOrderItemRepository
+	refundRepo       *mocks.MockRefundRepository
+	reservationRepo  *mocks.MockReservationRepository
+	movementRepo     *mocks.MockMovementRepository
+	productStockRepo *mocks.MockProductStockRepository
+	outboxRepo       *mocks.MockOutboxRepository
+}
+
+func newTestPaymentUsecase(t *testing.T, provider *payment.Fake) (domain.PaymentUsecase, paymentTestDeps) {
+	d := paymentTestDeps{
+		paymentRepo:      mocks.NewMockPaymentRepository(t),
+		orderRepo:        mocks.NewMockOrderRepository(t),
+		orderItemRepo:    mocks.NewMockOrderItemRepository(t),
+		refundRepo:       mocks.NewMockRefundRepository(t),
+		reservationRepo:  mocks.NewMockReservationRepository(t),
+		movementRepo:     mocks.NewMockMovementRepository(t),
+		productStockRepo: mocks.NewMockProductStockRepository(t),
+		outboxRepo:       mocks.NewMockOutboxRepository(t),
+	}
+
+	uc := NewPaymentUsecase(&fakeDB{}, d.paymentRepo, provider, d.orderRepo, d.orderItemRepo, d.refundRepo, d.reservationRepo, d.movementRepo, d.productStockRepo, d.outboxRepo, log.Nop())
+	return uc, d
+}
+
+func testIntent(orderID uuid.UUID) *domain.PaymentIntent {
+	return &domain.PaymentIntent{
+		ID:          uuid.New(),
+		OrderID:     orderID,
+		Provider:    payment.FakeName,
+		ProviderRef: "pi_fake_1",
+		Amount:      1000,
+		Status:      domain.PaymentRequiresPayment,
+	}
+}
+
+func TestPayment_HandleWebhook_SucceededConfirmsOrder(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("secret")
+	uc, d := newTestPaymentUsecase(t, provider)
+
+	order := &domain.Order{ID: uuid.New(), ShopID: uuid.New(), Status: domain.StatusAwaitingPayment, Total: 1000}
+	intent := testIntent(order.ID)
+	reservation := domain.Reservation{ProductID: uuid.New(), WarehouseID: uuid.New(), Qty: 2, Status: domain.ResvPending, ExpiresAt: time.Now().Add(time.Minute)}
+
+	d.paymentRepo.EXPECT().GetIntentForUpdate(ctx, mock.Anything, payment.FakeName, intent.ProviderRef).Return(intent, nil)
+	d.paymentRepo.EXPECT().RecordEvent(ctx, mock.Anything, payment.FakeName, mock.MatchedBy(func(e domain.PaymentEvent) bool {
+		return e.ID == "evt_1" && e.Type == domain.PaymentEventSucceeded
+	}), intent.ID).Return(true, nil)
+	d.reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return([]domain.Reservation{reservation}, nil)
+	d.orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	d.paymentRepo.EXPECT().UpdateIntentStatus(ctx, mock.Anything, intent.ID, domain.PaymentSucceeded).Return(nil)
+	d.reservationRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.Reservation{reservation}, nil)
+	d.productStockRepo.EXPECT().CommitStock(ctx, mock.Anything, reservation.ProductID, reservation.WarehouseID, int32(2)).Return(nil)
+	d.movementRepo.EXPECT().Append(ctx, mock.Anything, reservation.ProductID, reservation.WarehouseID, "COMMIT", 2, "ORDER_PAYMENT", order.ID).Return(nil)
+	d.reservationRepo.EXPECT().MarkCommitted(ctx, mock.Anything, order.ID).Return(1, nil)
+	d.orderRepo.EXPECT().Updatestatus(ctx, mock.Anything, order.ID, domain.StatusPaid).Return(nil)
+	d.outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.OrderPaymentConfirmed")).Return(nil)
+	d.outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.StockDecreased")).Return(nil)
+
+	header, body, err := provider.Webhook(payment.FakeEvent{ID: "evt_1", Type: "succeeded", Intent: intent.ProviderRef, Amount: 1000})
+	assert.NoError(t, err)
+
+	duplicate, err := uc.HandleWebhook(ctx, header, body)
+	assert.NoError(t, err)
+	assert.False(t, duplicate)
+}
+
+func TestPayment_HandleWebhook_DuplicateEvent(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("secret")
+	uc, d := newTestPaymentUsecase(t, provider)
+
+	intent := testIntent(uuid.New())
+	d.paymentRepo.EXPECT().GetIntentForUpdate(ctx, mock.Anything, payment.FakeName, intent.ProviderRef).Return(intent, nil)
+	d.paymentRepo.EXPECT().RecordEvent(ctx, mock.Anything, payment.FakeName, mock.Anything, intent.ID).Return(false, nil)
+
+	header, body, _ := provider.Webhook(payment.FakeEvent{ID: "evt_1", Type: "succeeded", Intent: intent.ProviderRef, Amount: 1000})
+
+	duplicate, err := uc.HandleWebhook(ctx, header, body)
+	assert.NoError(t, err)
+	assert.True(t, duplicate)
+}
+
+func TestPayment_HandleWebhook_FailedCancelsOrder(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("secret")
+	uc, d := newTestPaymentUsecase(t, provider)
+
+	order := &domain.Order{ID: uuid.New(), ShopID: uuid.New(), Status: domain.StatusAwaitingPayment, Total: 1000}
+	intent := testIntent(order.ID)
+	reservation := domain.Reservation{ProductID: uuid.New(), WarehouseID: uuid.New(), Qty: 1, Status: domain.ResvPending}
+
+	d.paymentRepo.EXPECT().GetIntentForUpdate(ctx, mock.Anything, payment.FakeName, intent.ProviderRef).Return(intent, nil)
+	d.paymentRepo.EXPECT().RecordEvent(ctx, mock.Anything, payment.FakeName, mock.Anything, intent.ID).Return(true, nil)
+	d.reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return([]domain.Reservation{reservation}, nil)
+	d.orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	d.paymentRepo.EXPECT().UpdateIntentStatus(ctx, mock.Anything, intent.ID, domain.PaymentFailed).Return(nil)
+	d.productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, reservation.ProductID, reservation.WarehouseID, int32(1)).Return(nil)
+	d.movementRepo.EXPECT().Append(ctx, mock.Anything, reservation.ProductID, reservation.WarehouseID, "RELEASE", 1, "ORDER_CANCELLED", order.ID).Return(nil)
+	d.reservationRepo.EXPECT().MarkReleased(ctx, mock.Anything, order.ID).Return(1, nil)
+	d.orderRepo.EXPECT().Updatestatus(ctx, mock.Anything, order.ID, domain.StatusCancelled).Return(nil)
+	d.outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.OrderCancelled")).Return(nil)
+
+	header, body, _ := provider.Webhook(payment.FakeEvent{ID: "evt_2", Type: "failed", Intent: intent.ProviderRef})
+
+	_, err := uc.HandleWebhook(ctx, header, body)
+	assert.NoError(t, err)
+}
+
+func TestPayment_HandleWebhook_AmountMismatchDoesNotConfirm(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("secret")
+	uc, d := newTestPaymentUsecase(t, provider)
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusAwaitingPayment, Total: 1000}
+	intent := testIntent(order.ID)
+
+	d.paymentRepo.EXPECT().GetIntentForUpdate(ctx, mock.Anything, payment.FakeName, intent.ProviderRef).Return(intent, nil)
+	d.paymentRepo.EXPECT().RecordEvent(ctx, mock.Anything, payment.FakeName, mock.Anything, intent.ID).Return(true, nil)
+	d.reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return(nil, nil)
+	d.orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+
+	header, body, _ := provider.Webhook(payment.FakeEvent{ID: "evt_3", Type: "succeeded", Intent: intent.ProviderRef, Amount: 1})
+
+	_, err := uc.HandleWebhook(ctx, header, body)
+	assert.NoError(t, err)
+}
+
+func TestPayment_HandleWebhook_SucceededAfterReservationExpiredIsAcknowledged(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("secret")
+	uc, d := newTestPaymentUsecase(t, provider)
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusAwaitingPayment, Total: 1000}
+	intent := testIntent(order.ID)
+	reservation := domain.Reservation{ProductID: uuid.New(), WarehouseID: uuid.New(), Qty: 1, Status: domain.ResvPending, ExpiresAt: time.Now().Add(-time.Minute)}
+
+	d.paymentRepo.EXPECT().GetIntentForUpdate(ctx, mock.Anything, payment.FakeName, intent.ProviderRef).Return(intent, nil)
+	d.paymentRepo.EXPECT().RecordEvent(ctx, mock.Anything, payment.FakeName, mock.Anything, intent.ID).Return(true, nil)
+	d.reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return([]domain.Reservation{reservation}, nil)
+	d.orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	d.paymentRepo.EXPECT().UpdateIntentStatus(ctx, mock.Anything, intent.ID, domain.PaymentSucceeded).Return(nil)
+	d.reservationRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.Reservation{reservation}, nil)
+
+	header, body, _ := provider.Webhook(payment.FakeEvent{ID: "evt_7", Type: "succeeded", Intent: intent.ProviderRef, Amount: 1000})
+
+	_, err := uc.HandleWebhook(ctx, header, body)
+	assert.NoError(t, err)
+	d.productStockRepo.AssertNotCalled(t, "CommitStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPayment_HandleWebhook_RejectsBadSignature(t *testing.T) {
+	ctx := context.Background()
+	uc, _ := newTestPaymentUsecase(t, payment.NewFake("secret"))
+
+	header, body, _ := payment.NewFake("other").Webhook(payment.FakeEvent{ID: "evt_1", Type: "succeeded", Intent: "pi_fake_1", Amount: 1000})
+
+	_, err := uc.HandleWebhook(ctx, header, body)
+	assert.True(t, errx.IsCode(err, errx.CodeUnauthenticated))
+}
+
+func TestPayment_HandleWebhook_RejectsWithoutSecret(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("")
+	uc, _ := newTestPaymentUsecase(t, provider)
+
+	header, body, _ := provider.Webhook(payment.FakeEvent{ID: "evt_1", Type: "succeeded", Intent: "pi_fake_1", Amount: 1000})
+
+	_, err := uc.HandleWebhook(ctx, header, body)
+	assert.True(t, errx.IsCode(err, errx.CodeUnauthenticated))
+}
+
+func TestPayment_CreateIntent(t *testing.T) {
+	ctx := context.Background()
+	uc, d := newTestPaymentUsecase(t, payment.NewFake("secret"))
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusAwaitingPayment, Total: 1000}
+	d.orderRepo.EXPECT().GetByID(ctx, order.ID).Return(order, nil)
+	d.paymentRepo.EXPECT().GetOpenIntent(ctx, mock.Anything, order.ID).Return(nil, sql.ErrNoRows)
+	d.paymentRepo.EXPECT().CreateIntent(ctx, mock.Anything, mock.AnythingOfType("*domain.PaymentIntent")).Return(nil)
+
+	intent, err := uc.CreateIntent(ctx, order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), intent.Amount)
+	assert.Equal(t, payment.FakeName, intent.Provider)
+	assert.NotEmpty(t, intent.ProviderRef)
+	assert.NotEmpty(t, intent.CheckoutURL)
+}
+
+func TestPayment_CreateIntent_ReusesOpenIntent(t *testing.T) {
+	ctx := context.Background()
+	uc, d := newTestPaymentUsecase(t, payment.NewFake("secret"))
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusAwaitingPayment, Total: 1000}
+	open := testIntent(order.ID)
+	d.orderRepo.EXPECT().GetByID(ctx, order.ID).Return(order, nil)
+	d.paymentRepo.EXPECT().GetOpenIntent(ctx, mock.Anything, order.ID).Return(open, nil)
+
+	intent, err := uc.CreateIntent(ctx, order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, open, intent)
+}
+
+func TestPayment_CreateIntent_RequiresAwaitingPayment(t *testing.T) {
+	ctx := context.Background()
+	uc, d := newTestPaymentUsecase(t, payment.NewFake("secret"))
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusPaid}
+	d.orderRepo.EXPECT().GetByID(ctx, order.ID).Return(order, nil)
+
+	_, err := uc.CreateIntent(ctx, order.ID)
+	assert.True(t, errx.IsCode(err, errx.CodePrecondition))
+}
+
+func TestPayment_HandleWebhook_RefundedRefundsPaidOrder(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("secret")
+	uc, d := newTestPaymentUsecase(t, provider)
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusPaid, Total: 1000}
+	intent := testIntent(order.ID)
+	intent.Status = domain.PaymentSucceeded
+	item := domain.OrderItem{ID: uuid.New(), ProductID: uuid.New(), Qty: 2, Price: 500}
+
+	d.paymentRepo.EXPECT().GetIntentForUpdate(ctx, mock.Anything, payment.FakeName, intent.ProviderRef).Return(intent, nil)
+	d.paymentRepo.EXPECT().RecordEvent(ctx, mock.Anything, payment.FakeName, mock.Anything, intent.ID).Return(true, nil)
+	d.reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return(nil, nil)
+	d.orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	d.paymentRepo.EXPECT().UpdateIntentStatus(ctx, mock.Anything, intent.ID, domain.PaymentRefunded).Return(nil)
+	d.orderItemRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.OrderItem{item}, nil)
+	d.refundRepo.EXPECT().RefundedQty(ctx, mock.Anything, order.ID).Return(map[uuid.UUID]int{}, nil)
+	d.refundRepo.EXPECT().Create(ctx, mock.Anything, mock.MatchedBy(func(r *domain.Refund) bool {
+		return r.Amount == 1000 && !r.Restock
+	})).Return(nil)
+	d.orderRepo.EXPECT().Updatestatus(ctx, mock.Anything, order.ID, domain.StatusRefunded).Return(nil)
+	d.outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.OrderRefunded")).Return(nil)
+
+	header, body, _ := provider.Webhook(payment.FakeEvent{ID: "evt_4", Type: "refunded", Intent: intent.ProviderRef, Amount: 1000})
+
+	_, err := uc.HandleWebhook(ctx, header, body)
+	assert.NoError(t, err)
+}
+
+func TestPayment_HandleWebhook_RefundedConfirmsOwnRefund(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("secret")
+	uc, d := newTestPaymentUsecase(t, provider)
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusRefunded, Total: 1000}
+	intent := testIntent(order.ID)
+	intent.Status = domain.PaymentSucceeded
+	refund := &domain.Refund{ID: uuid.New(), OrderID: order.ID, Amount: 1000, Status: domain.RefundPending}
+
+	d.paymentRepo.EXPECT().GetIntentForUpdate(ctx, mock.Anything, payment.FakeName, intent.ProviderRef).Return(intent, nil)
+	d.paymentRepo.EXPECT().RecordEvent(ctx, mock.Anything, payment.FakeName, mock.Anything, intent.ID).Return(true, nil)
+	d.reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return(nil, nil)
+	d.orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	d.refundRepo.EXPECT().Get(ctx, mock.Anything, refund.ID).Return(refund, nil)
+	d.refundRepo.EXPECT().UpdateStatus(ctx, mock.Anything, refund.ID, domain.RefundSucceeded).Return(nil)
+	d.paymentRepo.EXPECT().UpdateIntentStatus(ctx, mock.Anything, intent.ID, domain.PaymentRefunded).Return(nil)
+
+	header, body, _ := provider.Webhook(payment.FakeEvent{ID: "evt_5", Type: "refunded", Intent: intent.ProviderRef, Amount: 1000, Refund: refund.ID.String()})
+
+	_, err := uc.HandleWebhook(ctx, header, body)
+	assert.NoError(t, err)
+	d.refundRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPayment_HandleWebhook_PartialProviderRefundNeedsReview(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("secret")
+	uc, d := newTestPaymentUsecase(t, provider)
+
+	order := &domain.Order{ID: uuid.New(), Status: domain.StatusPaid, Total: 1000}
+	intent := testIntent(order.ID)
+	intent.Status = domain.PaymentSucceeded
+	item := domain.OrderItem{ID: uuid.New(), ProductID: uuid.New(), Qty: 2, Price: 500}
+
+	d.paymentRepo.EXPECT().GetIntentForUpdate(ctx, mock.Anything, payment.FakeName, intent.ProviderRef).Return(intent, nil)
+	d.paymentRepo.EXPECT().RecordEvent(ctx, mock.Anything, payment.FakeName, mock.Anything, intent.ID).Return(true, nil)
+	d.reservationRepo.EXPECT().LockPendingByOrder(ctx, mock.Anything, order.ID).Return(nil, nil)
+	d.orderRepo.EXPECT().GetByIDForUpdate(ctx, mock.Anything, order.ID).Return(order, nil)
+	d.orderItemRepo.EXPECT().GetByOrderID(ctx, mock.Anything, order.ID).Return([]domain.OrderItem{item}, nil)
+	d.refundRepo.EXPECT().RefundedQty(ctx, mock.Anything, order.ID).Return(map[uuid.UUID]int{}, nil)
+
+	header, body, _ := provider.Webhook(payment.FakeEvent{ID: "evt_6", Type: "refunded", Intent: intent.ProviderRef, Amount: 400})
+
+	_, err := uc.HandleWebhook(ctx, header, body)
+	assert.NoError(t, err)
+	d.refundRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	d.paymentRepo.AssertNotCalled(t, "UpdateIntentStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
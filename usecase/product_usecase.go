@@ -2,21 +2,28 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/pkg/errx"
 	"github.com/dyaksa/warehouse/pkg/paginator"
+	"github.com/dyaksa/warehouse/pkg/tracing"
 	"github.com/google/uuid"
 )
 
 type productUsecase struct {
 	productRepository      domain.ProductRepository
 	productStockRepository domain.ProductStockRepository
+	warehouseRepository    domain.WarehouseRepository
 	paginator              paginator.Paginator[domain.RetrieveProduct]
 }
 
 // RetrieveAll implements domain.ProductUsecase.
 func (pu *productUsecase) RetrieveAll(ctx context.Context, pagination paginator.PaginationRequest) (*paginator.PaginationResult[domain.RetrieveProduct], error) {
+	ctx, span := tracing.Start(ctx, "ProductUsecase.RetrieveAll")
+	defer span.End()
+
 	return pu.paginator.Paginate(ctx, pagination, func(ctx context.Context, offset, limit int) (items []domain.RetrieveProduct, totalItems int, err error) {
 		items, err = pu.productRepository.RetrieveAll(ctx, limit, offset)
 		if err != nil {
@@ -28,7 +35,48 @@ func (pu *productUsecase) RetrieveAll(ctx context.Context, pagination paginator.
 	})
 }
 
+// RetrieveByShop implements domain.ProductUsecase.
+func (pu *productUsecase) RetrieveByShop(ctx context.Context, shopID uuid.UUID, pagination paginator.PaginationRequest) (*paginator.PaginationResult[domain.RetrieveProduct], error) {
+	ctx, span := tracing.Start(ctx, "ProductUsecase.RetrieveByShop", tracing.ShopID(shopID))
+	defer span.End()
+
+	return pu.paginator.Paginate(ctx, pagination, func(ctx context.Context, offset, limit int) (items []domain.RetrieveProduct, totalItems int, err error) {
+		items, err = pu.productRepository.RetrieveByShop(ctx, shopID, limit, offset)
+		if err != nil {
+			return items, totalItems, errx.E(errx.CodeInternal, "failed to retrieve products", errx.Op("productUsecase.RetrieveByShop"), err)
+		}
+
+		totalItems = len(items)
+		return items, totalItems, nil
+	})
+}
+
+// CreateForShop implements domain.ProductUsecase.
+func (pu *productUsecase) CreateForShop(ctx context.Context, shopID uuid.UUID, payload domain.CreateProductRequest) error {
+	warehouseId, err := uuid.Parse(payload.WarehouseID)
+	if err != nil {
+		return errx.E(errx.CodeValidation, "invalid warehouse UUID", errx.Op("productUsecase.CreateForShop"), err)
+	}
+
+	warehouse, err := pu.warehouseRepository.Retrieve(ctx, warehouseId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errx.E(errx.CodeNotFound, "warehouse not found", errx.Op("productUsecase.CreateForShop"), err)
+		}
+		return errx.E(errx.CodeInternal, "failed to get warehouse", errx.Op("productUsecase.CreateForShop"), err)
+	}
+
+	if warehouse.ShopID != shopID {
+		return errx.E(errx.CodePermission, "warehouse does not belong to the shop", errx.Op("productUsecase.CreateForShop"))
+	}
+
+	return pu.Create(ctx, payload)
+}
+
 func (pu *productUsecase) Create(ctx context.Context, payload domain.CreateProductRequest) error {
+	ctx, span := tracing.Start(ctx, "ProductUsecase.Create")
+	defer span.End()
+
 	warehouseId, err := uuid.Parse(payload.WarehouseID)
 	if err != nil {
 		return errx.E(errx.CodeValidation, "invalid warehouse UUID", errx.Op("productUsecase.Create"), err)
@@ -60,10 +108,12 @@ func (pu *productUsecase) Create(ctx context.Context, payload domain.CreateProdu
 func NewProductUsecase(
 	productRepository domain.ProductRepository,
 	productStockUsecase domain.ProductStockRepository,
+	warehouseRepository domain.WarehouseRepository,
 ) domain.ProductUsecase {
 	return &productUsecase{
 		productRepository:      productRepository,
 		productStockRepository: productStockUsecase,
+		warehouseRepository:    warehouseRepository,
 		paginator:              paginator.NewOffsetPaginator[domain.RetrieveProduct](),
 	}
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/dyaksa/warehouse/domain"
 	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
 	"github.com/dyaksa/warehouse/pkg/paginator"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -18,7 +19,7 @@ func TestProductUsecase_Create_Success(t *testing.T) {
 	productRepo := mocks.NewMockProductRepository(t)
 	stockRepo := mocks.NewMockProductStockRepository(t)
 
-	uc := NewProductUsecase(productRepo, stockRepo)
+	uc := NewProductUsecase(productRepo, stockRepo, nil)
 
 	warehouseID := uuid.New()
 	newProductID := uuid.New()
@@ -49,7 +50,7 @@ func TestProductUsecase_Create_InvalidWarehouseUUID(t *testing.T) {
 	ctx := context.Background()
 	productRepo := mocks.NewMockProductRepository(t)
 	stockRepo := mocks.NewMockProductStockRepository(t)
-	uc := NewProductUsecase(productRepo, stockRepo)
+	uc := NewProductUsecase(productRepo, stockRepo, nil)
 
 	err := uc.Create(ctx, domain.CreateProductRequest{WarehouseID: "not-a-uuid", SKU: "SKU-123", Name: "Sample", OnHand: 1})
 	assert.Error(t, err)
@@ -59,7 +60,7 @@ func TestProductUsecase_Create_ProductRepoError(t *testing.T) {
 	ctx := context.Background()
 	productRepo := mocks.NewMockProductRepository(t)
 	stockRepo := mocks.NewMockProductStockRepository(t)
-	uc := NewProductUsecase(productRepo, stockRepo)
+	uc := NewProductUsecase(productRepo, stockRepo, nil)
 
 	warehouseID := uuid.New()
 	expectedErr := errors.New("db error")
@@ -78,7 +79,7 @@ func TestProductUsecase_RetrieveAll_Success(t *testing.T) {
 	ctx := context.Background()
 	productRepo := mocks.NewMockProductRepository(t)
 	stockRepo := mocks.NewMockProductStockRepository(t)
-	uc := NewProductUsecase(productRepo, stockRepo)
+	uc := NewProductUsecase(productRepo, stockRepo, nil)
 
 	// We expect paginator to call RetrieveAll with (limit, offset)
 	products := []domain.RetrieveProduct{{SKU: "A"}, {SKU: "B"}}
@@ -90,3 +91,47 @@ func TestProductUsecase_RetrieveAll_Success(t *testing.T) {
 	assert.Equal(t, 2, result.TotalItems)
 	assert.Len(t, result.Items, 2)
 }
+
+func TestProductUsecase_CreateForShop_RejectsOtherShopsWarehouse(t *testing.T) {
+	ctx := context.Background()
+	productRepo := mocks.NewMockProductRepository(t)
+	stockRepo := mocks.NewMockProductStockRepository(t)
+	warehouseRepo := mocks.NewMockWarehouseRepository(t)
+	uc := NewProductUsecase(productRepo, stockRepo, warehouseRepo)
+
+	warehouse := &domain.WareHouse{ID: uuid.New(), ShopID: uuid.New()}
+	warehouseRepo.EXPECT().Retrieve(ctx, warehouse.ID).Return(warehouse, nil)
+
+	err := uc.CreateForShop(ctx, uuid.New(), domain.CreateProductRequest{WarehouseID: warehouse.ID.String(), SKU: "SKU-123", Name: "Sample", OnHand: 1})
+	assert.True(t, errx.IsCode(err, errx.CodePermission))
+}
+
+func TestProductUsecase_CreateForShop_Success(t *testing.T) {
+	ctx := context.Background()
+	productRepo := mocks.NewMockProductRepository(t)
+	stockRepo := mocks.NewMockProductStockRepository(t)
+	warehouseRepo := mocks.NewMockWarehouseRepository(t)
+	uc := NewProductUsecase(productRepo, stockRepo, warehouseRepo)
+
+	warehouse := &domain.WareHouse{ID: uuid.New(), ShopID: uuid.New()}
+	warehouseRepo.EXPECT().Retrieve(ctx, warehouse.ID).Return(warehouse, nil)
+	productRepo.EXPECT().Create(ctx, mock.Anything).Return(uuid.New(), nil)
+	stockRepo.EXPECT().Create(ctx, mock.Anything).Return(uuid.New(), nil)
+
+	err := uc.CreateForShop(ctx, warehouse.ShopID, domain.CreateProductRequest{WarehouseID: warehouse.ID.String(), SKU: "SKU-123", Name: "Sample", OnHand: 1})
+	assert.NoError(t, err)
+}
+
+func TestProductUsecase_RetrieveByShop(t *testing.T) {
+	ctx := context.Background()
+	productRepo := mocks.NewMockProductRepository(t)
+	stockRepo := mocks.NewMockProductStockRepository(t)
+	uc := NewProductUsecase(productRepo, stockRepo, nil)
+
+	shopID := uuid.New()
+	productRepo.EXPECT().RetrieveByShop(ctx, shopID, 10, 0).Return([]domain.RetrieveProduct{{SKU: "A"}}, nil)
+
+	result, err := uc.RetrieveByShop(ctx, shopID, paginator.PaginationRequest{Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+}
@@ -2,9 +2,12 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/tracing"
 	"github.com/google/uuid"
 )
 
@@ -13,9 +16,13 @@ type shopUsecase struct {
 }
 
 // Create implements domain.ShopUsecase.
-func (s *shopUsecase) Create(ctx context.Context, payload domain.CreateShopRequest) error {
+func (s *shopUsecase) Create(ctx context.Context, ownerID uuid.UUID, payload domain.CreateShopRequest) error {
+	ctx, span := tracing.Start(ctx, "ShopUsecase.Create", tracing.UserID(ownerID))
+	defer span.End()
+
 	shop := &domain.Shop{
-		Name: payload.Name,
+		Name:    payload.Name,
+		OwnerID: &ownerID,
 	}
 
 	if _, err := s.shopRepository.Create(ctx, shop); err != nil {
@@ -27,6 +34,9 @@ func (s *shopUsecase) Create(ctx context.Context, payload domain.CreateShopReque
 
 // Delete implements domain.ShopUsecase.
 func (s *shopUsecase) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "ShopUsecase.Delete", tracing.ShopID(id))
+	defer span.End()
+
 	if err := s.shopRepository.Delete(ctx, id); err != nil {
 		return errx.E(errx.CodeInternal, "failed to delete shop", errx.Op("shopUsecase.Delete"), err)
 	}
@@ -36,6 +46,9 @@ func (s *shopUsecase) Delete(ctx context.Context, id uuid.UUID) error {
 
 // Retrieve implements domain.ShopUsecase.
 func (s *shopUsecase) Retrieve(ctx context.Context, id uuid.UUID) (*domain.Shop, error) {
+	ctx, span := tracing.Start(ctx, "ShopUsecase.Retrieve", tracing.ShopID(id))
+	defer span.End()
+
 	shop, err := s.shopRepository.Retrieve(ctx, id)
 	if err != nil {
 		return nil, errx.E(errx.CodeInternal, "failed to retrieve shop", errx.Op("shopUsecase.Retrieve"), err)
@@ -46,6 +59,9 @@ func (s *shopUsecase) Retrieve(ctx context.Context, id uuid.UUID) (*domain.Shop,
 
 // Update implements domain.ShopUsecase.
 func (s *shopUsecase) Update(ctx context.Context, payload domain.UpdateShopRequest) error {
+	ctx, span := tracing.Start(ctx, "ShopUsecase.Update")
+	defer span.End()
+
 	shop := &domain.Shop{
 		ID:   payload.ID,
 		Name: payload.Name,
@@ -58,6 +74,23 @@ func (s *shopUsecase) Update(ctx context.Context, payload domain.UpdateShopReque
 	return nil
 }
 
+// ensureShopOwner fails unless userID owns shopID. Shops without a recorded owner belong to nobody.
+func ensureShopOwner(ctx context.Context, shopRepo domain.ShopRepository, shopID, userID uuid.UUID, op string) error {
+	shop, err := shopRepo.Retrieve(ctx, shopID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return errx.E(errx.CodeNotFound, "shop not found", errx.Op(op), err)
+	case err != nil:
+		return errx.E(errx.CodeInternal, "failed to retrieve shop", errx.Op(op), err)
+	}
+
+	if shop.OwnerID == nil || *shop.OwnerID != userID {
+		return errx.E(errx.CodePermission, "only the shop owner can do this", errx.Op(op))
+	}
+
+	return nil
+}
+
 func NewShopUsecase(shopRepository domain.ShopRepository) domain.ShopUsecase {
 	return &shopUsecase{
 		shopRepository: shopRepository,
@@ -16,15 +16,17 @@ func TestShopUsecase_Create_Success(t *testing.T) {
 	ctx := context.Background()
 	repo := mocks.NewMockShopRepository(t)
 	uc := NewShopUsecase(repo)
+	ownerID := uuid.New()
 
 	repo.EXPECT().Create(ctx, mock.Anything).RunAndReturn(
 		func(c context.Context, s *domain.Shop) (uuid.UUID, error) {
 			assert.Equal(t, "Main Shop", s.Name)
+			assert.Equal(t, &ownerID, s.OwnerID)
 			return uuid.New(), nil
 		},
 	)
 
-	err := uc.Create(ctx, domain.CreateShopRequest{Name: "Main Shop"})
+	err := uc.Create(ctx, ownerID, domain.CreateShopRequest{Name: "Main Shop"})
 	assert.NoError(t, err)
 }
 
@@ -36,7 +38,7 @@ func TestShopUsecase_Create_RepoError(t *testing.T) {
 
 	repo.EXPECT().Create(ctx, mock.Anything).Return(uuid.Nil, expected)
 
-	err := uc.Create(ctx, domain.CreateShopRequest{Name: "X"})
+	err := uc.Create(ctx, uuid.New(), domain.CreateShopRequest{Name: "X"})
 	assert.ErrorIs(t, err, expected)
 }
 
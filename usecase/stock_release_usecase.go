@@ -3,16 +3,34 @@ package usecase
 import (
 	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"time"
 
 	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
 	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/metrics"
+	"github.com/dyaksa/warehouse/pkg/paginator"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"github.com/google/uuid"
 )
 
 type StockReleaseUsecase interface {
-	ProcessExpiredReservations(ctx context.Context, batchSize int) error
+	// ProcessExpiredReservations releases up to batchSize expired reservations and returns how many it released.
+	ProcessExpiredReservations(ctx context.Context, batchSize int) (int, error)
 	ReleaseReservationStock(ctx context.Context, reservation domain.Reservation) error
+	// State returns the worker state shared by all replicas.
+	State(ctx context.Context) (*domain.StockReleaseState, error)
+	// SetPaused pauses or resumes scheduled batches on every replica. It reports false if they already were.
+	SetPaused(ctx context.Context, paused bool) (bool, error)
+	// RecordRun stores the outcome of a batch, so State reports it on every replica.
+	RecordRun(ctx context.Context, released int, runErr error) error
+	ListQuarantined(ctx context.Context, pagination paginator.PaginationRequest) (*paginator.PaginationResult[domain.QuarantinedReservation], error)
+	// RetryQuarantined hands a quarantined reservation back to the worker with a fresh attempt budget.
+	RetryQuarantined(ctx context.Context, id uuid.UUID) error
+	// ReleaseQuarantined releases the stock of a quarantined reservation now and settles its order.
+	ReleaseQuarantined(ctx context.Context, id uuid.UUID) error
 }
 
 type stockReleaseUsecase struct {
@@ -21,10 +39,26 @@ type stockReleaseUsecase struct {
 	productStockRepo domain.ProductStockRepository
 	movementRepo     domain.MovementRepository
 	orderRepo        domain.OrderRepository
+	outboxRepo       domain.OutboxRepository
+	stateRepo        domain.StockReleaseStateRepository
+	// maxReleaseAttempts is how many times releasing an expired reservation may fail before it is quarantined.
+	maxReleaseAttempts int
+	logger             log.Logger
 }
 
 // ProcessExpiredReservations implements StockReleaseUsecase.
-func (s *stockReleaseUsecase) ProcessExpiredReservations(ctx context.Context, batchSize int) error {
+//
+// Each reservation is released inside its own savepoint, so one that fails (a poison reservation)
+// is rolled back on its own while the rest of the batch still expires. Its failure is counted, and
+// after maxReleaseAttempts failures it is quarantined so later batches stop picking it up. Transient
+// database errors are not counted, since they say nothing about the reservation.
+func (s *stockReleaseUsecase) ProcessExpiredReservations(ctx context.Context, batchSize int) (int, error) {
+	ctx, span := tracing.Start(ctx, "StockReleaseUsecase.ProcessExpiredReservations")
+	defer span.End()
+
+	var released, quarantined []domain.Reservation
+	l := log.WithContext(ctx, s.logger)
+
 	_, err := s.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
 		// Pick expired reservations for update
 		expiredReservations, err := s.reservationRepo.PickExpiredForUpdate(ctx, tx, batchSize)
@@ -32,37 +66,99 @@ func (s *stockReleaseUsecase) ProcessExpiredReservations(ctx context.Context, ba
 			return nil, errx.E(errx.CodeInternal, "failed to pick expired reservations", errx.Op("stockReleaseUsecase.ProcessExpiredReservations"), err)
 		}
 
-		log.Printf("Found %d expired reservations to process", len(expiredReservations))
+		l.Debug("picked expired reservations", log.Int64("count", int64(len(expiredReservations))))
 
 		for _, reservation := range expiredReservations {
-			// Release stock for each expired reservation
-			if err := s.releaseStockForReservation(ctx, tx, reservation); err != nil {
-				log.Printf("Error releasing stock for reservation %s: %v", reservation.ID, err)
-				return nil, errx.E(errx.CodeInternal, "failed to release stock for reservation", errx.Op("stockReleaseUsecase.ProcessExpiredReservations"), err)
+			err := s.db.Savepoint(ctx, tx, func(ctx context.Context) error {
+				return s.expireReservation(ctx, tx, reservation)
+			})
+			if err == nil {
+				released = append(released, reservation)
+				l.Info("reservation expired and stock released",
+					log.String("reservation_id", reservation.ID.String()),
+					log.String("order_id", reservation.OrderID.String()),
+				)
+				continue
+			}
+
+			if pqsql.IsTransient(err) {
+				l.Warn("failed to release expired reservation on a transient error, will retry",
+					log.String("reservation_id", reservation.ID.String()),
+					log.String("order_id", reservation.OrderID.String()),
+					log.Error("error", err),
+				)
+				continue
 			}
 
-			// Mark reservation as expired
-			if err := s.reservationRepo.MarkExpired(ctx, tx, reservation.ID); err != nil {
-				log.Printf("Error marking reservation %s as expired: %v", reservation.ID, err)
-				return nil, errx.E(errx.CodeInternal, "failed to mark reservation as expired", errx.Op("stockReleaseUsecase.ProcessExpiredReservations"), err)
+			failed, recordErr := s.reservationRepo.RecordReleaseFailure(ctx, tx, reservation.ID, err.Error(), s.maxReleaseAttempts)
+			if recordErr != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to record reservation release failure", errx.Op("stockReleaseUsecase.ProcessExpiredReservations"), recordErr)
 			}
 
-			log.Printf("Successfully released stock and marked reservation %s as expired", reservation.ID)
+			fields := []log.LoggerContextFn{
+				log.String("reservation_id", reservation.ID.String()),
+				log.String("order_id", reservation.OrderID.String()),
+				log.Int64("attempts", int64(failed.ReleaseAttempts)),
+				log.Error("error", err),
+			}
+			if failed.Status == domain.ResvQuarantined {
+				quarantined = append(quarantined, *failed)
+				l.Error("reservation quarantined after repeated release failures, its stock stays reserved until it is resolved", fields...)
+				continue
+			}
+			l.Warn("failed to release expired reservation, will retry", fields...)
 		}
 
-		// Check if all reservations for orders are expired and update order status
-		if err := s.updateOrderStatusIfAllReservationsExpired(ctx, tx, expiredReservations); err != nil {
+		if err := s.updateOrderStatuses(ctx, tx, append(released, quarantined...)); err != nil {
 			return nil, errx.E(errx.CodeInternal, "failed to update order statuses", errx.Op("stockReleaseUsecase.ProcessExpiredReservations"), err)
 		}
 
 		return nil, nil
 	})
+	if err != nil {
+		return 0, err
+	}
 
-	return err
+	metrics.StockReleaseBatchSize.Observe(float64(len(released)))
+	metrics.Reservations.WithLabelValues(metrics.ReservationExpired).Add(float64(len(released)))
+	metrics.Reservations.WithLabelValues(metrics.ReservationQuarantined).Add(float64(len(quarantined)))
+
+	return len(released), nil
+}
+
+// expireReservation releases the stock of an expired reservation, marks it expired and records the
+// event. Errors are returned unwrapped, so the recorded failure reason names the actual cause.
+func (s *stockReleaseUsecase) expireReservation(ctx context.Context, tx *sql.Tx, reservation domain.Reservation) error {
+	if err := s.releaseStockForReservation(ctx, tx, reservation); err != nil {
+		return err
+	}
+
+	if err := s.reservationRepo.MarkExpired(ctx, tx, reservation.ID); err != nil {
+		return err
+	}
+
+	if err := s.outboxRepo.Append(ctx, tx, domain.ReservationExpired{
+		ReservationID: reservation.ID,
+		OrderID:       reservation.OrderID,
+		ProductID:     reservation.ProductID,
+		WarehouseID:   reservation.WarehouseID,
+		Qty:           reservation.Qty,
+		ExpiredAt:     reservation.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 // ReleaseReservationStock implements StockReleaseUsecase.
 func (s *stockReleaseUsecase) ReleaseReservationStock(ctx context.Context, reservation domain.Reservation) error {
+	ctx, span := tracing.Start(ctx, "StockReleaseUsecase.ReleaseReservationStock",
+		tracing.OrderID(reservation.OrderID),
+		tracing.WarehouseID(reservation.WarehouseID),
+	)
+	defer span.End()
+
 	_, err := s.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
 		if err := s.releaseStockForReservation(ctx, tx, reservation); err != nil {
 			return nil, errx.E(errx.CodeInternal, "failed to release stock for reservation", errx.Op("stockReleaseUsecase.ReleaseReservationStock"), err)
@@ -88,37 +184,184 @@ func (s *stockReleaseUsecase) releaseStockForReservation(ctx context.Context, tx
 	return nil
 }
 
-func (s *stockReleaseUsecase) updateOrderStatusIfAllReservationsExpired(ctx context.Context, tx *sql.Tx, expiredReservations []domain.Reservation) error {
-	orderIDs := make(map[string]bool)
+// updateOrderStatuses decides the status of the orders of reservations that were just expired or
+// quarantined.
+func (s *stockReleaseUsecase) updateOrderStatuses(ctx context.Context, tx *sql.Tx, reservations []domain.Reservation) error {
+	seen := make(map[uuid.UUID]bool)
+	for _, reservation := range reservations {
+		if seen[reservation.OrderID] {
+			continue
+		}
+		seen[reservation.OrderID] = true
 
-	// Collect unique order IDs
-	for _, reservation := range expiredReservations {
-		orderIDs[reservation.OrderID.String()] = true
+		if err := s.updateOrderStatus(ctx, tx, reservation.OrderID); err != nil {
+			return err
+		}
 	}
 
-	// Check each order to see if all reservations are expired
-	for orderIDStr := range orderIDs {
-		orderID := expiredReservations[0].OrderID // Get the actual UUID
-		for _, res := range expiredReservations {
-			if res.OrderID.String() == orderIDStr {
-				orderID = res.OrderID
-				break
-			}
+	return nil
+}
+
+// updateOrderStatus expires an unpaid order once none of its reservations holds stock. An order
+// waiting only on quarantined reservations keeps awaiting payment, since their stock is still
+// reserved; it is decided again when an operator releases them.
+func (s *stockReleaseUsecase) updateOrderStatus(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error {
+	l := log.WithContext(ctx, s.logger)
+
+	pending, quarantined, err := s.reservationRepo.HeldCountByOrder(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return nil
+	}
+	if quarantined > 0 {
+		l.Warn("order waits on quarantined reservations and stays awaiting payment until they are resolved",
+			log.String("order_id", orderID.String()),
+			log.Int64("quarantined", int64(quarantined)),
+		)
+		return nil
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	// It may have been paid or cancelled while a reservation sat in quarantine
+	if order.Status != domain.StatusAwaitingPayment && order.Status != domain.StatusPending {
+		return nil
+	}
+
+	if err := s.orderRepo.Updatestatus(ctx, tx, orderID, domain.StatusExpired); err != nil {
+		return err
+	}
+
+	if err := s.outboxRepo.Append(ctx, tx, domain.OrderExpired{OrderID: orderID, ShopID: order.ShopID, ExpiredAt: time.Now()}); err != nil {
+		return err
+	}
+	l.Info("order expired, all reservations have expired", log.String("order_id", orderID.String()))
+
+	return nil
+}
+
+// ListQuarantined implements StockReleaseUsecase.
+func (s *stockReleaseUsecase) ListQuarantined(ctx context.Context, pagination paginator.PaginationRequest) (*paginator.PaginationResult[domain.QuarantinedReservation], error) {
+	ctx, span := tracing.Start(ctx, "StockReleaseUsecase.ListQuarantined")
+	defer span.End()
+
+	pagination.ValidateAndSetDefault()
+
+	reservations, total, err := s.reservationRepo.ListQuarantined(ctx, pagination.Limit, pagination.GetOffset())
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to list quarantined reservations", errx.Op("stockReleaseUsecase.ListQuarantined"), err)
+	}
+
+	return paginator.NewPaginationResult(reservations, total, pagination), nil
+}
+
+// RetryQuarantined implements StockReleaseUsecase.
+func (s *stockReleaseUsecase) RetryQuarantined(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "StockReleaseUsecase.RetryQuarantined")
+	defer span.End()
+
+	_, err := s.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		if _, err := s.lockQuarantined(ctx, tx, id, "stockReleaseUsecase.RetryQuarantined"); err != nil {
+			return nil, err
+		}
+
+		if err := s.reservationRepo.RequeueQuarantined(ctx, tx, id); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to requeue reservation", errx.Op("stockReleaseUsecase.RetryQuarantined"), err)
 		}
 
-		// Count pending reservations for this order
-		pendingCount, err := s.reservationRepo.PendingCountByOrder(ctx, tx, orderID)
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.WithContext(ctx, s.logger).Info("quarantined reservation requeued", log.String("reservation_id", id.String()))
+
+	return nil
+}
+
+// ReleaseQuarantined implements StockReleaseUsecase.
+func (s *stockReleaseUsecase) ReleaseQuarantined(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "StockReleaseUsecase.ReleaseQuarantined")
+	defer span.End()
+
+	_, err := s.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		reservation, err := s.lockQuarantined(ctx, tx, id, "stockReleaseUsecase.ReleaseQuarantined")
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// If no pending reservations left, mark order as expired
-		if pendingCount == 0 {
-			if err := s.orderRepo.Updatestatus(ctx, orderID, domain.StatusExpired); err != nil {
-				return err
-			}
-			log.Printf("Order %s marked as expired - all reservations have expired", orderID)
+		if err := s.expireReservation(ctx, tx, *reservation); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to release reservation", errx.Op("stockReleaseUsecase.ReleaseQuarantined"), err)
 		}
+
+		if err := s.updateOrderStatus(ctx, tx, reservation.OrderID); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to update order status", errx.Op("stockReleaseUsecase.ReleaseQuarantined"), err)
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.Reservations.WithLabelValues(metrics.ReservationExpired).Inc()
+	log.WithContext(ctx, s.logger).Info("quarantined reservation released", log.String("reservation_id", id.String()))
+
+	return nil
+}
+
+// lockQuarantined locks the quarantined reservation id until tx ends.
+func (s *stockReleaseUsecase) lockQuarantined(ctx context.Context, tx *sql.Tx, id uuid.UUID, op string) (*domain.Reservation, error) {
+	reservation, err := s.reservationRepo.GetQuarantinedForUpdate(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errx.E(errx.CodeNotFound, "quarantined reservation not found", errx.Op(op), err)
+		}
+		return nil, errx.E(errx.CodeInternal, "failed to get reservation", errx.Op(op), err)
+	}
+
+	return reservation, nil
+}
+
+// State implements StockReleaseUsecase.
+func (s *stockReleaseUsecase) State(ctx context.Context) (*domain.StockReleaseState, error) {
+	state, err := s.stateRepo.Get(ctx)
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to get stock release state", errx.Op("stockReleaseUsecase.State"), err)
+	}
+
+	return state, nil
+}
+
+// SetPaused implements StockReleaseUsecase.
+func (s *stockReleaseUsecase) SetPaused(ctx context.Context, paused bool) (bool, error) {
+	changed, err := s.stateRepo.SetPaused(ctx, paused)
+	if err != nil {
+		return false, errx.E(errx.CodeInternal, "failed to update stock release state", errx.Op("stockReleaseUsecase.SetPaused"), err)
+	}
+
+	if changed {
+		log.WithContext(ctx, s.logger).Info("stock release worker paused state changed", log.Bool("paused", paused))
+	}
+
+	return changed, nil
+}
+
+// RecordRun implements StockReleaseUsecase.
+func (s *stockReleaseUsecase) RecordRun(ctx context.Context, released int, runErr error) error {
+	var lastError *string
+	if runErr != nil {
+		msg := runErr.Error()
+		lastError = &msg
+	}
+
+	if err := s.stateRepo.RecordRun(ctx, released, lastError); err != nil {
+		return errx.E(errx.CodeInternal, "failed to record stock release run", errx.Op("stockReleaseUsecase.RecordRun"), err)
 	}
 
 	return nil
@@ -130,12 +373,24 @@ func NewStockReleaseUsecase(
 	productStockRepo domain.ProductStockRepository,
 	movementRepo domain.MovementRepository,
 	orderRepo domain.OrderRepository,
+	outboxRepo domain.OutboxRepository,
+	stateRepo domain.StockReleaseStateRepository,
+	maxReleaseAttempts int,
+	logger log.Logger,
 ) StockReleaseUsecase {
+	if maxReleaseAttempts <= 0 {
+		maxReleaseAttempts = domain.DefaultMaxReleaseAttempts
+	}
+
 	return &stockReleaseUsecase{
-		db:               db,
-		reservationRepo:  reservationRepo,
-		productStockRepo: productStockRepo,
-		movementRepo:     movementRepo,
-		orderRepo:        orderRepo,
+		db:                 db,
+		reservationRepo:    reservationRepo,
+		productStockRepo:   productStockRepo,
+		movementRepo:       movementRepo,
+		orderRepo:          orderRepo,
+		outboxRepo:         outboxRepo,
+		stateRepo:          stateRepo,
+		maxReleaseAttempts: maxReleaseAttempts,
+		logger:             logger,
 	}
 }
@@ -4,12 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/dyaksa/warehouse/domain"
 	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -30,6 +34,10 @@ func (f *fakeDBStock) Transaction(ctx context.Context, fn func(ctx context.Conte
 	return fn(ctx, nil)
 }
 
+func (f *fakeDBStock) Savepoint(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestStockRelease_ProcessExpiredReservations_Success(t *testing.T) {
 	ctx := context.Background()
 	db := &fakeDBStock{}
@@ -38,7 +46,8 @@ func TestStockRelease_ProcessExpiredReservations_Success(t *testing.T) {
 	movementRepo := mocks.NewMockMovementRepository(t)
 	orderRepo := mocks.NewMockOrderRepository(t)
 
-	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo, outboxRepo, mocks.NewMockStockReleaseStateRepository(t), 3, log.Nop())
 
 	orderID := uuid.New()
 	productID := uuid.New()
@@ -50,11 +59,15 @@ func TestStockRelease_ProcessExpiredReservations_Success(t *testing.T) {
 	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, productID, warehouseID, int32(3)).Return(nil)
 	movementRepo.EXPECT().Append(ctx, mock.Anything, productID, warehouseID, "RELEASE", 3, "RESERVATION_EXPIRED", res1.ID).Return(nil)
 	reservationRepo.EXPECT().MarkExpired(ctx, mock.Anything, res1.ID).Return(nil)
-	reservationRepo.EXPECT().PendingCountByOrder(ctx, mock.Anything, orderID).Return(0, nil)
-	orderRepo.EXPECT().Updatestatus(ctx, orderID, domain.StatusExpired).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.ReservationExpired")).Return(nil)
+	reservationRepo.EXPECT().HeldCountByOrder(ctx, mock.Anything, orderID).Return(0, 0, nil)
+	orderRepo.EXPECT().GetByID(ctx, orderID).Return(&domain.Order{ID: orderID, ShopID: uuid.New(), Status: domain.StatusAwaitingPayment}, nil)
+	orderRepo.EXPECT().Updatestatus(ctx, mock.Anything, orderID, domain.StatusExpired).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.OrderExpired")).Return(nil)
 
-	err := uc.ProcessExpiredReservations(ctx, 50)
+	released, err := uc.ProcessExpiredReservations(ctx, 50)
 	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
 }
 
 func TestStockRelease_ProcessExpiredReservations_PendingRemain(t *testing.T) {
@@ -65,7 +78,8 @@ func TestStockRelease_ProcessExpiredReservations_PendingRemain(t *testing.T) {
 	movementRepo := mocks.NewMockMovementRepository(t)
 	orderRepo := mocks.NewMockOrderRepository(t)
 
-	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo, outboxRepo, mocks.NewMockStockReleaseStateRepository(t), 3, log.Nop())
 
 	orderID := uuid.New()
 	productID := uuid.New()
@@ -77,10 +91,11 @@ func TestStockRelease_ProcessExpiredReservations_PendingRemain(t *testing.T) {
 	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, productID, warehouseID, int32(2)).Return(nil)
 	movementRepo.EXPECT().Append(ctx, mock.Anything, productID, warehouseID, "RELEASE", 2, "RESERVATION_EXPIRED", res1.ID).Return(nil)
 	reservationRepo.EXPECT().MarkExpired(ctx, mock.Anything, res1.ID).Return(nil)
-	reservationRepo.EXPECT().PendingCountByOrder(ctx, mock.Anything, orderID).Return(1, nil) // still pending others
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.ReservationExpired")).Return(nil)
+	reservationRepo.EXPECT().HeldCountByOrder(ctx, mock.Anything, orderID).Return(1, 0, nil) // still pending others
 	// orderRepo.Updatestatus should NOT be called; absence is asserted by mock expectations auto-verify
 
-	err := uc.ProcessExpiredReservations(ctx, 10)
+	_, err := uc.ProcessExpiredReservations(ctx, 10)
 	assert.NoError(t, err)
 }
 
@@ -92,7 +107,8 @@ func TestStockRelease_ReleaseReservationStock_Success(t *testing.T) {
 	movementRepo := mocks.NewMockMovementRepository(t)
 	orderRepo := mocks.NewMockOrderRepository(t)
 
-	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo, outboxRepo, mocks.NewMockStockReleaseStateRepository(t), 3, log.Nop())
 
 	reservation := domain.Reservation{ID: uuid.New(), ProductID: uuid.New(), WarehouseID: uuid.New(), Qty: 5}
 	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, reservation.ProductID, reservation.WarehouseID, int32(reservation.Qty)).Return(nil)
@@ -110,16 +126,17 @@ func TestStockRelease_ProcessExpiredReservations_ErrorOnPick(t *testing.T) {
 	movementRepo := mocks.NewMockMovementRepository(t)
 	orderRepo := mocks.NewMockOrderRepository(t)
 
-	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo, outboxRepo, mocks.NewMockStockReleaseStateRepository(t), 3, log.Nop())
 
 	expected := errors.New("pick failed")
 	reservationRepo.EXPECT().PickExpiredForUpdate(ctx, mock.Anything, 5).Return(nil, expected)
 
-	err := uc.ProcessExpiredReservations(ctx, 5)
+	_, err := uc.ProcessExpiredReservations(ctx, 5)
 	assert.ErrorIs(t, err, expected)
 }
 
-func TestStockRelease_ProcessExpiredReservations_ErrorOnReleaseStock(t *testing.T) {
+func TestStockRelease_ProcessExpiredReservations_PoisonReservationIsolated(t *testing.T) {
 	ctx := context.Background()
 	db := &fakeDBStock{}
 	reservationRepo := mocks.NewMockReservationRepository(t)
@@ -127,18 +144,135 @@ func TestStockRelease_ProcessExpiredReservations_ErrorOnReleaseStock(t *testing.
 	movementRepo := mocks.NewMockMovementRepository(t)
 	orderRepo := mocks.NewMockOrderRepository(t)
 
-	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo, outboxRepo, mocks.NewMockStockReleaseStateRepository(t), 3, log.Nop())
 
-	orderID := uuid.New()
-	productID := uuid.New()
 	warehouseID := uuid.New()
-	res1 := domain.Reservation{ID: uuid.New(), OrderID: orderID, ProductID: productID, WarehouseID: warehouseID, Qty: 1}
-	expired := []domain.Reservation{res1}
+	poison := domain.Reservation{ID: uuid.New(), OrderID: uuid.New(), ProductID: uuid.New(), WarehouseID: warehouseID, Qty: 1}
+	healthy := domain.Reservation{ID: uuid.New(), OrderID: uuid.New(), ProductID: uuid.New(), WarehouseID: warehouseID, Qty: 2}
+
+	reservationRepo.EXPECT().PickExpiredForUpdate(ctx, mock.Anything, 3).Return([]domain.Reservation{poison, healthy}, nil)
+	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, poison.ProductID, warehouseID, int32(1)).Return(errors.New("release fail"))
+	reservationRepo.EXPECT().RecordReleaseFailure(ctx, mock.Anything, poison.ID, mock.Anything, 3).
+		Return(&domain.Reservation{ID: poison.ID, Status: domain.ResvPending, ReleaseAttempts: 1}, nil)
+
+	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, healthy.ProductID, warehouseID, int32(2)).Return(nil)
+	movementRepo.EXPECT().Append(ctx, mock.Anything, healthy.ProductID, warehouseID, "RELEASE", 2, "RESERVATION_EXPIRED", healthy.ID).Return(nil)
+	reservationRepo.EXPECT().MarkExpired(ctx, mock.Anything, healthy.ID).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.ReservationExpired")).Return(nil)
+	reservationRepo.EXPECT().HeldCountByOrder(ctx, mock.Anything, healthy.OrderID).Return(1, 0, nil)
+	// the poison reservation's order is not checked, it still has a pending reservation
+
+	released, err := uc.ProcessExpiredReservations(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+}
+
+func TestStockRelease_ProcessExpiredReservations_QuarantinesAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDBStock{}
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	productStockRepo := mocks.NewMockProductStockRepository(t)
+	movementRepo := mocks.NewMockMovementRepository(t)
+	orderRepo := mocks.NewMockOrderRepository(t)
 
-	reservationRepo.EXPECT().PickExpiredForUpdate(ctx, mock.Anything, 3).Return(expired, nil)
-	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, productID, warehouseID, int32(1)).Return(errors.New("release fail"))
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo, outboxRepo, mocks.NewMockStockReleaseStateRepository(t), 3, log.Nop())
 
-	err := uc.ProcessExpiredReservations(ctx, 3)
+	poison := domain.Reservation{ID: uuid.New(), OrderID: uuid.New(), ProductID: uuid.New(), WarehouseID: uuid.New(), Qty: 1, ReleaseAttempts: 2}
+
+	reservationRepo.EXPECT().PickExpiredForUpdate(ctx, mock.Anything, 3).Return([]domain.Reservation{poison}, nil)
+	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, poison.ProductID, poison.WarehouseID, int32(1)).Return(nil)
+	movementRepo.EXPECT().Append(ctx, mock.Anything, poison.ProductID, poison.WarehouseID, "RELEASE", 1, "RESERVATION_EXPIRED", poison.ID).Return(nil)
+	reservationRepo.EXPECT().MarkExpired(ctx, mock.Anything, poison.ID).Return(errors.New("check constraint violated"))
+	reservationRepo.EXPECT().RecordReleaseFailure(ctx, mock.Anything, poison.ID, mock.MatchedBy(func(reason string) bool {
+		return strings.Contains(reason, "check constraint violated")
+	}), 3).Return(&domain.Reservation{ID: poison.ID, OrderID: poison.OrderID, Status: domain.ResvQuarantined, ReleaseAttempts: 3}, nil)
+	// the quarantined row still holds stock, so its order is left awaiting payment
+	reservationRepo.EXPECT().HeldCountByOrder(ctx, mock.Anything, poison.OrderID).Return(0, 1, nil)
+
+	released, err := uc.ProcessExpiredReservations(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, released)
+}
+
+func TestStockRelease_ProcessExpiredReservations_TransientErrorNotCounted(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDBStock{}
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	productStockRepo := mocks.NewMockProductStockRepository(t)
+
+	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, mocks.NewMockMovementRepository(t), mocks.NewMockOrderRepository(t), mocks.NewMockOutboxRepository(t), mocks.NewMockStockReleaseStateRepository(t), 3, log.Nop())
+
+	res1 := domain.Reservation{ID: uuid.New(), OrderID: uuid.New(), ProductID: uuid.New(), WarehouseID: uuid.New(), Qty: 1}
+
+	reservationRepo.EXPECT().PickExpiredForUpdate(ctx, mock.Anything, 3).Return([]domain.Reservation{res1}, nil)
+	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, res1.ProductID, res1.WarehouseID, int32(1)).
+		Return(&pq.Error{Code: "55P03", Message: "could not obtain lock on row"})
+	// no RecordReleaseFailure: a lock timeout says nothing about the reservation
+
+	released, err := uc.ProcessExpiredReservations(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, released)
+}
+
+func TestStockRelease_ReleaseQuarantined_ExpiresOrder(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDBStock{}
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	productStockRepo := mocks.NewMockProductStockRepository(t)
+	movementRepo := mocks.NewMockMovementRepository(t)
+	orderRepo := mocks.NewMockOrderRepository(t)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo, outboxRepo, mocks.NewMockStockReleaseStateRepository(t), 3, log.Nop())
+
+	res := domain.Reservation{ID: uuid.New(), OrderID: uuid.New(), ProductID: uuid.New(), WarehouseID: uuid.New(), Qty: 2, Status: domain.ResvQuarantined}
+
+	reservationRepo.EXPECT().GetQuarantinedForUpdate(ctx, mock.Anything, res.ID).Return(&res, nil)
+	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, res.ProductID, res.WarehouseID, int32(2)).Return(nil)
+	movementRepo.EXPECT().Append(ctx, mock.Anything, res.ProductID, res.WarehouseID, "RELEASE", 2, "RESERVATION_EXPIRED", res.ID).Return(nil)
+	reservationRepo.EXPECT().MarkExpired(ctx, mock.Anything, res.ID).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.ReservationExpired")).Return(nil)
+	// the other reservations expired in earlier batches, so the order is settled now
+	reservationRepo.EXPECT().HeldCountByOrder(ctx, mock.Anything, res.OrderID).Return(0, 0, nil)
+	orderRepo.EXPECT().GetByID(ctx, res.OrderID).Return(&domain.Order{ID: res.OrderID, Status: domain.StatusAwaitingPayment}, nil)
+	orderRepo.EXPECT().Updatestatus(ctx, mock.Anything, res.OrderID, domain.StatusExpired).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.OrderExpired")).Return(nil)
+
+	err := uc.ReleaseQuarantined(ctx, res.ID)
+	assert.NoError(t, err)
+}
+
+func TestStockRelease_RetryQuarantined_NotQuarantined(t *testing.T) {
+	ctx := context.Background()
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	uc := NewStockReleaseUsecase(&fakeDBStock{}, reservationRepo, nil, nil, nil, nil, nil, 3, log.Nop())
+
+	id := uuid.New()
+	reservationRepo.EXPECT().GetQuarantinedForUpdate(ctx, mock.Anything, id).Return(nil, sql.ErrNoRows)
+
+	err := uc.RetryQuarantined(ctx, id)
+	assert.True(t, errx.IsCode(err, errx.CodeNotFound))
+}
+
+func TestStockRelease_ProcessExpiredReservations_ErrorOnRecordFailure(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDBStock{}
+	reservationRepo := mocks.NewMockReservationRepository(t)
+	productStockRepo := mocks.NewMockProductStockRepository(t)
+	movementRepo := mocks.NewMockMovementRepository(t)
+	orderRepo := mocks.NewMockOrderRepository(t)
+
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo, outboxRepo, mocks.NewMockStockReleaseStateRepository(t), 3, log.Nop())
+
+	res1 := domain.Reservation{ID: uuid.New(), OrderID: uuid.New(), ProductID: uuid.New(), WarehouseID: uuid.New(), Qty: 1}
+
+	reservationRepo.EXPECT().PickExpiredForUpdate(ctx, mock.Anything, 3).Return([]domain.Reservation{res1}, nil)
+	productStockRepo.EXPECT().ReleaseStock(ctx, mock.Anything, res1.ProductID, res1.WarehouseID, int32(1)).Return(errors.New("release fail"))
+	reservationRepo.EXPECT().RecordReleaseFailure(ctx, mock.Anything, res1.ID, mock.Anything, 3).Return(nil, errors.New("connection reset"))
+
+	_, err := uc.ProcessExpiredReservations(ctx, 3)
 	assert.Error(t, err)
 }
 
@@ -151,12 +285,27 @@ func TestStockRelease_ProcessExpiredReservations_NoExpired(t *testing.T) {
 	movementRepo := mocks.NewMockMovementRepository(t)
 	orderRepo := mocks.NewMockOrderRepository(t)
 
-	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewStockReleaseUsecase(db, reservationRepo, productStockRepo, movementRepo, orderRepo, outboxRepo, mocks.NewMockStockReleaseStateRepository(t), 3, log.Nop())
 
 	reservationRepo.EXPECT().PickExpiredForUpdate(ctx, mock.Anything, 20).Return([]domain.Reservation{}, nil)
 	// No further calls expected
-	err := uc.ProcessExpiredReservations(ctx, 20)
+	released, err := uc.ProcessExpiredReservations(ctx, 20)
 	assert.NoError(t, err)
+	assert.Equal(t, 0, released)
 	// time-based no action side effects
 	_ = time.Now() // keep time import used when no other test uses time
 }
+
+func TestStockRelease_RecordRun_StoresError(t *testing.T) {
+	ctx := context.Background()
+	stateRepo := mocks.NewMockStockReleaseStateRepository(t)
+	uc := NewStockReleaseUsecase(&fakeDBStock{}, nil, nil, nil, nil, nil, stateRepo, 3, log.Nop())
+
+	stateRepo.EXPECT().RecordRun(ctx, 2, mock.MatchedBy(func(lastError *string) bool {
+		return lastError != nil && *lastError == "lock timeout"
+	})).Return(nil)
+
+	err := uc.RecordRun(ctx, 2, errors.New("lock timeout"))
+	assert.NoError(t, err)
+}
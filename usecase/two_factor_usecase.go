@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/crypto"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/passwordutils"
+	"github.com/dyaksa/warehouse/pkg/tokenutils"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+const (
+	recoveryCodeCount = 10
+	totpSkew          = 1
+)
+
+type twoFactorUsecase struct {
+	db            pqsql.Database
+	userRepo      domain.UserRepository
+	twoFactorRepo domain.TwoFactorRepository
+	crypto        crypto.Crypto
+	env           *bootstrap.Env
+}
+
+// Enroll implements domain.TwoFactorUsecase.
+// A new secret replaces any pending enrollment; it only takes effect once Activate confirms a code.
+func (t *twoFactorUsecase) Enroll(ctx context.Context, userID uuid.UUID) (domain.TwoFactorEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorUsecase.Enroll", tracing.UserID(userID))
+	defer span.End()
+
+	var enrollment domain.TwoFactorEnrollment
+
+	user, err := t.userRepo.GetByID(ctx, userID, decryptUser(t.crypto))
+	if err != nil {
+		return enrollment, errx.E(errx.CodeNotFound, "user not found", errx.Op("twoFactorUsecase.Enroll"), err)
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return enrollment, errx.E(errx.CodeConflict, "two-factor authentication already enabled", errx.Op("twoFactorUsecase.Enroll"))
+	}
+
+	secret, err := tokenutils.NewTOTPSecret()
+	if err != nil {
+		return enrollment, errx.E(errx.CodeInternal, "failed to generate secret", errx.Op("twoFactorUsecase.Enroll"), err)
+	}
+
+	_, err = t.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		return nil, t.twoFactorRepo.SaveSecret(ctx, tx, userID, t.crypto.Encrypt(secret), t.crypto.KeyVersion())
+	})
+	if err != nil {
+		return enrollment, errx.E(errx.CodeInternal, "failed to save secret", errx.Op("twoFactorUsecase.Enroll"), err)
+	}
+
+	enrollment.Secret = secret
+	enrollment.ProvisioningURI = tokenutils.TOTPProvisioningURI(t.issuer(), user.Email.To(), secret)
+
+	return enrollment, nil
+}
+
+// Activate implements domain.TwoFactorUsecase.
+func (t *twoFactorUsecase) Activate(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorUsecase.Activate", tracing.UserID(userID))
+	defer span.End()
+
+	codes, hashes, err := t.newRecoveryCodes(userID)
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to generate recovery codes", errx.Op("twoFactorUsecase.Activate"), err)
+	}
+
+	_, err = t.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		tf, err := t.load(ctx, tx, userID, "twoFactorUsecase.Activate")
+		if err != nil {
+			return nil, err
+		}
+
+		if tf.EnabledAt != nil {
+			return nil, errx.E(errx.CodeConflict, "two-factor authentication already enabled", errx.Op("twoFactorUsecase.Activate"))
+		}
+
+		step, ok := tokenutils.ValidateTOTP(tf.Secret.To(), code, time.Now(), totpSkew)
+		if !ok {
+			return nil, errx.E(errx.CodeValidation, "invalid two-factor code", errx.Op("twoFactorUsecase.Activate"))
+		}
+
+		if err := t.twoFactorRepo.Enable(ctx, tx, userID, step); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to enable two-factor authentication", errx.Op("twoFactorUsecase.Activate"), err)
+		}
+
+		if err := t.twoFactorRepo.ReplaceRecoveryCodes(ctx, tx, userID, hashes); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to save recovery codes", errx.Op("twoFactorUsecase.Activate"), err)
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable implements domain.TwoFactorUsecase.
+func (t *twoFactorUsecase) Disable(ctx context.Context, userID uuid.UUID, payload domain.TwoFactorDisableRequest) error {
+	ctx, span := tracing.Start(ctx, "TwoFactorUsecase.Disable", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := t.userRepo.GetByID(ctx, userID, decryptUser(t.crypto))
+	if err != nil {
+		return errx.E(errx.CodeNotFound, "user not found", errx.Op("twoFactorUsecase.Disable"), err)
+	}
+
+	if ok := passwordutils.VerifyPassword(payload.Password, user.PasswordHash); !ok {
+		return errx.E(errx.CodeUnauthorized, "invalid credentials", errx.Op("twoFactorUsecase.Disable"), errors.New("password mismatch"))
+	}
+
+	_, err = t.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		tf, err := t.load(ctx, tx, userID, "twoFactorUsecase.Disable")
+		if err != nil {
+			return nil, err
+		}
+
+		if tf.EnabledAt != nil {
+			if err := t.verifyCode(ctx, tx, tf, payload.Code, "twoFactorUsecase.Disable"); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := t.twoFactorRepo.Disable(ctx, tx, userID); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to disable two-factor authentication", errx.Op("twoFactorUsecase.Disable"), err)
+		}
+
+		return nil, nil
+	})
+
+	return err
+}
+
+// RegenerateRecoveryCodes implements domain.TwoFactorUsecase.
+func (t *twoFactorUsecase) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorUsecase.RegenerateRecoveryCodes", tracing.UserID(userID))
+	defer span.End()
+
+	codes, hashes, err := t.newRecoveryCodes(userID)
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to generate recovery codes", errx.Op("twoFactorUsecase.RegenerateRecoveryCodes"), err)
+	}
+
+	_, err = t.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		tf, err := t.loadEnabled(ctx, tx, userID, "twoFactorUsecase.RegenerateRecoveryCodes")
+		if err != nil {
+			return nil, err
+		}
+
+		if err := t.verifyCode(ctx, tx, tf, code, "twoFactorUsecase.RegenerateRecoveryCodes"); err != nil {
+			return nil, err
+		}
+
+		if err := t.twoFactorRepo.ReplaceRecoveryCodes(ctx, tx, userID, hashes); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to save recovery codes", errx.Op("twoFactorUsecase.RegenerateRecoveryCodes"), err)
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// CompleteLogin implements domain.TwoFactorUsecase.
+func (t *twoFactorUsecase) CompleteLogin(ctx context.Context, payload domain.TwoFactorLoginRequest) (string, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorUsecase.CompleteLogin")
+	defer span.End()
+
+	id, err := tokenutils.ExtractIDFromChallengeToken(payload.ChallengeToken, t.env.JwtSecret)
+	if err != nil {
+		return "", errx.E(errx.CodeUnauthenticated, "invalid or expired challenge token", errx.Op("twoFactorUsecase.CompleteLogin"), err)
+	}
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return "", errx.E(errx.CodeUnauthenticated, "invalid or expired challenge token", errx.Op("twoFactorUsecase.CompleteLogin"), err)
+	}
+
+	_, err = t.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		tf, err := t.loadEnabled(ctx, tx, userID, "twoFactorUsecase.CompleteLogin")
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, t.verifyCode(ctx, tx, tf, payload.Code, "twoFactorUsecase.CompleteLogin")
+	})
+	if err != nil {
+		return "", err
+	}
+
+	accessToken, err := tokenutils.CreateAccessToken(&domain.User{ID: userID}, t.env.JwtSecret, t.env.JwtExpiry)
+	if err != nil {
+		return "", errx.E(errx.CodeInternal, "failed to create access token", errx.Op("twoFactorUsecase.CompleteLogin"), err)
+	}
+
+	return accessToken, nil
+}
+
+func (t *twoFactorUsecase) load(ctx context.Context, tx *sql.Tx, userID uuid.UUID, op string) (*domain.TwoFactor, error) {
+	tf, err := t.twoFactorRepo.Get(ctx, tx, userID, func(data *domain.TwoFactor) (err error) {
+		data.Secret, err = t.crypto.DecryptVersion(data.KeyVersion, "")
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errx.E(errx.CodePrecondition, "two-factor enrollment not started", errx.Op(op), err)
+		}
+		return nil, errx.E(errx.CodeInternal, "failed to load two-factor settings", errx.Op(op), err)
+	}
+
+	return tf, nil
+}
+
+func (t *twoFactorUsecase) loadEnabled(ctx context.Context, tx *sql.Tx, userID uuid.UUID, op string) (*domain.TwoFactor, error) {
+	tf, err := t.load(ctx, tx, userID, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if tf.EnabledAt == nil {
+		return nil, errx.E(errx.CodePrecondition, "two-factor authentication is not enabled", errx.Op(op))
+	}
+
+	return tf, nil
+}
+
+// verifyCode accepts either a TOTP code or an unused recovery code. TOTP codes are
+// bound to their time step, so the same code cannot be replayed within its window.
+func (t *twoFactorUsecase) verifyCode(ctx context.Context, tx *sql.Tx, tf *domain.TwoFactor, code string, op string) error {
+	code = strings.TrimSpace(code)
+
+	if len(code) == tokenutils.TOTPDigits {
+		step, ok := tokenutils.ValidateTOTP(tf.Secret.To(), code, time.Now(), totpSkew)
+		if !ok || step <= tf.LastStep {
+			return errx.E(errx.CodeUnauthenticated, "invalid two-factor code", errx.Op(op))
+		}
+
+		if err := t.twoFactorRepo.UpdateLastStep(ctx, tx, tf.UserID, step); err != nil {
+			return errx.E(errx.CodeInternal, "failed to record two-factor code", errx.Op(op), err)
+		}
+
+		return nil
+	}
+
+	used, err := t.twoFactorRepo.UseRecoveryCode(ctx, tx, tf.UserID, t.hashRecoveryCode(tf.UserID, code))
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to use recovery code", errx.Op(op), err)
+	}
+
+	if !used {
+		return errx.E(errx.CodeUnauthenticated, "invalid two-factor code", errx.Op(op))
+	}
+
+	return nil
+}
+
+func (t *twoFactorUsecase) newRecoveryCodes(userID uuid.UUID) ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		code, err := tokenutils.NewRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, t.hashRecoveryCode(userID, code))
+	}
+
+	return codes, hashes, nil
+}
+
+func (t *twoFactorUsecase) hashRecoveryCode(userID uuid.UUID, code string) string {
+	return tokenutils.HashOTP(strings.ToLower(code), "recovery:"+userID.String(), verificationSecret(t.env))
+}
+
+func (t *twoFactorUsecase) issuer() string {
+	if t.env.TwoFactorIssuer != "" {
+		return t.env.TwoFactorIssuer
+	}
+
+	return "Warehouse"
+}
+
+func NewTwoFactorUsecase(
+	db pqsql.Database,
+	userRepo domain.UserRepository,
+	twoFactorRepo domain.TwoFactorRepository,
+	crypto crypto.Crypto,
+	env *bootstrap.Env,
+) domain.TwoFactorUsecase {
+	return &twoFactorUsecase{
+		db:            db,
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		crypto:        crypto,
+		env:           env,
+	}
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/tokenutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTwoFactorUsecaseForTest(t *testing.T) (domain.TwoFactorUsecase, *mocks.MockUserRepository, *mocks.MockTwoFactorRepository, *bootstrap.Env) {
+	userRepo := mocks.NewMockUserRepository(t)
+	twoFactorRepo := mocks.NewMockTwoFactorRepository(t)
+	env := &bootstrap.Env{JwtSecret: "secret", JwtExpiry: 1, VerificationSecret: "verify-secret"}
+	uc := NewTwoFactorUsecase(&fakeDB{}, userRepo, twoFactorRepo, simpleCryptoStub{}, env)
+	return uc, userRepo, twoFactorRepo, env
+}
+
+func enabledTwoFactor(t *testing.T, userID uuid.UUID, lastStep int64) (*domain.TwoFactor, string) {
+	secret, err := tokenutils.NewTOTPSecret()
+	assert.NoError(t, err)
+
+	now := time.Now()
+	return &domain.TwoFactor{
+		UserID:    userID,
+		Secret:    simpleCryptoStub{}.Encrypt(secret),
+		EnabledAt: &now,
+		LastStep:  lastStep,
+	}, secret
+}
+
+func TestTwoFactorUsecase_Enroll_Success(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo, twoFactorRepo, _ := newTwoFactorUsecaseForTest(t)
+	userID := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, userID, mock.Anything).Return(&domain.User{ID: userID}, nil)
+	twoFactorRepo.EXPECT().SaveSecret(ctx, mock.Anything, userID, mock.Anything, 1).Return(nil)
+
+	enrollment, err := uc.Enroll(ctx, userID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/")
+	assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+}
+
+func TestTwoFactorUsecase_Activate_Success(t *testing.T) {
+	ctx := context.Background()
+	uc, _, twoFactorRepo, _ := newTwoFactorUsecaseForTest(t)
+	userID := uuid.New()
+	tf, secret := enabledTwoFactor(t, userID, 0)
+	tf.EnabledAt = nil
+
+	step := tokenutils.TOTPStep(time.Now())
+	code, err := tokenutils.TOTPCode(secret, step)
+	assert.NoError(t, err)
+
+	twoFactorRepo.EXPECT().Get(ctx, mock.Anything, userID, mock.Anything).Return(tf, nil)
+	twoFactorRepo.EXPECT().Enable(ctx, mock.Anything, userID, mock.AnythingOfType("int64")).Return(nil)
+	twoFactorRepo.EXPECT().ReplaceRecoveryCodes(ctx, mock.Anything, userID, mock.MatchedBy(func(hashes []string) bool {
+		return len(hashes) == recoveryCodeCount
+	})).Return(nil)
+
+	codes, err := uc.Activate(ctx, userID, code)
+	assert.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+}
+
+func TestTwoFactorUsecase_Activate_InvalidCode(t *testing.T) {
+	ctx := context.Background()
+	uc, _, twoFactorRepo, _ := newTwoFactorUsecaseForTest(t)
+	userID := uuid.New()
+	tf, secret := enabledTwoFactor(t, userID, 0)
+	tf.EnabledAt = nil
+
+	code, err := tokenutils.TOTPCode(secret, tokenutils.TOTPStep(time.Now())+10)
+	assert.NoError(t, err)
+
+	twoFactorRepo.EXPECT().Get(ctx, mock.Anything, userID, mock.Anything).Return(tf, nil)
+
+	_, err = uc.Activate(ctx, userID, code)
+	assert.True(t, errx.IsCode(err, errx.CodeValidation))
+}
+
+func TestTwoFactorUsecase_CompleteLogin_TOTP(t *testing.T) {
+	ctx := context.Background()
+	uc, _, twoFactorRepo, env := newTwoFactorUsecaseForTest(t)
+	userID := uuid.New()
+	tf, secret := enabledTwoFactor(t, userID, 0)
+
+	step := tokenutils.TOTPStep(time.Now())
+	code, err := tokenutils.TOTPCode(secret, step)
+	assert.NoError(t, err)
+
+	challenge, err := tokenutils.CreateChallengeToken(userID, env.JwtSecret, time.Minute)
+	assert.NoError(t, err)
+
+	twoFactorRepo.EXPECT().Get(ctx, mock.Anything, userID, mock.Anything).Return(tf, nil)
+	twoFactorRepo.EXPECT().UpdateLastStep(ctx, mock.Anything, userID, step).Return(nil)
+
+	token, err := uc.CompleteLogin(ctx, domain.TwoFactorLoginRequest{ChallengeToken: challenge, Code: code})
+	assert.NoError(t, err)
+
+	id, err := tokenutils.ExtractIDFromToken(token, env.JwtSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, userID.String(), id)
+}
+
+func TestTwoFactorUsecase_CompleteLogin_ReplayedCode(t *testing.T) {
+	ctx := context.Background()
+	uc, _, twoFactorRepo, env := newTwoFactorUsecaseForTest(t)
+	userID := uuid.New()
+	step := tokenutils.TOTPStep(time.Now())
+	tf, secret := enabledTwoFactor(t, userID, step)
+
+	code, err := tokenutils.TOTPCode(secret, step)
+	assert.NoError(t, err)
+
+	challenge, err := tokenutils.CreateChallengeToken(userID, env.JwtSecret, time.Minute)
+	assert.NoError(t, err)
+
+	twoFactorRepo.EXPECT().Get(ctx, mock.Anything, userID, mock.Anything).Return(tf, nil)
+
+	_, err = uc.CompleteLogin(ctx, domain.TwoFactorLoginRequest{ChallengeToken: challenge, Code: code})
+	assert.True(t, errx.IsCode(err, errx.CodeUnauthenticated))
+}
+
+func TestTwoFactorUsecase_CompleteLogin_RecoveryCode(t *testing.T) {
+	ctx := context.Background()
+	uc, _, twoFactorRepo, env := newTwoFactorUsecaseForTest(t)
+	userID := uuid.New()
+	tf, _ := enabledTwoFactor(t, userID, 0)
+
+	challenge, err := tokenutils.CreateChallengeToken(userID, env.JwtSecret, time.Minute)
+	assert.NoError(t, err)
+
+	hash := tokenutils.HashOTP("abcde-fghij", "recovery:"+userID.String(), env.VerificationSecret)
+
+	twoFactorRepo.EXPECT().Get(ctx, mock.Anything, userID, mock.Anything).Return(tf, nil)
+	twoFactorRepo.EXPECT().UseRecoveryCode(ctx, mock.Anything, userID, hash).Return(true, nil)
+
+	token, err := uc.CompleteLogin(ctx, domain.TwoFactorLoginRequest{ChallengeToken: challenge, Code: "ABCDE-FGHIJ"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+}
+
+func TestTwoFactorUsecase_CompleteLogin_RejectsAccessToken(t *testing.T) {
+	ctx := context.Background()
+	uc, _, _, env := newTwoFactorUsecaseForTest(t)
+
+	accessToken, err := tokenutils.CreateAccessToken(&domain.User{ID: uuid.New()}, env.JwtSecret, env.JwtExpiry)
+	assert.NoError(t, err)
+
+	_, err = uc.CompleteLogin(ctx, domain.TwoFactorLoginRequest{ChallengeToken: accessToken, Code: "123456"})
+	assert.True(t, errx.IsCode(err, errx.CodeUnauthenticated))
+}
+
+func TestAuthUsecase_Login_TwoFactorChallenge(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	env := &bootstrap.Env{JwtSecret: "secret", JwtExpiry: 1}
+	uc := NewAuthUsecase(userRepo, simpleCryptoStub{}, env)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	userID := uuid.New()
+	now := time.Now()
+	userRepo.EXPECT().GetMailOrPhone(ctx, mock.Anything, mock.Anything, mock.Anything).
+		Return(&domain.User{ID: userID, PasswordHash: string(hash), TOTPEnabledAt: &now}, nil)
+
+	result, err := uc.Login(ctx, domain.AuthLoginRequest{Identifier: "user@example.com", Password: "password123"})
+	assert.NoError(t, err)
+	assert.True(t, result.TwoFactorRequired)
+	assert.Empty(t, result.AccessToken)
+
+	// a challenge token must not be usable as an access token
+	_, err = tokenutils.ExtractIDFromToken(result.ChallengeToken, env.JwtSecret)
+	assert.Error(t, err)
+
+	id, err := tokenutils.ExtractIDFromChallengeToken(result.ChallengeToken, env.JwtSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, userID.String(), id)
+}
@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/crypto"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/helper"
+	"github.com/dyaksa/warehouse/pkg/passwordutils"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+const exportPageSize = 100
+
+type userUsecase struct {
+	db            pqsql.Database
+	userRepo      domain.UserRepository
+	orderRepo     domain.OrderRepository
+	orderItemRepo domain.OrderItemRepository
+	jobUsecase    domain.JobUsecase
+	crypto        crypto.Crypto
+}
+
+// Me implements domain.UserUsecase.
+func (u *userUsecase) Me(ctx context.Context, id uuid.UUID) (domain.UserProfile, error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.Me", tracing.UserID(id))
+	defer span.End()
+
+	var profile domain.UserProfile
+
+	user, err := u.userRepo.GetByID(ctx, id, u.decrypt)
+	if err != nil {
+		return profile, errx.E(errx.CodeNotFound, "user not found", errx.Op("userUsecase.Me"), err)
+	}
+
+	profile.ID = user.ID
+	profile.Email = user.Email.To()
+	profile.Phone = user.Phone.To()
+	profile.EmailVerifiedAt = user.EmailVerifiedAt
+	profile.PhoneVerifiedAt = user.PhoneVerifiedAt
+	profile.TwoFactorEnabled = user.TOTPEnabledAt != nil
+
+	return profile, nil
+}
+
+// ChangeEmail implements domain.UserUsecase.
+func (u *userUsecase) ChangeEmail(ctx context.Context, id uuid.UUID, payload domain.ChangeEmailRequest) error {
+	ctx, span := tracing.Start(ctx, "UserUsecase.ChangeEmail", tracing.UserID(id))
+	defer span.End()
+
+	kind, norm, ok := helper.NormalizeIdentifier(payload.Email)
+	if !ok || kind != "email" {
+		return errx.E(errx.CodeValidation, "invalid email address", errx.Op("userUsecase.ChangeEmail"))
+	}
+
+	user, err := u.authorize(ctx, id, payload.Password, "userUsecase.ChangeEmail")
+	if err != nil {
+		return err
+	}
+
+	// Fails early with a clear error; the unique blind index still decides a concurrent change.
+	if err := u.ensureAvailable(ctx, id, norm, "userUsecase.ChangeEmail"); err != nil {
+		return err
+	}
+
+	// The blind index is computed from the normalized identifier, the same value Login looks up.
+	email := u.crypto.Encrypt(norm)
+	_, err = u.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		if err := u.userRepo.UpdateEmail(ctx, tx, id, email, u.crypto.HashString(norm), u.crypto.KeyVersion()); err != nil {
+			return nil, err
+		}
+
+		return nil, u.rebindHeap(ctx, &domain.User{Email: user.Email}, &domain.User{Email: email})
+	})
+	if errors.Is(err, domain.ErrIdentifierTaken) {
+		return errx.E(errx.CodeConflict, "identifier already in use", errx.Op("userUsecase.ChangeEmail"), err)
+	}
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to update email", errx.Op("userUsecase.ChangeEmail"), err)
+	}
+
+	return nil
+}
+
+// ChangePhone implements domain.UserUsecase.
+func (u *userUsecase) ChangePhone(ctx context.Context, id uuid.UUID, payload domain.ChangePhoneRequest) error {
+	ctx, span := tracing.Start(ctx, "UserUsecase.ChangePhone", tracing.UserID(id))
+	defer span.End()
+
+	kind, norm, ok := helper.NormalizeIdentifier(payload.Phone)
+	if !ok || kind != "phone" {
+		return errx.E(errx.CodeValidation, "invalid phone number", errx.Op("userUsecase.ChangePhone"))
+	}
+
+	user, err := u.authorize(ctx, id, payload.Password, "userUsecase.ChangePhone")
+	if err != nil {
+		return err
+	}
+
+	if err := u.ensureAvailable(ctx, id, norm, "userUsecase.ChangePhone"); err != nil {
+		return err
+	}
+
+	// Phone numbers are stored without the leading "+", as in Register.
+	phone := u.crypto.Encrypt(strings.TrimPrefix(norm, "+"))
+	_, err = u.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		if err := u.userRepo.UpdatePhone(ctx, tx, id, phone, u.crypto.HashString(norm), u.crypto.KeyVersion()); err != nil {
+			return nil, err
+		}
+
+		return nil, u.rebindHeap(ctx, &domain.User{Phone: user.Phone}, &domain.User{Phone: phone})
+	})
+	if errors.Is(err, domain.ErrIdentifierTaken) {
+		return errx.E(errx.CodeConflict, "identifier already in use", errx.Op("userUsecase.ChangePhone"), err)
+	}
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to update phone", errx.Op("userUsecase.ChangePhone"), err)
+	}
+
+	return nil
+}
+
+// rebindHeap indexes the changed identifier in the crypto heap and drops the old one, unless the
+// user changed it to the same value.
+func (u *userUsecase) rebindHeap(ctx context.Context, old, updated *domain.User) error {
+	if err := u.crypto.BindHeap(updated); err != nil {
+		return err
+	}
+
+	if old.Email.To() == updated.Email.To() && old.Phone.To() == updated.Phone.To() {
+		return nil
+	}
+
+	return u.crypto.UnbindHeap(ctx, old)
+}
+
+// ChangePassword implements domain.UserUsecase.
+func (u *userUsecase) ChangePassword(ctx context.Context, id uuid.UUID, payload domain.ChangePasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "UserUsecase.ChangePassword", tracing.UserID(id))
+	defer span.End()
+
+	if _, err := u.authorize(ctx, id, payload.CurrentPassword, "userUsecase.ChangePassword"); err != nil {
+		return err
+	}
+
+	passwordHash, err := passwordutils.HashPassword(payload.NewPassword)
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to hash password", errx.Op("userUsecase.ChangePassword"), err)
+	}
+
+	_, err = u.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		return nil, u.userRepo.UpdatePassword(ctx, tx, id, passwordHash)
+	})
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to update password", errx.Op("userUsecase.ChangePassword"), err)
+	}
+
+	return nil
+}
+
+// DeleteAccount implements domain.UserUsecase.
+// PII is shredded rather than the row deleted, so orders and stock movements stay intact for accounting.
+// The user's API keys and webhooks are cleaned up by a job queued with the shred.
+func (u *userUsecase) DeleteAccount(ctx context.Context, id uuid.UUID, payload domain.DeleteAccountRequest) error {
+	ctx, span := tracing.Start(ctx, "UserUsecase.DeleteAccount", tracing.UserID(id))
+	defer span.End()
+
+	user, err := u.authorize(ctx, id, payload.Password, "userUsecase.DeleteAccount")
+	if err != nil {
+		return err
+	}
+
+	_, err = u.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		// The heap lives in its own database and needs the plaintext, which Shred destroys, so it goes
+		// first; deleting it is idempotent if the transaction is retried.
+		if err := u.crypto.UnbindHeap(ctx, user); err != nil {
+			return nil, err
+		}
+
+		if err := u.userRepo.Shred(ctx, tx, id); err != nil {
+			return nil, err
+		}
+
+		return u.jobUsecase.Enqueue(ctx, tx, domain.UserErasedJob{UserID: id}, domain.EnqueueJobOptions{})
+	})
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to delete account", errx.Op("userUsecase.DeleteAccount"), err)
+	}
+
+	return nil
+}
+
+// Export implements domain.UserUsecase.
+func (u *userUsecase) Export(ctx context.Context, id uuid.UUID) (domain.UserDataExport, error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.Export", tracing.UserID(id))
+	defer span.End()
+
+	export := domain.UserDataExport{Orders: []domain.UserDataExportOrder{}}
+
+	profile, err := u.Me(ctx, id)
+	if err != nil {
+		return export, err
+	}
+	export.Profile = profile
+
+	for offset := 0; ; offset += exportPageSize {
+		orders, total, err := u.orderRepo.GetByUserID(ctx, id, exportPageSize, offset)
+		if err != nil {
+			return export, errx.E(errx.CodeInternal, "failed to load orders", errx.Op("userUsecase.Export"), err)
+		}
+
+		for _, order := range orders {
+			items, err := u.exportOrderItems(ctx, order.ID)
+			if err != nil {
+				return export, errx.E(errx.CodeInternal, "failed to load order items", errx.Op("userUsecase.Export"), err)
+			}
+			export.Orders = append(export.Orders, domain.UserDataExportOrder{OrderListItem: order, Items: items})
+		}
+
+		if len(orders) == 0 || len(export.Orders) >= total {
+			break
+		}
+	}
+
+	export.ExportedAt = time.Now().UTC()
+
+	return export, nil
+}
+
+func (u *userUsecase) exportOrderItems(ctx context.Context, orderID uuid.UUID) ([]domain.UserDataExportOrderItem, error) {
+	res, err := u.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		return u.orderItemRepo.GetByOrderID(ctx, tx, orderID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orderItems, _ := res.([]domain.OrderItem)
+	items := make([]domain.UserDataExportOrderItem, 0, len(orderItems))
+	for _, item := range orderItems {
+		items = append(items, domain.UserDataExportOrderItem{ProductID: item.ProductID, Qty: item.Qty, Price: item.Price})
+	}
+
+	return items, nil
+}
+
+func (u *userUsecase) authorize(ctx context.Context, id uuid.UUID, password string, op string) (*domain.User, error) {
+	user, err := u.userRepo.GetByID(ctx, id, u.decrypt)
+	if err != nil {
+		return nil, errx.E(errx.CodeNotFound, "user not found", errx.Op(op), err)
+	}
+
+	if ok := passwordutils.VerifyPassword(password, user.PasswordHash); !ok {
+		return nil, errx.E(errx.CodeUnauthorized, "invalid credentials", errx.Op(op), errors.New("password mismatch"))
+	}
+
+	return user, nil
+}
+
+func (u *userUsecase) ensureAvailable(ctx context.Context, id uuid.UUID, normalized string, op string) error {
+	existing, err := findUserByIdentifier(ctx, u.userRepo, u.crypto, normalized)
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		return nil
+	case err != nil:
+		return errx.E(errx.CodeInternal, "failed to check identifier", errx.Op(op), err)
+	case existing.ID != id:
+		return errx.E(errx.CodeConflict, "identifier already in use", errx.Op(op))
+	}
+
+	return nil
+}
+
+func (u *userUsecase) decrypt(data *domain.User) error {
+	return decryptUser(u.crypto)(data)
+}
+
+func NewUserUsecase(
+	db pqsql.Database,
+	userRepo domain.UserRepository,
+	orderRepo domain.OrderRepository,
+	orderItemRepo domain.OrderItemRepository,
+	jobUsecase domain.JobUsecase,
+	crypto crypto.Crypto,
+) domain.UserUsecase {
+	return &userUsecase{
+		db:            db,
+		userRepo:      userRepo,
+		orderRepo:     orderRepo,
+		orderItemRepo: orderItemRepo,
+		jobUsecase:    jobUsecase,
+		crypto:        crypto,
+	}
+}
+
+// NewUserErasedJobHandler handles domain.UserErasedJob. Revoking and deleting are both idempotent,
+// so a job that runs twice does no harm.
+func NewUserErasedJobHandler(db pqsql.Database, apiKeyRepo domain.APIKeyRepository, webhookRepo domain.WebhookRepository) domain.JobHandler {
+	return domain.HandleJob(func(ctx context.Context, job domain.UserErasedJob) error {
+		_, err := db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+			if err := apiKeyRepo.RevokeByUser(ctx, tx, job.UserID); err != nil {
+				return nil, err
+			}
+
+			return nil, webhookRepo.DeleteByUser(ctx, tx, job.UserID)
+		})
+
+		return err
+	})
+}
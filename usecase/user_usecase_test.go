@@ -0,0 +1,332 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/domain"
+	cryptomocks "github.com/dyaksa/warehouse/mocks/crypto"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func userWithPassword(t *testing.T, id uuid.UUID, password string) *domain.User {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	c := simpleCryptoStub{}
+	return &domain.User{
+		ID:           id,
+		Email:        c.Encrypt("user@example.com"),
+		Phone:        c.Encrypt("6281234567890"),
+		PasswordHash: string(hash),
+	}
+}
+
+// heapRecordingCrypto records the users bound to and unbound from the crypto heap.
+type heapRecordingCrypto struct {
+	simpleCryptoStub
+	bound, unbound []*domain.User
+}
+
+func (c *heapRecordingCrypto) BindHeap(entity any) error {
+	c.bound = append(c.bound, entity.(*domain.User))
+	return nil
+}
+
+func (c *heapRecordingCrypto) UnbindHeap(ctx context.Context, entity any) error {
+	c.unbound = append(c.unbound, entity.(*domain.User))
+	return nil
+}
+
+func TestUserUsecase_Me_Success(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+	now := time.Now()
+
+	user := userWithPassword(t, id, "password123")
+	user.TOTPEnabledAt = &now
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(user, nil)
+
+	profile, err := uc.Me(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, id, profile.ID)
+	assert.Equal(t, "user@example.com", profile.Email)
+	assert.Equal(t, "6281234567890", profile.Phone)
+	assert.True(t, profile.TwoFactorEnabled)
+}
+
+func TestUserUsecase_Me_NotFound(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(nil, domain.ErrUserNotFound)
+
+	_, err := uc.Me(ctx, id)
+	assert.True(t, errx.IsCode(err, errx.CodeNotFound))
+}
+
+func TestUserUsecase_ChangeEmail_Success(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	userRepo.EXPECT().GetMailOrPhone(ctx, "hash(new@example.com)", "hash(new@example.com)", mock.Anything).Return(nil, domain.ErrUserNotFound)
+	userRepo.EXPECT().UpdateEmail(ctx, mock.Anything, id, mock.Anything, "hash(new@example.com)", 1).Return(nil)
+
+	err := uc.ChangeEmail(ctx, id, domain.ChangeEmailRequest{Email: "new@EXAMPLE.com", Password: "password123"})
+	assert.NoError(t, err)
+}
+
+func TestUserUsecase_ChangeEmail_RebindsHeap(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	crypto := &heapRecordingCrypto{}
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), crypto)
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	userRepo.EXPECT().GetMailOrPhone(ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil, domain.ErrUserNotFound)
+	userRepo.EXPECT().UpdateEmail(ctx, mock.Anything, id, mock.Anything, mock.Anything, 1).Return(nil)
+
+	err := uc.ChangeEmail(ctx, id, domain.ChangeEmailRequest{Email: "new@example.com", Password: "password123"})
+	assert.NoError(t, err)
+	if assert.Len(t, crypto.bound, 1) && assert.Len(t, crypto.unbound, 1) {
+		assert.Equal(t, "new@example.com", crypto.bound[0].Email.To())
+		assert.Equal(t, "user@example.com", crypto.unbound[0].Email.To())
+		assert.Empty(t, crypto.unbound[0].Phone.To())
+	}
+}
+
+func TestUserUsecase_ChangeEmail_SameValueKeepsHeap(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	crypto := &heapRecordingCrypto{}
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), crypto)
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	userRepo.EXPECT().GetMailOrPhone(ctx, mock.Anything, mock.Anything, mock.Anything).Return(&domain.User{ID: id}, nil)
+	userRepo.EXPECT().UpdateEmail(ctx, mock.Anything, id, mock.Anything, mock.Anything, 1).Return(nil)
+
+	err := uc.ChangeEmail(ctx, id, domain.ChangeEmailRequest{Email: "user@example.com", Password: "password123"})
+	assert.NoError(t, err)
+	assert.Len(t, crypto.bound, 1)
+	assert.Empty(t, crypto.unbound)
+}
+
+func TestUserUsecase_ChangeEmail_WrongPassword(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+
+	err := uc.ChangeEmail(ctx, id, domain.ChangeEmailRequest{Email: "new@example.com", Password: "wrongpassword"})
+	assert.True(t, errx.IsCode(err, errx.CodeUnauthorized))
+}
+
+func TestUserUsecase_ChangeEmail_InUse(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	userRepo.EXPECT().GetMailOrPhone(ctx, mock.Anything, mock.Anything, mock.Anything).Return(&domain.User{ID: uuid.New()}, nil)
+
+	err := uc.ChangeEmail(ctx, id, domain.ChangeEmailRequest{Email: "taken@example.com", Password: "password123"})
+	assert.True(t, errx.IsCode(err, errx.CodeConflict))
+}
+
+func TestUserUsecase_ChangeEmail_TakenConcurrently(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	userRepo.EXPECT().GetMailOrPhone(ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil, domain.ErrUserNotFound)
+	userRepo.EXPECT().UpdateEmail(ctx, mock.Anything, id, mock.Anything, mock.Anything, 1).Return(domain.ErrIdentifierTaken)
+
+	err := uc.ChangeEmail(ctx, id, domain.ChangeEmailRequest{Email: "taken@example.com", Password: "password123"})
+	assert.True(t, errx.IsCode(err, errx.CodeConflict))
+}
+
+func TestUserUsecase_ChangeEmail_LookupError(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	userRepo.EXPECT().GetMailOrPhone(ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	err := uc.ChangeEmail(ctx, id, domain.ChangeEmailRequest{Email: "new@example.com", Password: "password123"})
+	assert.True(t, errx.IsCode(err, errx.CodeInternal))
+}
+
+func TestUserUsecase_ChangePhone_Success(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	userRepo.EXPECT().GetMailOrPhone(ctx, "hash(+6281298765432)", "hash(+6281298765432)", mock.Anything).Return(nil, domain.ErrUserNotFound)
+	userRepo.EXPECT().UpdatePhone(ctx, mock.Anything, id, mock.Anything, "hash(+6281298765432)", 1).Return(nil)
+
+	err := uc.ChangePhone(ctx, id, domain.ChangePhoneRequest{Phone: "081298765432", Password: "password123"})
+	assert.NoError(t, err)
+}
+
+func TestUserUsecase_ChangePhone_RebindsHeap(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	crypto := &heapRecordingCrypto{}
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), crypto)
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	userRepo.EXPECT().GetMailOrPhone(ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil, domain.ErrUserNotFound)
+	userRepo.EXPECT().UpdatePhone(ctx, mock.Anything, id, mock.Anything, mock.Anything, 1).Return(nil)
+
+	err := uc.ChangePhone(ctx, id, domain.ChangePhoneRequest{Phone: "081298765432", Password: "password123"})
+	assert.NoError(t, err)
+	if assert.Len(t, crypto.bound, 1) && assert.Len(t, crypto.unbound, 1) {
+		assert.Equal(t, "6281298765432", crypto.bound[0].Phone.To())
+		assert.Equal(t, "6281234567890", crypto.unbound[0].Phone.To())
+	}
+}
+
+func TestUserUsecase_ChangePassword_Success(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	userRepo.EXPECT().UpdatePassword(ctx, mock.Anything, id, mock.Anything).Return(nil)
+
+	err := uc.ChangePassword(ctx, id, domain.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword123"})
+	assert.NoError(t, err)
+}
+
+func TestUserUsecase_DeleteAccount_Success(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	jobRepo := mocks.NewMockJobRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, jobRepo, nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	userRepo.EXPECT().Shred(ctx, mock.Anything, id).Return(nil)
+	jobRepo.EXPECT().Create(ctx, mock.Anything, mock.MatchedBy(func(job *domain.Job) bool {
+		return job.Type == domain.JobUserErased && string(job.Payload) == `{"user_id":"`+id.String()+`"}`
+	})).Return(nil)
+
+	err := uc.DeleteAccount(ctx, id, domain.DeleteAccountRequest{Password: "password123"})
+	assert.NoError(t, err)
+}
+
+func TestUserUsecase_DeleteAccount_UnbindsHeapBeforeShredding(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	jobRepo := mocks.NewMockJobRepository(t)
+	crypto := cryptomocks.NewMockCrypto(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, jobRepo, nil, log.Nop()), crypto)
+	id := uuid.New()
+	user := userWithPassword(t, id, "password123")
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(user, nil)
+	mock.InOrder(
+		crypto.EXPECT().UnbindHeap(ctx, user).Return(nil).Call,
+		userRepo.EXPECT().Shred(ctx, mock.Anything, id).Return(nil).Call,
+	)
+	jobRepo.EXPECT().Create(ctx, mock.Anything, mock.Anything).Return(nil)
+
+	err := uc.DeleteAccount(ctx, id, domain.DeleteAccountRequest{Password: "password123"})
+	assert.NoError(t, err)
+}
+
+func TestUserUsecase_DeleteAccount_HeapFailureKeepsAccount(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	crypto := cryptomocks.NewMockCrypto(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), crypto)
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	crypto.EXPECT().UnbindHeap(ctx, mock.Anything).Return(errors.New("heap down"))
+
+	err := uc.DeleteAccount(ctx, id, domain.DeleteAccountRequest{Password: "password123"})
+	assert.True(t, errx.IsCode(err, errx.CodeInternal))
+}
+
+func TestUserErasedJobHandler(t *testing.T) {
+	ctx := context.Background()
+	apiKeyRepo := mocks.NewMockAPIKeyRepository(t)
+	webhookRepo := mocks.NewMockWebhookRepository(t)
+	handle := NewUserErasedJobHandler(&fakeDB{}, apiKeyRepo, webhookRepo)
+	id := uuid.New()
+
+	apiKeyRepo.EXPECT().RevokeByUser(ctx, mock.Anything, id).Return(nil)
+	webhookRepo.EXPECT().DeleteByUser(ctx, mock.Anything, id).Return(nil)
+
+	err := handle(ctx, json.RawMessage(`{"user_id":"`+id.String()+`"}`))
+	assert.NoError(t, err)
+}
+
+func TestUserUsecase_DeleteAccount_WrongPassword(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, mocks.NewMockOrderRepository(t), mocks.NewMockOrderItemRepository(t), NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+
+	err := uc.DeleteAccount(ctx, id, domain.DeleteAccountRequest{Password: "wrongpassword"})
+	assert.True(t, errx.IsCode(err, errx.CodeUnauthorized))
+}
+
+func TestUserUsecase_Export_PagesThroughOrders(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(t)
+	orderRepo := mocks.NewMockOrderRepository(t)
+	orderItemRepo := mocks.NewMockOrderItemRepository(t)
+	uc := NewUserUsecase(&fakeDB{}, userRepo, orderRepo, orderItemRepo, NewJobUsecase(&fakeDB{}, mocks.NewMockJobRepository(t), nil, log.Nop()), simpleCryptoStub{})
+	id := uuid.New()
+
+	firstPage := make([]domain.OrderListItem, exportPageSize)
+	lastOrderID := uuid.New()
+	secondPage := []domain.OrderListItem{{ID: lastOrderID}}
+	productID := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, id, mock.Anything).Return(userWithPassword(t, id, "password123"), nil)
+	orderRepo.EXPECT().GetByUserID(ctx, id, exportPageSize, 0).Return(firstPage, exportPageSize+1, nil)
+	orderRepo.EXPECT().GetByUserID(ctx, id, exportPageSize, exportPageSize).Return(secondPage, exportPageSize+1, nil)
+	orderItemRepo.EXPECT().GetByOrderID(ctx, mock.Anything, uuid.Nil).Return(nil, nil).Times(exportPageSize)
+	orderItemRepo.EXPECT().GetByOrderID(ctx, mock.Anything, lastOrderID).Return([]domain.OrderItem{{OrderID: lastOrderID, ProductID: productID, Qty: 2, Price: 1500}}, nil)
+
+	export, err := uc.Export(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", export.Profile.Email)
+	assert.Len(t, export.Orders, exportPageSize+1)
+	assert.Equal(t, []domain.UserDataExportOrderItem{{ProductID: productID, Qty: 2, Price: 1500}}, export.Orders[exportPageSize].Items)
+	assert.False(t, export.ExportedAt.IsZero())
+}
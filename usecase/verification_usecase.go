@@ -0,0 +1,388 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/crypto"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/helper"
+	"github.com/dyaksa/warehouse/pkg/passwordutils"
+	"github.com/dyaksa/warehouse/pkg/tokenutils"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+const (
+	otpDigits      = 6
+	maxOTPAttempts = 5
+)
+
+type verificationUsecase struct {
+	db        pqsql.Database
+	userRepo  domain.UserRepository
+	tokenRepo domain.UserTokenRepository
+	notifier  domain.Notifier
+	crypto    crypto.Crypto
+	env       *bootstrap.Env
+}
+
+// RequestEmailVerification implements domain.VerificationUsecase.
+func (v *verificationUsecase) RequestEmailVerification(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "VerificationUsecase.RequestEmailVerification", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := v.userRepo.GetByID(ctx, userID, v.decrypt)
+	if err != nil {
+		return errx.E(errx.CodeNotFound, "user not found", errx.Op("verificationUsecase.RequestEmailVerification"), err)
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return errx.E(errx.CodeConflict, "email already verified", errx.Op("verificationUsecase.RequestEmailVerification"))
+	}
+
+	token, err := v.issueToken(ctx, user.ID, domain.PurposeEmailVerification, minutesOr(v.env.EmailVerificationTTL, 24*60))
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to issue verification token", errx.Op("verificationUsecase.RequestEmailVerification"), err)
+	}
+
+	return v.notify(ctx, domain.Notification{
+		Channel:   domain.ChannelEmail,
+		Recipient: user.Email.To(),
+		Subject:   "Verify your email address",
+		Body:      fmt.Sprintf("Use this token to verify your email address: %s", token),
+	}, "verificationUsecase.RequestEmailVerification")
+}
+
+// ConfirmEmail implements domain.VerificationUsecase.
+func (v *verificationUsecase) ConfirmEmail(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "VerificationUsecase.ConfirmEmail")
+	defer span.End()
+
+	hash, err := tokenutils.VerifyOneTimeToken(token, v.secret())
+	if err != nil {
+		return errx.E(errx.CodeValidation, "invalid or expired token", errx.Op("verificationUsecase.ConfirmEmail"), err)
+	}
+
+	_, err = v.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		t, err := v.consumeByHash(ctx, tx, domain.PurposeEmailVerification, hash, "verificationUsecase.ConfirmEmail")
+		if err != nil {
+			return nil, err
+		}
+
+		if err := v.userRepo.MarkEmailVerified(ctx, tx, t.UserID); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to mark email as verified", errx.Op("verificationUsecase.ConfirmEmail"), err)
+		}
+
+		return nil, nil
+	})
+
+	return err
+}
+
+// RequestPhoneVerification implements domain.VerificationUsecase.
+func (v *verificationUsecase) RequestPhoneVerification(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "VerificationUsecase.RequestPhoneVerification", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := v.userRepo.GetByID(ctx, userID, v.decrypt)
+	if err != nil {
+		return errx.E(errx.CodeNotFound, "user not found", errx.Op("verificationUsecase.RequestPhoneVerification"), err)
+	}
+
+	if user.PhoneVerifiedAt != nil {
+		return errx.E(errx.CodeConflict, "phone already verified", errx.Op("verificationUsecase.RequestPhoneVerification"))
+	}
+
+	code, err := tokenutils.NewOTP(otpDigits)
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to generate verification code", errx.Op("verificationUsecase.RequestPhoneVerification"), err)
+	}
+
+	tokenID := uuid.New()
+	ttl := minutesOr(v.env.PhoneOTPTTL, 10)
+
+	_, err = v.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		if err := v.tokenRepo.RevokeActive(ctx, tx, user.ID, domain.PurposePhoneVerification); err != nil {
+			return nil, err
+		}
+
+		return nil, v.tokenRepo.Create(ctx, tx, &domain.UserToken{
+			ID:        tokenID,
+			UserID:    user.ID,
+			Purpose:   domain.PurposePhoneVerification,
+			TokenHash: tokenutils.HashOTP(code, tokenID.String(), v.secret()),
+			ExpiresAt: time.Now().Add(ttl),
+		})
+	})
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to issue verification code", errx.Op("verificationUsecase.RequestPhoneVerification"), err)
+	}
+
+	return v.notify(ctx, domain.Notification{
+		Channel:   domain.ChannelSMS,
+		Recipient: "+" + user.Phone.To(),
+		Body:      fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(ttl.Minutes())),
+	}, "verificationUsecase.RequestPhoneVerification")
+}
+
+// ConfirmPhone implements domain.VerificationUsecase.
+func (v *verificationUsecase) ConfirmPhone(ctx context.Context, userID uuid.UUID, code string) error {
+	ctx, span := tracing.Start(ctx, "VerificationUsecase.ConfirmPhone", tracing.UserID(userID))
+	defer span.End()
+
+	// A wrong code must still commit the attempt counter, so the mismatch is reported after the transaction.
+	matched, err := v.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		t, err := v.tokenRepo.FindActiveByUser(ctx, tx, userID, domain.PurposePhoneVerification)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errx.E(errx.CodeValidation, "no active verification code", errx.Op("verificationUsecase.ConfirmPhone"), err)
+			}
+			return nil, errx.E(errx.CodeInternal, "failed to load verification code", errx.Op("verificationUsecase.ConfirmPhone"), err)
+		}
+
+		expected := tokenutils.HashOTP(code, t.ID.String(), v.secret())
+		if !hmac.Equal([]byte(expected), []byte(t.TokenHash)) {
+			attempts, err := v.tokenRepo.IncrementAttempts(ctx, tx, t.ID)
+			if err != nil {
+				return nil, errx.E(errx.CodeInternal, "failed to record verification attempt", errx.Op("verificationUsecase.ConfirmPhone"), err)
+			}
+
+			if attempts >= maxOTPAttempts {
+				if err := v.tokenRepo.MarkUsed(ctx, tx, t.ID); err != nil {
+					return nil, errx.E(errx.CodeInternal, "failed to revoke verification code", errx.Op("verificationUsecase.ConfirmPhone"), err)
+				}
+			}
+
+			return false, nil
+		}
+
+		if err := v.tokenRepo.MarkUsed(ctx, tx, t.ID); err != nil {
+			return nil, errx.E(errx.CodeValidation, "invalid or expired verification code", errx.Op("verificationUsecase.ConfirmPhone"), err)
+		}
+
+		if err := v.userRepo.MarkPhoneVerified(ctx, tx, userID); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to mark phone as verified", errx.Op("verificationUsecase.ConfirmPhone"), err)
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if ok, _ := matched.(bool); !ok {
+		return errx.E(errx.CodeValidation, "invalid verification code", errx.Op("verificationUsecase.ConfirmPhone"))
+	}
+
+	return nil
+}
+
+// RequestPasswordReset implements domain.VerificationUsecase.
+// Unknown identifiers are accepted silently so the endpoint cannot be used to enumerate accounts.
+func (v *verificationUsecase) RequestPasswordReset(ctx context.Context, identifier string) error {
+	ctx, span := tracing.Start(ctx, "VerificationUsecase.RequestPasswordReset")
+	defer span.End()
+
+	kind, norm, ok := helper.NormalizeIdentifier(identifier)
+	if !ok {
+		return errx.E(errx.CodeValidation, "invalid identifier", errx.Op("verificationUsecase.RequestPasswordReset"))
+	}
+
+	user, err := findUserByIdentifier(ctx, v.userRepo, v.crypto, norm)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to look up user", errx.Op("verificationUsecase.RequestPasswordReset"), err)
+	}
+
+	token, err := v.issueToken(ctx, user.ID, domain.PurposePasswordReset, minutesOr(v.env.PasswordResetTTL, 30))
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to issue reset token", errx.Op("verificationUsecase.RequestPasswordReset"), err)
+	}
+
+	n := domain.Notification{
+		Channel:   domain.ChannelEmail,
+		Recipient: user.Email.To(),
+		Subject:   "Reset your password",
+		Body:      fmt.Sprintf("Use this token to reset your password: %s", token),
+	}
+	if kind == "phone" {
+		n.Channel = domain.ChannelSMS
+		n.Recipient = "+" + user.Phone.To()
+		n.Subject = ""
+	}
+
+	return v.notify(ctx, n, "verificationUsecase.RequestPasswordReset")
+}
+
+// ResetPassword implements domain.VerificationUsecase.
+func (v *verificationUsecase) ResetPassword(ctx context.Context, payload domain.ResetPasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "VerificationUsecase.ResetPassword")
+	defer span.End()
+
+	hash, err := tokenutils.VerifyOneTimeToken(payload.Token, v.secret())
+	if err != nil {
+		return errx.E(errx.CodeValidation, "invalid or expired token", errx.Op("verificationUsecase.ResetPassword"), err)
+	}
+
+	passwordHash, err := passwordutils.HashPassword(payload.NewPassword)
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to hash password", errx.Op("verificationUsecase.ResetPassword"), err)
+	}
+
+	_, err = v.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		t, err := v.consumeByHash(ctx, tx, domain.PurposePasswordReset, hash, "verificationUsecase.ResetPassword")
+		if err != nil {
+			return nil, err
+		}
+
+		if err := v.userRepo.UpdatePassword(ctx, tx, t.UserID, passwordHash); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to update password", errx.Op("verificationUsecase.ResetPassword"), err)
+		}
+
+		if err := v.tokenRepo.RevokeActive(ctx, tx, t.UserID, domain.PurposePasswordReset); err != nil {
+			return nil, errx.E(errx.CodeInternal, "failed to revoke reset tokens", errx.Op("verificationUsecase.ResetPassword"), err)
+		}
+
+		return nil, nil
+	})
+
+	return err
+}
+
+// EnsureVerified implements domain.VerificationUsecase.
+func (v *verificationUsecase) EnsureVerified(ctx context.Context, userID uuid.UUID, policy domain.VerificationPolicy) error {
+	ctx, span := tracing.Start(ctx, "VerificationUsecase.EnsureVerified", tracing.UserID(userID))
+	defer span.End()
+
+	if policy == "" || policy == domain.VerificationNone {
+		return nil
+	}
+
+	user, err := v.userRepo.GetByID(ctx, userID, v.decrypt)
+	if err != nil {
+		return errx.E(errx.CodeNotFound, "user not found", errx.Op("verificationUsecase.EnsureVerified"), err)
+	}
+
+	emailOK := user.EmailVerifiedAt != nil
+	phoneOK := user.PhoneVerifiedAt != nil
+
+	switch policy {
+	case domain.VerificationEmail:
+		if !emailOK {
+			return errx.E(errx.CodePermission, "email address must be verified", errx.Op("verificationUsecase.EnsureVerified"))
+		}
+	case domain.VerificationPhone:
+		if !phoneOK {
+			return errx.E(errx.CodePermission, "phone number must be verified", errx.Op("verificationUsecase.EnsureVerified"))
+		}
+	case domain.VerificationBoth:
+		if !emailOK || !phoneOK {
+			return errx.E(errx.CodePermission, "email address and phone number must be verified", errx.Op("verificationUsecase.EnsureVerified"))
+		}
+	}
+
+	return nil
+}
+
+func (v *verificationUsecase) issueToken(ctx context.Context, userID uuid.UUID, purpose domain.TokenPurpose, ttl time.Duration) (string, error) {
+	token, hash, err := tokenutils.NewOneTimeToken(v.secret())
+	if err != nil {
+		return "", err
+	}
+
+	_, err = v.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		if err := v.tokenRepo.RevokeActive(ctx, tx, userID, purpose); err != nil {
+			return nil, err
+		}
+
+		return nil, v.tokenRepo.Create(ctx, tx, &domain.UserToken{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: hash,
+			ExpiresAt: time.Now().Add(ttl),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (v *verificationUsecase) consumeByHash(ctx context.Context, tx *sql.Tx, purpose domain.TokenPurpose, hash string, op string) (*domain.UserToken, error) {
+	t, err := v.tokenRepo.FindActiveByHash(ctx, tx, purpose, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errx.E(errx.CodeValidation, "invalid or expired token", errx.Op(op), err)
+		}
+		return nil, errx.E(errx.CodeInternal, "failed to load token", errx.Op(op), err)
+	}
+
+	if err := v.tokenRepo.MarkUsed(ctx, tx, t.ID); err != nil {
+		return nil, errx.E(errx.CodeValidation, "invalid or expired token", errx.Op(op), err)
+	}
+
+	return t, nil
+}
+
+func (v *verificationUsecase) notify(ctx context.Context, n domain.Notification, op string) error {
+	if err := v.notifier.Notify(ctx, n); err != nil {
+		return errx.E(errx.CodeUnavailable, "failed to send notification", errx.Op(op), err)
+	}
+
+	return nil
+}
+
+func (v *verificationUsecase) decrypt(data *domain.User) error {
+	return decryptUser(v.crypto)(data)
+}
+
+func (v *verificationUsecase) secret() string {
+	return verificationSecret(v.env)
+}
+
+// verificationSecret is the HMAC key for one-time tokens and codes.
+func verificationSecret(env *bootstrap.Env) string {
+	if env.VerificationSecret != "" {
+		return env.VerificationSecret
+	}
+
+	return env.JwtSecret
+}
+
+func minutesOr(minutes int, def int) time.Duration {
+	if minutes <= 0 {
+		minutes = def
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+func NewVerificationUsecase(
+	db pqsql.Database,
+	userRepo domain.UserRepository,
+	tokenRepo domain.UserTokenRepository,
+	notifier domain.Notifier,
+	crypto crypto.Crypto,
+	env *bootstrap.Env,
+) domain.VerificationUsecase {
+	return &verificationUsecase{
+		db:        db,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		notifier:  notifier,
+		crypto:    crypto,
+		env:       env,
+	}
+}
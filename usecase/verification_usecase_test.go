@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/tokenutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newVerificationUsecaseForTest(t *testing.T) (domain.VerificationUsecase, *mocks.MockUserRepository, *mocks.MockUserTokenRepository, *mocks.MockNotifier, *bootstrap.Env) {
+	userRepo := mocks.NewMockUserRepository(t)
+	tokenRepo := mocks.NewMockUserTokenRepository(t)
+	notifier := mocks.NewMockNotifier(t)
+	env := &bootstrap.Env{JwtSecret: "secret", VerificationSecret: "verify-secret"}
+	uc := NewVerificationUsecase(&fakeDB{}, userRepo, tokenRepo, notifier, simpleCryptoStub{}, env)
+	return uc, userRepo, tokenRepo, notifier, env
+}
+
+func TestVerificationUsecase_RequestEmailVerification_Success(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo, tokenRepo, notifier, _ := newVerificationUsecaseForTest(t)
+	userID := uuid.New()
+
+	userRepo.EXPECT().GetByID(ctx, userID, mock.Anything).Return(&domain.User{ID: userID}, nil)
+	tokenRepo.EXPECT().RevokeActive(ctx, mock.Anything, userID, domain.PurposeEmailVerification).Return(nil)
+	tokenRepo.EXPECT().Create(ctx, mock.Anything, mock.Anything).RunAndReturn(
+		func(ctx context.Context, tx *sql.Tx, token *domain.UserToken) error {
+			assert.Equal(t, domain.PurposeEmailVerification, token.Purpose)
+			assert.WithinDuration(t, time.Now().Add(24*time.Hour), token.ExpiresAt, 5*time.Second)
+			return nil
+		},
+	)
+	notifier.EXPECT().Notify(ctx, mock.MatchedBy(func(n domain.Notification) bool {
+		return n.Channel == domain.ChannelEmail
+	})).Return(nil)
+
+	err := uc.RequestEmailVerification(ctx, userID)
+	assert.NoError(t, err)
+}
+
+func TestVerificationUsecase_RequestEmailVerification_AlreadyVerified(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo, _, _, _ := newVerificationUsecaseForTest(t)
+	userID := uuid.New()
+	now := time.Now()
+
+	userRepo.EXPECT().GetByID(ctx, userID, mock.Anything).Return(&domain.User{ID: userID, EmailVerifiedAt: &now}, nil)
+
+	err := uc.RequestEmailVerification(ctx, userID)
+	assert.True(t, errx.IsCode(err, errx.CodeConflict))
+}
+
+func TestVerificationUsecase_ConfirmEmail_ForgedToken(t *testing.T) {
+	ctx := context.Background()
+	uc, _, _, _, _ := newVerificationUsecaseForTest(t)
+
+	token, _, err := tokenutils.NewOneTimeToken("another-secret")
+	assert.NoError(t, err)
+
+	err = uc.ConfirmEmail(ctx, token)
+	assert.True(t, errx.IsCode(err, errx.CodeValidation))
+}
+
+func TestVerificationUsecase_ConfirmEmail_Success(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo, tokenRepo, _, env := newVerificationUsecaseForTest(t)
+	userID := uuid.New()
+	tokenID := uuid.New()
+
+	token, hash, err := tokenutils.NewOneTimeToken(env.VerificationSecret)
+	assert.NoError(t, err)
+
+	tokenRepo.EXPECT().FindActiveByHash(ctx, mock.Anything, domain.PurposeEmailVerification, hash).Return(&domain.UserToken{ID: tokenID, UserID: userID}, nil)
+	tokenRepo.EXPECT().MarkUsed(ctx, mock.Anything, tokenID).Return(nil)
+	userRepo.EXPECT().MarkEmailVerified(ctx, mock.Anything, userID).Return(nil)
+
+	err = uc.ConfirmEmail(ctx, token)
+	assert.NoError(t, err)
+}
+
+func TestVerificationUsecase_ConfirmEmail_UnknownToken(t *testing.T) {
+	ctx := context.Background()
+	uc, _, tokenRepo, _, env := newVerificationUsecaseForTest(t)
+
+	token, hash, err := tokenutils.NewOneTimeToken(env.VerificationSecret)
+	assert.NoError(t, err)
+
+	tokenRepo.EXPECT().FindActiveByHash(ctx, mock.Anything, domain.PurposeEmailVerification, hash).Return(nil, sql.ErrNoRows)
+
+	err = uc.ConfirmEmail(ctx, token)
+	assert.True(t, errx.IsCode(err, errx.CodeValidation))
+}
+
+func TestVerificationUsecase_ConfirmPhone_WrongCodeCountsAttempt(t *testing.T) {
+	ctx := context.Background()
+	uc, _, tokenRepo, _, env := newVerificationUsecaseForTest(t)
+	userID := uuid.New()
+	tokenID := uuid.New()
+	stored := tokenutils.HashOTP("123456", tokenID.String(), env.VerificationSecret)
+
+	tokenRepo.EXPECT().FindActiveByUser(ctx, mock.Anything, userID, domain.PurposePhoneVerification).Return(&domain.UserToken{ID: tokenID, UserID: userID, TokenHash: stored}, nil)
+	tokenRepo.EXPECT().IncrementAttempts(ctx, mock.Anything, tokenID).Return(maxOTPAttempts, nil)
+	tokenRepo.EXPECT().MarkUsed(ctx, mock.Anything, tokenID).Return(nil)
+
+	err := uc.ConfirmPhone(ctx, userID, "654321")
+	assert.True(t, errx.IsCode(err, errx.CodeValidation))
+}
+
+func TestVerificationUsecase_ConfirmPhone_Success(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo, tokenRepo, _, env := newVerificationUsecaseForTest(t)
+	userID := uuid.New()
+	tokenID := uuid.New()
+	stored := tokenutils.HashOTP("123456", tokenID.String(), env.VerificationSecret)
+
+	tokenRepo.EXPECT().FindActiveByUser(ctx, mock.Anything, userID, domain.PurposePhoneVerification).Return(&domain.UserToken{ID: tokenID, UserID: userID, TokenHash: stored}, nil)
+	tokenRepo.EXPECT().MarkUsed(ctx, mock.Anything, tokenID).Return(nil)
+	userRepo.EXPECT().MarkPhoneVerified(ctx, mock.Anything, userID).Return(nil)
+
+	err := uc.ConfirmPhone(ctx, userID, "123456")
+	assert.NoError(t, err)
+}
+
+func TestVerificationUsecase_RequestPasswordReset_UnknownUserIsSilent(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo, _, _, _ := newVerificationUsecaseForTest(t)
+
+	userRepo.EXPECT().GetMailOrPhone(ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil, domain.ErrUserNotFound)
+
+	err := uc.RequestPasswordReset(ctx, "nobody@example.com")
+	assert.NoError(t, err)
+}
+
+func TestVerificationUsecase_RequestPasswordReset_LookupError(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo, _, _, _ := newVerificationUsecaseForTest(t)
+
+	userRepo.EXPECT().GetMailOrPhone(ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	err := uc.RequestPasswordReset(ctx, "someone@example.com")
+	assert.True(t, errx.IsCode(err, errx.CodeInternal))
+}
+
+func TestVerificationUsecase_ResetPassword_Success(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo, tokenRepo, _, env := newVerificationUsecaseForTest(t)
+	userID := uuid.New()
+	tokenID := uuid.New()
+
+	token, hash, err := tokenutils.NewOneTimeToken(env.VerificationSecret)
+	assert.NoError(t, err)
+
+	tokenRepo.EXPECT().FindActiveByHash(ctx, mock.Anything, domain.PurposePasswordReset, hash).Return(&domain.UserToken{ID: tokenID, UserID: userID}, nil)
+	tokenRepo.EXPECT().MarkUsed(ctx, mock.Anything, tokenID).Return(nil)
+	userRepo.EXPECT().UpdatePassword(ctx, mock.Anything, userID, mock.Anything).Return(nil)
+	tokenRepo.EXPECT().RevokeActive(ctx, mock.Anything, userID, domain.PurposePasswordReset).Return(nil)
+
+	err = uc.ResetPassword(ctx, domain.ResetPasswordRequest{Token: token, NewPassword: "newpassword123"})
+	assert.NoError(t, err)
+}
+
+func TestVerificationUsecase_EnsureVerified(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo, _, _, _ := newVerificationUsecaseForTest(t)
+	userID := uuid.New()
+	now := time.Now()
+
+	assert.NoError(t, uc.EnsureVerified(ctx, userID, domain.VerificationNone))
+
+	userRepo.EXPECT().GetByID(ctx, userID, mock.Anything).Return(&domain.User{ID: userID, EmailVerifiedAt: &now}, nil)
+
+	assert.NoError(t, uc.EnsureVerified(ctx, userID, domain.VerificationEmail))
+	assert.True(t, errx.IsCode(uc.EnsureVerified(ctx, userID, domain.VerificationPhone), errx.CodePermission))
+	assert.True(t, errx.IsCode(uc.EnsureVerified(ctx, userID, domain.VerificationBoth), errx.CodePermission))
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/dyaksa/warehouse/domain"
 	"github.com/dyaksa/warehouse/infrastructure/pqsql"
 	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/metrics"
+	"github.com/dyaksa/warehouse/pkg/tracing"
 	"github.com/google/uuid"
 )
 
@@ -17,10 +20,14 @@ type warehouseTransferUsecase struct {
 	warehouseRepo    domain.WarehouseRepository
 	productStockRepo domain.ProductStockRepository
 	movementRepo     domain.MovementRepository
+	outboxRepo       domain.OutboxRepository
 }
 
 // CreateTransfer implements domain.WarehouseTransferUsecase.
 func (wtu *warehouseTransferUsecase) CreateTransfer(ctx context.Context, req domain.CreateTransferRequest) (*domain.WarehouseTransfer, error) {
+	ctx, span := tracing.Start(ctx, "WarehouseTransferUsecase.CreateTransfer")
+	defer span.End()
+
 	fromWarehouseID, err := uuid.Parse(req.FromWarehouseID)
 	if err != nil {
 		return nil, errx.E(errx.CodeValidation, "invalid from_warehouse_id", errx.Op("warehouseTransferUsecase.CreateTransfer"), err)
@@ -35,6 +42,8 @@ func (wtu *warehouseTransferUsecase) CreateTransfer(ctx context.Context, req dom
 		return nil, errx.E(errx.CodeValidation, "cannot transfer to the same warehouse", errx.Op("warehouseTransferUsecase.CreateTransfer"), nil)
 	}
 
+	span.SetAttributes(tracing.SourceWarehouseID(fromWarehouseID), tracing.DestinationWarehouseID(toWarehouseID))
+
 	// Validate warehouses exist and are active
 	fromWarehouse, err := wtu.warehouseRepo.Retrieve(ctx, fromWarehouseID)
 	if err != nil {
@@ -102,11 +111,17 @@ func (wtu *warehouseTransferUsecase) CreateTransfer(ctx context.Context, req dom
 		return nil, errx.E(errx.CodeInternal, "failed to create transfer", errx.Op("warehouseTransferUsecase.CreateTransfer"), err)
 	}
 
+	span.SetAttributes(tracing.TransferID(transfer.ID))
+	observeTransfer(domain.TransferStatusRequested)
+
 	return transfer, nil
 }
 
 // UpdateTransferStatus implements domain.WarehouseTransferUsecase.
 func (wtu *warehouseTransferUsecase) UpdateTransferStatus(ctx context.Context, transferID uuid.UUID, req domain.UpdateTransferStatusRequest) error {
+	ctx, span := tracing.Start(ctx, "WarehouseTransferUsecase.UpdateTransferStatus", tracing.TransferID(transferID))
+	defer span.End()
+
 	// Get current transfer
 	transfer, err := wtu.transferRepo.GetByID(ctx, transferID)
 	if err != nil {
@@ -129,28 +144,47 @@ func (wtu *warehouseTransferUsecase) UpdateTransferStatus(ctx context.Context, t
 
 	// For other status updates, just update the status
 	_, err = wtu.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
-		return nil, wtu.transferRepo.UpdateStatus(ctx, tx, transferID, req.Status)
+		if err := wtu.transferRepo.UpdateStatus(ctx, tx, transferID, req.Status); err != nil {
+			return nil, err
+		}
+
+		if req.Status == domain.TransferStatusCompleted {
+			return nil, wtu.outboxRepo.Append(ctx, tx, transferCompleted(transfer))
+		}
+
+		return nil, nil
 	})
 
 	if err != nil {
 		return errx.E(errx.CodeInternal, "failed to update transfer status", errx.Op("warehouseTransferUsecase.UpdateTransferStatus"), err)
 	}
 
+	observeTransfer(req.Status)
+
 	return nil
 }
 
 // GetTransfer implements domain.WarehouseTransferUsecase.
 func (wtu *warehouseTransferUsecase) GetTransfer(ctx context.Context, transferID uuid.UUID) (*domain.WarehouseTransfer, error) {
+	ctx, span := tracing.Start(ctx, "WarehouseTransferUsecase.GetTransfer", tracing.TransferID(transferID))
+	defer span.End()
+
 	return wtu.transferRepo.GetByID(ctx, transferID)
 }
 
 // GetTransfersByWarehouse implements domain.WarehouseTransferUsecase.
 func (wtu *warehouseTransferUsecase) GetTransfersByWarehouse(ctx context.Context, warehouseID uuid.UUID, limit, offset int) ([]domain.WarehouseTransfer, int, error) {
+	ctx, span := tracing.Start(ctx, "WarehouseTransferUsecase.GetTransfersByWarehouse", tracing.WarehouseID(warehouseID))
+	defer span.End()
+
 	return wtu.transferRepo.GetByWarehouse(ctx, warehouseID, limit, offset)
 }
 
 // ExecuteTransfer implements domain.WarehouseTransferUsecase.
 func (wtu *warehouseTransferUsecase) ExecuteTransfer(ctx context.Context, transferID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "WarehouseTransferUsecase.ExecuteTransfer", tracing.TransferID(transferID))
+	defer span.End()
+
 	// Get transfer details
 	transfer, err := wtu.transferRepo.GetByID(ctx, transferID)
 	if err != nil {
@@ -161,6 +195,12 @@ func (wtu *warehouseTransferUsecase) ExecuteTransfer(ctx context.Context, transf
 		return fmt.Errorf("cannot execute transfer with status %s", transfer.Status)
 	}
 
+	// The source shop is told its stock went down
+	source, err := wtu.warehouseRepo.Retrieve(ctx, transfer.FromWarehouseID)
+	if err != nil {
+		return fmt.Errorf("failed to get source warehouse: %w", err)
+	}
+
 	_, err = wtu.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
 		// Check stock availability and reserve stock from source warehouse
 		for _, item := range transfer.Items {
@@ -184,6 +224,17 @@ func (wtu *warehouseTransferUsecase) ExecuteTransfer(ctx context.Context, transf
 			if err != nil {
 				return nil, fmt.Errorf("failed to commit stock from source: %w", err)
 			}
+
+			err = wtu.outboxRepo.Append(ctx, tx, domain.StockDecreased{
+				ShopID:      source.ShopID,
+				ProductID:   item.ProductID,
+				WarehouseID: transfer.FromWarehouseID,
+				Qty:         int(item.Qty),
+				Reason:      "TRANSFER",
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to record stock event: %w", err)
+			}
 		}
 
 		// Update transfer status to IN_TRANSIT
@@ -213,12 +264,38 @@ func (wtu *warehouseTransferUsecase) ExecuteTransfer(ctx context.Context, transf
 			return nil, fmt.Errorf("failed to mark transfer as completed: %w", err)
 		}
 
+		if err = wtu.outboxRepo.Append(ctx, tx, transferCompleted(transfer)); err != nil {
+			return nil, fmt.Errorf("failed to record transfer event: %w", err)
+		}
+
 		return nil, nil
 	})
+	if err == nil {
+		observeTransfer(domain.TransferStatusInTransit)
+		observeTransfer(domain.TransferStatusCompleted)
+	}
 
 	return err
 }
 
+func transferCompleted(transfer *domain.WarehouseTransfer) domain.TransferCompleted {
+	event := domain.TransferCompleted{
+		TransferID:      transfer.ID,
+		FromWarehouseID: transfer.FromWarehouseID,
+		ToWarehouseID:   transfer.ToWarehouseID,
+		CompletedAt:     time.Now(),
+	}
+	for _, item := range transfer.Items {
+		event.Items = append(event.Items, domain.TransferEventItem{ProductID: item.ProductID, Qty: item.Qty})
+	}
+
+	return event
+}
+
+func observeTransfer(status domain.TransferStatus) {
+	metrics.Transfers.WithLabelValues(string(status)).Inc()
+}
+
 // isValidStatusTransition validates if a status transition is allowed
 func (wtu *warehouseTransferUsecase) isValidStatusTransition(from, to domain.TransferStatus) bool {
 	switch from {
@@ -241,6 +318,7 @@ func NewWarehouseTransferUsecase(
 	warehouseRepo domain.WarehouseRepository,
 	productStockRepo domain.ProductStockRepository,
 	movementRepo domain.MovementRepository,
+	outboxRepo domain.OutboxRepository,
 ) domain.WarehouseTransferUsecase {
 	return &warehouseTransferUsecase{
 		db:               db,
@@ -248,5 +326,6 @@ func NewWarehouseTransferUsecase(
 		warehouseRepo:    warehouseRepo,
 		productStockRepo: productStockRepo,
 		movementRepo:     movementRepo,
+		outboxRepo:       outboxRepo,
 	}
 }
@@ -11,6 +11,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type fakeDBTransfer struct{}
@@ -28,6 +33,10 @@ func (f *fakeDBTransfer) Transaction(ctx context.Context, fn func(ctx context.Co
 	return fn(ctx, nil)
 }
 
+func (f *fakeDBTransfer) Savepoint(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestWarehouseTransfer_CreateTransfer_Success(t *testing.T) {
 	ctx := context.Background()
 	db := &fakeDBTransfer{}
@@ -35,7 +44,8 @@ func TestWarehouseTransfer_CreateTransfer_Success(t *testing.T) {
 	warehouseRepo := mocks.NewMockWarehouseRepository(t)
 	productStockRepo := mocks.NewMockProductStockRepository(t)
 	movementRepo := mocks.NewMockMovementRepository(t)
-	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo, outboxRepo)
 
 	shopID := uuid.New()
 	fromW := &domain.WareHouse{ID: uuid.New(), ShopID: shopID, IsActive: true}
@@ -61,10 +71,46 @@ func TestWarehouseTransfer_CreateTransfer_Success(t *testing.T) {
 	assert.Len(t, transfer.Items, 1)
 }
 
+func TestWarehouseTransfer_CreateTransfer_Span(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	ctx := context.Background()
+	transferRepo := mocks.NewMockWarehouseTransferRepository(t)
+	warehouseRepo := mocks.NewMockWarehouseRepository(t)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewWarehouseTransferUsecase(&fakeDBTransfer{}, transferRepo, warehouseRepo, mocks.NewMockProductStockRepository(t), mocks.NewMockMovementRepository(t), outboxRepo)
+
+	shopID := uuid.New()
+	fromW := &domain.WareHouse{ID: uuid.New(), ShopID: shopID, IsActive: true}
+	toW := &domain.WareHouse{ID: uuid.New(), ShopID: shopID, IsActive: true}
+
+	warehouseRepo.EXPECT().Retrieve(mock.Anything, fromW.ID).Return(fromW, nil)
+	warehouseRepo.EXPECT().Retrieve(mock.Anything, toW.ID).Return(toW, nil)
+	transferRepo.EXPECT().Create(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	transferRepo.EXPECT().CreateItems(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	transfer, err := uc.CreateTransfer(ctx, domain.CreateTransferRequest{
+		FromWarehouseID: fromW.ID.String(),
+		ToWarehouseID:   toW.ID.String(),
+		Items:           []domain.CreateTransferItemRequest{{ProductID: uuid.NewString(), Qty: 1}},
+	})
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "WarehouseTransferUsecase.CreateTransfer", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), attribute.String("transfer.id", transfer.ID.String()))
+		assert.Contains(t, spans[0].Attributes(), attribute.String("warehouse.source_id", fromW.ID.String()))
+		assert.Contains(t, spans[0].Attributes(), attribute.String("warehouse.destination_id", toW.ID.String()))
+	}
+}
+
 func TestWarehouseTransfer_CreateTransfer_InvalidWarehouseID(t *testing.T) {
 	ctx := context.Background()
 	db := &fakeDBTransfer{}
-	uc := NewWarehouseTransferUsecase(db, nil, nil, nil, nil)
+	uc := NewWarehouseTransferUsecase(db, nil, nil, nil, nil, nil)
 
 	_, err := uc.CreateTransfer(ctx, domain.CreateTransferRequest{FromWarehouseID: "bad", ToWarehouseID: uuid.New().String(), Items: []domain.CreateTransferItemRequest{}})
 	assert.Error(t, err)
@@ -74,7 +120,7 @@ func TestWarehouseTransfer_CreateTransfer_SameWarehouse(t *testing.T) {
 	ctx := context.Background()
 	db := &fakeDBTransfer{}
 	id := uuid.New()
-	uc := NewWarehouseTransferUsecase(db, nil, nil, nil, nil)
+	uc := NewWarehouseTransferUsecase(db, nil, nil, nil, nil, nil)
 	_, err := uc.CreateTransfer(ctx, domain.CreateTransferRequest{FromWarehouseID: id.String(), ToWarehouseID: id.String(), Items: []domain.CreateTransferItemRequest{}})
 	assert.Error(t, err)
 }
@@ -86,7 +132,8 @@ func TestWarehouseTransfer_CreateTransfer_InactiveWarehouse(t *testing.T) {
 	warehouseRepo := mocks.NewMockWarehouseRepository(t)
 	productStockRepo := mocks.NewMockProductStockRepository(t)
 	movementRepo := mocks.NewMockMovementRepository(t)
-	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo, outboxRepo)
 
 	shopID := uuid.New()
 	fromW := &domain.WareHouse{ID: uuid.New(), ShopID: shopID, IsActive: false}
@@ -106,7 +153,8 @@ func TestWarehouseTransfer_CreateTransfer_DifferentShop(t *testing.T) {
 	warehouseRepo := mocks.NewMockWarehouseRepository(t)
 	productStockRepo := mocks.NewMockProductStockRepository(t)
 	movementRepo := mocks.NewMockMovementRepository(t)
-	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo, outboxRepo)
 
 	fromW := &domain.WareHouse{ID: uuid.New(), ShopID: uuid.New(), IsActive: true}
 	toW := &domain.WareHouse{ID: uuid.New(), ShopID: uuid.New(), IsActive: true}
@@ -126,7 +174,8 @@ func TestWarehouseTransfer_CreateTransfer_InactiveDestination(t *testing.T) {
 	warehouseRepo := mocks.NewMockWarehouseRepository(t)
 	productStockRepo := mocks.NewMockProductStockRepository(t)
 	movementRepo := mocks.NewMockMovementRepository(t)
-	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo, outboxRepo)
 
 	shopID := uuid.New()
 	fromW := &domain.WareHouse{ID: uuid.New(), ShopID: shopID, IsActive: true}
@@ -147,23 +196,28 @@ func TestWarehouseTransfer_ExecuteTransfer_Success(t *testing.T) {
 	warehouseRepo := mocks.NewMockWarehouseRepository(t)
 	productStockRepo := mocks.NewMockProductStockRepository(t)
 	movementRepo := mocks.NewMockMovementRepository(t)
-	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo, outboxRepo)
 
 	transferID := uuid.New()
 	fromW := uuid.New()
 	toW := uuid.New()
 	productID := uuid.New()
+	shopID := uuid.New()
 	items := []domain.WarehouseTransferItem{{ID: uuid.New(), TransferID: transferID, ProductID: productID, Qty: 3}}
 	transfer := &domain.WarehouseTransfer{ID: transferID, FromWarehouseID: fromW, ToWarehouseID: toW, Status: domain.TransferStatusRequested, Items: items}
 
 	transferRepo.EXPECT().GetByID(ctx, transferID).Return(transfer, nil)
+	warehouseRepo.EXPECT().Retrieve(ctx, fromW).Return(&domain.WareHouse{ID: fromW, ShopID: shopID}, nil)
 	productStockRepo.EXPECT().TryReserveStock(ctx, mock.Anything, productID, fromW, int32(3)).Return(true, nil)
 	movementRepo.EXPECT().Append(ctx, mock.Anything, productID, fromW, mock.Anything, 3, mock.Anything, transferID).Return(nil)
 	productStockRepo.EXPECT().CommitStock(ctx, mock.Anything, productID, fromW, int32(3)).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, domain.StockDecreased{ShopID: shopID, ProductID: productID, WarehouseID: fromW, Qty: 3, Reason: "TRANSFER"}).Return(nil)
 	transferRepo.EXPECT().UpdateStatus(ctx, mock.Anything, transferID, domain.TransferStatusInTransit).Return(nil)
 	movementRepo.EXPECT().Append(ctx, mock.Anything, productID, toW, mock.Anything, 3, mock.Anything, transferID).Return(nil)
 	productStockRepo.EXPECT().AddStock(ctx, mock.Anything, productID, toW, int32(3)).Return(nil)
 	transferRepo.EXPECT().UpdateStatus(ctx, mock.Anything, transferID, domain.TransferStatusCompleted).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.TransferCompleted")).Return(nil)
 
 	err := uc.ExecuteTransfer(ctx, transferID)
 	assert.NoError(t, err)
@@ -176,7 +230,8 @@ func TestWarehouseTransfer_ExecuteTransfer_InsufficientStock(t *testing.T) {
 	warehouseRepo := mocks.NewMockWarehouseRepository(t)
 	productStockRepo := mocks.NewMockProductStockRepository(t)
 	movementRepo := mocks.NewMockMovementRepository(t)
-	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo, outboxRepo)
 
 	transferID := uuid.New()
 	fromW := uuid.New()
@@ -186,6 +241,7 @@ func TestWarehouseTransfer_ExecuteTransfer_InsufficientStock(t *testing.T) {
 	transfer := &domain.WarehouseTransfer{ID: transferID, FromWarehouseID: fromW, ToWarehouseID: toW, Status: domain.TransferStatusRequested, Items: items}
 
 	transferRepo.EXPECT().GetByID(ctx, transferID).Return(transfer, nil)
+	warehouseRepo.EXPECT().Retrieve(ctx, fromW).Return(&domain.WareHouse{ID: fromW}, nil)
 	productStockRepo.EXPECT().TryReserveStock(ctx, mock.Anything, productID, fromW, int32(4)).Return(false, nil)
 
 	err := uc.ExecuteTransfer(ctx, transferID)
@@ -199,7 +255,8 @@ func TestWarehouseTransfer_UpdateTransferStatus_ExecuteInTransit(t *testing.T) {
 	warehouseRepo := mocks.NewMockWarehouseRepository(t)
 	productStockRepo := mocks.NewMockProductStockRepository(t)
 	movementRepo := mocks.NewMockMovementRepository(t)
-	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo, outboxRepo)
 
 	transferID := uuid.New()
 	fromW := uuid.New()
@@ -212,13 +269,16 @@ func TestWarehouseTransfer_UpdateTransferStatus_ExecuteInTransit(t *testing.T) {
 	transferRepo.EXPECT().GetByID(ctx, transferID).Return(transfer, nil).Once()
 	// ExecuteTransfer internals
 	transferRepo.EXPECT().GetByID(ctx, transferID).Return(transfer, nil).Once()
+	warehouseRepo.EXPECT().Retrieve(ctx, fromW).Return(&domain.WareHouse{ID: fromW}, nil)
 	productStockRepo.EXPECT().TryReserveStock(ctx, mock.Anything, productID, fromW, int32(1)).Return(true, nil)
 	movementRepo.EXPECT().Append(ctx, mock.Anything, productID, fromW, mock.Anything, 1, mock.Anything, transferID).Return(nil)
 	productStockRepo.EXPECT().CommitStock(ctx, mock.Anything, productID, fromW, int32(1)).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.StockDecreased")).Return(nil)
 	transferRepo.EXPECT().UpdateStatus(ctx, mock.Anything, transferID, domain.TransferStatusInTransit).Return(nil)
 	movementRepo.EXPECT().Append(ctx, mock.Anything, productID, toW, mock.Anything, 1, mock.Anything, transferID).Return(nil)
 	productStockRepo.EXPECT().AddStock(ctx, mock.Anything, productID, toW, int32(1)).Return(nil)
 	transferRepo.EXPECT().UpdateStatus(ctx, mock.Anything, transferID, domain.TransferStatusCompleted).Return(nil)
+	outboxRepo.EXPECT().Append(ctx, mock.Anything, mock.AnythingOfType("domain.TransferCompleted")).Return(nil)
 
 	err := uc.UpdateTransferStatus(ctx, transferID, domain.UpdateTransferStatusRequest{Status: domain.TransferStatusInTransit})
 	assert.NoError(t, err)
@@ -231,7 +291,8 @@ func TestWarehouseTransfer_UpdateTransferStatus_InvalidTransition(t *testing.T)
 	warehouseRepo := mocks.NewMockWarehouseRepository(t)
 	productStockRepo := mocks.NewMockProductStockRepository(t)
 	movementRepo := mocks.NewMockMovementRepository(t)
-	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo)
+	outboxRepo := mocks.NewMockOutboxRepository(t)
+	uc := NewWarehouseTransferUsecase(db, transferRepo, warehouseRepo, productStockRepo, movementRepo, outboxRepo)
 
 	transferID := uuid.New()
 	transfer := &domain.WarehouseTransfer{ID: transferID, Status: domain.TransferStatusCompleted}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/pkg/tracing"
 	"github.com/google/uuid"
 )
 
@@ -15,6 +16,9 @@ type warehouseUsecase struct {
 
 // Create implements domain.WarehouseUsecase.
 func (w *warehouseUsecase) Create(ctx context.Context, payload domain.WarehouseCreateRequest) error {
+	ctx, span := tracing.Start(ctx, "WarehouseUsecase.Create")
+	defer span.End()
+
 	shopID, err := uuid.Parse(payload.ShopID)
 	if err != nil {
 		return err
@@ -31,11 +35,17 @@ func (w *warehouseUsecase) Create(ctx context.Context, payload domain.WarehouseC
 
 // Delete implements domain.WarehouseUsecase.
 func (w *warehouseUsecase) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "WarehouseUsecase.Delete", tracing.WarehouseID(id))
+	defer span.End()
+
 	return w.warehouseRepo.Delete(ctx, id)
 }
 
 // Retrieve implements domain.WarehouseUsecase.
 func (w *warehouseUsecase) Retrieve(ctx context.Context, id uuid.UUID) (*domain.WareHouseFormatter, error) {
+	ctx, span := tracing.Start(ctx, "WarehouseUsecase.Retrieve", tracing.WarehouseID(id))
+	defer span.End()
+
 	warehouse, err := w.warehouseRepo.Retrieve(ctx, id)
 	if err != nil {
 		return nil, err
@@ -53,6 +63,9 @@ func (w *warehouseUsecase) Retrieve(ctx context.Context, id uuid.UUID) (*domain.
 
 // Update implements domain.WarehouseUsecase.
 func (w *warehouseUsecase) Update(ctx context.Context, id uuid.UUID, payload domain.WarehouseCreateRequest) error {
+	ctx, span := tracing.Start(ctx, "WarehouseUsecase.Update", tracing.WarehouseID(id))
+	defer span.End()
+
 	shopID, err := uuid.Parse(payload.ShopID)
 	if err != nil {
 		return err
@@ -70,6 +83,9 @@ func (w *warehouseUsecase) Update(ctx context.Context, id uuid.UUID, payload dom
 
 // SetActive implements domain.WarehouseUsecase.
 func (w *warehouseUsecase) SetActive(ctx context.Context, id uuid.UUID, isActive bool) error {
+	ctx, span := tracing.Start(ctx, "WarehouseUsecase.SetActive", tracing.WarehouseID(id))
+	defer span.End()
+
 	// If trying to deactivate, check for active transfers
 	if !isActive {
 		activeTransfers, err := w.transferRepo.GetActiveTransfersByWarehouse(ctx, id)
@@ -86,6 +102,9 @@ func (w *warehouseUsecase) SetActive(ctx context.Context, id uuid.UUID, isActive
 
 // GetByShopID implements domain.WarehouseUsecase.
 func (w *warehouseUsecase) GetByShopID(ctx context.Context, shopID uuid.UUID) ([]domain.WareHouseFormatter, error) {
+	ctx, span := tracing.Start(ctx, "WarehouseUsecase.GetByShopID", tracing.ShopID(shopID))
+	defer span.End()
+
 	warehouses, err := w.warehouseRepo.GetByShopID(ctx, shopID)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,344 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/crypto"
+	"github.com/dyaksa/warehouse/infrastructure/pqsql"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	"github.com/dyaksa/warehouse/pkg/metrics"
+	"github.com/dyaksa/warehouse/pkg/paginator"
+	"github.com/dyaksa/warehouse/pkg/tokenutils"
+	"github.com/dyaksa/warehouse/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+const (
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = time.Hour
+	webhookClaimMargin = time.Minute
+)
+
+type webhookUsecase struct {
+	db          pqsql.Database
+	webhookRepo domain.WebhookRepository
+	shopRepo    domain.ShopRepository
+	sender      domain.WebhookSender
+	crypto      crypto.Crypto
+	env         *bootstrap.Env
+	logger      log.Logger
+}
+
+// Create implements domain.WebhookUsecase.
+func (w *webhookUsecase) Create(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, payload domain.CreateWebhookRequest) (domain.WebhookSubscriptionCreated, error) {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.Create", tracing.UserID(userID), tracing.ShopID(shopID))
+	defer span.End()
+
+	var created domain.WebhookSubscriptionCreated
+
+	if u, err := url.Parse(payload.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return created, errx.E(errx.CodeValidation, "webhook url must be an absolute http(s) url", errx.Op("webhookUsecase.Create"), err)
+	}
+
+	eventTypes, err := normalizeEventTypes(payload.EventTypes)
+	if err != nil {
+		return created, errx.E(errx.CodeValidation, err.Error(), errx.Op("webhookUsecase.Create"), err)
+	}
+
+	if err := ensureShopOwner(ctx, w.shopRepo, shopID, userID, "webhookUsecase.Create"); err != nil {
+		return created, err
+	}
+
+	if err := w.sender.CheckURL(ctx, payload.URL); err != nil {
+		return created, errx.E(errx.CodeValidation, "webhook url is not reachable from the public internet", errx.Op("webhookUsecase.Create"), err)
+	}
+
+	secret, err := tokenutils.NewWebhookSecret()
+	if err != nil {
+		return created, errx.E(errx.CodeInternal, "failed to generate webhook secret", errx.Op("webhookUsecase.Create"), err)
+	}
+
+	sub := domain.WebhookSubscription{
+		ID:               uuid.New(),
+		ShopID:           shopID,
+		UserID:           userID,
+		URL:              payload.URL,
+		Secret:           w.crypto.Encrypt(secret),
+		SecretKeyVersion: w.crypto.KeyVersion(),
+		EventTypes:       eventTypes,
+	}
+
+	_, err = w.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		return nil, w.webhookRepo.Create(ctx, tx, &sub)
+	})
+	if err != nil {
+		return created, errx.E(errx.CodeInternal, "failed to save webhook", errx.Op("webhookUsecase.Create"), err)
+	}
+
+	return domain.WebhookSubscriptionCreated{WebhookSubscription: sub, SigningSecret: secret}, nil
+}
+
+// List implements domain.WebhookUsecase.
+func (w *webhookUsecase) List(ctx context.Context, userID uuid.UUID, shopID uuid.UUID) ([]domain.WebhookSubscription, error) {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.List", tracing.UserID(userID), tracing.ShopID(shopID))
+	defer span.End()
+
+	subs, err := w.webhookRepo.ListByShop(ctx, shopID, userID)
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to list webhooks", errx.Op("webhookUsecase.List"), err)
+	}
+
+	return subs, nil
+}
+
+// Delete implements domain.WebhookUsecase.
+func (w *webhookUsecase) Delete(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.Delete", tracing.UserID(userID), tracing.ShopID(shopID))
+	defer span.End()
+
+	if _, err := w.load(ctx, userID, shopID, id, "webhookUsecase.Delete"); err != nil {
+		return err
+	}
+
+	_, err := w.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		return nil, w.webhookRepo.Delete(ctx, tx, id)
+	})
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to delete webhook", errx.Op("webhookUsecase.Delete"), err)
+	}
+
+	return nil
+}
+
+// ListDeliveries implements domain.WebhookUsecase.
+func (w *webhookUsecase) ListDeliveries(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, id uuid.UUID, pagination paginator.PaginationRequest) (*paginator.PaginationResult[domain.WebhookDelivery], error) {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.ListDeliveries", tracing.UserID(userID), tracing.ShopID(shopID))
+	defer span.End()
+
+	if _, err := w.load(ctx, userID, shopID, id, "webhookUsecase.ListDeliveries"); err != nil {
+		return nil, err
+	}
+
+	pagination.ValidateAndSetDefault()
+
+	deliveries, total, err := w.webhookRepo.ListDeliveries(ctx, id, pagination.Limit, pagination.GetOffset())
+	if err != nil {
+		return nil, errx.E(errx.CodeInternal, "failed to list webhook deliveries", errx.Op("webhookUsecase.ListDeliveries"), err)
+	}
+
+	return paginator.NewPaginationResult(deliveries, total, pagination), nil
+}
+
+// Redeliver implements domain.WebhookUsecase. Any delivery can be sent again, including
+// successful ones, e.g. after the receiver lost data.
+func (w *webhookUsecase) Redeliver(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, id uuid.UUID, deliveryID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.Redeliver", tracing.UserID(userID), tracing.ShopID(shopID))
+	defer span.End()
+
+	if _, err := w.load(ctx, userID, shopID, id, "webhookUsecase.Redeliver"); err != nil {
+		return err
+	}
+
+	_, err := w.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		if err := w.webhookRepo.Redeliver(ctx, tx, id, deliveryID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errx.E(errx.CodeNotFound, "webhook delivery not found", errx.Op("webhookUsecase.Redeliver"), err)
+			}
+			return nil, errx.E(errx.CodeInternal, "failed to requeue webhook delivery", errx.Op("webhookUsecase.Redeliver"), err)
+		}
+
+		return nil, nil
+	})
+
+	return err
+}
+
+// Enqueue implements domain.WebhookUsecase.
+func (w *webhookUsecase) Enqueue(ctx context.Context, event domain.OutboxEvent) error {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.Enqueue")
+	defer span.End()
+
+	if !slices.Contains(domain.WebhookEventTypes, event.EventType) {
+		return nil
+	}
+
+	var owner struct {
+		ShopID uuid.UUID `json:"shop_id"`
+	}
+	if err := json.Unmarshal(event.Payload, &owner); err != nil {
+		return errx.E(errx.CodeInternal, "failed to read event shop", errx.Op("webhookUsecase.Enqueue"), err)
+	}
+
+	subscriberIDs, err := w.webhookRepo.SubscriberIDs(ctx, owner.ShopID, event.EventType)
+	if err != nil {
+		return errx.E(errx.CodeInternal, "failed to find webhook subscribers", errx.Op("webhookUsecase.Enqueue"), err)
+	}
+
+	for _, subscriptionID := range subscriberIDs {
+		if err := w.webhookRepo.EnqueueDelivery(ctx, &domain.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: subscriptionID,
+			EventID:        event.ID,
+			EventType:      event.EventType,
+			Payload:        event.Payload,
+		}); err != nil {
+			return errx.E(errx.CodeInternal, "failed to enqueue webhook delivery", errx.Op("webhookUsecase.Enqueue"), err)
+		}
+	}
+
+	return nil
+}
+
+// DeliverBatch implements domain.WebhookUsecase. Any 2xx response counts as delivered.
+// Deliveries are claimed in one short transaction and sent after it commits, so no row lock is
+// held while waiting on a subscriber; each outcome is then recorded in its own transaction.
+func (w *webhookUsecase) DeliverBatch(ctx context.Context, limit int) (int, error) {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.DeliverBatch")
+	defer span.End()
+
+	maxAttempts := w.env.WebhookMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 8
+	}
+
+	// The lease outlasts sending the whole batch, after which an unrecorded delivery is retried.
+	lease := time.Duration(limit)*time.Duration(w.env.WebhookTimeout)*time.Second + webhookClaimMargin
+
+	res, err := w.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		return w.webhookRepo.ClaimDueDeliveries(ctx, tx, limit, lease, func(dispatch *domain.WebhookDispatch) (err error) {
+			dispatch.Secret, err = w.crypto.DecryptVersion(dispatch.SecretKeyVersion, "")
+			return err
+		})
+	})
+	if err != nil {
+		return 0, errx.E(errx.CodeInternal, "failed to claim webhook deliveries", errx.Op("webhookUsecase.DeliverBatch"), err)
+	}
+
+	dispatches, _ := res.([]domain.WebhookDispatch)
+
+	delivered := 0
+	for _, dispatch := range dispatches {
+		ok, err := w.deliver(ctx, dispatch, maxAttempts)
+		if err != nil {
+			return delivered, err
+		}
+
+		if ok {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// deliver sends one claimed delivery and records the outcome, reporting whether it succeeded.
+func (w *webhookUsecase) deliver(ctx context.Context, dispatch domain.WebhookDispatch, maxAttempts int) (bool, error) {
+	statusCode, sendErr := w.sender.Send(ctx, dispatch.URL, dispatch.Secret.To(), dispatch.WebhookDelivery)
+	if sendErr == nil && statusCode >= 200 && statusCode < 300 {
+		_, err := w.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+			return nil, w.webhookRepo.MarkSucceeded(ctx, tx, dispatch.ID, statusCode)
+		})
+		if err != nil {
+			return false, errx.E(errx.CodeInternal, "failed to mark webhook delivered", errx.Op("webhookUsecase.DeliverBatch"), err)
+		}
+
+		metrics.WebhookDeliveries.WithLabelValues("succeeded").Inc()
+		return true, nil
+	}
+
+	var code *int
+	lastError := fmt.Sprintf("unexpected status %d", statusCode)
+	if sendErr != nil {
+		lastError = sendErr.Error()
+	} else {
+		code = &statusCode
+	}
+
+	attempts := dispatch.Attempts + 1
+	if attempts >= maxAttempts {
+		log.WithContext(ctx, w.logger).Warn("webhook delivery exhausted its retries",
+			log.String("delivery_id", dispatch.ID.String()),
+			log.String("subscription_id", dispatch.SubscriptionID.String()),
+			log.String("error", lastError),
+		)
+
+		_, err := w.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+			return nil, w.webhookRepo.MarkDead(ctx, tx, dispatch.ID, code, lastError)
+		})
+		if err != nil {
+			return false, errx.E(errx.CodeInternal, "failed to mark webhook dead", errx.Op("webhookUsecase.DeliverBatch"), err)
+		}
+
+		metrics.WebhookDeliveries.WithLabelValues("dead").Inc()
+		return false, nil
+	}
+
+	nextAttemptAt := time.Now().Add(backoff(attempts, webhookBaseBackoff, webhookMaxBackoff))
+	_, err := w.db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) (any, error) {
+		return nil, w.webhookRepo.MarkRetry(ctx, tx, dispatch.ID, code, lastError, nextAttemptAt)
+	})
+	if err != nil {
+		return false, errx.E(errx.CodeInternal, "failed to schedule webhook retry", errx.Op("webhookUsecase.DeliverBatch"), err)
+	}
+
+	metrics.WebhookDeliveries.WithLabelValues("retry").Inc()
+	return false, nil
+}
+
+func (w *webhookUsecase) load(ctx context.Context, userID uuid.UUID, shopID uuid.UUID, id uuid.UUID, op string) (*domain.WebhookSubscription, error) {
+	sub, err := w.webhookRepo.Get(ctx, shopID, userID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errx.E(errx.CodeNotFound, "webhook not found", errx.Op(op), err)
+		}
+		return nil, errx.E(errx.CodeInternal, "failed to load webhook", errx.Op(op), err)
+	}
+
+	return sub, nil
+}
+
+// normalizeEventTypes rejects events that cannot be subscribed to and drops duplicates.
+func normalizeEventTypes(eventTypes []domain.EventType) ([]domain.EventType, error) {
+	out := make([]domain.EventType, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if !slices.Contains(domain.WebhookEventTypes, t) {
+			return nil, errors.New("unknown event type " + string(t))
+		}
+
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+
+	return out, nil
+}
+
+func NewWebhookUsecase(
+	db pqsql.Database,
+	webhookRepo domain.WebhookRepository,
+	shopRepo domain.ShopRepository,
+	sender domain.WebhookSender,
+	crypto crypto.Crypto,
+	env *bootstrap.Env,
+	logger log.Logger,
+) domain.WebhookUsecase {
+	return &webhookUsecase{
+		db:          db,
+		webhookRepo: webhookRepo,
+		shopRepo:    shopRepo,
+		sender:      sender,
+		crypto:      crypto,
+		env:         env,
+		logger:      logger,
+	}
+}
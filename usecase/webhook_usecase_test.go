@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dyaksa/warehouse/bootstrap"
+	"github.com/dyaksa/warehouse/domain"
+	"github.com/dyaksa/warehouse/infrastructure/webhook"
+	mocks "github.com/dyaksa/warehouse/mocks/repository"
+	"github.com/dyaksa/warehouse/pkg/errx"
+	"github.com/dyaksa/warehouse/pkg/log"
+	webhooksig "github.com/dyaksa/warehouse/pkg/webhook"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testWebhookSecret = "whsec_test"
+
+func newTestWebhookUsecase(webhookRepo *mocks.MockWebhookRepository, shopRepo *mocks.MockShopRepository) domain.WebhookUsecase {
+	return NewWebhookUsecase(&fakeDB{}, webhookRepo, shopRepo, webhook.NewHTTPSender(time.Second, true), simpleCryptoStub{}, &bootstrap.Env{WebhookMaxAttempts: 3}, log.Nop())
+}
+
+func testDispatch(url string, attempts int) domain.WebhookDispatch {
+	return domain.WebhookDispatch{
+		WebhookDelivery: domain.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: uuid.New(),
+			EventID:        uuid.New(),
+			EventType:      domain.EventOrderPaymentConfirmed,
+			Payload:        json.RawMessage(`{"order_id":"1"}`),
+			Attempts:       attempts,
+			CreatedAt:      time.Now(),
+		},
+		URL:    url,
+		Secret: simpleCryptoStub{}.Encrypt(testWebhookSecret),
+	}
+}
+
+func TestWebhook_DeliverBatch_SignedDelivery(t *testing.T) {
+	ctx := context.Background()
+	webhookRepo := mocks.NewMockWebhookRepository(t)
+	uc := newTestWebhookUsecase(webhookRepo, nil)
+
+	var received struct {
+		ID   string          `json:"id"`
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := webhooksig.Verify(testWebhookSecret, r.Header.Get(webhooksig.HeaderSignature), r.Header.Get(webhooksig.HeaderTimestamp), body, time.Now(), time.Minute)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	dispatch := testDispatch(receiver.URL, 0)
+	webhookRepo.EXPECT().ClaimDueDeliveries(ctx, mock.Anything, 20, mock.Anything, mock.Anything).Return([]domain.WebhookDispatch{dispatch}, nil)
+	webhookRepo.EXPECT().MarkSucceeded(ctx, mock.Anything, dispatch.ID, http.StatusNoContent).Return(nil)
+
+	delivered, err := uc.DeliverBatch(ctx, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, dispatch.EventID.String(), received.ID)
+	assert.Equal(t, string(domain.EventOrderPaymentConfirmed), received.Type)
+	assert.JSONEq(t, `{"order_id":"1"}`, string(received.Data))
+}
+
+func TestWebhook_DeliverBatch_RetryThenDead(t *testing.T) {
+	ctx := context.Background()
+	webhookRepo := mocks.NewMockWebhookRepository(t)
+	uc := newTestWebhookUsecase(webhookRepo, nil)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	retry := testDispatch(receiver.URL, 0)
+	last := testDispatch(receiver.URL, 2) // third attempt with WebhookMaxAttempts 3
+	status := http.StatusInternalServerError
+
+	webhookRepo.EXPECT().ClaimDueDeliveries(ctx, mock.Anything, 20, mock.Anything, mock.Anything).Return([]domain.WebhookDispatch{retry, last}, nil)
+	webhookRepo.EXPECT().MarkRetry(ctx, mock.Anything, retry.ID, &status, "unexpected status 500", mock.MatchedBy(func(next time.Time) bool {
+		return next.After(time.Now().Add(25*time.Second)) && next.Before(time.Now().Add(35*time.Second))
+	})).Return(nil)
+	webhookRepo.EXPECT().MarkDead(ctx, mock.Anything, last.ID, &status, "unexpected status 500").Return(nil)
+
+	delivered, err := uc.DeliverBatch(ctx, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+}
+
+func TestWebhook_DeliverBatch_Unreachable(t *testing.T) {
+	ctx := context.Background()
+	webhookRepo := mocks.NewMockWebhookRepository(t)
+	uc := newTestWebhookUsecase(webhookRepo, nil)
+
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	receiver.Close() // nothing listens on the URL any more
+
+	dispatch := testDispatch(receiver.URL, 0)
+	webhookRepo.EXPECT().ClaimDueDeliveries(ctx, mock.Anything, 20, mock.Anything, mock.Anything).Return([]domain.WebhookDispatch{dispatch}, nil)
+	webhookRepo.EXPECT().MarkRetry(ctx, mock.Anything, dispatch.ID, (*int)(nil), mock.Anything, mock.Anything).Return(nil)
+
+	_, err := uc.DeliverBatch(ctx, 20)
+	assert.NoError(t, err)
+}
+
+func TestWebhook_Enqueue_FansOutToSubscribers(t *testing.T) {
+	ctx := context.Background()
+	webhookRepo := mocks.NewMockWebhookRepository(t)
+	uc := newTestWebhookUsecase(webhookRepo, nil)
+
+	shopID := uuid.New()
+	subA, subB := uuid.New(), uuid.New()
+	payload, _ := json.Marshal(domain.OrderExpired{OrderID: uuid.New(), ShopID: shopID})
+	event := domain.OutboxEvent{ID: uuid.New(), EventType: domain.EventOrderExpired, Payload: payload}
+
+	webhookRepo.EXPECT().SubscriberIDs(ctx, shopID, domain.EventOrderExpired).Return([]uuid.UUID{subA, subB}, nil)
+	for _, sub := range []uuid.UUID{subA, subB} {
+		webhookRepo.EXPECT().EnqueueDelivery(ctx, mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+			return d.SubscriptionID == sub && d.EventID == event.ID
+		})).Return(nil)
+	}
+
+	assert.NoError(t, uc.Enqueue(ctx, event))
+}
+
+func TestWebhook_Enqueue_IgnoresInternalEvents(t *testing.T) {
+	uc := newTestWebhookUsecase(mocks.NewMockWebhookRepository(t), nil)
+
+	err := uc.Enqueue(context.Background(), domain.OutboxEvent{ID: uuid.New(), EventType: domain.EventTransferCompleted, Payload: json.RawMessage(`{}`)})
+	assert.NoError(t, err)
+}
+
+func TestWebhook_Create(t *testing.T) {
+	ctx := context.Background()
+	webhookRepo := mocks.NewMockWebhookRepository(t)
+	shopRepo := mocks.NewMockShopRepository(t)
+	uc := newTestWebhookUsecase(webhookRepo, shopRepo)
+
+	userID, shopID := uuid.New(), uuid.New()
+	payload := domain.CreateWebhookRequest{
+		URL:        "https://erp.example.com/hooks",
+		EventTypes: []domain.EventType{domain.EventOrderPaymentConfirmed, domain.EventOrderPaymentConfirmed, domain.EventStockDecreased},
+	}
+
+	shopRepo.EXPECT().Retrieve(ctx, shopID).Return(&domain.Shop{ID: shopID, OwnerID: &userID}, nil)
+	webhookRepo.EXPECT().Create(ctx, mock.Anything, mock.Anything).Return(nil)
+
+	created, err := uc.Create(ctx, userID, shopID, payload)
+	assert.NoError(t, err)
+	assert.Contains(t, created.SigningSecret, "whsec_")
+	assert.Equal(t, []domain.EventType{domain.EventOrderPaymentConfirmed, domain.EventStockDecreased}, created.EventTypes)
+}
+
+func TestWebhook_Create_Rejects(t *testing.T) {
+	uc := newTestWebhookUsecase(mocks.NewMockWebhookRepository(t), mocks.NewMockShopRepository(t))
+
+	cases := []domain.CreateWebhookRequest{
+		{URL: "ftp://erp.example.com/hooks", EventTypes: []domain.EventType{domain.EventOrderExpired}},
+		{URL: "https://erp.example.com/hooks", EventTypes: []domain.EventType{domain.EventTransferCompleted}},
+	}
+	for _, payload := range cases {
+		_, err := uc.Create(context.Background(), uuid.New(), uuid.New(), payload)
+		assert.Error(t, err)
+	}
+}
+
+func TestWebhook_Create_NotShopOwner(t *testing.T) {
+	ctx := context.Background()
+	shopRepo := mocks.NewMockShopRepository(t)
+	uc := newTestWebhookUsecase(mocks.NewMockWebhookRepository(t), shopRepo)
+	ownerID, shopID := uuid.New(), uuid.New()
+
+	shopRepo.EXPECT().Retrieve(ctx, shopID).Return(&domain.Shop{ID: shopID, OwnerID: &ownerID}, nil)
+
+	_, err := uc.Create(ctx, uuid.New(), shopID, domain.CreateWebhookRequest{
+		URL:        "https://erp.example.com/hooks",
+		EventTypes: []domain.EventType{domain.EventOrderExpired},
+	})
+	assert.True(t, errx.IsCode(err, errx.CodePermission))
+}
+
+func TestWebhook_Create_RejectsInternalAddresses(t *testing.T) {
+	ctx := context.Background()
+	shopRepo := mocks.NewMockShopRepository(t)
+	uc := NewWebhookUsecase(&fakeDB{}, mocks.NewMockWebhookRepository(t), shopRepo, webhook.NewHTTPSender(time.Second, false), simpleCryptoStub{}, &bootstrap.Env{}, log.Nop())
+	userID, shopID := uuid.New(), uuid.New()
+
+	shopRepo.EXPECT().Retrieve(ctx, shopID).Return(&domain.Shop{ID: shopID, OwnerID: &userID}, nil)
+
+	for _, u := range []string{"http://127.0.0.1/hooks", "http://[::1]:8080/hooks", "http://169.254.169.254/latest", "https://10.1.2.3/hooks", "http://100.64.0.1/hooks"} {
+		_, err := uc.Create(ctx, userID, shopID, domain.CreateWebhookRequest{
+			URL:        u,
+			EventTypes: []domain.EventType{domain.EventOrderExpired},
+		})
+		assert.True(t, errx.IsCode(err, errx.CodeValidation), u)
+	}
+}
+
+func TestWebhook_DeliverBatch_RefusesInternalAddress(t *testing.T) {
+	ctx := context.Background()
+	webhookRepo := mocks.NewMockWebhookRepository(t)
+	uc := NewWebhookUsecase(&fakeDB{}, webhookRepo, nil, webhook.NewHTTPSender(time.Second, false), simpleCryptoStub{}, &bootstrap.Env{WebhookMaxAttempts: 3}, log.Nop())
+
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	// The URL passed the check when it was registered but now points at loopback.
+	dispatch := testDispatch(receiver.URL, 0)
+	webhookRepo.EXPECT().ClaimDueDeliveries(ctx, mock.Anything, 20, mock.Anything, mock.Anything).Return([]domain.WebhookDispatch{dispatch}, nil)
+	webhookRepo.EXPECT().MarkRetry(ctx, mock.Anything, dispatch.ID, (*int)(nil), mock.MatchedBy(func(lastError string) bool {
+		return strings.Contains(lastError, webhook.ErrForbiddenAddress.Error())
+	}), mock.Anything).Return(nil)
+
+	_, err := uc.DeliverBatch(ctx, 20)
+	assert.NoError(t, err)
+	assert.False(t, called)
+}